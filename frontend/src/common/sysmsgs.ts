@@ -6,6 +6,7 @@ import ProgressNotify from '@/components/progress-notify/progress-notify.vue';
 
 export function initSysMsgs() {
     registerDbSqlExecProgress();
+    registerDbDataGenProgress();
 }
 
 const sqlExecNotifyMap: Map<string, any> = new Map();
@@ -45,3 +46,40 @@ function registerDbSqlExecProgress() {
         }
     });
 }
+
+const dataGenNotifyMap: Map<string, any> = new Map();
+
+function registerDbDataGenProgress() {
+    syssocket.registerMsgHandler('dataGenProgress', function (message: any) {
+        const content = JSON.parse(message.msg);
+        const id = content.id;
+        let progress = dataGenNotifyMap.get(id);
+        if (content.terminated) {
+            if (progress != undefined) {
+                progress.notification?.close();
+                dataGenNotifyMap.delete(id);
+            }
+            return;
+        }
+
+        if (progress == undefined) {
+            progress = {
+                props: reactive(buildProgressProps()),
+                notification: undefined,
+            };
+        }
+
+        progress.props.progress.title = content.title;
+        progress.props.progress.executedStatements = `${content.inserted} / ${content.total}`;
+        if (!dataGenNotifyMap.has(id)) {
+            progress.notification = ElNotification({
+                duration: 0,
+                title: message.title,
+                message: h(ProgressNotify, progress.props),
+                type: syssocket.getMsgType(message.type),
+                showClose: false,
+            });
+            dataGenNotifyMap.set(id, progress);
+        }
+    });
+}
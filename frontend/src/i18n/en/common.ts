@@ -232,17 +232,38 @@ export default {
         machineScriptDelete: 'Script-Delete',
         machineScriptRun: 'Script-Run',
         machineKillprocess: 'Kill Process',
+        machineServiceStart: 'Service-Start',
+        machineServiceStop: 'Service-Stop',
+        machineServiceRestart: 'Service-Restart',
+        machineServiceEnable: 'Service-Enable',
+        machineServiceDisable: 'Service-Disable',
+        machineContainerOp: 'Container-Operate',
+        machineContainerRemove: 'Container-Remove',
+        machineContainerExec: 'Container-Terminal',
+        machineContainerLogs: 'Container-Logs',
+        machineHostKey: 'Host Key Management',
         machineCronJob: 'Cron Job',
         machineCronJobSvae: 'Cron Job-Save',
         machineCronJobDelete: 'Cron Job-Delete',
         machineSecurityConfig: 'Security Config',
         machineSecurityCmdSvae: 'Cmd Config-Save',
         machineSecurityCmdDelete: 'Cmd Config-Delete',
+        machineAlertSave: 'Alert Rule-Save',
+        machineAlertDelete: 'Alert Rule-Delete',
+        machineCmdBatch: 'Batch Run Command',
+        machineFileTransfer: 'Machine File Transfer',
+        machineFileTail: 'Machine File-Tail',
+        machinePortForward: 'Machine Port Forward',
+        machineTerminalMonitor: 'Terminal Session-Monitor',
+        machineTerminalKill: 'Terminal Session-Terminate',
 
         dbms: 'DBMS',
         dbDataOp: 'Data Operation',
         dbDataOpBase: 'Base Permission',
         dbDataOpSqlScriptRun: 'SQL Script Run',
+        dbDataOpDataGen: 'Test Data Generate',
+        dbSqlReportSave: 'Save SQL Report',
+        dbSqlReportDelete: 'Delete SQL Report',
         dbInstance: 'DB Instance',
         dbInstanceBase: 'Base Permission',
         dbInstanceSave: 'Save Instance',
@@ -257,6 +278,7 @@ export default {
         dbDataSyncChangeStatus: 'Enable/Disable Sync Task',
         dbDataSyncLog: 'Sync Log',
         dbTransfer: 'DB Transfer',
+        dbSqlReport: 'SQL Report',
         dbTransferBase: 'Base Permission',
         dbTransferSave: 'Save Transfer Task',
         dbTransferDelete: 'Delete Transfer Task',
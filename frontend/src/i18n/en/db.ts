@@ -41,6 +41,37 @@ export default {
         createTable: 'Create Table',
         tableOp: 'Table Operation',
         copyTable: 'Copy Table',
+        dataGen: 'Generate Test Data',
+        dataGenNum: 'Rows',
+        dataGenBatchSize: 'Batch Size',
+        dataGenGenerator: 'Generator',
+        dataGenRule: 'Rule',
+        dataGenMin: 'Min',
+        dataGenMax: 'Max',
+        dataGenLength: 'Length',
+        dataGenEnumValues: 'Optional values',
+        dataGenRefTable: 'Ref table',
+        dataGenRefColumn: 'Ref column',
+        dataGenRun: 'Generate',
+        dataGenRefEmpty: 'The ref table and column of column [{column}] cannot be empty',
+        dataGenSubmitted: 'The data generation task has been submitted, please check the progress in the notification',
+        dataGenProgress: 'Inserted',
+        dataGenSkip: 'Skip',
+        dataGenNull: 'Null',
+        dataGenFixed: 'Fixed value',
+        dataGenInt: 'Integer',
+        dataGenDecimal: 'Decimal',
+        dataGenString: 'Random string',
+        dataGenName: 'Name',
+        dataGenEmail: 'Email',
+        dataGenPhone: 'Phone',
+        dataGenUuid: 'UUID',
+        dataGenBool: 'Boolean',
+        dataGenDate: 'Date',
+        dataGenDatetime: 'Datetime',
+        dataGenTime: 'Time',
+        dataGenEnum: 'Enum',
+        dataGenRef: 'Foreign key reference',
         renameTable: 'Rename',
         editTable: 'Edit',
         delTable: 'Delete Table',
@@ -154,6 +185,7 @@ export default {
         runConfirm: 'Sure to run?',
         transferFileManage: 'Transfer file management',
         dbFileType: 'DB dialect file',
+        targetFileType: 'Target file type',
         targetDb: 'Target DB',
         fileDbType: 'SQL Dialect',
         transferFileRunDialogTitle: 'Specify the database to execute the sql file',
@@ -208,6 +240,48 @@ export default {
         notSelectSql: 'sql statement error, please enter the select statement',
         notOneSql: 'sql statement error, please enter a single query statement',
         notColumnSql: 'No field found. Check your sql',
+        syncTables: 'Sync Tables',
+        syncTable: 'Sync Table',
+        syncTableSort: 'Sort',
+        syncTableSortTips: 'Tables are synchronized in ascending order, tables with dependencies should be placed first',
+        syncTableRequired: 'At least one sync table is required',
+        syncTableIncomplete: 'Sync table [{name}]: the data sql and target table cannot be empty',
+        syncTableDuplicate: 'Sync table [{name}]: the target table is duplicated',
+        syncTableNoFieldMap: 'Sync table [{name}]: please load and configure the field mapping',
+        loadFieldMap: 'Load Fields',
+        updateFieldValueSyncedTips: 'The current value is maintained by the sync task and cannot be modified',
+
+        // sql report
+        sqlReport: 'SQL Report',
+        sqlReportName: 'Report Name',
+        sqlReportSql: 'Saved SQL',
+        sqlReportSqlPlaceholder: 'Please select the saved sql of the target db',
+        sqlReportResultFormat: 'Result Format',
+        sqlReportMaxRows: 'Max Rows',
+        sqlReportMsgTmpl: 'Report Template',
+        sqlReportAlertMsgTmpl: 'Alert Template',
+        sqlReportReceivers: 'Receivers',
+        sqlReportRunConfirm: 'Are you sure to execute the report now?',
+
+        // saved sql
+        sqlName: 'SQL Name',
+        sqlFolder: 'Folder',
+        sqlFolderPlaceholder: 'Select or enter a folder',
+        sqlVersionRemark: 'Version Remark',
+        sqlParams: 'Named Params',
+        sqlParamsEmpty: 'No named params such as :name in the sql',
+        sqlParamName: 'Param',
+        sqlParamLabel: 'Label',
+        sqlParamType: 'Type',
+        sqlParamDefault: 'Default',
+        sqlParamRequired: 'Required',
+        sqlRunWithParams: 'Run With Params',
+        sqlVersions: 'Versions',
+        sqlVersionDiffLatest: 'Diff Latest',
+        sqlVersionDiffPrevious: 'Diff Previous',
+        sqlVersionNoDifference: 'No difference',
+        sqlShare: 'Share',
+        sqlShareNoTeam: 'You are not a member of any team',
 
         // enums
         getDbNamesModeAuto: 'Real-time get db',
@@ -99,6 +99,7 @@ export default {
         // FlowBizType
         dbSqlExec: 'DBMS-Run SQL',
         redisRunCmd: 'Redis-Run Cmd',
+        machineTermCmd: 'Machine-Terminal Cmd',
 
         // task
         approveNode: 'Approval Node',
@@ -37,9 +37,15 @@ export default {
 
         // MachineRec
         playback: 'Playback',
+        guacRecLoadFail: 'Failed to load the session recording',
         cmd: 'Command',
         execCmdRecord: 'Executive command record',
         execTime: 'Execution time',
+        termRecs: 'Terminal records',
+        cmdSearch: 'Command search',
+        cmdOffset: 'Playback offset',
+        playFromCmd: 'Play from here',
+        exportTranscript: 'Export transcript',
         operator: 'Operator',
         beginTime: 'Begin Time',
         endTime: 'End Time',
@@ -89,13 +95,79 @@ export default {
         scriptTypeEnumPublic: 'Public',
         category: 'Category',
         categoryTips: 'support input new category and selection',
+        scriptParamTips3: '3. String, enum and secret values are shell-escaped automatically, do not wrap the placeholders in quotes',
+        scriptParamType: 'Type',
+        scriptParamDefault: 'Default',
+        scriptParamRule: 'Rule',
+        scriptParamPattern: 'Regexp the value must fully match',
+        scriptParamMin: 'Min',
+        scriptParamMax: 'Max',
+        scriptParamNotMatch: 'The value does not match: {pattern}',
+        scriptParamTypeEnumString: 'String',
+        scriptParamTypeEnumNumber: 'Number',
+        scriptParamTypeEnumEnum: 'Enum',
+        scriptParamTypeEnumBoolean: 'Boolean',
+        scriptParamTypeEnumSecret: 'Secret',
+        batchRun: 'Batch Run',
+        batchRunTargetTag: 'Target Tag',
+        batchRunConcurrency: 'Concurrency',
+        batchRunTimeout: 'Timeout(s)',
+        batchRunSubmitted: 'The batch run has been submitted',
+        runHistory: 'Run History',
+        runCmd: 'Command',
+        runSummary: 'Success / Fail / Total',
+        runOutput: 'Output',
+        runErrorMsg: 'Error',
+        cmdBatchStatusEnumRunning: 'Running',
+        cmdBatchStatusEnumDone: 'Done',
+        cmdBatchResultStatusEnumWaiting: 'Waiting',
+        cmdBatchResultStatusEnumRunning: 'Running',
+        cmdBatchResultStatusEnumSuccess: 'Success',
+        cmdBatchResultStatusEnumFail: 'Fail',
+        cmdBatchResultStatusEnumRejected: 'Rejected',
+        cmdBatchResultStatusEnumTimeout: 'Timeout',
 
         // security
         cmdConfig: 'Command Config',
         filterCmds: 'Filter command',
         relateMachine: 'Associated machine',
         newCmd: 'New Command',
-        cmdPlaceholder: 'Enter the command regular expression',
+        cmdPlaceholder: 'Command regex, or an argument rule prefixed with cmd:, e.g. cmd:rm -r|-R|--recursive /*',
+        cmdStratege: 'Strategy',
+        cmdStrategeDeny: 'Deny',
+        cmdStrategeApprove: 'Approve',
+
+        // alert
+        alertRule: 'Alert Rules',
+        alertSilence: 'Alert Silences',
+        alertHistory: 'Alert History',
+        alertMetric: 'Metric',
+        alertMetricCpu: 'CPU usage (%)',
+        alertMetricMem: 'Memory usage (%)',
+        alertMetricLoad1: 'Load 1m',
+        alertMetricLoad5: 'Load 5m',
+        alertMetricLoad15: 'Load 15m',
+        alertMetricDisk: 'Disk usage (%)',
+        alertMetricUnreachable: 'Consecutive unreachable count',
+        alertMountPoint: 'Mount Point',
+        alertMountPointPlaceholder: 'All mount points are checked if empty',
+        alertCondition: 'Condition',
+        alertThreshold: 'Threshold',
+        alertDuration: 'Duration (minutes)',
+        alertDurationTips: 'How long the condition must hold, 0 to fire immediately',
+        alertMsgTmpl: 'Notify Template',
+        alertReceivers: 'Notify Receivers',
+        alertRuleAll: 'All rules',
+        alertMachineAll: 'All machines',
+        alertSilenceTime: 'Silence Time',
+        alertTarget: 'Target',
+        alertValue: 'Value',
+        alertStartTime: 'Start Time',
+        alertResolvedTime: 'Resolved Time',
+        alertSilenced: 'Silenced',
+        alertNotifyErr: 'Notify Error',
+        alertStatusFiring: 'Firing',
+        alertStatusResolved: 'Resolved',
 
         // cronjob
         cronjob: 'Cronjob',
@@ -105,6 +177,8 @@ export default {
         cronjobRun: 'Execute',
         cronJobExecStatusEnumSuccess: 'Success',
         cronJobExecStatusEnumFail: 'Fail',
+        cronJobExecStatusEnumTimeout: 'Timeout',
+        cronJobExecStatusEnumCancel: 'Cancelled',
         cronjobExecResult: 'Execute Result',
         cronjobExecTime: 'Execute Time',
         cronjobExecRecord: 'Record of execution',
@@ -129,6 +203,124 @@ export default {
         user: 'User',
         group: 'Group',
         renameTips: 'rename: Double-click the file name cell and then press Ente',
+        tail: 'Tail Log',
+        fileTransfer: 'File Transfer',
+        fileTransferSrc: 'Source',
+        fileTransferDst: 'Destination',
+        fileTransferDstMachine: 'Target Machine',
+        fileTransferDstPath: 'Target Path',
+        fileTransferDstPathPlaceholder: 'Absolute path of the target file',
+        fileTransferOverwrite: 'Overwrite',
+        fileTransferStart: 'Start Transfer',
+        fileTransferProgress: 'Progress',
+        fileTransferCancelConfirm: 'Cancel the transfer of [{path}]?',
+        fileTransferStatusRunning: 'Transferring',
+        fileTransferStatusSuccess: 'Success',
+        fileTransferStatusFail: 'Fail',
+        fileTransferStatusCancelled: 'Cancelled',
+        tailFilter: 'Filter',
+        tailFilterPlaceholder: 'Only show lines containing it',
+        tailHighlight: 'Highlight',
+        tailHighlightPlaceholder: 'Highlight the filter when empty',
+        tailRegexp: 'Regexp',
+        tailIgnoreCase: 'Ignore Case',
+        tailPause: 'Pause',
+        tailResume: 'Resume',
+        tailClear: 'Clear',
+        tailDisconnected: 'Disconnected',
+        symlink: 'Symlink',
+        symlinkTarget: 'Link Target',
+        editAttribute: 'Edit Attributes',
+        fileMode: 'Mode',
+        fileModePlaceholder: 'Octal mode, e.g. 755',
+        recursive: 'Apply recursively to subdirectories and files',
+        attributeUpdateSuccess: 'Updated successfully',
+        fileVersion: 'History Versions',
+        diffWithCurrent: 'Diff Current',
+        diffWithPrevious: 'Diff Previous',
+        currentContent: 'Current',
+        noDifference: 'No difference',
+        restore: 'Restore',
+        restoreVersionConfirm: 'The file will be restored to {version}, and the current content will be saved as a new version. Continue?',
+        restoreSuccess: 'Restored successfully',
+        portForward: 'Port Forward',
+        targetHost: 'Target Host',
+        targetPort: 'Target Port',
+        portForwardTtl: 'TTL(min)',
+        openPortForward: 'Open',
+        portForwardAllowIp: 'Only {ip} allowed',
+        listenAddr: 'Listen Address',
+        target: 'Target',
+        forwarding: 'Forwarding',
+        closed: 'Closed',
+        connCount: 'Conns',
+        traffic: 'Traffic',
+        expireTime: 'Expire Time',
+        portForwardOpened: 'Port forward opened, please connect to {addr}',
+        systemdService: 'Service',
+        unitName: 'Unit',
+        unitLoad: 'Load',
+        unitActive: 'Active',
+        unitSub: 'Sub',
+        unitFileState: 'Boot',
+        unitDescription: 'Description',
+        serviceStatus: 'Status',
+        serviceJournal: 'Journal',
+        journalLines: 'Lines',
+        serviceStart: 'Start',
+        serviceStop: 'Stop',
+        serviceRestart: 'Restart',
+        serviceEnable: 'Enable',
+        serviceDisable: 'Disable',
+        serviceOpConfirm: 'Are you sure to {action} the service [{unit}]?',
+        serviceOpSuccess: 'Operation succeeded',
+        container: 'Container',
+        containerName: 'Name',
+        containerImage: 'Image',
+        containerPorts: 'Ports',
+        containerMem: 'Memory',
+        containerLogs: 'Logs',
+        containerLogsTail: 'Tail',
+        containerLogsClear: 'Clear',
+        containerInspect: 'Inspect',
+        containerTerminal: 'Terminal',
+        containerStart: 'Start',
+        containerStop: 'Stop',
+        containerRestart: 'Restart',
+        containerRemove: 'Remove',
+        containerOpConfirm: 'Are you sure to {action} the container [{name}]?',
+        containerOpSuccess: 'Operation succeeded',
+        import: 'Import',
+        terminalSessions: 'Terminal Sessions',
+        terminalSessionUser: 'User',
+        terminalSessionLoginUser: 'Login User',
+        terminalSessionStartTime: 'Start Time',
+        terminalSessionCmds: 'Commands',
+        terminalSessionShadowCount: 'Shadows',
+        terminalSessionShadow: 'Shadow',
+        terminalSessionShadowClosed: 'The session has ended or the connection was closed',
+        terminalSessionKill: 'Kill',
+        terminalSessionKillConfirm: 'Kill the terminal session of [{user}] on [{machine}]?',
+        terminalSessionKillMsgPlaceholder: 'Reason shown to the user (optional)',
+        importMachine: 'Import Machines',
+        importFormat: 'Format',
+        importContent: 'Content',
+        importSelectFile: 'Select File',
+        importDefaultTag: 'Default Tag',
+        importDefaultAuthCert: 'Default Auth Cert',
+        importPreview: 'Preview',
+        importLine: 'Line',
+        importAddress: 'Address',
+        importAuthCert: 'Auth Cert',
+        importMessage: 'Message',
+        importResult: 'Total {total}, to create / created {created}, existed {existed}, failed {failed}',
+        importSuccess: '{created} machines imported',
+        importStatus: {
+            create: 'To Create',
+            created: 'Created',
+            exist: 'Existed',
+            error: 'Failed',
+        },
         fileDetail: 'File Details',
         createFile: 'Create File',
         pasteSuccess: 'Paste successfully',
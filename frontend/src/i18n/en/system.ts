@@ -110,12 +110,34 @@ export default {
             uploadMaxFileSizePlaceholder: 'Maximum file size allowed to upload (1MB, 2GB, etc.)',
             termOpSaveDays: 'Terminal records the retention time',
             termOpSaveDaysPlaceholder: 'Unit day, after which the terminal operation record will be deleted',
+            monitorRawDays: 'Monitor raw data retention',
+            monitorRawDaysPlaceholder: 'Unit day, default 7. Raw monitor samples older than this are aggregated into hourly data',
+            monitorSaveDays: 'Monitor data retention',
+            monitorSaveDaysPlaceholder: 'Unit day, default 90. Monitor data older than this will be deleted',
             guacdHost: 'guacd server ip',
             guacdHostPlaceholder: 'guacd server ip, default 127.0.0.1',
             guacdPort: 'guacd server port',
             guacdPortPlaceholder: 'guacd server port, default 4822',
             guacdFilePath: 'guacd server file path',
             guacdFilePathPlaceholder: 'guacd serves the file storage location for mounting the RDP folder',
+            guacdRecPath: 'guacd recording storage location',
+            guacdRecPathPlaceholder: 'The path where the guacd /rdp-rec directory is mounted on this server, used for RDP/VNC playback and cleanup',
+            cmdApproveTimeout: 'Terminal command approval timeout',
+            cmdApproveTimeoutPlaceholder: 'Seconds to wait for approval of terminal commands that require approval, default 300',
+            portForwardBindHost: 'Port forward bind address',
+            portForwardBindHostPlaceholder: 'Listening address of the machine port forward on this server, default 127.0.0.1, only the client that opened the forward can connect when listening on a non-loopback address',
+            portForwardHost: 'Port forward access address',
+            portForwardHostPlaceholder: 'Address shown to users for connecting to the forward port, default is the bind address, required when listening on all addresses (e.g. 0.0.0.0)',
+            portForwardPorts: 'Port forward port range',
+            portForwardPortsPlaceholder: 'Listening port range such as 30000-30100, randomly assigned when empty',
+            portForwardMaxTtl: 'Port forward max TTL',
+            portForwardMaxTtlPlaceholder: 'Maximum duration (minutes) of a port forward, default 120',
+            portForwardUserLimit: 'Port forward limit per user',
+            portForwardUserLimitPlaceholder: 'Maximum number of port forwards opened at the same time by a user, default 3',
+            hostKeyAlertReceiverIds: 'Host key alert receivers',
+            hostKeyAlertReceiverIdsPlaceholder: 'Account ids notified when a host key mismatches, separated by commas, default admin',
+            hostKeyAlertMsgTmplCode: 'Host key alert template',
+            hostKeyAlertMsgTmplCodePlaceholder: 'Message template code used to send host key mismatch alerts, only system messages are sent if empty',
 
             systemConf: 'System-wide styling',
             systemConfRemark: 'Configuration of system icon, title, watermark information, etc',
@@ -242,17 +242,38 @@ export default {
         machineScriptDelete: '脚本-删除',
         machineScriptRun: '脚本-执行',
         machineKillprocess: '终止进程',
+        machineServiceStart: '服务-启动',
+        machineServiceStop: '服务-停止',
+        machineServiceRestart: '服务-重启',
+        machineServiceEnable: '服务-开机启动',
+        machineServiceDisable: '服务-禁止开机启动',
+        machineContainerOp: '容器-操作',
+        machineContainerRemove: '容器-删除',
+        machineContainerExec: '容器-终端',
+        machineContainerLogs: '容器-日志',
+        machineHostKey: '主机公钥管理',
         machineCronJob: '计划任务',
         machineCronJobSvae: '计划任务-保存',
         machineCronJobDelete: '计划任务-删除',
         machineSecurityConfig: '安全配置',
         machineSecurityCmdSvae: '命令配置-保存',
         machineSecurityCmdDelete: '命令配置-删除',
+        machineAlertSave: '告警规则-保存',
+        machineAlertDelete: '告警规则-删除',
+        machineCmdBatch: '批量执行命令',
+        machineFileTransfer: '机器间文件传输',
+        machineFileTail: '机器文件-跟踪日志',
+        machinePortForward: '机器端口转发',
+        machineTerminalMonitor: '终端会话-监控',
+        machineTerminalKill: '终端会话-强制断开',
 
         dbms: 'DBMS',
         dbDataOp: '数据操作',
         dbDataOpBase: '基本权限',
         dbDataOpSqlScriptRun: 'SQL脚本执行',
+        dbDataOpDataGen: '测试数据生成',
+        dbSqlReportSave: '保存SQL报表',
+        dbSqlReportDelete: '删除SQL报表',
         dbInstance: '数据库实例',
         dbInstanceBase: '基本权限',
         dbInstanceSave: '保存实例',
@@ -267,6 +288,7 @@ export default {
         dbDataSyncChangeStatus: '启用停用',
         dbDataSyncLog: '同步日志',
         dbTransfer: '数据库迁移',
+        dbSqlReport: 'SQL报表',
         dbTransferBase: '基本权限',
         dbTransferSave: '保存迁移任务',
         dbTransferDelete: '删除迁移任务',
@@ -41,6 +41,37 @@ export default {
         createTable: '创建表',
         tableOp: '表操作',
         copyTable: '复制表',
+        dataGen: '生成测试数据',
+        dataGenNum: '生成条数',
+        dataGenBatchSize: '每批条数',
+        dataGenGenerator: '生成器',
+        dataGenRule: '生成规则',
+        dataGenMin: '最小值',
+        dataGenMax: '最大值',
+        dataGenLength: '长度',
+        dataGenEnumValues: '可选值',
+        dataGenRefTable: '关联表',
+        dataGenRefColumn: '关联列',
+        dataGenRun: '生成',
+        dataGenRefEmpty: '列[{column}]的关联表及关联列不能为空',
+        dataGenSubmitted: '数据生成任务已提交，可在通知中查看进度',
+        dataGenProgress: '已插入',
+        dataGenSkip: '跳过',
+        dataGenNull: 'null值',
+        dataGenFixed: '固定值',
+        dataGenInt: '整数',
+        dataGenDecimal: '小数',
+        dataGenString: '随机字符串',
+        dataGenName: '姓名',
+        dataGenEmail: '邮箱',
+        dataGenPhone: '手机号',
+        dataGenUuid: 'UUID',
+        dataGenBool: '布尔值',
+        dataGenDate: '日期',
+        dataGenDatetime: '日期时间',
+        dataGenTime: '时间',
+        dataGenEnum: '枚举',
+        dataGenRef: '外键关联',
         renameTable: '重命名',
         editTable: '编辑表',
         delTable: '删除表',
@@ -152,6 +183,7 @@ export default {
         runConfirm: '确定运行?',
         transferFileManage: '迁移文件管理',
         dbFileType: '文件数据库类型',
+        targetFileType: '目标文件类型',
         targetDb: '目标数据库',
         fileDbType: 'sql语言',
         transferFileRunDialogTitle: '指定数据库执行sql文件',
@@ -204,6 +236,48 @@ export default {
         notSelectSql: 'sql语句错误，请输入select语句',
         notOneSql: 'sql语句错误，请输入单条查询语句',
         notColumnSql: '没有查询到字段，请检查sql',
+        syncTables: '同步表',
+        syncTable: '同步表',
+        syncTableSort: '顺序',
+        syncTableSortTips: '按顺序从小到大依次同步，被依赖的表应排在前面',
+        syncTableRequired: '至少需要一个同步表',
+        syncTableIncomplete: '同步表[{name}]：数据sql及目标表不能为空',
+        syncTableDuplicate: '同步表[{name}]：目标表重复',
+        syncTableNoFieldMap: '同步表[{name}]：请加载并配置字段映射',
+        loadFieldMap: '加载字段',
+        updateFieldValueSyncedTips: '当前值由同步任务维护，不可修改',
+
+        // sql报表
+        sqlReport: 'SQL报表',
+        sqlReportName: '报表名称',
+        sqlReportSql: '保存的SQL',
+        sqlReportSqlPlaceholder: '请选择目标库下保存的sql',
+        sqlReportResultFormat: '结果格式',
+        sqlReportMaxRows: '最大行数',
+        sqlReportMsgTmpl: '报表模板',
+        sqlReportAlertMsgTmpl: '告警模板',
+        sqlReportReceivers: '接收人',
+        sqlReportRunConfirm: '确定立即执行该报表?',
+
+        // 保存的sql
+        sqlName: 'SQL名称',
+        sqlFolder: '文件夹',
+        sqlFolderPlaceholder: '选择或输入文件夹',
+        sqlVersionRemark: '版本说明',
+        sqlParams: '命名参数',
+        sqlParamsEmpty: 'sql中不存在如 :name 的命名参数',
+        sqlParamName: '参数',
+        sqlParamLabel: '输入提示',
+        sqlParamType: '类型',
+        sqlParamDefault: '默认值',
+        sqlParamRequired: '必填',
+        sqlRunWithParams: '参数化执行',
+        sqlVersions: '历史版本',
+        sqlVersionDiffLatest: '对比最新版',
+        sqlVersionDiffPrevious: '对比上一版',
+        sqlVersionNoDifference: '内容无差异',
+        sqlShare: '共享',
+        sqlShareNoTeam: '当前账号不属于任何团队',
 
         // enums
         getDbNamesModeAuto: '实时获取',
@@ -99,6 +99,7 @@ export default {
         // FlowBizType
         dbSqlExec: 'DBMS-执行SQL',
         redisRunCmd: 'Redis-执行命令',
+        machineTermCmd: '机器-终端命令',
 
         // task
         approveNode: '审批节点',
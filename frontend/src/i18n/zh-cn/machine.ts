@@ -38,9 +38,15 @@ export default {
 
         // MachineRec
         playback: '回放',
+        guacRecLoadFail: '会话录像加载失败',
         cmd: '命令',
         execCmdRecord: '执行命令记录', //Executive command record
         execTime: '执行时间', // execution time
+        termRecs: '终端记录',
+        cmdSearch: '命令检索',
+        cmdOffset: '回放位置',
+        playFromCmd: '从此处回放',
+        exportTranscript: '导出文本记录',
         operator: '操作人',
         beginTime: '开始时间',
         endTime: '结束时间',
@@ -90,13 +96,79 @@ export default {
         scriptTypeEnumPublic: '公共',
         category: '分类',
         categoryTips: '支持输入新分类并选择',
+        scriptParamTips3: '3. 字符串、枚举及密文类参数值会自动进行shell转义，占位符无需再使用引号包裹',
+        scriptParamType: '类型',
+        scriptParamDefault: '默认值',
+        scriptParamRule: '校验规则',
+        scriptParamPattern: '参数值需完整匹配的正则',
+        scriptParamMin: '最小值',
+        scriptParamMax: '最大值',
+        scriptParamNotMatch: '参数值不匹配: {pattern}',
+        scriptParamTypeEnumString: '字符串',
+        scriptParamTypeEnumNumber: '数字',
+        scriptParamTypeEnumEnum: '枚举',
+        scriptParamTypeEnumBoolean: '布尔',
+        scriptParamTypeEnumSecret: '密文',
+        batchRun: '批量执行',
+        batchRunTargetTag: '目标标签',
+        batchRunConcurrency: '并发数',
+        batchRunTimeout: '超时时间(秒)',
+        batchRunSubmitted: '批量执行已提交',
+        runHistory: '执行历史',
+        runCmd: '执行命令',
+        runSummary: '成功 / 失败 / 总数',
+        runOutput: '输出',
+        runErrorMsg: '错误信息',
+        cmdBatchStatusEnumRunning: '执行中',
+        cmdBatchStatusEnumDone: '已完成',
+        cmdBatchResultStatusEnumWaiting: '等待执行',
+        cmdBatchResultStatusEnumRunning: '执行中',
+        cmdBatchResultStatusEnumSuccess: '成功',
+        cmdBatchResultStatusEnumFail: '失败',
+        cmdBatchResultStatusEnumRejected: '拒绝执行',
+        cmdBatchResultStatusEnumTimeout: '超时',
 
         // security
         cmdConfig: '命令配置',
         filterCmds: '过滤命令',
         relateMachine: '关联机器',
         newCmd: '新建命令',
-        cmdPlaceholder: '请输入命令正则表达式',
+        cmdPlaceholder: '命令正则表达式，或以cmd:开头的参数规则，如 cmd:rm -r|-R|--recursive /*',
+        cmdStratege: '策略',
+        cmdStrategeDeny: '禁止执行',
+        cmdStrategeApprove: '审批后执行',
+
+        // alert
+        alertRule: '告警规则',
+        alertSilence: '告警静默',
+        alertHistory: '告警记录',
+        alertMetric: '监控指标',
+        alertMetricCpu: 'CPU使用率(%)',
+        alertMetricMem: '内存使用率(%)',
+        alertMetricLoad1: '1分钟负载',
+        alertMetricLoad5: '5分钟负载',
+        alertMetricLoad15: '15分钟负载',
+        alertMetricDisk: '磁盘使用率(%)',
+        alertMetricUnreachable: '连续不可达次数',
+        alertMountPoint: '挂载点',
+        alertMountPointPlaceholder: '为空则检测所有挂载点',
+        alertCondition: '触发条件',
+        alertThreshold: '阈值',
+        alertDuration: '持续时长(分钟)',
+        alertDurationTips: '持续满足条件的时长，0则立即触发',
+        alertMsgTmpl: '通知模板',
+        alertReceivers: '通知接收人',
+        alertRuleAll: '所有规则',
+        alertMachineAll: '所有机器',
+        alertSilenceTime: '静默时间',
+        alertTarget: '告警对象',
+        alertValue: '指标值',
+        alertStartTime: '开始时间',
+        alertResolvedTime: '恢复时间',
+        alertSilenced: '已静默',
+        alertNotifyErr: '通知失败信息',
+        alertStatusFiring: '触发中',
+        alertStatusResolved: '已恢复',
 
         // cronjob
         cronjob: '计划任务',
@@ -106,6 +178,8 @@ export default {
         cronjobRun: '执行',
         cronJobExecStatusEnumSuccess: '成功',
         cronJobExecStatusEnumFail: '失败',
+        cronJobExecStatusEnumTimeout: '超时',
+        cronJobExecStatusEnumCancel: '已取消',
         cronjobExecResult: '执行结果',
         cronjobExecTime: '执行时间',
         cronjobExecRecord: '执行记录',
@@ -130,6 +204,124 @@ export default {
         user: '用户',
         group: '组',
         renameTips: 'rename: 双击文件名单元格修改后回车',
+        tail: '跟踪日志',
+        fileTransfer: '文件传输',
+        fileTransferSrc: '源文件',
+        fileTransferDst: '目标文件',
+        fileTransferDstMachine: '目标机器',
+        fileTransferDstPath: '目标路径',
+        fileTransferDstPathPlaceholder: '目标文件的绝对路径',
+        fileTransferOverwrite: '覆盖已有文件',
+        fileTransferStart: '开始传输',
+        fileTransferProgress: '进度',
+        fileTransferCancelConfirm: '确定取消[{path}]的传输?',
+        fileTransferStatusRunning: '传输中',
+        fileTransferStatusSuccess: '成功',
+        fileTransferStatusFail: '失败',
+        fileTransferStatusCancelled: '已取消',
+        tailFilter: '过滤',
+        tailFilterPlaceholder: '仅显示包含该内容的行',
+        tailHighlight: '高亮',
+        tailHighlightPlaceholder: '为空则高亮过滤内容',
+        tailRegexp: '正则',
+        tailIgnoreCase: '忽略大小写',
+        tailPause: '暂停',
+        tailResume: '继续',
+        tailClear: '清屏',
+        tailDisconnected: '连接已断开',
+        symlink: '软链接',
+        symlinkTarget: '链接目标',
+        editAttribute: '修改属性',
+        fileMode: '权限',
+        fileModePlaceholder: '八进制权限，如755',
+        recursive: '递归应用至子目录及文件',
+        attributeUpdateSuccess: '修改成功',
+        fileVersion: '历史版本',
+        diffWithCurrent: '对比当前',
+        diffWithPrevious: '对比上一版',
+        currentContent: '当前内容',
+        noDifference: '内容无差异',
+        restore: '恢复',
+        restoreVersionConfirm: '文件将恢复至{version}，当前内容会保存为新的历史版本，是否继续？',
+        restoreSuccess: '恢复成功',
+        portForward: '端口转发',
+        targetHost: '目标地址',
+        targetPort: '目标端口',
+        portForwardTtl: '有效时长(分)',
+        openPortForward: '开启',
+        portForwardAllowIp: '仅允许{ip}连接',
+        listenAddr: '监听地址',
+        target: '目标',
+        forwarding: '转发中',
+        closed: '已关闭',
+        connCount: '连接数',
+        traffic: '流量',
+        expireTime: '过期时间',
+        portForwardOpened: '端口转发已开启，请连接 {addr}',
+        systemdService: '服务',
+        unitName: '单元',
+        unitLoad: '加载',
+        unitActive: '活动状态',
+        unitSub: '运行状态',
+        unitFileState: '开机启动',
+        unitDescription: '描述',
+        serviceStatus: '状态',
+        serviceJournal: '日志',
+        journalLines: '行数',
+        serviceStart: '启动',
+        serviceStop: '停止',
+        serviceRestart: '重启',
+        serviceEnable: '开机启动',
+        serviceDisable: '禁止开机启动',
+        serviceOpConfirm: '确定{action}服务[{unit}]?',
+        serviceOpSuccess: '操作成功',
+        container: '容器',
+        containerName: '名称',
+        containerImage: '镜像',
+        containerPorts: '端口',
+        containerMem: '内存',
+        containerLogs: '日志',
+        containerLogsTail: '行数',
+        containerLogsClear: '清空',
+        containerInspect: '详情',
+        containerTerminal: '终端',
+        containerStart: '启动',
+        containerStop: '停止',
+        containerRestart: '重启',
+        containerRemove: '删除',
+        containerOpConfirm: '确定{action}容器[{name}]?',
+        containerOpSuccess: '操作成功',
+        import: '导入',
+        terminalSessions: '终端会话',
+        terminalSessionUser: '用户',
+        terminalSessionLoginUser: '登录用户',
+        terminalSessionStartTime: '开始时间',
+        terminalSessionCmds: '执行命令',
+        terminalSessionShadowCount: '旁观数',
+        terminalSessionShadow: '旁观',
+        terminalSessionShadowClosed: '会话已结束或连接已关闭',
+        terminalSessionKill: '强制断开',
+        terminalSessionKillConfirm: '确定强制断开[{user}]在[{machine}]上的终端会话?',
+        terminalSessionKillMsgPlaceholder: '提示给用户的断开原因(可选)',
+        importMachine: '批量导入机器',
+        importFormat: '格式',
+        importContent: '内容',
+        importSelectFile: '选择文件',
+        importDefaultTag: '默认标签',
+        importDefaultAuthCert: '默认授权凭证',
+        importPreview: '预览',
+        importLine: '行号',
+        importAddress: '地址',
+        importAuthCert: '授权凭证',
+        importMessage: '提示信息',
+        importResult: '共 {total} 条，待创建/已创建 {created} 条，已存在 {existed} 条，失败 {failed} 条',
+        importSuccess: '成功导入 {created} 台机器',
+        importStatus: {
+            create: '待创建',
+            created: '已创建',
+            exist: '已存在',
+            error: '失败',
+        },
         fileDetail: '文件详情',
         createFile: '新建文件',
         pasteSuccess: '粘贴成功',
@@ -110,12 +110,34 @@ export default {
             uploadMaxFileSizePlaceholder: '允许上传的最大文件大小(1MB、2GB等)',
             termOpSaveDays: '终端记录保存时间',
             termOpSaveDaysPlaceholder: '单位天，超过该时间，将删除终端操作记录',
+            monitorRawDays: '监控原始数据保存时间',
+            monitorRawDaysPlaceholder: '单位天，默认7。超过该时间的原始监控采样将聚合为小时数据',
+            monitorSaveDays: '监控数据保存时间',
+            monitorSaveDaysPlaceholder: '单位天，默认90。超过该时间的监控数据将被删除',
             guacdHost: 'guacd服务ip',
             guacdHostPlaceholder: 'guacd服务ip，默认 127.0.0.1',
             guacdPort: 'guacd服务端口',
             guacdPortPlaceholder: 'guacd服务端口，默认 4822',
             guacdFilePath: 'guacd服务文件存储位置',
             guacdFilePathPlaceholder: 'guacd服务文件存储位置，用于挂载RDP文件夹',
+            guacdRecPath: 'guacd录像文件存储位置',
+            guacdRecPathPlaceholder: 'guacd服务/rdp-rec目录挂载至本服务的路径，用于RDP、VNC录像回放及清理',
+            cmdApproveTimeout: '终端命令审批超时时间',
+            cmdApproveTimeoutPlaceholder: '终端中需审批的命令等待审批的时间（秒），默认300',
+            portForwardBindHost: '端口转发监听地址',
+            portForwardBindHostPlaceholder: '机器端口转发在本服务的监听地址，默认127.0.0.1，监听非回环地址时仅允许开启转发的客户端连接',
+            portForwardHost: '端口转发访问地址',
+            portForwardHostPlaceholder: '展示给用户用于连接转发端口的地址，默认为监听地址，监听所有地址(如0.0.0.0)时必须配置',
+            portForwardPorts: '端口转发端口范围',
+            portForwardPortsPlaceholder: '监听端口范围，如30000-30100，为空则随机分配',
+            portForwardMaxTtl: '端口转发最大有效时长',
+            portForwardMaxTtlPlaceholder: '端口转发最大有效时长（分钟），默认120',
+            portForwardUserLimit: '端口转发用户上限',
+            portForwardUserLimitPlaceholder: '每个用户同时开启的端口转发数上限，默认3',
+            hostKeyAlertReceiverIds: '主机公钥告警接收人',
+            hostKeyAlertReceiverIdsPlaceholder: '主机公钥不匹配时通知的账号id，多个用逗号分隔，默认通知admin',
+            hostKeyAlertMsgTmplCode: '主机公钥告警模板',
+            hostKeyAlertMsgTmplCodePlaceholder: '发送主机公钥不匹配告警的消息模板编号，为空则仅发送站内消息',
 
             systemConf: '系统全局样式设置',
             systemConfRemark: '系统icon、标题、水印信息等配置',
@@ -43,4 +43,5 @@ export const HisProcinstOpState = {
 export const FlowBizType = {
     DbSqlExec: EnumValue.of('db_sql_exec_flow', 'flow.dbSqlExec').setTagType('warning'),
     RedisRunWriteCmd: EnumValue.of('redis_run_cmd_flow', 'flow.redisRunCmd').setTagType('danger'),
+    MachineTermCmd: EnumValue.of('machine_term_cmd_flow', 'flow.machineTermCmd').setTagType('warning'),
 };
@@ -12,6 +12,8 @@ export const dbApi = {
     tableIndex: Api.newGet('/dbs/{id}/t-index'),
     tableDdl: Api.newGet('/dbs/{id}/t-create-ddl'),
     copyTable: Api.newPost('/dbs/{id}/copy-table'),
+    dataGenRules: Api.newGet('/dbs/{id}/data-gen/rules'),
+    dataGen: Api.newPost('/dbs/{id}/data-gen'),
     columnMetadata: Api.newGet('/dbs/{id}/c-metadata'),
     pgSchemas: Api.newGet('/dbs/{id}/pg/schemas'),
     // 获取表即列提示
@@ -24,6 +26,16 @@ export const dbApi = {
     // 获取保存的sql names
     getSqlNames: Api.newGet('/dbs/{id}/sql-names'),
     deleteDbSql: Api.newDelete('/dbs/{id}/sql'),
+    // 解析sql命名参数
+    parseSqlParams: Api.newPost('/dbs/{id}/sql-params'),
+    getSqlById: Api.newGet('/dbs/{id}/sql/{sqlId}'),
+    getSqlParams: Api.newGet('/dbs/{id}/sql/{sqlId}/params'),
+    getSqlVersions: Api.newGet('/dbs/{id}/sql/{sqlId}/versions'),
+    diffSqlVersions: Api.newGet('/dbs/{id}/sql/{sqlId}/versions/diff'),
+    getSqlShareTeams: Api.newGet('/dbs/{id}/sql/{sqlId}/share'),
+    shareSql: Api.newPost('/dbs/{id}/sql/{sqlId}/share'),
+    // 使用参数值执行保存的sql查询
+    querySqlWithParams: Api.newPost('/dbs/{id}/sql/{sqlId}/query'),
     // 获取数据库sql执行记录
     getSqlExecs: Api.newGet('/dbs/sql-execs'),
     // 获取数据库兼容版本
@@ -62,7 +74,17 @@ export const dbApi = {
 
     // 数据同步相关
     datasyncTasks: Api.newGet('/datasync/tasks'),
-    saveDatasyncTask: Api.newPost('/datasync/tasks/save').withBeforeHandler(async (param: any) => await encryptField(param, 'dataSql')),
+    saveDatasyncTask: Api.newPost('/datasync/tasks/save').withBeforeHandler(async (param: any) => {
+        // 加密各同步表的sql
+        if (!param['_encrypted'] && param.tables) {
+            for (let table of param.tables) {
+                await encryptField(table, 'dataSql');
+                delete table['_encrypted'];
+            }
+            param['_encrypted'] = 1;
+        }
+        return param;
+    }),
     getDatasyncTask: Api.newGet('/datasync/tasks/{taskId}'),
     deleteDatasyncTask: Api.newDelete('/datasync/tasks/{taskId}/del'),
     updateDatasyncTaskStatus: Api.newPost('/datasync/tasks/{taskId}/status'),
@@ -82,6 +104,15 @@ export const dbApi = {
     dbTransferFileDel: Api.newPost('/dbTransfer/files/del/{fileId}'),
     dbTransferFileRun: Api.newPost('/dbTransfer/files/run'),
     dbTransferFileDown: Api.newGet('/dbTransfer/files/down/{fileUuid}'),
+
+    // sql报表相关
+    sqlReports: Api.newGet('/dbs/sql-reports'),
+    getSqlReport: Api.newGet('/dbs/sql-reports/{reportId}'),
+    saveSqlReport: Api.newPost('/dbs/sql-reports'),
+    deleteSqlReport: Api.newDelete('/dbs/sql-reports/{reportId}'),
+    updateSqlReportStatus: Api.newPost('/dbs/sql-reports/{reportId}/status'),
+    runSqlReport: Api.newPost('/dbs/sql-reports/{reportId}/run'),
+    sqlReportLogs: Api.newGet('/dbs/sql-reports/{reportId}/logs'),
 };
 
 export const dbSqlExecApi = {
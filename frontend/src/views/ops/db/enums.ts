@@ -50,8 +50,45 @@ export const DbTransferRunningStateEnum = {
     Stop: EnumValue.of(-2, 'db.stop').setTagType('warning'),
 };
 
+export const DbTransferFileTypeEnum = {
+    Sql: EnumValue.of('sql', 'SQL'),
+    Parquet: EnumValue.of('parquet', 'Parquet'),
+    Csv: EnumValue.of('csv', 'CSV'),
+    Jsonl: EnumValue.of('jsonl', 'JSON Lines'),
+};
+
 export const DbTransferFileStatusEnum = {
     Running: EnumValue.of(1, 'db.running').setTagType('primary'),
     Success: EnumValue.of(2, 'common.success').setTagType('success'),
     Fail: EnumValue.of(-1, 'common.fail').setTagType('danger'),
 };
+
+export const DbDataGenGeneratorEnum = {
+    Skip: EnumValue.of('skip', 'db.dataGenSkip'),
+    Null: EnumValue.of('null', 'db.dataGenNull'),
+    Fixed: EnumValue.of('fixed', 'db.dataGenFixed'),
+    Int: EnumValue.of('int', 'db.dataGenInt'),
+    Decimal: EnumValue.of('decimal', 'db.dataGenDecimal'),
+    String: EnumValue.of('string', 'db.dataGenString'),
+    Name: EnumValue.of('name', 'db.dataGenName'),
+    Email: EnumValue.of('email', 'db.dataGenEmail'),
+    Phone: EnumValue.of('phone', 'db.dataGenPhone'),
+    UUID: EnumValue.of('uuid', 'db.dataGenUuid'),
+    Bool: EnumValue.of('bool', 'db.dataGenBool'),
+    Date: EnumValue.of('date', 'db.dataGenDate'),
+    Datetime: EnumValue.of('datetime', 'db.dataGenDatetime'),
+    Time: EnumValue.of('time', 'db.dataGenTime'),
+    Enum: EnumValue.of('enum', 'db.dataGenEnum'),
+    Ref: EnumValue.of('ref', 'db.dataGenRef'),
+};
+
+export const DbSqlReportResultFormatEnum = {
+    Markdown: EnumValue.of('markdown', 'Markdown'),
+    Html: EnumValue.of('html', 'HTML'),
+    Csv: EnumValue.of('csv', 'CSV'),
+};
+
+export const DbSqlReportRunningStateEnum = {
+    Idle: EnumValue.of(0, 'db.waitRun').setTagType('primary'),
+    Running: EnumValue.of(1, 'db.running').setTagType('success'),
+};
@@ -3,4 +3,5 @@ export default {
     SqlExec: () => import('@/views/ops/db/SqlExec.vue'),
     SyncTaskList: () => import('@/views/ops/db/SyncTaskList.vue'),
     DbTransferList: () => import('@/views/ops/db/DbTransferList.vue'),
+    SqlReportList: () => import('@/views/ops/db/SqlReportList.vue'),
 };
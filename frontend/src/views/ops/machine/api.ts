@@ -13,9 +13,27 @@ export const machineApi = {
     process: Api.newGet('/machines/{id}/process'),
     // 终止进程
     killProcess: Api.newDelete('/machines/{id}/process'),
+    // systemd服务
+    services: Api.newGet('/machines/{id}/services'),
+    serviceStatus: Api.newGet('/machines/{id}/services/status'),
+    startService: Api.newPost('/machines/{id}/services/start'),
+    stopService: Api.newPost('/machines/{id}/services/stop'),
+    restartService: Api.newPost('/machines/{id}/services/restart'),
+    enableService: Api.newPost('/machines/{id}/services/enable'),
+    disableService: Api.newPost('/machines/{id}/services/disable'),
+    // docker容器
+    containers: Api.newGet('/machines/{id}/containers'),
+    containerStats: Api.newGet('/machines/{id}/containers/stats'),
+    inspectContainer: Api.newGet('/machines/{id}/containers/{containerId}/inspect'),
+    startContainer: Api.newPost('/machines/{id}/containers/{containerId}/start'),
+    stopContainer: Api.newPost('/machines/{id}/containers/{containerId}/stop'),
+    restartContainer: Api.newPost('/machines/{id}/containers/{containerId}/restart'),
+    removeContainer: Api.newDelete('/machines/{id}/containers/{containerId}'),
     users: Api.newGet('/machines/{id}/users'),
     groups: Api.newGet('/machines/{id}/groups'),
     testConn: Api.newPost('/machines/test-conn'),
+    // 批量导入
+    importMachines: Api.newPost('/machines/import'),
     // 保存按钮
     saveMachine: Api.newPost('/machines'),
     // 调整状态
@@ -24,9 +42,14 @@ export const machineApi = {
     del: Api.newDelete('/machines/{id}'),
     scripts: Api.newGet('/machines/{machineId}/scripts'),
     scriptCategorys: Api.newGet('/machines/scripts/categorys'),
-    runScript: Api.newGet('/machines/scripts/{scriptId}/{ac}/run'),
+    runScript: Api.newPost('/machines/scripts/{scriptId}/{ac}/run'),
+    renderScript: Api.newPost('/machines/scripts/{scriptId}/{ac}/render'),
     saveScript: Api.newPost('/machines/{machineId}/scripts'),
     deleteScript: Api.newDelete('/machines/{machineId}/scripts/{scriptId}'),
+    // 批量执行
+    cmdBatches: Api.newGet('/machine/cmd-batches'),
+    runCmdBatch: Api.newPost('/machine/cmd-batches'),
+    cmdBatchResults: Api.newGet('/machine/cmd-batches/{id}/results'),
     // 获取配置文件列表
     files: Api.newGet('/machines/{id}/files'),
     lsFile: Api.newGet('/machines/{machineId}/files/{fileId}/read-dir'),
@@ -36,18 +59,48 @@ export const machineApi = {
     cpFile: Api.newPost('/machines/{machineId}/files/{fileId}/cp'),
     renameFile: Api.newPost('/machines/{machineId}/files/{fileId}/rename'),
     mvFile: Api.newPost('/machines/{machineId}/files/{fileId}/mv'),
+    chmodFile: Api.newPost('/machines/{machineId}/files/{fileId}/chmod'),
+    chownFile: Api.newPost('/machines/{machineId}/files/{fileId}/chown'),
+    symlinkFile: Api.newPost('/machines/{machineId}/files/{fileId}/symlink'),
+    chtimesFile: Api.newPost('/machines/{machineId}/files/{fileId}/chtimes'),
     uploadFile: Api.newPost('/machines/{machineId}/files/{fileId}/upload?' + joinClientParams()),
+    initChunkUpload: Api.newPost('/machines/{machineId}/files/{fileId}/chunk-upload'),
+    uploadChunk: Api.newPut('/machines/{machineId}/files/{fileId}/chunk-upload/{uploadId}'),
+    completeChunkUpload: Api.newPost('/machines/{machineId}/files/{fileId}/chunk-upload/{uploadId}/complete'),
     fileContent: Api.newGet('/machines/{machineId}/files/{fileId}/read'),
     downloadFile: Api.newGet('/machines/{machineId}/files/{fileId}/download'),
     createFile: Api.newPost('/machines/{machineId}/files/{id}/create-file'),
     // 修改文件内容
     updateFileContent: Api.newPost('/machines/{machineId}/files/{id}/write'),
+    // 文件历史版本
+    fileVersions: Api.newGet('/machines/{machineId}/files/{fileId}/versions'),
+    fileVersionContent: Api.newGet('/machines/{machineId}/files/{fileId}/versions/{versionId}/content'),
+    diffFileVersion: Api.newGet('/machines/{machineId}/files/{fileId}/versions/diff'),
+    restoreFileVersion: Api.newPost('/machines/{machineId}/files/{fileId}/versions/{versionId}/restore'),
+    // 对比不同机器上的文件
+    diffMachineFile: Api.newPost('/machines/files/diff'),
     // 添加文件or目录
     addConf: Api.newPost('/machines/{machineId}/files'),
     // 删除配置的文件or目录
     delConf: Api.newDelete('/machines/{machineId}/files/{id}'),
     // 机器终端操作记录列表
     termOpRecs: Api.newGet('/machines/{machineId}/term-recs'),
+    termOpRec: Api.newGet('/machines/{machineId}/term-recs/{recId}'),
+    // 终端操作记录中执行的命令，offset为命令在回放中的偏移秒数
+    termOpCmds: Api.newGet('/machines/{machineId}/term-recs/{recId}/cmds'),
+    // 跨终端操作记录检索执行的命令
+    searchTermOpCmds: Api.newGet('/machines/term-recs/cmds'),
+    // 活跃终端会话
+    terminalSessions: Api.newGet('/machines/terminal-sessions'),
+    killTerminalSession: Api.newPost('/machines/terminal-sessions/{sessionId}/kill'),
+    // 机器间文件传输
+    fileTransfers: Api.newGet('/machine/file-transfers'),
+    startFileTransfer: Api.newPost('/machine/file-transfers'),
+    cancelFileTransfer: Api.newPost('/machine/file-transfers/{id}/cancel'),
+    // 端口转发
+    portForwards: Api.newGet('/machine/port-forwards'),
+    openPortForward: Api.newPost('/machine/port-forwards'),
+    closePortForward: Api.newPost('/machine/port-forwards/{id}/close'),
 };
 
 export const cronJobApi = {
@@ -66,10 +119,55 @@ export const cmdConfApi = {
     delete: Api.newDelete('/machine/security/cmd-confs/{id}'),
 };
 
+export const machineAlertApi = {
+    rules: Api.newGet('/machine/alert/rules'),
+    saveRule: Api.newPost('/machine/alert/rules'),
+    deleteRule: Api.newDelete('/machine/alert/rules/{id}'),
+    alerts: Api.newGet('/machine/alert/alerts'),
+    silences: Api.newGet('/machine/alert/silences'),
+    saveSilence: Api.newPost('/machine/alert/silences'),
+    deleteSilence: Api.newDelete('/machine/alert/silences/{id}'),
+};
+
 export function getMachineTerminalSocketUrl(authCertName: any) {
     return `${config.baseWsUrl}/machines/terminal/${authCertName}?${joinClientParams()}`;
 }
 
+// 只读旁观指定的活跃终端会话
+export function getMachineTerminalShadowSocketUrl(sessionId: string) {
+    return `${config.baseWsUrl}/machines/terminal-sessions/${sessionId}/shadow?${joinClientParams()}`;
+}
+
+// docker exec进入容器的终端
+export function getMachineContainerTerminalSocketUrl(authCertName: any, containerId: string) {
+    return `${config.baseWsUrl}/machines/container-terminal/${authCertName}?${joinClientParams()}&containerId=${encodeURIComponent(containerId)}`;
+}
+
+export function getMachineContainerLogsSocketUrl(machineId: any, containerId: string, tail: number) {
+    return `${config.baseWsUrl}/machines/${machineId}/containers/${encodeURIComponent(containerId)}/logs?${joinClientParams()}&tail=${tail}`;
+}
+
+// rdp、vnc会话的guacd录像文件
+export function getMachineGuacRecUrl(machineId: any, recId: any) {
+    return `${config.baseApiUrl}/machines/${machineId}/term-recs/${recId}/guac-rec?${joinClientParams()}`;
+}
+
+// 终端操作记录导出的纯文本记录
+export function getMachineTermOpTranscriptUrl(machineId: any, recId: any) {
+    return `${config.baseApiUrl}/machines/${machineId}/term-recs/${recId}/transcript?${joinClientParams()}`;
+}
+
 export function getMachineRdpSocketUrl(authCertName: any) {
     return `${config.baseWsUrl}/machines/rdp/${authCertName}`;
 }
+
+export function getMachineFileTailSocketUrl(machineId: any, fileId: any, params: any) {
+    const query = new URLSearchParams(params).toString();
+    return `${config.baseWsUrl}/machines/${machineId}/files/${fileId}/tail?${joinClientParams()}&${query}`;
+}
+
+// websocket方式的端口转发，连接建立后收发的二进制消息即为目标的tcp流
+export function getMachinePortForwardSocketUrl(params: any) {
+    const query = new URLSearchParams(params).toString();
+    return `${config.baseWsUrl}/machine/port-forwards/ws?${joinClientParams()}&${query}`;
+}
@@ -19,6 +19,31 @@ export const ScriptTypeEnum = {
     Public: EnumValue.of(2, 'machine.scriptTypeEnumPublic'),
 };
 
+// 脚本参数类型
+export const ScriptParamTypeEnum = {
+    String: EnumValue.of('string', 'machine.scriptParamTypeEnumString'),
+    Number: EnumValue.of('number', 'machine.scriptParamTypeEnumNumber'),
+    Enum: EnumValue.of('enum', 'machine.scriptParamTypeEnumEnum'),
+    Boolean: EnumValue.of('boolean', 'machine.scriptParamTypeEnumBoolean'),
+    Secret: EnumValue.of('secret', 'machine.scriptParamTypeEnumSecret'),
+};
+
+// 批量执行状态
+export const CmdBatchStatusEnum = {
+    Running: EnumValue.of(1, 'machine.cmdBatchStatusEnumRunning').tagTypeWarning(),
+    Done: EnumValue.of(2, 'machine.cmdBatchStatusEnumDone').tagTypeSuccess(),
+};
+
+// 批量执行在单台机器上的执行状态
+export const CmdBatchResultStatusEnum = {
+    Waiting: EnumValue.of(1, 'machine.cmdBatchResultStatusEnumWaiting').tagTypeInfo(),
+    Running: EnumValue.of(2, 'machine.cmdBatchResultStatusEnumRunning').tagTypeWarning(),
+    Success: EnumValue.of(3, 'machine.cmdBatchResultStatusEnumSuccess').tagTypeSuccess(),
+    Fail: EnumValue.of(-1, 'machine.cmdBatchResultStatusEnumFail').tagTypeDanger(),
+    Rejected: EnumValue.of(-2, 'machine.cmdBatchResultStatusEnumRejected').tagTypeDanger(),
+    Timeout: EnumValue.of(-3, 'machine.cmdBatchResultStatusEnumTimeout').tagTypeDanger(),
+};
+
 // 文件类型枚举
 export const FileTypeEnum = {
     Directory: EnumValue.of(1, 'machine.directory'),
@@ -42,4 +67,35 @@ export const CronJobSaveExecResTypeEnum = {
 export const CronJobExecStatusEnum = {
     Error: EnumValue.of(-1, 'machine.cronJobExecStatusEnumFail').tagTypeDanger(),
     Success: EnumValue.of(1, 'machine.cronJobExecStatusEnumSuccess').tagTypeSuccess(),
+    Timeout: EnumValue.of(-2, 'machine.cronJobExecStatusEnumTimeout').tagTypeDanger(),
+    Cancel: EnumValue.of(-3, 'machine.cronJobExecStatusEnumCancel').tagTypeWarning(),
+};
+
+// 机器间文件传输状态
+export const MachineFileTransferStatusEnum = {
+    Running: EnumValue.of(1, 'machine.fileTransferStatusRunning').setTagType('primary'),
+    Success: EnumValue.of(2, 'machine.fileTransferStatusSuccess').tagTypeSuccess(),
+    Fail: EnumValue.of(-1, 'machine.fileTransferStatusFail').tagTypeDanger(),
+    Cancelled: EnumValue.of(-2, 'machine.fileTransferStatusCancelled').tagTypeWarning(),
+};
+
+// 告警监控指标
+export const MachineAlertMetricEnum = {
+    Cpu: EnumValue.of('cpu', 'machine.alertMetricCpu'),
+    Mem: EnumValue.of('mem', 'machine.alertMetricMem'),
+    Load1: EnumValue.of('load1', 'machine.alertMetricLoad1'),
+    Load5: EnumValue.of('load5', 'machine.alertMetricLoad5'),
+    Load15: EnumValue.of('load15', 'machine.alertMetricLoad15'),
+    Disk: EnumValue.of('disk', 'machine.alertMetricDisk'),
+    Unreachable: EnumValue.of('unreachable', 'machine.alertMetricUnreachable'),
+};
+
+export const MachineAlertRuleStatusEnum = {
+    Enable: EnumValue.of(1, 'common.enable').tagTypeSuccess(),
+    Disable: EnumValue.of(-1, 'common.disable').tagTypeDanger(),
+};
+
+export const MachineAlertStatusEnum = {
+    Firing: EnumValue.of(1, 'machine.alertStatusFiring').tagTypeDanger(),
+    Resolved: EnumValue.of(2, 'machine.alertStatusResolved').tagTypeSuccess(),
 };
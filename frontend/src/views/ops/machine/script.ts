@@ -0,0 +1,17 @@
+import { ScriptParamTypeEnum } from './enums';
+
+/**
+ * 解析脚本参数定义，未指定类型的参数有可选值则为枚举类型，否则为字符串类型
+ */
+export function parseScriptParams(script: any): any[] {
+    if (!script?.params) {
+        return [];
+    }
+    try {
+        return (JSON.parse(script.params) || []).map((x: any) => {
+            return { ...x, type: x.type || (x.options ? ScriptParamTypeEnum.Enum.value : ScriptParamTypeEnum.String.value) };
+        });
+    } catch (e) {
+        return [];
+    }
+}
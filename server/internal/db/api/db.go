@@ -36,6 +36,7 @@ type Db struct {
 	instanceApp  application.Instance  `inject:"T"`
 	dbApp        application.Db        `inject:"T"`
 	dbSqlExecApp application.DbSqlExec `inject:"T"`
+	dbDataGenApp application.DbDataGen `inject:"T"`
 	msgApp       msgapp.Msg            `inject:"T"`
 	tagApp       tagapp.TagTree        `inject:"T"`
 }
@@ -70,6 +71,10 @@ func (d *Db) ReqConfs() *req.Confs {
 		req.NewGet(":dbId/hint-tables", d.HintTables),
 
 		req.NewPost(":dbId/copy-table", d.CopyTable),
+
+		req.NewGet(":dbId/data-gen/rules", d.DataGenRules),
+
+		req.NewPost(":dbId/data-gen", d.DataGen).Log(req.NewLogSaveI(imsg.LogDbDataGen)).RequiredPermissionCode("db:data:gen"),
 	}
 
 	return req.NewConfs("/dbs", reqs[:]...)
@@ -361,6 +366,37 @@ func (d *Db) CopyTable(rc *req.Ctx) {
 	biz.ErrIsNilAppendErr(err, "copy table error: %s")
 }
 
+// @router /api/dbs/:dbId/data-gen/rules [get]
+func (d *Db) DataGenRules(rc *req.Ctx) {
+	tn := rc.Query("tableName")
+	biz.NotEmpty(tn, "tableName cannot be empty")
+
+	dbConn := d.getDbConn(rc)
+	biz.ErrIsNilAppendErr(d.tagApp.CanAccess(rc.GetLoginAccount().Id, dbConn.Info.CodePath...), "%s")
+	res, err := d.dbDataGenApp.GetColumnRules(rc.MetaCtx, dbConn, tn)
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+// @router /api/dbs/:dbId/data-gen [post]
+func (d *Db) DataGen(rc *req.Ctx) {
+	form := req.BindJsonAndValid[*form.DbDataGenForm](rc)
+
+	dbConn, err := d.dbApp.GetDbConn(rc.MetaCtx, getDbId(rc), form.Db)
+	biz.ErrIsNil(err)
+	biz.ErrIsNilAppendErr(d.tagApp.CanAccess(rc.GetLoginAccount().Id, dbConn.Info.CodePath...), "%s")
+	rc.ReqParam = collx.Kvs("db", dbConn.Info.GetLogDesc(), "table", form.TableName, "num", form.Num)
+
+	biz.ErrIsNil(d.dbDataGenApp.GenData(rc.MetaCtx, &dto.DbDataGen{
+		DbConn:    dbConn,
+		TableName: form.TableName,
+		Num:       form.Num,
+		BatchSize: form.BatchSize,
+		Columns:   form.Columns,
+		ClientId:  form.ClientId,
+	}))
+}
+
 func getDbId(rc *req.Ctx) uint64 {
 	dbId := rc.PathParamInt("dbId")
 	biz.IsTrue(dbId > 0, "dbId error")
@@ -4,6 +4,7 @@ import (
 	"mayfly-go/internal/db/api/form"
 	"mayfly-go/internal/db/api/vo"
 	"mayfly-go/internal/db/application"
+	"mayfly-go/internal/db/application/dto"
 	"mayfly-go/internal/db/domain/entity"
 	"mayfly-go/internal/db/imsg"
 	"mayfly-go/internal/pkg/utils"
@@ -11,6 +12,7 @@ import (
 	"mayfly-go/pkg/model"
 	"mayfly-go/pkg/req"
 	"mayfly-go/pkg/utils/stringx"
+	"mayfly-go/pkg/utils/structx"
 	"strings"
 
 	"github.com/may-fly/cast"
@@ -64,17 +66,45 @@ func (d *DataSyncTask) Logs(rc *req.Ctx) {
 }
 
 func (d *DataSyncTask) SaveTask(rc *req.Ctx) {
-	form, task := req.BindJsonAndCopyTo[*form.DataSyncTaskForm, *entity.DataSyncTask](rc)
-
-	// 解码base64 sql
-	sqlStr, err := utils.AesDecryptByLa(task.DataSql, rc.GetLoginAccount())
-	biz.ErrIsNilAppendErr(err, "sql decoding failure: %s")
-	sql := stringx.TrimSpaceAndBr(sqlStr)
-	task.DataSql = sql
-	form.DataSql = sql
-
-	rc.ReqParam = form
-	biz.ErrIsNil(d.dataSyncTaskApp.Save(rc.MetaCtx, task))
+	taskForm, task := req.BindJsonAndCopyTo[*form.DataSyncTaskForm, *entity.DataSyncTask](rc)
+
+	tableForms := taskForm.Tables
+	// 兼容旧版本单表同步配置
+	if len(tableForms) == 0 && taskForm.DataSql != "" {
+		tableForms = []*form.DataSyncTaskTableForm{{
+			DataSql:           taskForm.DataSql,
+			UpdField:          taskForm.UpdField,
+			UpdFieldVal:       taskForm.UpdFieldVal,
+			UpdFieldSrc:       taskForm.UpdFieldSrc,
+			TargetTableName:   taskForm.TargetTableName,
+			FieldMap:          taskForm.FieldMap,
+			DuplicateStrategy: taskForm.DuplicateStrategy,
+		}}
+	}
+	biz.IsTrue(len(tableForms) > 0, "tables cannot be empty")
+	// 同步配置统一保存至同步表，任务中的旧版单表字段不再保存(避免保存加密的sql及过期的更新值)
+	task.DataSql = ""
+	task.UpdField = ""
+	task.UpdFieldVal = ""
+	task.UpdFieldSrc = ""
+	task.TargetTableName = ""
+	task.FieldMap = ""
+	task.DuplicateStrategy = 0
+
+	tables := make([]*entity.DataSyncTaskTable, 0, len(tableForms))
+	for _, tableForm := range tableForms {
+		// 解码base64 sql
+		sqlStr, err := utils.AesDecryptByLa(tableForm.DataSql, rc.GetLoginAccount())
+		biz.ErrIsNilAppendErr(err, "sql decoding failure: %s")
+		tableForm.DataSql = stringx.TrimSpaceAndBr(sqlStr)
+
+		tables = append(tables, structx.CopyTo[*entity.DataSyncTaskTable](tableForm))
+	}
+	rc.ReqParam = taskForm
+	biz.ErrIsNil(d.dataSyncTaskApp.SaveTask(rc.MetaCtx, &dto.SaveDataSyncTask{
+		DataSyncTask: task,
+		Tables:       tables,
+	}))
 }
 
 func (d *DataSyncTask) DeleteTask(rc *req.Ctx) {
@@ -116,8 +146,15 @@ func (d *DataSyncTask) Stop(rc *req.Ctx) {
 
 func (d *DataSyncTask) GetTask(rc *req.Ctx) {
 	taskId := d.getTaskId(rc)
-	dbEntity, _ := d.dataSyncTaskApp.GetById(taskId)
-	rc.ResData = dbEntity
+	task, err := d.dataSyncTaskApp.GetById(taskId)
+	biz.ErrIsNil(err, "task not found")
+
+	tables, err := d.dataSyncTaskApp.GetTaskTables(taskId)
+	biz.ErrIsNil(err)
+	rc.ResData = &vo.DataSyncTaskVO{
+		DataSyncTask: task,
+		Tables:       tables,
+	}
 }
 
 func (d *DataSyncTask) getTaskId(rc *req.Ctx) uint64 {
@@ -3,14 +3,22 @@ package api
 import (
 	"mayfly-go/internal/db/api/form"
 	"mayfly-go/internal/db/application"
+	"mayfly-go/internal/db/application/dto"
 	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/internal/db/imsg"
+	tagapp "mayfly-go/internal/tag/application"
+	tagentity "mayfly-go/internal/tag/domain/entity"
 	"mayfly-go/pkg/biz"
-	"mayfly-go/pkg/model"
 	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/collx"
+	"mayfly-go/pkg/utils/jsonx"
 )
 
 type DbSql struct {
 	dbSqlApp application.DbSql `inject:"T"`
+	dbApp    application.Db    `inject:"T"`
+	tagApp   tagapp.TagTree    `inject:"T"`
+	teamApp  tagapp.Team       `inject:"T"`
 }
 
 func (d *DbSql) ReqConfs() *req.Confs {
@@ -23,6 +31,22 @@ func (d *DbSql) ReqConfs() *req.Confs {
 		req.NewDelete(":dbId/sql", d.DeleteSql),
 
 		req.NewGet(":dbId/sql-names", d.GetSqlNames),
+
+		req.NewPost(":dbId/sql-params", d.ParseSqlParams),
+
+		req.NewGet(":dbId/sql/:sqlId", d.GetSqlById),
+
+		req.NewGet(":dbId/sql/:sqlId/params", d.GetSqlParams),
+
+		req.NewGet(":dbId/sql/:sqlId/versions", d.GetSqlVersions),
+
+		req.NewGet(":dbId/sql/:sqlId/versions/diff", d.DiffSqlVersions),
+
+		req.NewGet(":dbId/sql/:sqlId/share", d.GetShareTeams),
+
+		req.NewPost(":dbId/sql/:sqlId/share", d.ShareSql).Log(req.NewLogSaveI(imsg.LogDbSqlShare)),
+
+		req.NewPost(":dbId/sql/:sqlId/query", d.QueryWithParams),
 	}
 
 	return req.NewConfs("/dbs", reqs[:]...)
@@ -33,32 +57,15 @@ func (d *DbSql) SaveSql(rc *req.Ctx) {
 	dbSqlForm := req.BindJsonAndValid[*form.DbSqlSaveForm](rc)
 	rc.ReqParam = dbSqlForm
 
-	dbId := getDbId(rc)
-
-	account := rc.GetLoginAccount()
-	// 获取用于是否有该dbsql的保存记录，有则更改，否则新增
-	dbSql := &entity.DbSql{Type: dbSqlForm.Type, DbId: dbId, Name: dbSqlForm.Name, Db: dbSqlForm.Db}
-	dbSql.CreatorId = account.Id
-	e := d.dbSqlApp.GetByCond(dbSql)
-
-	// 更新sql信息
-	dbSql.Sql = dbSqlForm.Sql
-	if e == nil {
-		d.dbSqlApp.UpdateById(rc.MetaCtx, dbSql)
-	} else {
-		d.dbSqlApp.Insert(rc.MetaCtx, dbSql)
-	}
+	dbSql := &entity.DbSql{Type: dbSqlForm.Type, DbId: getDbId(rc), Name: dbSqlForm.Name, Db: dbSqlForm.Db, Sql: dbSqlForm.Sql, Folder: dbSqlForm.Folder}
+	dbSql.CreatorId = rc.GetLoginAccount().Id
+	biz.ErrIsNil(d.dbSqlApp.SaveSql(rc.MetaCtx, &dto.SaveDbSql{DbSql: dbSql, Params: dbSqlForm.Params, Remark: dbSqlForm.Remark}))
 }
 
-// 获取所有保存的sql names
+// 获取所有保存的sql names，包括共享至所在团队的sql
 func (d *DbSql) GetSqlNames(rc *req.Ctx) {
-	dbId := getDbId(rc)
-	dbName := getDbName(rc)
-	// 获取用于是否有该dbsql的保存记录，有则更改，否则新增
-	dbSql := &entity.DbSql{Type: 1, DbId: dbId, Db: dbName}
-	dbSql.CreatorId = rc.GetLoginAccount().Id
-	sqls, _ := d.dbSqlApp.ListByCond(model.NewModelCond(dbSql).Columns("id", "name"))
-
+	sqls, err := d.dbSqlApp.GetAccessibleSqls(rc.GetLoginAccount().Id, getDbId(rc), getDbName(rc))
+	biz.ErrIsNil(err)
 	rc.ResData = sqls
 }
 
@@ -68,8 +75,9 @@ func (d *DbSql) DeleteSql(rc *req.Ctx) {
 	dbSql.CreatorId = rc.GetLoginAccount().Id
 	dbSql.Name = rc.Query("name")
 	dbSql.Db = rc.Query("db")
+	biz.ErrIsNil(d.dbSqlApp.GetByCond(dbSql), "sql not found")
 
-	biz.ErrIsNil(d.dbSqlApp.DeleteByCond(rc.MetaCtx, dbSql))
+	biz.ErrIsNil(d.dbSqlApp.DeleteSql(rc.MetaCtx, dbSql.CreatorId, dbSql.Id))
 }
 
 // @router /api/db/:dbId/sql [get]
@@ -87,3 +95,97 @@ func (d *DbSql) GetSql(rc *req.Ctx) {
 	}
 	rc.ResData = dbSql
 }
+
+// @router /api/db/:dbId/sql/:sqlId [get]
+func (d *DbSql) GetSqlById(rc *req.Ctx) {
+	rc.ResData = d.getAccessibleSql(rc)
+}
+
+// 获取sql的命名参数定义
+func (d *DbSql) GetSqlParams(rc *req.Ctx) {
+	dbSql := d.getAccessibleSql(rc)
+	params := make([]*entity.DbSqlParam, 0)
+	if dbSql.Params != "" {
+		var err error
+		params, err = jsonx.To[[]*entity.DbSqlParam](dbSql.Params)
+		biz.ErrIsNil(err)
+	}
+	rc.ResData = params
+}
+
+// 获取sql历史版本
+func (d *DbSql) GetSqlVersions(rc *req.Ctx) {
+	dbSql := d.getAccessibleSql(rc)
+	versions, err := d.dbSqlApp.GetVersions(dbSql.Id)
+	biz.ErrIsNil(err)
+	rc.ResData = versions
+}
+
+// 对比sql两个版本的差异
+func (d *DbSql) DiffSqlVersions(rc *req.Ctx) {
+	dbSql := d.getAccessibleSql(rc)
+	from := rc.QueryInt("from")
+	to := rc.QueryInt("to")
+	biz.IsTrue(from > 0 && to > 0, "from and to version cannot be empty")
+
+	diff, err := d.dbSqlApp.DiffVersions(dbSql.Id, from, to)
+	biz.ErrIsNil(err)
+	rc.ResData = diff
+}
+
+// 解析sql中的命名参数，并合并已有的参数定义
+func (d *DbSql) ParseSqlParams(rc *req.Ctx) {
+	parseForm := req.BindJsonAndValid[*form.DbSqlParamsParseForm](rc)
+	params, err := d.dbSqlApp.ParseParams(parseForm.Sql, parseForm.Params)
+	biz.ErrIsNil(err)
+	rc.ResData = params
+}
+
+// 获取sql共享的团队及当前账号可共享的团队
+func (d *DbSql) GetShareTeams(rc *req.Ctx) {
+	dbSql := d.getAccessibleSql(rc)
+	teams := make([]*tagentity.Team, 0)
+	if teamIds := d.teamApp.GetAccountTeamIds(rc.GetLoginAccount().Id); len(teamIds) > 0 {
+		var err error
+		teams, err = d.teamApp.GetByIds(teamIds, "Id", "Name")
+		biz.ErrIsNil(err)
+	}
+	rc.ResData = collx.M{
+		"teamIds": d.dbSqlApp.GetShareTeamIds(dbSql.Id),
+		"teams":   teams,
+	}
+}
+
+// 共享sql至团队
+func (d *DbSql) ShareSql(rc *req.Ctx) {
+	shareForm := req.BindJsonAndValid[*form.DbSqlShareForm](rc)
+	rc.ReqParam = shareForm
+
+	sqlId := uint64(rc.PathParamInt("sqlId"))
+	biz.ErrIsNil(d.dbSqlApp.Share(rc.MetaCtx, rc.GetLoginAccount().Id, sqlId, shareForm.TeamIds))
+}
+
+// 使用参数值执行保存的sql查询
+func (d *DbSql) QueryWithParams(rc *req.Ctx) {
+	queryForm := req.BindJsonAndValid[*form.DbSqlQueryForm](rc)
+	rc.ReqParam = queryForm
+
+	dbSql := d.getAccessibleSql(rc)
+	dbConn, err := d.dbApp.GetDbConn(rc.MetaCtx, dbSql.DbId, dbSql.Db)
+	biz.ErrIsNil(err)
+	biz.ErrIsNilAppendErr(d.tagApp.CanAccess(rc.GetLoginAccount().Id, dbConn.Info.CodePath...), "%s")
+
+	res, err := d.dbSqlApp.QueryWithParams(rc.MetaCtx, dbConn, dbSql, queryForm.Params)
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+func (d *DbSql) getAccessibleSql(rc *req.Ctx) *entity.DbSql {
+	sqlId := rc.PathParamInt("sqlId")
+	biz.IsTrue(sqlId > 0, "sqlId error")
+
+	dbSql, err := d.dbSqlApp.GetAccessibleSql(rc.GetLoginAccount().Id, uint64(sqlId))
+	biz.ErrIsNil(err)
+	biz.IsTrue(dbSql.DbId == getDbId(rc), "sql does not belong to the db")
+	return dbSql
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"mayfly-go/internal/db/api/form"
+	"mayfly-go/internal/db/application"
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/internal/db/imsg"
+	tagapp "mayfly-go/internal/tag/application"
+	tagentity "mayfly-go/internal/tag/domain/entity"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/collx"
+	"strings"
+
+	"github.com/may-fly/cast"
+)
+
+type DbSqlReport struct {
+	dbSqlReportApp application.DbSqlReport `inject:"T"`
+	dbApp          application.Db          `inject:"T"`
+	dbSqlApp       application.DbSql       `inject:"T"`
+	tagApp         tagapp.TagTree          `inject:"T"`
+}
+
+func (d *DbSqlReport) ReqConfs() *req.Confs {
+	reqs := [...]*req.Conf{
+		// 获取报表列表
+		req.NewGet("", d.Reports),
+
+		req.NewGet(":reportId", d.GetReport),
+
+		req.NewGet(":reportId/logs", d.Logs),
+
+		req.NewPost("", d.SaveReport).Log(req.NewLogSaveI(imsg.LogSqlReportSave)).RequiredPermissionCode("db:sqlreport:save"),
+
+		req.NewDelete(":reportId", d.DeleteReport).Log(req.NewLogSaveI(imsg.LogSqlReportDelete)).RequiredPermissionCode("db:sqlreport:del"),
+
+		req.NewPost(":reportId/status", d.ChangeStatus).Log(req.NewLogSaveI(imsg.LogSqlReportChangeStatus)).RequiredPermissionCode("db:sqlreport:save"),
+
+		// 立即执行报表
+		req.NewPost(":reportId/run", d.Run).Log(req.NewLogSaveI(imsg.LogSqlReportRun)).RequiredPermissionCode("db:sqlreport:save"),
+	}
+
+	return req.NewConfs("/dbs/sql-reports", reqs[:]...)
+}
+
+func (d *DbSqlReport) Reports(rc *req.Ctx) {
+	queryCond := req.BindQuery[*entity.DbSqlReportQuery](rc)
+
+	// 仅可查看自己创建或可访问数据库下的报表
+	accountId := rc.GetLoginAccount().Id
+	queryCond.CreatorId = accountId
+	if dbCodes := d.tagApp.GetAccountTags(accountId, &tagentity.TagTreeQuery{Types: collx.AsArray(tagentity.TagTypeDb)}).GetCodes(); len(dbCodes) > 0 {
+		dbs, err := d.dbApp.ListByCond(model.NewCond().In("code", dbCodes), "id")
+		biz.ErrIsNil(err)
+		queryCond.DbIds = collx.ArrayMap(dbs, func(db *entity.Db) uint64 {
+			return db.Id
+		})
+	}
+
+	res, err := d.dbSqlReportApp.GetPageList(queryCond)
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+func (d *DbSqlReport) GetReport(rc *req.Ctx) {
+	rc.ResData = d.getAccessibleReport(rc, d.getReportId(rc))
+}
+
+func (d *DbSqlReport) Logs(rc *req.Ctx) {
+	queryCond := req.BindQuery[*entity.DbSqlReportLogQuery](rc)
+	queryCond.ReportId = d.getAccessibleReport(rc, d.getReportId(rc)).Id
+	res, err := d.dbSqlReportApp.GetLogPageList(queryCond)
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+func (d *DbSqlReport) SaveReport(rc *req.Ctx) {
+	reportForm, report := req.BindJsonAndCopyTo[*form.DbSqlReportForm, *entity.DbSqlReport](rc)
+	rc.ReqParam = reportForm
+
+	if report.Id != 0 {
+		d.getAccessibleReport(rc, report.Id)
+	}
+
+	// 校验目标数据库的访问权限
+	accountId := rc.GetLoginAccount().Id
+	dbConn, err := d.dbApp.GetDbConn(rc.MetaCtx, report.DbId, report.Db)
+	biz.ErrIsNil(err)
+	biz.ErrIsNilAppendErr(d.tagApp.CanAccess(accountId, dbConn.Info.CodePath...), "%s")
+
+	// 校验保存的sql为自己创建或共享至所在团队的sql
+	_, err = d.dbSqlApp.GetAccessibleSql(accountId, report.DbSqlId)
+	biz.ErrIsNil(err)
+
+	biz.ErrIsNil(d.dbSqlReportApp.SaveReport(rc.MetaCtx, report))
+}
+
+func (d *DbSqlReport) DeleteReport(rc *req.Ctx) {
+	reportId := rc.PathParam("reportId")
+	rc.ReqParam = reportId
+
+	for _, v := range strings.Split(reportId, ",") {
+		id := d.getAccessibleReport(rc, cast.ToUint64(v)).Id
+		biz.ErrIsNil(d.dbSqlReportApp.DeleteReport(rc.MetaCtx, id))
+	}
+}
+
+func (d *DbSqlReport) ChangeStatus(rc *req.Ctx) {
+	statusForm := req.BindJsonAndValid[*form.DbSqlReportStatusForm](rc)
+	rc.ReqParam = statusForm
+	d.getAccessibleReport(rc, statusForm.Id)
+	biz.ErrIsNil(d.dbSqlReportApp.ChangeStatus(rc.MetaCtx, statusForm.Id, statusForm.Status))
+}
+
+func (d *DbSqlReport) Run(rc *req.Ctx) {
+	reportId := d.getReportId(rc)
+	rc.ReqParam = reportId
+	d.getAccessibleReport(rc, reportId)
+	biz.ErrIsNil(d.dbSqlReportApp.Run(rc.MetaCtx, reportId))
+}
+
+// getAccessibleReport 获取报表，仅报表创建者或拥有目标数据库访问权限的账号可访问
+func (d *DbSqlReport) getAccessibleReport(rc *req.Ctx, reportId uint64) *entity.DbSqlReport {
+	report, err := d.dbSqlReportApp.GetById(reportId)
+	biz.ErrIsNil(err, "report not found")
+
+	accountId := rc.GetLoginAccount().Id
+	if report.CreatorId == accountId {
+		return report
+	}
+	db, err := d.dbApp.GetById(report.DbId, "code")
+	biz.ErrIsNil(err, "db not found")
+	biz.ErrIsNilAppendErr(d.tagApp.CanAccess(accountId, d.tagApp.ListTagPathByTypeAndCode(int8(tagentity.TagTypeDb), db.Code)...), "%s")
+	return report
+}
+
+func (d *DbSqlReport) getReportId(rc *req.Ctx) uint64 {
+	reportId := rc.PathParamInt("reportId")
+	biz.IsTrue(reportId > 0, "reportId error")
+	return uint64(reportId)
+}
@@ -146,6 +146,7 @@ func (d *DbTransferTask) FileRun(rc *req.Ctx) {
 
 	tFile, err := d.dbTransferFile.GetById(fm.Id)
 	biz.IsTrue(tFile != nil && err == nil, "file not found")
+	biz.IsTrue(tFile.FileType == "" || tFile.FileType == entity.DbTransferFileTypeSql, "only sql files can be executed")
 
 	targetDbConn, err := d.dbApp.GetDbConn(rc.MetaCtx, fm.TargetDbId, fm.TargetDbName)
 	biz.ErrIsNilAppendErr(err, "failed to connect to the target database: %s")
@@ -1,6 +1,9 @@
 package form
 
-import "mayfly-go/internal/db/domain/entity"
+import (
+	"mayfly-go/internal/db/application/dto"
+	"mayfly-go/internal/db/domain/entity"
+)
 
 type DbForm struct {
 	Id              uint64                   `json:"id"`
@@ -13,10 +16,29 @@ type DbForm struct {
 }
 
 type DbSqlSaveForm struct {
-	Name string `json:"name" binding:"required"`
-	Sql  string `json:"sql" binding:"required"`
-	Type int    `json:"type" binding:"required"`
-	Db   string `json:"db" binding:"required"`
+	Name   string               `json:"name" binding:"required"`
+	Sql    string               `json:"sql" binding:"required"`
+	Type   int                  `json:"type" binding:"required"`
+	Db     string               `json:"db" binding:"required"`
+	Folder string               `json:"folder"`
+	Params []*entity.DbSqlParam `json:"params"` // 命名参数定义，未定义的参数默认为字符串类型
+	Remark string               `json:"remark"` // 版本说明
+}
+
+// DbSqlParamsParseForm sql命名参数解析表单
+type DbSqlParamsParseForm struct {
+	Sql    string               `json:"sql" binding:"required"`
+	Params []*entity.DbSqlParam `json:"params"` // 已有的参数定义
+}
+
+// DbSqlShareForm sql共享表单
+type DbSqlShareForm struct {
+	TeamIds []uint64 `json:"teamIds"`
+}
+
+// DbSqlQueryForm 带参数执行保存的sql表单
+type DbSqlQueryForm struct {
+	Params map[string]string `json:"params"`
 }
 
 // 数据库SQL执行表单
@@ -34,3 +56,13 @@ type DbCopyTableForm struct {
 	TableName string `binding:"required" json:"tableName"`
 	CopyData  bool   `json:"copyData"` // 是否复制数据
 }
+
+// 表测试数据生成
+type DbDataGenForm struct {
+	Db        string                 `binding:"required" json:"db"`
+	TableName string                 `binding:"required" json:"tableName"`
+	Num       int                    `binding:"required" json:"num"` // 生成条数
+	BatchSize int                    `json:"batchSize"`              // 每批插入条数
+	Columns   []*dto.DbDataGenColumn `json:"columns"`                // 自定义列生成规则
+	ClientId  string                 `json:"clientId"`
+}
@@ -7,20 +7,37 @@ type DataSyncTaskForm struct {
 	TaskKey  string `json:"taskKey"`
 	Status   int    `binding:"required" json:"status"`
 
-	SrcDbId     int64  `binding:"required" json:"srcDbId"`
-	SrcDbName   string `binding:"required" json:"srcDbName"`
-	SrcTagPath  string `binding:"required" json:"srcTagPath"`
-	DataSql     string `binding:"required" json:"dataSql"`
-	PageSize    int    `binding:"required" json:"pageSize"`
-	UpdField    string `binding:"required" json:"updField"`
-	UpdFieldVal string `binding:"required" json:"updFieldVal"`
-	UpdFieldSrc string `json:"updFieldSrc"`
+	SrcDbId    int64  `binding:"required" json:"srcDbId"`
+	SrcDbName  string `binding:"required" json:"srcDbName"`
+	SrcTagPath string `binding:"required" json:"srcTagPath"`
+	PageSize   int    `binding:"required" json:"pageSize"`
 
-	TargetDbId        int64  `binding:"required" json:"targetDbId"`
-	TargetDbName      string `binding:"required" json:"targetDbName"`
-	TargetTagPath     string `binding:"required" json:"targetTagPath"`
-	TargetTableName   string `binding:"required" json:"targetTableName"`
-	FieldMap          string `binding:"required" json:"fieldMap"`
+	TargetDbId    int64  `binding:"required" json:"targetDbId"`
+	TargetDbName  string `binding:"required" json:"targetDbName"`
+	TargetTagPath string `binding:"required" json:"targetTagPath"`
+
+	// 多表同步配置，按sort依赖顺序执行
+	Tables []*DataSyncTaskTableForm `json:"tables"`
+
+	// 单表同步配置，兼容旧版本，tables为空时使用
+	DataSql           string `json:"dataSql"`
+	UpdField          string `json:"updField"`
+	UpdFieldVal       string `json:"updFieldVal"`
+	UpdFieldSrc       string `json:"updFieldSrc"`
+	TargetTableName   string `json:"targetTableName"`
+	FieldMap          string `json:"fieldMap"`
+	DuplicateStrategy int    `json:"duplicateStrategy"`
+}
+
+type DataSyncTaskTableForm struct {
+	Id                uint64 `json:"id"`
+	Sort              int    `json:"sort"`
+	DataSql           string `json:"dataSql"`
+	UpdField          string `json:"updField"`
+	UpdFieldVal       string `json:"updFieldVal"`
+	UpdFieldSrc       string `json:"updFieldSrc"`
+	TargetTableName   string `json:"targetTableName"`
+	FieldMap          string `json:"fieldMap"`
 	DuplicateStrategy int    `json:"duplicateStrategy"`
 }
 
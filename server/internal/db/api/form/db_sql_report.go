@@ -0,0 +1,21 @@
+package form
+
+type DbSqlReportForm struct {
+	Id             uint64 `json:"id"`
+	Name           string `binding:"required" json:"name"`
+	DbSqlId        uint64 `binding:"required" json:"dbSqlId"`
+	DbId           uint64 `binding:"required" json:"dbId"`
+	Db             string `binding:"required" json:"db"`
+	Cron           string `binding:"required" json:"cron"`
+	ResultFormat   string `binding:"required" json:"resultFormat"`
+	MaxRows        int    `json:"maxRows"`
+	MsgTmplId      uint64 `binding:"required" json:"msgTmplId"`
+	AlertMsgTmplId uint64 `json:"alertMsgTmplId"`
+	ReceiverIds    string `json:"receiverIds"`
+	Status         int8   `json:"status"`
+}
+
+type DbSqlReportStatusForm struct {
+	Id     uint64 `binding:"required" json:"id"`
+	Status int8   `binding:"required" json:"status"`
+}
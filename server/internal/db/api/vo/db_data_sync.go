@@ -1,6 +1,9 @@
 package vo
 
-import "time"
+import (
+	"mayfly-go/internal/db/domain/entity"
+	"time"
+)
 
 type DataSyncTaskListVO struct {
 	Id           int64      `json:"id"`
@@ -20,6 +23,12 @@ type DataSyncLogListVO struct {
 	CreateTime  *time.Time `json:"createTime"`
 	DataSqlFull string     `json:"dataSqlFull"`
 	ResNum      int        `json:"resNum"`
+	TableStats  string     `json:"tableStats"`
 	ErrText     string     `json:"errText"`
 	Status      *int       `json:"status"`
 }
+
+type DataSyncTaskVO struct {
+	*entity.DataSyncTask
+	Tables []*entity.DataSyncTaskTable `json:"tables"` // 同步表配置
+}
@@ -18,6 +18,7 @@ type DbTransferTaskListVO struct {
 	CronAble         int    `json:"cronAble"`         // 是否定时  1是 -1否
 	Cron             string `json:"cron"`             // 定时任务cron表达式
 	Mode             int    `json:"mode"`             // 数据迁移方式，1、迁移到数据库  2、迁移到文件
+	TargetFileType   string `json:"targetFileType"`   // 目标文件类型
 	TargetFileDbType string `json:"targetFileDbType"` // 目标文件数据库类型
 	FileSaveDays     int    `json:"fileSaveDays"`     // 文件保存天数
 
@@ -13,6 +13,8 @@ func InitIoc() {
 	ioc.Register(new(dataSyncAppImpl), ioc.WithComponentName("DbDataSyncTaskApp"))
 	ioc.Register(new(dbTransferAppImpl), ioc.WithComponentName("DbTransferTaskApp"))
 	ioc.Register(new(dbTransferFileAppImpl), ioc.WithComponentName("DbTransferFileApp"))
+	ioc.Register(new(dbDataGenAppImpl), ioc.WithComponentName("DbDataGenApp"))
+	ioc.Register(new(dbSqlReportAppImpl), ioc.WithComponentName("DbSqlReportApp"))
 }
 
 func Init() {
@@ -20,6 +22,7 @@ func Init() {
 		GetDataSyncTaskApp().InitCronJob()
 		GetDbTransferTaskApp().InitCronJob()
 		GetDbTransferTaskApp().TimerDeleteTransferFile()
+		GetDbSqlReportApp().InitCronJob()
 		InitDbFlowHandler()
 	})()
 }
@@ -32,6 +35,10 @@ func GetDataSyncTaskApp() DataSyncTask {
 	return ioc.Get[DataSyncTask]("DbDataSyncTaskApp")
 }
 
+func GetDbSqlReportApp() DbSqlReport {
+	return ioc.Get[DbSqlReport]("DbSqlReportApp")
+}
+
 func GetDbTransferTaskApp() DbTransferTask {
 	return ioc.Get[DbTransferTask]("DbTransferTaskApp")
 }
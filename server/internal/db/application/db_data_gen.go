@@ -0,0 +1,467 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"mayfly-go/internal/db/application/dto"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/internal/db/imsg"
+	msgapp "mayfly-go/internal/msg/application"
+	msgdto "mayfly-go/internal/msg/application/dto"
+	"mayfly-go/pkg/contextx"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/i18n"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/utils/anyx"
+	"mayfly-go/pkg/utils/collx"
+	"mayfly-go/pkg/utils/stringx"
+	"mayfly-go/pkg/ws"
+	"strings"
+	"time"
+
+	"github.com/may-fly/cast"
+)
+
+// 列数据生成器类型
+const (
+	DataGenSkip     = "skip"     // 不生成，由数据库默认值或自增填充
+	DataGenNull     = "null"     // null值
+	DataGenFixed    = "fixed"    // 固定值
+	DataGenInt      = "int"      // 范围内整数
+	DataGenDecimal  = "decimal"  // 范围内小数
+	DataGenString   = "string"   // 随机字符串
+	DataGenName     = "name"     // 姓名
+	DataGenEmail    = "email"    // 邮箱
+	DataGenPhone    = "phone"    // 手机号
+	DataGenUUID     = "uuid"     // uuid
+	DataGenBool     = "bool"     // 布尔值
+	DataGenDate     = "date"     // 范围内日期
+	DataGenDatetime = "datetime" // 范围内日期时间
+	DataGenTime     = "time"     // 时间
+	DataGenEnum     = "enum"     // 从可选值中随机选择
+	DataGenRef      = "ref"      // 从关联表的列值中采样
+)
+
+const (
+	dataGenProgressCategory = "dataGenProgress"
+	dataGenMaxNum           = 1000000 // 单次最大生成条数
+	dataGenDefaultBatchSize = 500
+	dataGenSampleLimit      = 1000 // 枚举、关联表采样值最大数量
+	dataGenEnumMaxDistinct  = 20   // 已有数据不同值数量不超过该值时，视为枚举列
+)
+
+// 名称中包含以下关键字的列，尝试从已有数据中获取枚举值
+var dataGenEnumColumnKeywords = []string{"status", "state", "type", "category", "gender", "sex", "level", "kind"}
+
+type DbDataGen interface {
+	// GetColumnRules 根据表的列信息推断各列的数据生成规则
+	GetColumnRules(ctx context.Context, dbConn *dbi.DbConn, tableName string) ([]*dto.DbDataGenColumn, error)
+
+	// GenData 后台生成表测试数据，并通过websocket推送生成进度
+	GenData(ctx context.Context, param *dto.DbDataGen) error
+}
+
+type dbDataGenAppImpl struct {
+	msgApp msgapp.Msg `inject:"T"`
+}
+
+var _ (DbDataGen) = (*dbDataGenAppImpl)(nil)
+
+// dataGenProgressMsg 数据生成进度消息
+type dataGenProgressMsg struct {
+	Id         string `json:"id"`
+	Title      string `json:"title"`
+	Total      int    `json:"total"`
+	Inserted   int    `json:"inserted"`
+	Terminated bool   `json:"terminated"`
+}
+
+func (d *dbDataGenAppImpl) GetColumnRules(ctx context.Context, dbConn *dbi.DbConn, tableName string) ([]*dto.DbDataGenColumn, error) {
+	columns, err := dbConn.GetMetadata().GetColumns(tableName)
+	if err != nil {
+		return nil, errorx.NewBiz("failed to get table [%s] columns: %s", tableName, err.Error())
+	}
+
+	fks := d.getForeignKeys(dbConn, tableName)
+	rules := make([]*dto.DbDataGenColumn, 0, len(columns))
+	for _, column := range columns {
+		rule := inferDataGenRule(dbConn.Info.Type, column, fks)
+		if rule.Generator == DataGenString && isDataGenEnumColumn(column.ColumnName) {
+			values, err := d.sampleColumnValues(ctx, dbConn, tableName, column.ColumnName, dataGenEnumMaxDistinct+1)
+			if err == nil && len(values) > 0 && len(values) <= dataGenEnumMaxDistinct {
+				rule.Generator = DataGenEnum
+				rule.Values = values
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (d *dbDataGenAppImpl) GenData(ctx context.Context, param *dto.DbDataGen) error {
+	if param.Num <= 0 || param.Num > dataGenMaxNum {
+		return errorx.NewBiz("the number of generated rows must be between 1 and %d", dataGenMaxNum)
+	}
+	if param.BatchSize <= 0 {
+		param.BatchSize = dataGenDefaultBatchSize
+	}
+
+	dbConn := param.DbConn
+	tableName := param.TableName
+	columns, err := dbConn.GetMetadata().GetColumns(tableName)
+	if err != nil {
+		return errorx.NewBiz("failed to get table [%s] columns: %s", tableName, err.Error())
+	}
+
+	customRules := collx.ArrayToMap(param.Columns, func(c *dto.DbDataGenColumn) string {
+		return c.ColumnName
+	})
+
+	// 需要插入的列及其对应的值生成函数
+	fks := d.getForeignKeys(dbConn, tableName)
+	insertColumns := make([]dbi.Column, 0, len(columns))
+	valueFuncs := make([]func() any, 0, len(columns))
+	for _, column := range columns {
+		rule := customRules[column.ColumnName]
+		if rule == nil {
+			rule = inferDataGenRule(dbConn.Info.Type, column, fks)
+		}
+		if rule.Generator == DataGenSkip {
+			continue
+		}
+
+		if rule.Generator == DataGenRef {
+			if rule.RefTable == "" || rule.RefColumn == "" {
+				return errorx.NewBiz("column [%s] reference table and column cannot be empty", column.ColumnName)
+			}
+			values, err := d.sampleColumnValues(ctx, dbConn, rule.RefTable, rule.RefColumn, dataGenSampleLimit)
+			if err != nil {
+				return errorx.NewBiz("failed to sample reference values of column [%s]: %s", column.ColumnName, err.Error())
+			}
+			if len(values) == 0 {
+				return errorx.NewBiz("column [%s] reference table [%s] has no data", column.ColumnName, rule.RefTable)
+			}
+			rule.Values = values
+		}
+
+		valueFunc, err := newDataGenValueFunc(column, rule)
+		if err != nil {
+			return err
+		}
+		insertColumns = append(insertColumns, column)
+		valueFuncs = append(valueFuncs, valueFunc)
+	}
+	if len(insertColumns) == 0 {
+		return errorx.NewBiz("there is no column to generate data")
+	}
+
+	la := contextx.GetLoginAccount(ctx)
+	go d.doGenData(contextx.NewLoginAccount(la), param, insertColumns, valueFuncs)
+	return nil
+}
+
+func (d *dbDataGenAppImpl) doGenData(ctx context.Context, param *dto.DbDataGen, columns []dbi.Column, valueFuncs []func() any) {
+	dbConn := param.DbConn
+	tableName := param.TableName
+	clientId := param.ClientId
+	la := contextx.GetLoginAccount(ctx)
+	needSendMsg := la != nil && clientId != ""
+	title := fmt.Sprintf("%s.%s", dbConn.Info.GetLogDesc(), tableName)
+
+	inserted := 0
+	progressId := stringx.Rand(32)
+	sendProgress := func(terminated bool) {
+		if needSendMsg {
+			ws.SendJsonMsg(ws.UserId(la.Id), clientId, msgdto.InfoSysMsg(i18n.T(imsg.DataGenProgress), &dataGenProgressMsg{
+				Id:         progressId,
+				Title:      title,
+				Total:      param.Num,
+				Inserted:   inserted,
+				Terminated: terminated,
+			}).WithCategory(dataGenProgressCategory))
+		}
+	}
+
+	defer func() {
+		if err := recover(); err != nil {
+			errInfo := anyx.ToString(err)
+			logx.Errorf("generate table [%s] data error: %s", title, errInfo)
+			if needSendMsg {
+				d.msgApp.CreateAndSend(la, msgdto.ErrSysMsg(i18n.T(imsg.DataGenFail), fmt.Sprintf("[%s] generate data error: %s", title, stringx.Truncate(errInfo, 300, 10, "..."))).WithClientId(clientId))
+			}
+		}
+	}()
+	defer sendProgress(true)
+
+	sqlGenerator := dbConn.GetDialect().GetSQLGenerator()
+	for inserted < param.Num {
+		batchNum := min(param.BatchSize, param.Num-inserted)
+		rows := make([][]any, batchNum)
+		for i := range rows {
+			row := make([]any, len(valueFuncs))
+			for j, valueFunc := range valueFuncs {
+				row[j] = valueFunc()
+			}
+			rows[i] = row
+		}
+
+		if err := d.insertBatch(dbConn, sqlGenerator.GenInsert(tableName, columns, rows, dbi.DuplicateStrategyNone)); err != nil {
+			logx.Errorf("generate table [%s] data error: %s", title, err.Error())
+			if needSendMsg {
+				errInfo := stringx.Truncate(err.Error(), 300, 10, "...")
+				d.msgApp.CreateAndSend(la, msgdto.ErrSysMsg(i18n.T(imsg.DataGenFail), fmt.Sprintf("[%s] inserted %d rows, error: %s", title, inserted, errInfo)).WithClientId(clientId))
+			}
+			return
+		}
+
+		inserted += batchNum
+		sendProgress(false)
+	}
+
+	logx.Infof("generate table [%s] data success, rows: %d", title, inserted)
+	if needSendMsg {
+		d.msgApp.CreateAndSend(la, msgdto.SuccessSysMsg(i18n.T(imsg.DataGenSuccess), fmt.Sprintf("[%s] inserted %d rows", title, inserted)).WithClientId(clientId))
+	}
+}
+
+// insertBatch 在同一事务中执行一批insert语句
+func (d *dbDataGenAppImpl) insertBatch(dbConn *dbi.DbConn, insertSqls []string) error {
+	tx, err := dbConn.Begin()
+	if err != nil {
+		return err
+	}
+	for _, insertSql := range insertSqls {
+		if _, err := dbConn.TxExec(tx, insertSql); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// getForeignKeys 获取表的外键信息，key为列名。数据库不支持或获取失败时返回空
+func (d *dbDataGenAppImpl) getForeignKeys(dbConn *dbi.DbConn, tableName string) map[string]dbi.ForeignKey {
+	fkMeta, ok := dbConn.GetMetadata().(dbi.ForeignKeyMetadata)
+	if !ok {
+		return nil
+	}
+	fks, err := fkMeta.GetForeignKeys(tableName)
+	if err != nil {
+		logx.Warnf("failed to get table [%s] foreign keys: %s", tableName, err.Error())
+		return nil
+	}
+
+	res := make(map[string]dbi.ForeignKey, len(fks))
+	for _, fk := range fks {
+		if _, ok := res[fk.ColumnName]; ok || fk.RefTableName == "" {
+			continue
+		}
+		// 未指定关联列时(如sqlite)，默认关联表主键
+		if fk.RefColumnName == "" {
+			if fk.RefColumnName, err = dbConn.GetMetadata().GetPrimaryKey(fk.RefTableName); err != nil {
+				continue
+			}
+		}
+		res[fk.ColumnName] = fk
+	}
+	return res
+}
+
+// sampleColumnValues 获取表中指定列的不同值，最多获取limit个
+func (d *dbDataGenAppImpl) sampleColumnValues(ctx context.Context, dbConn *dbi.DbConn, tableName string, columnName string, limit int) ([]string, error) {
+	quote := dbConn.GetDialect().Quoter().Quote
+	querySql := fmt.Sprintf("SELECT DISTINCT %s FROM %s WHERE %s IS NOT NULL", quote(columnName), quote(tableName), quote(columnName))
+
+	values := make([]string, 0)
+	_, err := dbConn.WalkQueryRows(ctx, querySql, func(row map[string]any, columns []*dbi.QueryColumn) error {
+		if len(values) >= limit {
+			return dbi.NewStopWalkQueryError("sample limit reached")
+		}
+		values = append(values, cast.ToString(row[columns[0].Name]))
+		return nil
+	})
+	return values, err
+}
+
+// inferDataGenRule 根据列名、数据类型及外键推断列的生成规则，外键列从关联表的列值中采样
+func inferDataGenRule(dbType dbi.DbType, column dbi.Column, fks map[string]dbi.ForeignKey) *dto.DbDataGenColumn {
+	rule := &dto.DbDataGenColumn{ColumnName: column.ColumnName, DataType: column.GetColumnType()}
+	if column.AutoIncrement {
+		rule.Generator = DataGenSkip
+		return rule
+	}
+	if fk, ok := fks[column.ColumnName]; ok {
+		rule.Generator, rule.RefTable, rule.RefColumn = DataGenRef, fk.RefTableName, fk.RefColumnName
+		return rule
+	}
+
+	switch dbi.GetDbDataType(dbType, column.DataType).DataType {
+	case dbi.DTBit, dbi.DTBool:
+		rule.Generator = DataGenBool
+	case dbi.DTByte, dbi.DTInt8:
+		rule.Generator, rule.Min, rule.Max = DataGenInt, "0", "100"
+	case dbi.DTInt16, dbi.DTInt32, dbi.DTInt64, dbi.DTUint64:
+		rule.Generator, rule.Min, rule.Max = DataGenInt, "1", "10000"
+	case dbi.DTNumeric, dbi.DTDecimal:
+		rule.Generator, rule.Min, rule.Max = DataGenDecimal, "0", "10000"
+	case dbi.DTDate:
+		rule.Generator = DataGenDate
+	case dbi.DTDateTime:
+		rule.Generator = DataGenDatetime
+	case dbi.DTTime:
+		rule.Generator = DataGenTime
+	case dbi.DTBytes:
+		rule.Generator = DataGenNull
+	default:
+		rule.Generator = inferStringDataGen(column.ColumnName)
+		rule.Length = min(max(column.CharMaxLength, 1), 32)
+	}
+
+	if rule.Generator == DataGenNull && !column.Nullable {
+		rule.Generator = DataGenSkip
+	}
+	return rule
+}
+
+// inferStringDataGen 根据列名推断字符串列的生成器
+func inferStringDataGen(columnName string) string {
+	name := strings.ToLower(columnName)
+	switch {
+	case strings.Contains(name, "email") || strings.Contains(name, "mail"):
+		return DataGenEmail
+	case strings.Contains(name, "phone") || strings.Contains(name, "mobile") || strings.Contains(name, "tel"):
+		return DataGenPhone
+	case strings.Contains(name, "uuid") || strings.Contains(name, "guid"):
+		return DataGenUUID
+	case strings.Contains(name, "name"):
+		return DataGenName
+	default:
+		return DataGenString
+	}
+}
+
+func isDataGenEnumColumn(columnName string) bool {
+	return collx.ArrayAnyMatches(dataGenEnumColumnKeywords, strings.ToLower(columnName))
+}
+
+// newDataGenValueFunc 根据生成规则创建列值生成函数
+func newDataGenValueFunc(column dbi.Column, rule *dto.DbDataGenColumn) (func() any, error) {
+	maxLength := column.CharMaxLength
+
+	switch rule.Generator {
+	case DataGenNull:
+		return func() any { return nil }, nil
+	case DataGenFixed:
+		return func() any { return rule.Value }, nil
+	case DataGenInt:
+		minVal, maxVal := cast.ToInt64(rule.Min), cast.ToInt64(rule.Max)
+		if maxVal < minVal {
+			return nil, errorx.NewBiz("column [%s] max value must be greater than min value", column.ColumnName)
+		}
+		return func() any { return minVal + rand.Int64N(maxVal-minVal+1) }, nil
+	case DataGenDecimal:
+		minVal, maxVal := cast.ToFloat64(rule.Min), cast.ToFloat64(rule.Max)
+		if maxVal < minVal {
+			return nil, errorx.NewBiz("column [%s] max value must be greater than min value", column.ColumnName)
+		}
+		scale := column.NumScale
+		if scale <= 0 {
+			scale = 2
+		}
+		return func() any {
+			return fmt.Sprintf("%.*f", scale, minVal+rand.Float64()*(maxVal-minVal))
+		}, nil
+	case DataGenString:
+		length := rule.Length
+		if length <= 0 {
+			length = 16
+		}
+		return func() any { return stringx.Rand(length) }, nil
+	case DataGenName:
+		return func() any { return truncateDataGenValue(randName(), maxLength) }, nil
+	case DataGenEmail:
+		return func() any {
+			return truncateDataGenValue(fmt.Sprintf("%s%d@%s", strings.ToLower(stringx.RandByChars(6, "abcdefghijklmnopqrstuvwxyz")), rand.IntN(1000), dataGenEmailDomains[rand.IntN(len(dataGenEmailDomains))]), maxLength)
+		}, nil
+	case DataGenPhone:
+		return func() any {
+			return truncateDataGenValue(fmt.Sprintf("1%s%s", dataGenPhonePrefixes[rand.IntN(len(dataGenPhonePrefixes))], stringx.RandByChars(8, "0123456789")), maxLength)
+		}, nil
+	case DataGenUUID:
+		return func() any { return truncateDataGenValue(stringx.RandUUID(), maxLength) }, nil
+	case DataGenBool:
+		return func() any { return rand.IntN(2) }, nil
+	case DataGenDate, DataGenDatetime:
+		layout := time.DateTime
+		if rule.Generator == DataGenDate {
+			layout = time.DateOnly
+		}
+		start, end, err := parseDataGenTimeRange(rule)
+		if err != nil {
+			return nil, errorx.NewBiz("column [%s] date range error: %s", column.ColumnName, err.Error())
+		}
+		seconds := int64(end.Sub(start).Seconds())
+		return func() any {
+			return start.Add(time.Duration(rand.Int64N(seconds+1)) * time.Second).Format(layout)
+		}, nil
+	case DataGenTime:
+		return func() any {
+			return fmt.Sprintf("%02d:%02d:%02d", rand.IntN(24), rand.IntN(60), rand.IntN(60))
+		}, nil
+	case DataGenEnum, DataGenRef:
+		values := rule.Values
+		if len(values) == 0 {
+			return nil, errorx.NewBiz("column [%s] optional values cannot be empty", column.ColumnName)
+		}
+		return func() any { return values[rand.IntN(len(values))] }, nil
+	default:
+		return nil, errorx.NewBiz("column [%s] unsupported generator: %s", column.ColumnName, rule.Generator)
+	}
+}
+
+// parseDataGenTimeRange 解析日期范围，未指定时默认为最近一年
+func parseDataGenTimeRange(rule *dto.DbDataGenColumn) (time.Time, time.Time, error) {
+	end := time.Now()
+	start := end.AddDate(-1, 0, 0)
+	var err error
+	if rule.Min != "" {
+		if start, err = parseDataGenTime(rule.Min); err != nil {
+			return start, end, err
+		}
+	}
+	if rule.Max != "" {
+		if end, err = parseDataGenTime(rule.Max); err != nil {
+			return start, end, err
+		}
+	}
+	if end.Before(start) {
+		return start, end, errorx.NewBiz("end time must be after start time")
+	}
+	return start, end, nil
+}
+
+func parseDataGenTime(val string) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateTime, val, time.Local); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation(time.DateOnly, val, time.Local)
+}
+
+func truncateDataGenValue(val string, maxLength int) string {
+	if maxLength > 0 && len(val) > maxLength {
+		return val[:maxLength]
+	}
+	return val
+}
+
+var (
+	dataGenFirstNames    = []string{"James", "Mary", "John", "Linda", "Robert", "Emma", "Michael", "Olivia", "David", "Sophia", "Wei", "Fang", "Lei", "Jing", "Min", "Tao"}
+	dataGenLastNames     = []string{"Smith", "Johnson", "Brown", "Wilson", "Taylor", "Lee", "Wang", "Li", "Zhang", "Liu", "Chen", "Yang", "Zhao", "Huang"}
+	dataGenEmailDomains  = []string{"example.com", "example.org", "test.com", "mail.test"}
+	dataGenPhonePrefixes = []string{"30", "35", "38", "50", "58", "66", "77", "86", "89"}
+)
+
+func randName() string {
+	return fmt.Sprintf("%s %s", dataGenFirstNames[rand.IntN(len(dataGenFirstNames))], dataGenLastNames[rand.IntN(len(dataGenLastNames))])
+}
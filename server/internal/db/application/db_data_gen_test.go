@@ -0,0 +1,49 @@
+package application
+
+import (
+	"mayfly-go/internal/db/application/dto"
+	"mayfly-go/internal/db/dbm/dbi"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferDataGenRule(t *testing.T) {
+	const dbType dbi.DbType = "mysql"
+	fks := map[string]dbi.ForeignKey{
+		"user_id": {ColumnName: "user_id", RefTableName: "t_user", RefColumnName: "id"},
+		"id":      {ColumnName: "id", RefTableName: "t_order", RefColumnName: "id"},
+	}
+
+	// 外键列从关联表中采样
+	rule := inferDataGenRule(dbType, dbi.Column{ColumnName: "user_id", DataType: "bigint"}, fks)
+	require.Equal(t, DataGenRef, rule.Generator)
+	require.Equal(t, "t_user", rule.RefTable)
+	require.Equal(t, "id", rule.RefColumn)
+
+	// 自增列优先跳过
+	rule = inferDataGenRule(dbType, dbi.Column{ColumnName: "id", DataType: "bigint", AutoIncrement: true}, fks)
+	require.Equal(t, DataGenSkip, rule.Generator)
+
+	// 非外键列按列名推断
+	rule = inferDataGenRule(dbType, dbi.Column{ColumnName: "user_email", DataType: "varchar", CharMaxLength: 64}, fks)
+	require.Equal(t, DataGenEmail, rule.Generator)
+	require.Empty(t, rule.RefTable)
+
+	rule = inferDataGenRule(dbType, dbi.Column{ColumnName: "user_name", DataType: "varchar"}, nil)
+	require.Equal(t, DataGenName, rule.Generator)
+}
+
+func TestNewDataGenRefValueFunc(t *testing.T) {
+	column := dbi.Column{ColumnName: "user_id", DataType: "bigint"}
+
+	_, err := newDataGenValueFunc(column, &dto.DbDataGenColumn{Generator: DataGenRef, RefTable: "t_user", RefColumn: "id"})
+	require.Error(t, err)
+
+	refValues := []string{"1", "2", "3"}
+	valueFunc, err := newDataGenValueFunc(column, &dto.DbDataGenColumn{Generator: DataGenRef, Values: refValues})
+	require.NoError(t, err)
+	for range 10 {
+		require.Contains(t, refValues, valueFunc())
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"mayfly-go/internal/db/application/dto"
 	"mayfly-go/internal/db/dbm/dbi"
 	"mayfly-go/internal/db/domain/entity"
 	"mayfly-go/internal/db/domain/repository"
@@ -16,6 +17,7 @@ import (
 	"mayfly-go/pkg/model"
 	"mayfly-go/pkg/scheduler"
 	"mayfly-go/pkg/utils/collx"
+	"mayfly-go/pkg/utils/jsonx"
 	"regexp"
 	"strings"
 	"time"
@@ -30,7 +32,10 @@ type DataSyncTask interface {
 	// GetPageList 分页获取数据库实例
 	GetPageList(condition *entity.DataSyncTaskQuery, orderBy ...string) (*model.PageResult[*entity.DataSyncTask], error)
 
-	Save(ctx context.Context, instanceEntity *entity.DataSyncTask) error
+	SaveTask(ctx context.Context, param *dto.SaveDataSyncTask) error
+
+	// GetTaskTables 获取任务的同步表配置，按执行顺序排序
+	GetTaskTables(taskId uint64) ([]*entity.DataSyncTaskTable, error)
 
 	Delete(ctx context.Context, id uint64) error
 
@@ -52,7 +57,8 @@ var _ (DataSyncTask) = (*dataSyncAppImpl)(nil)
 type dataSyncAppImpl struct {
 	base.AppImpl[*entity.DataSyncTask, repository.DataSyncTask]
 
-	dbDataSyncLogRepo repository.DataSyncLog `inject:"T"`
+	dbDataSyncTaskTableRepo repository.DataSyncTaskTable `inject:"T"`
+	dbDataSyncLogRepo       repository.DataSyncLog       `inject:"T"`
 
 	dbApp Db `inject:"T"`
 }
@@ -69,17 +75,23 @@ func (app *dataSyncAppImpl) GetPageList(condition *entity.DataSyncTaskQuery, ord
 	return app.GetRepo().GetTaskList(condition, orderBy...)
 }
 
-func (app *dataSyncAppImpl) Save(ctx context.Context, taskEntity *entity.DataSyncTask) error {
-	var err error
-	if taskEntity.Id == 0 {
-		// 新建时生成key
-		taskEntity.TaskKey = uuid.New().String()
-		err = app.Insert(ctx, taskEntity)
-	} else {
-		taskEntity.TaskKey = ""
-		err = app.UpdateById(ctx, taskEntity)
+func (app *dataSyncAppImpl) SaveTask(ctx context.Context, param *dto.SaveDataSyncTask) error {
+	taskEntity := param.DataSyncTask
+	if len(param.Tables) == 0 {
+		return errorx.NewBiz("at least one sync table is required")
 	}
 
+	err := app.Tx(ctx, func(ctx context.Context) error {
+		if taskEntity.Id == 0 {
+			// 新建时生成key
+			taskEntity.TaskKey = uuid.New().String()
+			return app.Insert(ctx, taskEntity)
+		}
+		taskEntity.TaskKey = ""
+		return app.UpdateById(ctx, taskEntity)
+	}, func(ctx context.Context) error {
+		return app.saveTaskTables(ctx, taskEntity.Id, param.Tables)
+	})
 	if err != nil {
 		return err
 	}
@@ -92,8 +104,77 @@ func (app *dataSyncAppImpl) Save(ctx context.Context, taskEntity *entity.DataSyn
 	return nil
 }
 
+// saveTaskTables 保存任务的同步表配置，已存在的同步表(按id或目标表名匹配)原地更新，
+// 并保留其已同步的更新字段值，避免重置增量同步进度
+func (app *dataSyncAppImpl) saveTaskTables(ctx context.Context, taskId uint64, tables []*entity.DataSyncTaskTable) error {
+	existTables, err := app.GetTaskTables(taskId)
+	if err != nil {
+		return err
+	}
+	existById := make(map[uint64]*entity.DataSyncTaskTable, len(existTables))
+	existByTable := make(map[string]*entity.DataSyncTaskTable, len(existTables))
+	for _, et := range existTables {
+		existById[et.Id] = et
+		existByTable[et.TargetTableName] = et
+	}
+
+	keepIds := make(map[uint64]bool, len(tables))
+	var newTables []*entity.DataSyncTaskTable
+	for i, table := range tables {
+		if table.TargetTableName == "" || table.DataSql == "" {
+			return errorx.NewBiz("the data sql and target table name of the sync table cannot be empty")
+		}
+		table.TaskId = taskId
+		if table.Sort == 0 {
+			table.Sort = i + 1
+		}
+
+		exist := existById[table.Id]
+		if exist == nil {
+			exist = existByTable[table.TargetTableName]
+		}
+		if exist == nil || keepIds[exist.Id] {
+			// 新增同步表，使用表单中的更新字段值作为初始值
+			table.Id = 0
+			newTables = append(newTables, table)
+			continue
+		}
+
+		keepIds[exist.Id] = true
+		table.Id = exist.Id
+		// 更新字段值由同步任务维护，不使用表单中的值覆盖
+		if err := app.dbDataSyncTaskTableRepo.UpdateById(ctx, table, "sort", "data_sql", "upd_field", "upd_field_src", "target_table_name", "field_map", "duplicate_strategy"); err != nil {
+			return err
+		}
+	}
+
+	var delIds []uint64
+	for _, et := range existTables {
+		if !keepIds[et.Id] {
+			delIds = append(delIds, et.Id)
+		}
+	}
+	if len(delIds) > 0 {
+		if err := app.dbDataSyncTaskTableRepo.DeleteById(ctx, delIds...); err != nil {
+			return err
+		}
+	}
+	if len(newTables) == 0 {
+		return nil
+	}
+	return app.dbDataSyncTaskTableRepo.BatchInsert(ctx, newTables)
+}
+
+func (app *dataSyncAppImpl) GetTaskTables(taskId uint64) ([]*entity.DataSyncTaskTable, error) {
+	return app.dbDataSyncTaskTableRepo.SelectByCond(model.NewCond().Eq("task_id", taskId).OrderByAsc("sort").OrderByAsc("id"))
+}
+
 func (app *dataSyncAppImpl) Delete(ctx context.Context, id uint64) error {
-	if err := app.DeleteById(ctx, id); err != nil {
+	if err := app.Tx(ctx, func(ctx context.Context) error {
+		return app.DeleteById(ctx, id)
+	}, func(ctx context.Context) error {
+		return app.dbDataSyncTaskTableRepo.DeleteByCond(ctx, &entity.DataSyncTaskTable{TaskId: id})
+	}); err != nil {
 		return err
 	}
 	app.RemoveCronJobById(id)
@@ -139,105 +220,138 @@ func (app *dataSyncAppImpl) RunCronJob(ctx context.Context, id uint64) error {
 		return errorx.NewBiz("the task is in progress")
 	}
 
+	tables, err := app.GetTaskTables(id)
+	if err != nil {
+		return err
+	}
+	if len(tables) == 0 {
+		return errorx.NewBiz("the task has no sync table")
+	}
+
 	// 标记该任务运行中
 	app.MarkRunning(id)
 
 	go func() {
-		// 通过占位符格式化sql
-		updSql := ""
-		orderSql := ""
-		if task.UpdFieldVal != "0" && task.UpdFieldVal != "" && task.UpdField != "" {
-			if err != nil {
-				logx.ErrorfContext(ctx, "data source connection unavailable: %s", err.Error())
-				return
+		now := time.Now()
+		syncLog := &entity.DataSyncLog{
+			TaskId:     task.Id,
+			CreateTime: &now,
+			Status:     entity.DataSyncTaskStateRunning,
+		}
+		stats := make([]*entity.DataSyncTableStat, 0, len(tables))
+		sqls := make([]string, 0, len(tables))
+
+		// 按依赖顺序依次同步，前面的表失败则终止后续表的同步
+		var err error
+		for _, table := range tables {
+			stat := &entity.DataSyncTableStat{TargetTableName: table.TargetTableName, Status: entity.DataSyncTaskStateRunning}
+			stats = append(stats, stat)
+
+			var sqlStr string
+			sqlStr, err = app.buildQuerySql(ctx, task, table)
+			if err == nil {
+				sqls = append(sqls, sqlStr)
+				syncLog.DataSqlFull = strings.Join(sqls, ";\n")
+				err = app.doDataSync(ctx, sqlStr, task, table, syncLog, stats, stat)
+				// 已提交批次的更新值需保存，避免下次重复同步
+				app.updateTableUpdFieldVal(table)
 			}
-			srcConn, err := app.dbApp.GetDbConn(ctx, uint64(task.SrcDbId), task.SrcDbName)
+
 			if err != nil {
-				logx.ErrorfContext(ctx, "failed to connect to the source database: %s", err.Error())
-				return
+				stat.Status = entity.DataSyncTaskStateFail
+				stat.ErrText = err.Error()
+				break
 			}
-
-			updFieldDataType := dbi.DefaultDbDataType
-			srcConn.WalkQueryRows(context.Background(), task.DataSql, func(row map[string]any, columns []*dbi.QueryColumn) error {
-				for _, column := range columns {
-					if strings.EqualFold(column.Name, cmp.Or(task.UpdFieldSrc, task.UpdField)) {
-						updFieldDataType = column.DbDataType
-						break
-					}
-				}
-				return dbi.NewStopWalkQueryError("get column data type... ignore~")
-			})
-
-			updSql = fmt.Sprintf("and %s > %s", task.UpdField, updFieldDataType.DataType.SQLValue(task.UpdFieldVal))
-			orderSql = "order by " + task.UpdField + " asc "
-		}
-		// 正则判断DataSql是否以where .*结尾，如果是则不添加where 1 = 1
-		var where = "where 1=1"
-		if whereReg.MatchString(task.DataSql) {
-			where = ""
+			stat.Status = entity.DataSyncTaskStateSuccess
 		}
 
-		// 组装查询sql
-		sqlStr := fmt.Sprintf("%s %s %s %s", task.DataSql, where, updSql, orderSql)
-
-		log, err := app.doDataSync(ctx, sqlStr, task)
+		syncLog.TableStats = jsonx.ToStr(stats)
 		if err != nil {
-			log.ErrText = fmt.Sprintf("execution failure: %s", err.Error())
-			logx.ErrorContext(ctx, log.ErrText)
-			log.Status = entity.DataSyncTaskStateFail
+			syncLog.ErrText = fmt.Sprintf("execution failure: %s", err.Error())
+			logx.ErrorContext(ctx, syncLog.ErrText)
+			syncLog.Status = entity.DataSyncTaskStateFail
 		} else {
-			log.Status = entity.DataSyncTaskStateSuccess
+			syncLog.ErrText = fmt.Sprintf("the synchronous task was executed successfully. Tables: %d, new data: %d", len(tables), syncLog.ResNum)
+			syncLog.Status = entity.DataSyncTaskStateSuccess
 		}
 
-		app.endRunning(task, log)
+		app.endRunning(task, syncLog)
 	}()
 
 	return nil
 }
 
-func (app *dataSyncAppImpl) doDataSync(ctx context.Context, sql string, task *entity.DataSyncTask) (*entity.DataSyncLog, error) {
-	now := time.Now()
-	syncLog := &entity.DataSyncLog{
-		TaskId:      task.Id,
-		CreateTime:  &now,
-		DataSqlFull: sql,
-		Status:      entity.DataSyncTaskStateRunning,
+// buildQuerySql 根据同步表配置及当前更新字段值组装查询sql
+func (app *dataSyncAppImpl) buildQuerySql(ctx context.Context, task *entity.DataSyncTask, table *entity.DataSyncTaskTable) (string, error) {
+	// 通过占位符格式化sql
+	updSql := ""
+	orderSql := ""
+	if table.UpdFieldVal != "0" && table.UpdFieldVal != "" && table.UpdField != "" {
+		srcConn, err := app.dbApp.GetDbConn(ctx, uint64(task.SrcDbId), task.SrcDbName)
+		if err != nil {
+			return "", errorx.NewBiz("failed to connect to the source database: %s", err.Error())
+		}
+
+		updFieldDataType := dbi.DefaultDbDataType
+		srcConn.WalkQueryRows(context.Background(), table.DataSql, func(row map[string]any, columns []*dbi.QueryColumn) error {
+			for _, column := range columns {
+				if strings.EqualFold(column.Name, cmp.Or(table.UpdFieldSrc, table.UpdField)) {
+					updFieldDataType = column.DbDataType
+					break
+				}
+			}
+			return dbi.NewStopWalkQueryError("get column data type... ignore~")
+		})
+
+		updSql = fmt.Sprintf("and %s > %s", table.UpdField, updFieldDataType.DataType.SQLValue(table.UpdFieldVal))
+		orderSql = "order by " + table.UpdField + " asc "
+	}
+	// 正则判断DataSql是否以where .*结尾，如果是则不添加where 1 = 1
+	var where = "where 1=1"
+	if whereReg.MatchString(table.DataSql) {
+		where = ""
 	}
 
+	// 组装查询sql
+	return fmt.Sprintf("%s %s %s %s", table.DataSql, where, updSql, orderSql), nil
+}
+
+func (app *dataSyncAppImpl) doDataSync(ctx context.Context, sql string, task *entity.DataSyncTask, table *entity.DataSyncTaskTable, syncLog *entity.DataSyncLog, stats []*entity.DataSyncTableStat, stat *entity.DataSyncTableStat) error {
 	// 获取源数据库连接
 	srcConn, err := app.dbApp.GetDbConn(ctx, uint64(task.SrcDbId), task.SrcDbName)
-
 	if err != nil {
-		return syncLog, errorx.NewBiz("failed to connect to the source database: %s", err.Error())
+		return errorx.NewBiz("failed to connect to the source database: %s", err.Error())
 	}
 
 	// 获取目标数据库连接
 	targetConn, err := app.dbApp.GetDbConn(ctx, uint64(task.TargetDbId), task.TargetDbName)
 	if err != nil {
-		return syncLog, errorx.NewBiz("failed to connect to the target database: %s", err.Error())
+		return errorx.NewBiz("failed to connect to the target database: %s", err.Error())
 	}
 
-	// task.FieldMap为json数组字符串 [{"src":"id","target":"id"}]，转为map
+	// table.FieldMap为json数组字符串 [{"src":"id","target":"id"}]，转为map
 	var fieldMap []map[string]string
-	err = json.Unmarshal([]byte(task.FieldMap), &fieldMap)
+	err = json.Unmarshal([]byte(table.FieldMap), &fieldMap)
 	if err != nil {
-		return syncLog, errorx.NewBiz("there was an error parsing the field map json: %s", err.Error())
+		return errorx.NewBiz("there was an error parsing the field map json: %s", err.Error())
 	}
 
-	// 记录本次同步数据总数
+	// 记录本表同步数据总数
 	total := 0
+	// 本表同步前，其他表已同步的数据总数
+	baseResNum := syncLog.ResNum
 	batchSize := task.PageSize
 	result := make([]map[string]any, 0)
 
 	// 如果有数据库别名，则从UpdField中去掉数据库别名, 如：a.id => id，用于获取字段具体名称
-	updFieldName := task.UpdField
-	if task.UpdField != "" && strings.Contains(task.UpdField, ".") {
-		updFieldName = strings.Split(task.UpdField, ".")[1]
+	updFieldName := table.UpdField
+	if table.UpdField != "" && strings.Contains(table.UpdField, ".") {
+		updFieldName = strings.Split(table.UpdField, ".")[1]
 	}
 
-	targetTableColumns, err := targetConn.GetMetadata().GetColumns(task.TargetTableName)
+	targetTableColumns, err := targetConn.GetMetadata().GetColumns(table.TargetTableName)
 	if err != nil {
-		return syncLog, errorx.NewBiz("failed to get target table columns: %s", err.Error())
+		return errorx.NewBiz("failed to get target table columns: %s", err.Error())
 	}
 	targetColumnName2Column := collx.ArrayToMap(targetTableColumns, func(column dbi.Column) string {
 		return column.ColumnName
@@ -252,14 +366,16 @@ func (app *dataSyncAppImpl) doDataSync(ctx context.Context, sql string, task *en
 		total++
 		result = append(result, row)
 		if total%batchSize == 0 {
-			if err := app.srcData2TargetDb(result, fieldMap, updFieldName, task, targetConn, targetInsertColumns); err != nil {
+			if err := app.srcData2TargetDb(result, fieldMap, updFieldName, table, targetConn, targetInsertColumns); err != nil {
 				return err
 			}
 
 			// 记录当前已同步的数据量
-			syncLog.ErrText = fmt.Sprintf("during the execution of this task, %d has been synchronized", total)
+			stat.ResNum = total
+			syncLog.ResNum = baseResNum + total
+			syncLog.TableStats = jsonx.ToStr(stats)
+			syncLog.ErrText = fmt.Sprintf("during the execution of this task, table [%s] has synchronized %d, total %d", table.TargetTableName, total, syncLog.ResNum)
 			logx.InfoContext(ctx, syncLog.ErrText)
-			syncLog.ResNum = total
 			app.saveLog(syncLog)
 
 			result = result[:0]
@@ -274,26 +390,23 @@ func (app *dataSyncAppImpl) doDataSync(ctx context.Context, sql string, task *en
 	})
 
 	if err != nil {
-		return syncLog, err
+		return err
 	}
 
 	// 处理剩余的数据
 	if len(result) > 0 {
-		if err := app.srcData2TargetDb(result, fieldMap, updFieldName, task, targetConn, targetInsertColumns); err != nil {
-			return syncLog, err
+		if err := app.srcData2TargetDb(result, fieldMap, updFieldName, table, targetConn, targetInsertColumns); err != nil {
+			return err
 		}
 	}
 
-	logx.InfofContext(ctx, "synchronous task: [%s], finished execution, save records successfully: [%d]", task.TaskName, total)
-
-	// 执行成功日志
-	syncLog.ErrText = fmt.Sprintf("the synchronous task was executed successfully. New data: %d", total)
-	syncLog.ResNum = total
-
-	return syncLog, nil
+	stat.ResNum = total
+	syncLog.ResNum = baseResNum + total
+	logx.InfofContext(ctx, "synchronous task: [%s], table [%s] finished execution, save records successfully: [%d]", task.TaskName, table.TargetTableName, total)
+	return nil
 }
 
-func (app *dataSyncAppImpl) srcData2TargetDb(srcRes []map[string]any, fieldMap []map[string]string, updFieldName string, task *entity.DataSyncTask, targetDbConn *dbi.DbConn, targetInsertColumns []dbi.Column) (err error) {
+func (app *dataSyncAppImpl) srcData2TargetDb(srcRes []map[string]any, fieldMap []map[string]string, updFieldName string, table *entity.DataSyncTaskTable, targetDbConn *dbi.DbConn, targetInsertColumns []dbi.Column) (err error) {
 	// 遍历res，组装数据
 	var targetData = make([]map[string]any, 0)
 	for _, srcData := range srcRes {
@@ -319,7 +432,7 @@ func (app *dataSyncAppImpl) srcData2TargetDb(srcRes []map[string]any, fieldMap [
 	targetDialect := targetDbConn.GetDialect()
 
 	// 生成目标数据库批量插入sql，并执行
-	sqls := targetDialect.GetSQLGenerator().GenInsert(task.TargetTableName, targetInsertColumns, targetValues, cmp.Or(task.DuplicateStrategy, dbi.DuplicateStrategyNone))
+	sqls := targetDialect.GetSQLGenerator().GenInsert(table.TargetTableName, targetInsertColumns, targetValues, cmp.Or(table.DuplicateStrategy, dbi.DuplicateStrategyNone))
 
 	// 开启本批次执行事务
 	targetDbTx, err := targetDbConn.Begin()
@@ -355,14 +468,26 @@ func (app *dataSyncAppImpl) srcData2TargetDb(srcRes []map[string]any, fieldMap [
 			updFieldVal = srcRes[len(srcRes)-1][strings.ToLower(field)]
 		}
 
-		task.UpdFieldVal = cast.ToString(updFieldVal)
+		table.UpdFieldVal = cast.ToString(updFieldVal)
 	}
 	// 如果指定了更新字段，则以更新字段取值
-	setUpdateFieldVal(cmp.Or(task.UpdFieldSrc, updFieldName))
+	setUpdateFieldVal(cmp.Or(table.UpdFieldSrc, updFieldName))
 
 	return nil
 }
 
+// updateTableUpdFieldVal 保存同步表当前的更新字段值
+func (app *dataSyncAppImpl) updateTableUpdFieldVal(table *entity.DataSyncTaskTable) {
+	if table.UpdFieldVal == "" {
+		return
+	}
+	updTable := &entity.DataSyncTaskTable{UpdFieldVal: table.UpdFieldVal}
+	updTable.Id = table.Id
+	if err := app.dbDataSyncTaskTableRepo.UpdateById(context.Background(), updTable); err != nil {
+		logx.Errorf("failed to update the sync table [%s] update field value: %s", table.TargetTableName, err.Error())
+	}
+}
+
 func (app *dataSyncAppImpl) StopTask(ctx context.Context, taskId uint64) error {
 	task := new(entity.DataSyncTask)
 	task.Id = taskId
@@ -381,7 +506,6 @@ func (app *dataSyncAppImpl) endRunning(taskEntity *entity.DataSyncTask, log *ent
 	task := new(entity.DataSyncTask)
 	task.Id = taskEntity.Id
 	task.RecentState = state
-	task.UpdFieldVal = taskEntity.UpdFieldVal
 	task.RunningState = entity.DataSyncTaskRunStateReady
 	// 运行失败之后设置任务状态为禁用
 	//if state == entity.DataSyncTaskStateFail {
@@ -1,15 +1,363 @@
 package application
 
 import (
+	"context"
+	"fmt"
+	"mayfly-go/internal/db/application/dto"
+	"mayfly-go/internal/db/config"
+	"mayfly-go/internal/db/dbm/dbi"
 	"mayfly-go/internal/db/domain/entity"
 	"mayfly-go/internal/db/domain/repository"
+	tagapp "mayfly-go/internal/tag/application"
 	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/collx"
+	"mayfly-go/pkg/utils/diffx"
+	"mayfly-go/pkg/utils/jsonx"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/may-fly/cast"
 )
 
 type DbSql interface {
 	base.App[*entity.DbSql]
+
+	// SaveSql 保存sql，新建或sql、参数定义变更时生成新版本
+	SaveSql(ctx context.Context, param *dto.SaveDbSql) error
+
+	// DeleteSql 删除sql及其版本、共享信息，仅创建者可删除
+	DeleteSql(ctx context.Context, accountId uint64, sqlId uint64) error
+
+	// ParseParams 解析sql中的命名参数，并合并已有的参数定义，未定义的参数默认为必填的字符串类型
+	ParseParams(sql string, params []*entity.DbSqlParam) ([]*entity.DbSqlParam, error)
+
+	// GetAccessibleSqls 获取账号可访问的指定库的sql，包括本人保存及共享至所在团队的sql
+	GetAccessibleSqls(accountId uint64, dbId uint64, db string) ([]*entity.DbSql, error)
+
+	// GetAccessibleSql 获取账号可访问的sql
+	GetAccessibleSql(accountId uint64, sqlId uint64) (*entity.DbSql, error)
+
+	// GetVersions 获取sql的历史版本，按版本号倒序
+	GetVersions(sqlId uint64) ([]*entity.DbSqlVersion, error)
+
+	// DiffVersions 对比sql两个版本，返回统一diff格式的差异
+	DiffVersions(sqlId uint64, fromVersion, toVersion int) (string, error)
+
+	// Share 将sql共享至指定团队(全量覆盖)，仅创建者可共享
+	Share(ctx context.Context, accountId uint64, sqlId uint64, teamIds []uint64) error
+
+	// GetShareTeamIds 获取sql共享的团队id
+	GetShareTeamIds(sqlId uint64) []uint64
+
+	// QueryWithParams 使用参数值绑定sql中的命名参数并执行查询，仅支持只读查询语句
+	QueryWithParams(ctx context.Context, dbConn *dbi.DbConn, dbSql *entity.DbSql, values map[string]string) (*dto.DbSqlExecRes, error)
 }
 
 type dbSqlAppImpl struct {
 	base.AppImpl[*entity.DbSql, repository.DbSql]
+
+	dbSqlVersionRepo repository.DbSqlVersion `inject:"T"`
+	dbSqlShareRepo   repository.DbSqlShare   `inject:"T"`
+
+	teamApp tagapp.Team `inject:"T"`
+}
+
+var _ (DbSql) = (*dbSqlAppImpl)(nil)
+
+func (d *dbSqlAppImpl) SaveSql(ctx context.Context, param *dto.SaveDbSql) error {
+	dbSql := param.DbSql
+	params, err := completeSqlParams(dbSql.Sql, param.Params)
+	if err != nil {
+		return err
+	}
+	dbSql.Params = jsonx.ToStr(params)
+
+	// 根据创建者、库及名称判断是否已存在
+	old := &entity.DbSql{Type: dbSql.Type, DbId: dbSql.DbId, Db: dbSql.Db, Name: dbSql.Name}
+	old.CreatorId = dbSql.CreatorId
+	if err := d.GetByCond(old); err != nil {
+		dbSql.Version = 1
+		return d.Tx(ctx, func(ctx context.Context) error {
+			return d.Insert(ctx, dbSql)
+		}, func(ctx context.Context) error {
+			return d.saveVersion(ctx, dbSql, param.Remark)
+		})
+	}
+
+	dbSql.Id = old.Id
+	// sql及参数均未变更，仅更新文件夹等信息
+	if old.Sql == dbSql.Sql && old.Params == dbSql.Params {
+		update := &entity.DbSql{Folder: dbSql.Folder}
+		update.Id = old.Id
+		return d.UpdateById(ctx, update)
+	}
+
+	dbSql.Version = old.Version + 1
+	return d.Tx(ctx, func(ctx context.Context) error {
+		return d.UpdateById(ctx, dbSql)
+	}, func(ctx context.Context) error {
+		return d.saveVersion(ctx, dbSql, param.Remark)
+	})
+}
+
+func (d *dbSqlAppImpl) saveVersion(ctx context.Context, dbSql *entity.DbSql, remark string) error {
+	return d.dbSqlVersionRepo.Insert(ctx, &entity.DbSqlVersion{
+		DbSqlId: dbSql.Id,
+		Version: dbSql.Version,
+		Sql:     dbSql.Sql,
+		Params:  dbSql.Params,
+		Remark:  remark,
+	})
+}
+
+func (d *dbSqlAppImpl) DeleteSql(ctx context.Context, accountId uint64, sqlId uint64) error {
+	dbSql, err := d.GetById(sqlId)
+	if err != nil {
+		return errorx.NewBiz("sql not found")
+	}
+	if dbSql.CreatorId != accountId {
+		return errorx.NewBiz("only the creator can delete the sql")
+	}
+
+	return d.Tx(ctx, func(ctx context.Context) error {
+		return d.DeleteById(ctx, sqlId)
+	}, func(ctx context.Context) error {
+		return d.dbSqlVersionRepo.DeleteByCond(ctx, &entity.DbSqlVersion{DbSqlId: sqlId})
+	}, func(ctx context.Context) error {
+		return d.dbSqlShareRepo.DeleteByCond(ctx, &entity.DbSqlShare{DbSqlId: sqlId})
+	})
+}
+
+func (d *dbSqlAppImpl) ParseParams(sql string, params []*entity.DbSqlParam) ([]*entity.DbSqlParam, error) {
+	return completeSqlParams(sql, params)
+}
+
+func (d *dbSqlAppImpl) GetAccessibleSqls(accountId uint64, dbId uint64, db string) ([]*entity.DbSql, error) {
+	cond := model.NewCond().Eq("db_id", dbId).Eq("db", db).Eq("type", 1).
+		Columns("id", "name", "folder", "version", "creator_id", "creator", "update_time")
+
+	sharedSqlIds := d.getSharedSqlIds(accountId)
+	if len(sharedSqlIds) > 0 {
+		cond.And("(creator_id = ? OR id IN ?)", accountId, sharedSqlIds)
+	} else {
+		cond.Eq("creator_id", accountId)
+	}
+	return d.ListByCond(cond.OrderByAsc("folder").OrderByAsc("name"))
+}
+
+func (d *dbSqlAppImpl) GetAccessibleSql(accountId uint64, sqlId uint64) (*entity.DbSql, error) {
+	dbSql, err := d.GetById(sqlId)
+	if err != nil {
+		return nil, errorx.NewBiz("sql not found")
+	}
+	if dbSql.CreatorId == accountId || collx.ArrayContains(d.getSharedSqlIds(accountId), sqlId) {
+		return dbSql, nil
+	}
+	return nil, errorx.NewBiz("you do not have permission to access the sql")
+}
+
+// getSharedSqlIds 获取共享至账号所在团队的sql id
+func (d *dbSqlAppImpl) getSharedSqlIds(accountId uint64) []uint64 {
+	teamIds := d.teamApp.GetAccountTeamIds(accountId)
+	if len(teamIds) == 0 {
+		return nil
+	}
+	shares, err := d.dbSqlShareRepo.SelectByCond(model.NewCond().In("team_id", teamIds).Columns("db_sql_id"))
+	if err != nil {
+		return nil
+	}
+	return collx.ArrayDeduplicate(collx.ArrayMap(shares, func(s *entity.DbSqlShare) uint64 {
+		return s.DbSqlId
+	}))
+}
+
+func (d *dbSqlAppImpl) GetVersions(sqlId uint64) ([]*entity.DbSqlVersion, error) {
+	return d.dbSqlVersionRepo.SelectByCond(model.NewCond().Eq("db_sql_id", sqlId).OrderByDesc("version"))
+}
+
+func (d *dbSqlAppImpl) DiffVersions(sqlId uint64, fromVersion, toVersion int) (string, error) {
+	from := &entity.DbSqlVersion{DbSqlId: sqlId, Version: fromVersion}
+	if err := d.dbSqlVersionRepo.GetByCond(from); err != nil {
+		return "", errorx.NewBiz("version %d not found", fromVersion)
+	}
+	to := &entity.DbSqlVersion{DbSqlId: sqlId, Version: toVersion}
+	if err := d.dbSqlVersionRepo.GetByCond(to); err != nil {
+		return "", errorx.NewBiz("version %d not found", toVersion)
+	}
+
+	return diffx.Unified(fmt.Sprintf("v%d", fromVersion), fmt.Sprintf("v%d", toVersion), from.Sql, to.Sql, diffx.DefaultContext)
+}
+
+func (d *dbSqlAppImpl) Share(ctx context.Context, accountId uint64, sqlId uint64, teamIds []uint64) error {
+	dbSql, err := d.GetById(sqlId)
+	if err != nil {
+		return errorx.NewBiz("sql not found")
+	}
+	if dbSql.CreatorId != accountId {
+		return errorx.NewBiz("only the creator can share the sql")
+	}
+
+	oldTeamIds := d.GetShareTeamIds(sqlId)
+	add, del, _ := collx.ArrayCompare(collx.ArrayDeduplicate(teamIds), oldTeamIds)
+	// 只能共享给自己所属的团队
+	accountTeamIds := d.teamApp.GetAccountTeamIds(accountId)
+	for _, teamId := range add {
+		if !slices.Contains(accountTeamIds, teamId) {
+			return errorx.NewBiz("you are not a member of the team [%d]", teamId)
+		}
+	}
+
+	return d.Tx(ctx, func(ctx context.Context) error {
+		if len(add) == 0 {
+			return nil
+		}
+		return d.dbSqlShareRepo.BatchInsert(ctx, collx.ArrayMap(add, func(teamId uint64) *entity.DbSqlShare {
+			return &entity.DbSqlShare{DbSqlId: sqlId, TeamId: teamId}
+		}))
+	}, func(ctx context.Context) error {
+		if len(del) == 0 {
+			return nil
+		}
+		return d.dbSqlShareRepo.DeleteByCond(ctx, model.NewCond().Eq("db_sql_id", sqlId).In("team_id", del))
+	})
+}
+
+func (d *dbSqlAppImpl) GetShareTeamIds(sqlId uint64) []uint64 {
+	shares, err := d.dbSqlShareRepo.SelectByCond(&entity.DbSqlShare{DbSqlId: sqlId}, "team_id")
+	if err != nil {
+		return []uint64{}
+	}
+	return collx.ArrayMap(shares, func(s *entity.DbSqlShare) uint64 {
+		return s.TeamId
+	})
+}
+
+func (d *dbSqlAppImpl) QueryWithParams(ctx context.Context, dbConn *dbi.DbConn, dbSql *entity.DbSql, values map[string]string) (*dto.DbSqlExecRes, error) {
+	if err := checkReadOnlySql(dbConn, dbSql.Sql); err != nil {
+		return nil, err
+	}
+
+	querySql, args, err := bindSqlParams(dbConn.GetDialect(), dbSql, values)
+	if err != nil {
+		return nil, err
+	}
+
+	maxRows := config.GetDbms().MaxResultSet
+	res := make([]map[string]any, 0, 16)
+	cols, err := dbConn.WalkQueryRows(ctx, querySql, func(row map[string]any, columns []*dbi.QueryColumn) error {
+		if maxRows != 0 && len(res) >= maxRows {
+			return dbi.NewStopWalkQueryError(fmt.Sprintf("exceed the maximum number of query records %d", maxRows))
+		}
+		res = append(res, row)
+		return nil
+	}, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.DbSqlExecRes{
+		Sql:     dbSql.Sql,
+		Columns: cols,
+		Res:     res,
+	}, nil
+}
+
+// completeSqlParams 根据sql中的命名参数补全参数定义，未定义的参数默认为字符串类型，并移除sql中不存在的参数定义
+func completeSqlParams(sql string, params []*entity.DbSqlParam) ([]*entity.DbSqlParam, error) {
+	paramMap := collx.ArrayToMap(params, func(p *entity.DbSqlParam) string {
+		return p.Name
+	})
+
+	res := make([]*entity.DbSqlParam, 0)
+	for _, name := range dbi.ParseNamedParams(sql) {
+		param := paramMap[name]
+		if param == nil {
+			param = &entity.DbSqlParam{Name: name, Label: name, Type: entity.DbSqlParamTypeString, Required: true}
+		}
+		if param.Type == "" {
+			param.Type = entity.DbSqlParamTypeString
+		}
+		if param.Default != "" {
+			if _, err := convertSqlParamValue(param, param.Default); err != nil {
+				return nil, errorx.NewBiz("param [%s] default value error: %s", name, err.Error())
+			}
+		}
+		res = append(res, param)
+	}
+	return res, nil
+}
+
+// bindSqlParams 根据sql参数定义转换参数值类型，并绑定至sql中的命名参数
+func bindSqlParams(dialect dbi.Dialect, dbSql *entity.DbSql, values map[string]string) (string, []any, error) {
+	var params []*entity.DbSqlParam
+	if dbSql.Params != "" {
+		var err error
+		if params, err = jsonx.To[[]*entity.DbSqlParam](dbSql.Params); err != nil {
+			return "", nil, errorx.NewBiz("sql params definition error: %s", err.Error())
+		}
+	}
+	paramMap := collx.ArrayToMap(params, func(p *entity.DbSqlParam) string {
+		return p.Name
+	})
+
+	typedValues := make(map[string]any)
+	for _, name := range dbi.ParseNamedParams(dbSql.Sql) {
+		param := paramMap[name]
+		if param == nil {
+			param = &entity.DbSqlParam{Name: name, Type: entity.DbSqlParamTypeString}
+		}
+
+		val, ok := values[name]
+		if !ok || val == "" {
+			val = param.Default
+		}
+		if val == "" {
+			if param.Required {
+				return "", nil, errorx.NewBiz("param [%s] is required", name)
+			}
+			typedValues[name] = nil
+			continue
+		}
+
+		typedVal, err := convertSqlParamValue(param, val)
+		if err != nil {
+			return "", nil, errorx.NewBiz("param [%s] value error: %s", name, err.Error())
+		}
+		typedValues[name] = typedVal
+	}
+
+	return dbi.BindNamedParams(dialect, dbSql.Sql, typedValues)
+}
+
+// convertSqlParamValue 将参数值转换为参数定义的类型
+func convertSqlParamValue(param *entity.DbSqlParam, val string) (any, error) {
+	val = strings.TrimSpace(val)
+	switch param.Type {
+	case entity.DbSqlParamTypeInt:
+		return strconv.ParseInt(val, 10, 64)
+	case entity.DbSqlParamTypeNumber:
+		return strconv.ParseFloat(val, 64)
+	case entity.DbSqlParamTypeBool:
+		return cast.ToBoolE(val)
+	case entity.DbSqlParamTypeDate:
+		t, err := time.ParseInLocation(time.DateOnly, val, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		return t.Format(time.DateOnly), nil
+	case entity.DbSqlParamTypeDatetime:
+		t, err := time.ParseInLocation(time.DateTime, val, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		return t.Format(time.DateTime), nil
+	case entity.DbSqlParamTypeString, "":
+		return val, nil
+	default:
+		return nil, fmt.Errorf("unsupported param type: %s", param.Type)
+	}
 }
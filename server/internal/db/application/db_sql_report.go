@@ -0,0 +1,435 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"html"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/internal/db/dbm/sqlparser/sqlstmt"
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/internal/db/domain/repository"
+	"mayfly-go/internal/db/imsg"
+	msgapp "mayfly-go/internal/msg/application"
+	msgdto "mayfly-go/internal/msg/application/dto"
+	"mayfly-go/internal/msg/msgx"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/contextx"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/i18n"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/scheduler"
+	"mayfly-go/pkg/utils/collx"
+	"mayfly-go/pkg/utils/stringx"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/may-fly/cast"
+)
+
+const (
+	sqlReportDefaultMaxRows = 1000
+	sqlReportMaxRows        = 10000
+	sqlReportQueryTimeout   = 5 * time.Minute
+)
+
+type DbSqlReport interface {
+	base.App[*entity.DbSqlReport]
+
+	// GetPageList 分页获取sql报表
+	GetPageList(condition *entity.DbSqlReportQuery, orderBy ...string) (*model.PageResult[*entity.DbSqlReport], error)
+
+	// SaveReport 保存报表并根据状态添加或移除定时任务
+	SaveReport(ctx context.Context, report *entity.DbSqlReport) error
+
+	// DeleteReport 删除报表及其执行记录
+	DeleteReport(ctx context.Context, id uint64) error
+
+	// ChangeStatus 启停报表定时任务
+	ChangeStatus(ctx context.Context, id uint64, status int8) error
+
+	// Run 立即执行报表，执行sql并发送结果
+	Run(ctx context.Context, id uint64) error
+
+	// GetLogPageList 分页获取报表执行记录
+	GetLogPageList(condition *entity.DbSqlReportLogQuery, orderBy ...string) (*model.PageResult[*entity.DbSqlReportLog], error)
+
+	InitCronJob()
+}
+
+type dbSqlReportAppImpl struct {
+	base.AppImpl[*entity.DbSqlReport, repository.DbSqlReport]
+
+	dbSqlReportLogRepo repository.DbSqlReportLog `inject:"T"`
+
+	dbApp      Db             `inject:"T"`
+	dbSqlApp   DbSql          `inject:"T"`
+	msgApp     msgapp.Msg     `inject:"T"`
+	msgTmplApp msgapp.MsgTmpl `inject:"T"`
+}
+
+var _ (DbSqlReport) = (*dbSqlReportAppImpl)(nil)
+
+// sqlReportResult sql报表查询结果
+type sqlReportResult struct {
+	Columns []string
+	Rows    [][]string
+}
+
+func (app *dbSqlReportAppImpl) GetPageList(condition *entity.DbSqlReportQuery, orderBy ...string) (*model.PageResult[*entity.DbSqlReport], error) {
+	return app.GetRepo().GetPageList(condition, orderBy...)
+}
+
+func (app *dbSqlReportAppImpl) SaveReport(ctx context.Context, report *entity.DbSqlReport) error {
+	if !collx.ArrayContains([]string{entity.DbSqlReportFormatMarkdown, entity.DbSqlReportFormatHtml, entity.DbSqlReportFormatCsv}, report.ResultFormat) {
+		return errorx.NewBiz("unsupported result format: %s", report.ResultFormat)
+	}
+	if report.MaxRows <= 0 {
+		report.MaxRows = sqlReportDefaultMaxRows
+	}
+	if report.MaxRows > sqlReportMaxRows {
+		return errorx.NewBiz("the max rows cannot exceed %d", sqlReportMaxRows)
+	}
+	if err := scheduler.ValidateCron(report.Cron); err != nil {
+		return errorx.NewBiz("cron expression error: %s", err.Error())
+	}
+	dbSql, err := app.dbSqlApp.GetById(report.DbSqlId)
+	if err != nil {
+		return errorx.NewBiz("the saved sql does not exist")
+	}
+	if dbSql.DbId != report.DbId {
+		return errorx.NewBiz("the saved sql does not belong to the target database")
+	}
+
+	if report.Id == 0 {
+		report.TaskKey = uuid.New().String()
+		if report.Status == 0 {
+			report.Status = entity.DbSqlReportStatusEnable
+		}
+		if err := app.Insert(ctx, report); err != nil {
+			return err
+		}
+	} else {
+		report.TaskKey = ""
+		if err := app.UpdateById(ctx, report); err != nil {
+			return err
+		}
+	}
+
+	report, err = app.GetById(report.Id)
+	if err != nil {
+		return err
+	}
+	app.addCronJob(report)
+	return nil
+}
+
+func (app *dbSqlReportAppImpl) DeleteReport(ctx context.Context, id uint64) error {
+	report, err := app.GetById(id)
+	if err != nil {
+		return errorx.NewBiz("report not found")
+	}
+
+	if err := app.Tx(ctx, func(ctx context.Context) error {
+		return app.DeleteById(ctx, id)
+	}, func(ctx context.Context) error {
+		return app.dbSqlReportLogRepo.DeleteByCond(ctx, &entity.DbSqlReportLog{ReportId: id})
+	}); err != nil {
+		return err
+	}
+
+	scheduler.RemoveByKey(report.TaskKey)
+	return nil
+}
+
+func (app *dbSqlReportAppImpl) ChangeStatus(ctx context.Context, id uint64, status int8) error {
+	report := new(entity.DbSqlReport)
+	report.Id = id
+	report.Status = status
+	if err := app.UpdateById(ctx, report); err != nil {
+		return err
+	}
+
+	report, err := app.GetById(id)
+	if err != nil {
+		return errorx.NewBiz("report not found")
+	}
+	app.addCronJob(report)
+	return nil
+}
+
+func (app *dbSqlReportAppImpl) addCronJob(report *entity.DbSqlReport) {
+	key := report.TaskKey
+	// 先移除旧的任务
+	scheduler.RemoveByKey(key)
+
+	if report.Status != entity.DbSqlReportStatusEnable {
+		return
+	}
+
+	reportId := report.Id
+	logx.Infof("start add the sql report job: %s, cron[%s]", report.Name, report.Cron)
+	if err := scheduler.AddFunByKey(key, report.Cron, func() {
+		if err := app.Run(contextx.NewTraceId(), reportId); err != nil {
+			logx.Errorf("the sql report failed to execute at a scheduled time: %s", err.Error())
+		}
+	}); err != nil {
+		logx.ErrorTrace("add sql report job failed", err)
+	}
+}
+
+func (app *dbSqlReportAppImpl) InitCronJob() {
+	defer func() {
+		if err := recover(); err != nil {
+			logx.ErrorTrace("the sql report job failed to initialize", err)
+		}
+	}()
+
+	// 重启后，重置执行中的运行状态
+	_ = app.UpdateByCond(context.TODO(), collx.M{"running_state": entity.DbSqlReportRunStateIdle}, &entity.DbSqlReport{RunningState: entity.DbSqlReportRunStateRunning})
+
+	if err := app.CursorByCond(&entity.DbSqlReport{Status: entity.DbSqlReportStatusEnable}, func(report *entity.DbSqlReport) error {
+		app.addCronJob(report)
+		return nil
+	}); err != nil {
+		logx.ErrorTrace("the sql report job failed to initialize", err)
+	}
+}
+
+func (app *dbSqlReportAppImpl) Run(ctx context.Context, id uint64) error {
+	report, err := app.GetById(id)
+	if err != nil {
+		return errorx.NewBiz("report not found")
+	}
+
+	marked, err := app.GetRepo().TryMarkRunning(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !marked {
+		return errorx.NewBiz("the report is in progress")
+	}
+
+	now := time.Now()
+	reportLog := &entity.DbSqlReportLog{
+		CreateTime: &now,
+		ReportId:   id,
+		Status:     entity.DbSqlReportStateSuccess,
+	}
+	// 更新最近执行状态并释放运行状态
+	defer func() {
+		_ = app.UpdateByCond(context.Background(), collx.M{"recent_state": reportLog.Status, "running_state": entity.DbSqlReportRunStateIdle}, model.NewCond().Eq("id", id))
+	}()
+
+	runErr := app.doRun(ctx, report, reportLog)
+	reportLog.Duration = time.Since(now).Milliseconds()
+	if runErr != nil {
+		reportLog.Status = entity.DbSqlReportStateFail
+		reportLog.ErrText = runErr.Error()
+		app.sendFailAlert(ctx, report, runErr)
+	}
+
+	if err := app.dbSqlReportLogRepo.Insert(context.Background(), reportLog); err != nil {
+		logx.Errorf("failed to save sql report log: %s", err.Error())
+	}
+
+	return runErr
+}
+
+func (app *dbSqlReportAppImpl) doRun(ctx context.Context, report *entity.DbSqlReport, reportLog *entity.DbSqlReportLog) error {
+	dbSql, err := app.dbSqlApp.GetById(report.DbSqlId)
+	if err != nil {
+		return errorx.NewBiz("the saved sql does not exist")
+	}
+	reportLog.Sql = dbSql.Sql
+
+	dbConn, err := app.dbApp.GetDbConn(ctx, report.DbId, report.Db)
+	if err != nil {
+		return err
+	}
+
+	if err := checkReadOnlySql(dbConn, dbSql.Sql); err != nil {
+		return err
+	}
+
+	// 命名参数使用参数定义的默认值
+	querySql, args, err := bindSqlParams(dbConn.GetDialect(), dbSql, nil)
+	if err != nil {
+		return err
+	}
+
+	result, err := app.query(ctx, dbConn, querySql, report.MaxRows, args...)
+	if err != nil {
+		return errorx.NewBiz("query failed: %s", err.Error())
+	}
+	reportLog.RowNum = len(result.Rows)
+
+	content, err := renderSqlReportResult(report.ResultFormat, result)
+	if err != nil {
+		return err
+	}
+
+	msg := &msgx.Msg{
+		Params: collx.M{
+			"reportName": report.Name,
+			"db":         dbConn.Info.GetLogDesc(),
+			"rowNum":     len(result.Rows),
+			"runTime":    reportLog.CreateTime.Format(time.DateTime),
+			"result":     content,
+		},
+	}
+	if report.ResultFormat == entity.DbSqlReportFormatCsv {
+		msg.Attachments = []msgx.Attachment{{
+			Name:        fmt.Sprintf("%s_%s.csv", report.Name, reportLog.CreateTime.Format("20060102150405")),
+			ContentType: "text/csv",
+			Content:     []byte(content),
+		}}
+	}
+
+	if err := app.msgTmplApp.SendSync(ctx, report.MsgTmplId, msg, parseReceiverIds(report.ReceiverIds)...); err != nil {
+		return errorx.NewBiz("send report failed: %s", err.Error())
+	}
+	return nil
+}
+
+// query 执行查询，最多获取maxRows行结果
+func (app *dbSqlReportAppImpl) query(ctx context.Context, dbConn *dbi.DbConn, querySql string, maxRows int, args ...any) (*sqlReportResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, sqlReportQueryTimeout)
+	defer cancel()
+
+	result := &sqlReportResult{Rows: make([][]string, 0)}
+	columns, err := dbConn.WalkQueryRows(ctx, querySql, func(row map[string]any, columns []*dbi.QueryColumn) error {
+		if len(result.Rows) >= maxRows {
+			return dbi.NewStopWalkQueryError("max rows reached")
+		}
+		values := make([]string, len(columns))
+		for i, column := range columns {
+			values[i] = fileValueString(row[column.Name])
+		}
+		result.Rows = append(result.Rows, values)
+		return nil
+	}, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Columns = collx.ArrayMap(columns, func(c *dbi.QueryColumn) string {
+		return c.Name
+	})
+	return result, nil
+}
+
+// sendFailAlert 发送报表执行失败告警，通知报表创建者，并通过告警模板发送至配置的渠道
+func (app *dbSqlReportAppImpl) sendFailAlert(ctx context.Context, report *entity.DbSqlReport, runErr error) {
+	errInfo := stringx.Truncate(runErr.Error(), 300, 10, "...")
+	creator := &model.LoginAccount{Id: report.CreatorId, Username: report.Creator}
+	app.msgApp.CreateAndSend(creator, msgdto.ErrSysMsg(i18n.T(imsg.SqlReportRunFail), fmt.Sprintf("[%s] execution failure: %s", report.Name, errInfo)))
+
+	if report.AlertMsgTmplId == 0 {
+		return
+	}
+	if err := app.msgTmplApp.SendSync(ctx, report.AlertMsgTmplId, &msgx.Msg{
+		Params: collx.M{
+			"reportName": report.Name,
+			"runTime":    time.Now().Format(time.DateTime),
+			"error":      errInfo,
+		},
+	}, parseReceiverIds(report.ReceiverIds)...); err != nil {
+		logx.Errorf("failed to send sql report [%s] fail alert: %s", report.Name, err.Error())
+	}
+}
+
+func (app *dbSqlReportAppImpl) GetLogPageList(condition *entity.DbSqlReportLogQuery, orderBy ...string) (*model.PageResult[*entity.DbSqlReportLog], error) {
+	return app.dbSqlReportLogRepo.GetPageList(condition, orderBy...)
+}
+
+// checkReadOnlySql 校验sql是否为只读查询语句，命名参数替换为字面量后再进行解析
+func checkReadOnlySql(dbConn *dbi.DbConn, querySql string) error {
+	stmts, err := dbConn.GetDialect().GetSQLParser().Parse(dbi.ReplaceNamedParams(querySql, "0"))
+	if err != nil {
+		return errorx.NewBiz("sql parse failed: %s", err.Error())
+	}
+
+	queryNum := 0
+	for _, stmt := range stmts {
+		switch stmt.(type) {
+		case *sqlstmt.WithStmt:
+			continue
+		case *sqlstmt.SimpleSelectStmt, *sqlstmt.UnionSelectStmt, *sqlstmt.OtherReadStmt:
+			queryNum++
+		default:
+			return errorx.NewBiz("only read-only query sql is allowed")
+		}
+	}
+	if queryNum != 1 {
+		return errorx.NewBiz("the report sql must contain exactly one query statement")
+	}
+	return nil
+}
+
+// renderSqlReportResult 根据结果格式渲染查询结果
+func renderSqlReportResult(format string, result *sqlReportResult) (string, error) {
+	switch format {
+	case entity.DbSqlReportFormatMarkdown:
+		return renderMarkdownTable(result), nil
+	case entity.DbSqlReportFormatHtml:
+		return renderHtmlTable(result), nil
+	case entity.DbSqlReportFormatCsv:
+		var buf bytes.Buffer
+		cw := csv.NewWriter(&buf)
+		if err := cw.Write(result.Columns); err != nil {
+			return "", err
+		}
+		if err := cw.WriteAll(result.Rows); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	default:
+		return "", errorx.NewBiz("unsupported result format: %s", format)
+	}
+}
+
+func renderMarkdownTable(result *sqlReportResult) string {
+	escape := func(val string) string {
+		val = strings.ReplaceAll(val, "|", `\|`)
+		return strings.ReplaceAll(strings.ReplaceAll(val, "\r", ""), "\n", "<br>")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("| " + strings.Join(collx.ArrayMap(result.Columns, escape), " | ") + " |\n")
+	sb.WriteString("|" + strings.Repeat(" --- |", len(result.Columns)) + "\n")
+	for _, row := range result.Rows {
+		sb.WriteString("| " + strings.Join(collx.ArrayMap(row, escape), " | ") + " |\n")
+	}
+	return sb.String()
+}
+
+func renderHtmlTable(result *sqlReportResult) string {
+	var sb strings.Builder
+	sb.WriteString(`<table border="1" cellspacing="0" cellpadding="4"><thead><tr>`)
+	for _, column := range result.Columns {
+		sb.WriteString("<th>" + html.EscapeString(column) + "</th>")
+	}
+	sb.WriteString("</tr></thead><tbody>")
+	for _, row := range result.Rows {
+		sb.WriteString("<tr>")
+		for _, val := range row {
+			sb.WriteString("<td>" + html.EscapeString(val) + "</td>")
+		}
+		sb.WriteString("</tr>")
+	}
+	sb.WriteString("</tbody></table>")
+	return sb.String()
+}
+
+func parseReceiverIds(receiverIds string) []uint64 {
+	if receiverIds == "" {
+		return nil
+	}
+	return collx.ArrayMap(strings.Split(receiverIds, ","), func(id string) uint64 {
+		return cast.ToUint64(strings.TrimSpace(id))
+	})
+}
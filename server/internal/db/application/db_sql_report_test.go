@@ -0,0 +1,19 @@
+package application
+
+import (
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/internal/db/dbm/mysql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckReadOnlySqlWithNamedParams(t *testing.T) {
+	dbConn := &dbi.DbConn{Info: &dbi.DbInfo{Meta: new(mysql.Meta)}}
+
+	require.NoError(t, checkReadOnlySql(dbConn, "select * from t_user where name = :name and create_time > :start_date limit :size"))
+	require.NoError(t, checkReadOnlySql(dbConn, "select count(*) from t_order where status in (:status, 2) and remark like :remark"))
+
+	require.Error(t, checkReadOnlySql(dbConn, "delete from t_user where id = :id"))
+	require.Error(t, checkReadOnlySql(dbConn, "select * from t_user where id = :id; select 1"))
+}
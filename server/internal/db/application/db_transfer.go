@@ -74,6 +74,14 @@ func (app *dbTransferAppImpl) GetPageList(condition *entity.DbTransferTaskQuery,
 }
 
 func (app *dbTransferAppImpl) Save(ctx context.Context, taskEntity *entity.DbTransferTask) error {
+	if taskEntity.Mode == entity.DbTransferTaskModeFile && !collx.ArrayContains(supportedTransferFileTypes, taskEntity.GetTargetFileType()) {
+		return errorx.NewBiz("unsupported target file type: %s", taskEntity.TargetFileType)
+	}
+	// 仅sql文件需要指定目标文件的数据库方言
+	if taskEntity.Mode == entity.DbTransferTaskModeFile && taskEntity.GetTargetFileType() == entity.DbTransferFileTypeSql && taskEntity.TargetFileDbType == "" {
+		return errorx.NewBiz("the db type of the target sql file cannot be empty")
+	}
+
 	var err error
 	if taskEntity.Id == 0 { // 新建时生成key
 		taskEntity.TaskKey = uuid.New().String()
@@ -318,18 +326,27 @@ func (app *dbTransferAppImpl) transfer2Db(ctx context.Context, taskId uint64, lo
 }
 
 func (app *dbTransferAppImpl) transfer2File(ctx context.Context, taskId uint64, logId uint64, task *entity.DbTransferTask, srcConn *dbi.DbConn, start time.Time, tables []dbi.Table) {
+	fileType := task.GetTargetFileType()
 	// 1、新增迁移文件数据
 	nowTime := time.Now()
 	tFile := &entity.DbTransferFile{
 		TaskId:     taskId,
 		CreateTime: &nowTime,
 		Status:     entity.DbTransferFileStatusRunning,
-		FileDbType: cmp.Or(task.TargetFileDbType, task.TargetDbType),
+		FileType:   fileType,
 		LogId:      logId,
 	}
+	if fileType == entity.DbTransferFileTypeSql {
+		tFile.FileDbType = cmp.Or(task.TargetFileDbType, task.TargetDbType)
+	}
 	_ = app.transferFileApp.Save(ctx, tFile)
 
-	filename := fmt.Sprintf("dtf_%s_%s.sql", task.TaskName, timex.TimeNo())
+	// 非sql文件，每个表生成一个文件并打包为zip
+	fileExt := "zip"
+	if fileType == entity.DbTransferFileTypeSql {
+		fileExt = "sql"
+	}
+	filename := fmt.Sprintf("dtf_%s_%s.%s", task.TaskName, timex.TimeNo(), fileExt)
 	fileKey, writer, saveFileFunc, err := app.fileApp.NewWriter(ctx, "", filename)
 	if err != nil {
 		app.EndTransfer(ctx, logId, taskId, "create file error", err, nil)
@@ -340,7 +357,11 @@ func (app *dbTransferAppImpl) transfer2File(ctx context.Context, taskId uint64,
 	tableNames := collx.ArrayMap(tables, func(t dbi.Table) string { return t.TableName })
 	// 2、把源库数据迁移到文件
 	app.Log(ctx, logId, fmt.Sprintf("start transfer table data to files: %s", filename))
-	app.Log(ctx, logId, fmt.Sprintf("dialect type of target db file: %s", task.TargetFileDbType))
+	if fileType == entity.DbTransferFileTypeSql {
+		app.Log(ctx, logId, fmt.Sprintf("dialect type of target db file: %s", task.TargetFileDbType))
+	} else {
+		app.Log(ctx, logId, fmt.Sprintf("type of target file: %s", fileType))
+	}
 
 	go func() {
 		var err error
@@ -349,19 +370,23 @@ func (app *dbTransferAppImpl) transfer2File(ctx context.Context, taskId uint64,
 		defer app.logApp.Flush(logId, true)
 		ctx = context.Background()
 
-		err = app.dbApp.DumpDb(ctx, &dto.DumpDb{
-			LogId:        logId,
-			DbId:         uint64(task.SrcDbId),
-			DbName:       task.SrcDbName,
-			TargetDbType: dbi.DbType(task.TargetFileDbType),
-			Tables:       tableNames,
-			DumpDDL:      true,
-			DumpData:     true,
-			Writer:       writer,
-			Log: func(msg string) { // 记录日志
-				app.Log(ctx, logId, msg)
-			},
-		})
+		if fileType == entity.DbTransferFileTypeSql {
+			err = app.dbApp.DumpDb(ctx, &dto.DumpDb{
+				LogId:        logId,
+				DbId:         uint64(task.SrcDbId),
+				DbName:       task.SrcDbName,
+				TargetDbType: dbi.DbType(task.TargetFileDbType),
+				Tables:       tableNames,
+				DumpDDL:      true,
+				DumpData:     true,
+				Writer:       writer,
+				Log: func(msg string) { // 记录日志
+					app.Log(ctx, logId, msg)
+				},
+			})
+		} else {
+			err = app.dumpDataFile(ctx, taskId, logId, srcConn, fileType, tableNames, writer)
+		}
 		if err != nil {
 			app.EndTransfer(ctx, logId, taskId, "db transfer to file failed", err, nil)
 			tFile.Status = entity.DbTransferFileStatusFail
@@ -0,0 +1,189 @@
+package application
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mayfly-go/internal/db/dbm/dbi"
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/parquetx"
+	"time"
+
+	"github.com/may-fly/cast"
+)
+
+// 迁移到文件支持的文件类型
+var supportedTransferFileTypes = []string{
+	entity.DbTransferFileTypeSql,
+	entity.DbTransferFileTypeParquet,
+	entity.DbTransferFileTypeCsv,
+	entity.DbTransferFileTypeJsonl,
+}
+
+// tableDataWriter 单表数据文件writer
+type tableDataWriter interface {
+	// Write 写入一行数据，values顺序与列顺序一致
+	Write(values []any) error
+
+	// Close 写入剩余数据，不关闭底层writer
+	Close() error
+}
+
+// dumpDataFile 将表数据按指定文件类型导出，每个表一个文件并打包为zip写入writer
+func (app *dbTransferAppImpl) dumpDataFile(ctx context.Context, taskId uint64, logId uint64, srcConn *dbi.DbConn, fileType string, tableNames []string, writer io.Writer) error {
+	if len(tableNames) == 0 {
+		return errorx.NewBiz("there is no table to export")
+	}
+
+	zipWriter := zip.NewWriter(writer)
+	for _, tableName := range tableNames {
+		if !app.IsRunning(taskId) {
+			return errorx.NewBiz("transfer stopped")
+		}
+
+		columns, err := srcConn.GetMetadata().GetColumns(tableName)
+		if err != nil {
+			return errorx.NewBiz("failed to get table [%s] columns: %s", tableName, err.Error())
+		}
+
+		fw, err := zipWriter.Create(fmt.Sprintf("%s.%s", tableName, fileType))
+		if err != nil {
+			return err
+		}
+		tw, err := newTableDataWriter(fileType, fw, srcConn.Info.Type, columns)
+		if err != nil {
+			return err
+		}
+
+		app.Log(ctx, logId, fmt.Sprintf("start transfer table [%s] to %s file", tableName, fileType))
+		count := 0
+		_, err = srcConn.WalkTableRows(ctx, tableName, func(row map[string]any, _ []*dbi.QueryColumn) error {
+			values := make([]any, len(columns))
+			for i, col := range columns {
+				values[i] = row[col.ColumnName]
+			}
+			count++
+			if count%10000 == 0 {
+				app.logApp.SetExtra(logId, fmt.Sprintf("`%s` amount of transfer data currently: ", tableName), count)
+			}
+			return tw.Write(values)
+		})
+		if err != nil {
+			return errorx.NewBiz("transfer table [%s] failed: %s", tableName, err.Error())
+		}
+		if err := tw.Close(); err != nil {
+			return err
+		}
+		app.Log(ctx, logId, fmt.Sprintf("execute transfer table [%s] %d rows", tableName, count))
+	}
+
+	return zipWriter.Close()
+}
+
+func newTableDataWriter(fileType string, w io.Writer, dbType dbi.DbType, columns []dbi.Column) (tableDataWriter, error) {
+	switch fileType {
+	case entity.DbTransferFileTypeCsv:
+		return newCsvTableWriter(w, columns)
+	case entity.DbTransferFileTypeJsonl:
+		return &jsonlTableWriter{w: w, columns: columns}, nil
+	case entity.DbTransferFileTypeParquet:
+		pcs := make([]parquetx.Column, len(columns))
+		for i, column := range columns {
+			pcs[i] = parquetx.Column{Name: column.ColumnName, Type: parquetColumnType(dbType, column)}
+		}
+		return parquetx.NewWriter(w, pcs), nil
+	default:
+		return nil, errorx.NewBiz("unsupported file type: %s", fileType)
+	}
+}
+
+// parquetColumnType 根据列的数据类型获取parquet列类型，无法精确表示的类型（如decimal、日期）统一使用字符串
+func parquetColumnType(dbType dbi.DbType, column dbi.Column) parquetx.Type {
+	switch dbi.GetDbDataType(dbType, column.DataType).DataType {
+	case dbi.DTByte, dbi.DTInt8, dbi.DTInt16, dbi.DTInt32, dbi.DTInt64, dbi.DTUint64:
+		return parquetx.TypeInt64
+	case dbi.DTBool:
+		return parquetx.TypeBoolean
+	case dbi.DTBytes:
+		return parquetx.TypeByteArray
+	default:
+		return parquetx.TypeString
+	}
+}
+
+type csvTableWriter struct {
+	w *csv.Writer
+}
+
+func newCsvTableWriter(w io.Writer, columns []dbi.Column) (*csvTableWriter, error) {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.ColumnName
+	}
+	if err := cw.Write(header); err != nil {
+		return nil, err
+	}
+	return &csvTableWriter{w: cw}, nil
+}
+
+func (c *csvTableWriter) Write(values []any) error {
+	record := make([]string, len(values))
+	for i, val := range values {
+		record[i] = fileValueString(val)
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvTableWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonlTableWriter struct {
+	w       io.Writer
+	columns []dbi.Column
+}
+
+func (j *jsonlTableWriter) Write(values []any) error {
+	row := make(map[string]any, len(values))
+	for i, val := range values {
+		if bs, ok := val.([]byte); ok {
+			val = string(bs)
+		}
+		row[j.columns[i].ColumnName] = val
+	}
+	line, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	_, err = j.w.Write(append(line, '\n'))
+	return err
+}
+
+func (j *jsonlTableWriter) Close() error {
+	return nil
+}
+
+// fileValueString 将值转为文件中的字符串表示，nil为空字符串
+func fileValueString(val any) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.DateTime)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.Format(time.DateTime)
+	default:
+		return cast.ToString(v)
+	}
+}
@@ -36,3 +36,40 @@ func DefaultDumpLog(msg string) {
 func DefaultDumpProgress(currentTable string, stmtType dbi.StmtType, stmtCount int, currentStmtTypeEnd bool) {
 
 }
+
+type SaveDataSyncTask struct {
+	DataSyncTask *entity.DataSyncTask
+	Tables       []*entity.DataSyncTaskTable // 同步表配置，按sort依赖顺序执行
+}
+
+// DbDataGen 表测试数据生成参数
+type DbDataGen struct {
+	DbConn    *dbi.DbConn
+	TableName string
+	Num       int                // 生成数据条数
+	BatchSize int                // 每批插入条数
+	Columns   []*DbDataGenColumn // 用户自定义的列生成规则，未指定的列使用自动推断规则
+
+	ClientId string
+}
+
+// DbDataGenColumn 列数据生成规则
+type DbDataGenColumn struct {
+	ColumnName string   `json:"columnName"`
+	DataType   string   `json:"dataType"`
+	Generator  string   `json:"generator"` // 生成器类型
+	Min        string   `json:"min"`       // 数值或日期范围最小值
+	Max        string   `json:"max"`       // 数值或日期范围最大值
+	Length     int      `json:"length"`    // 字符串长度
+	Value      string   `json:"value"`     // 固定值
+	Values     []string `json:"values"`    // 枚举可选值
+	RefTable   string   `json:"refTable"`  // 关联表，用于从关联表中采样值
+	RefColumn  string   `json:"refColumn"` // 关联表列
+}
+
+// SaveDbSql 保存sql
+type SaveDbSql struct {
+	DbSql  *entity.DbSql
+	Params []*entity.DbSqlParam // 命名参数定义
+	Remark string               // 版本说明
+}
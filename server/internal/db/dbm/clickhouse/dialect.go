@@ -29,6 +29,10 @@ func (cd *ClickHouseDialect) GetDumpHelper() dbi.DumpHelper {
 	return new(dbi.DefaultDumpHelper)
 }
 
+func (cd *ClickHouseDialect) Placeholder(index int) string {
+	return "?"
+}
+
 func (cd *ClickHouseDialect) GetSQLParser() sqlparser.SqlParser {
 	return new(pgsql.PgsqlParser)
 }
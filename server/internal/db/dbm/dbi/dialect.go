@@ -37,6 +37,9 @@ type BaseDialect interface {
 
 	// GetSQLParser 获取sql解析器
 	GetSQLParser() sqlparser.SqlParser
+
+	// Placeholder 获取sql参数绑定的占位符，index从1开始，如mysql为?，postgres为$1
+	Placeholder(index int) string
 }
 
 // -----------------------------------元数据接口定义------------------------------------------
@@ -73,6 +76,10 @@ func (pd *DefaultDialect) GetSQLParser() sqlparser.SqlParser {
 	return new(pgsql.PgsqlParser)
 }
 
+func (dd *DefaultDialect) Placeholder(index int) string {
+	return "?"
+}
+
 // DumpHelper 导出辅助方法
 type DumpHelper interface {
 	BeforeInsert(writer io.Writer, tableName string)
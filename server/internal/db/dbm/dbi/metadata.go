@@ -82,6 +82,19 @@ type Index struct {
 	Extra        collx.M `json:"extra"`        // 其他额外信息，如索引列的前缀长度等
 }
 
+// 外键信息
+type ForeignKey struct {
+	ColumnName    string `json:"columnName"`    // 列名
+	RefTableName  string `json:"refTableName"`  // 关联表名
+	RefColumnName string `json:"refColumnName"` // 关联表列名
+}
+
+// ForeignKeyMetadata 外键元数据接口，支持获取外键信息的数据库Metadata实现该接口
+type ForeignKeyMetadata interface {
+	// GetForeignKeys 获取表的外键信息
+	GetForeignKeys(tableName string) ([]ForeignKey, error)
+}
+
 // ------------------------- 元数据sql操作 -------------------------
 //
 //go:embed metasql/*
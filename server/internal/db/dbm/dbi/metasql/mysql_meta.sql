@@ -76,4 +76,22 @@ FROM information_schema.COLUMNS
 WHERE table_schema = (SELECT DATABASE())
   AND table_name IN (%s)
 ORDER BY table_name,
-         ordinal_position
\ No newline at end of file
+         ordinal_position
+---------------------------------------
+--MYSQL_FOREIGN_KEY_INFO 外键信息
+SELECT
+  column_name columnName,
+  referenced_table_name refTableName,
+  referenced_column_name refColumnName
+FROM
+  information_schema.KEY_COLUMN_USAGE
+WHERE
+  table_schema = (
+    SELECT
+      database ()
+  )
+  AND table_name = ?
+  AND referenced_table_name IS NOT NULL
+ORDER BY
+  constraint_name asc,
+  ordinal_position asc
\ No newline at end of file
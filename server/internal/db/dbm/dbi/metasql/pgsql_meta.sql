@@ -111,3 +111,16 @@ WHERE
 ORDER BY
   a.table_name,
   a.ordinal_position;
+---------------------------------------
+--PGSQL_FOREIGN_KEY_INFO 表外键信息
+SELECT kcu.column_name AS "columnName",
+       ccu.table_name  AS "refTableName",
+       ccu.column_name AS "refColumnName"
+FROM information_schema.table_constraints tc
+         join information_schema.key_column_usage kcu
+              on tc.constraint_name = kcu.constraint_name AND tc.table_schema = kcu.table_schema
+         join information_schema.constraint_column_usage ccu
+              on tc.constraint_name = ccu.constraint_name AND tc.table_schema = ccu.table_schema
+WHERE tc.constraint_type = 'FOREIGN KEY'
+  AND tc.table_schema = (select current_schema())
+  AND tc.table_name = '%s'
\ No newline at end of file
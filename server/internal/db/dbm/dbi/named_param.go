@@ -0,0 +1,119 @@
+package dbi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseNamedParams 解析sql中的命名参数（如 :start_date），按首次出现顺序返回去重后的参数名。
+// 字符串、引用标识符、注释中的内容以及 ::type 类型转换、:= 赋值不会被识别为参数
+func ParseNamedParams(sql string) []string {
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	walkNamedParams(sql, func(name string) string {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+		return ""
+	})
+	return names
+}
+
+// BindNamedParams 将sql中的命名参数替换为数据库方言对应的占位符，并按占位符顺序返回绑定参数值，
+// 参数值由驱动进行绑定，避免sql拼接注入
+func BindNamedParams(dialect Dialect, sql string, params map[string]any) (string, []any, error) {
+	args := make([]any, 0)
+	var missing []string
+	boundSql := walkNamedParams(sql, func(name string) string {
+		val, ok := params[name]
+		if !ok {
+			missing = append(missing, name)
+		}
+		args = append(args, val)
+		return dialect.Placeholder(len(args))
+	})
+	if len(missing) > 0 {
+		return "", nil, fmt.Errorf("missing sql params: %s", strings.Join(missing, ", "))
+	}
+	return boundSql, args, nil
+}
+
+// ReplaceNamedParams 将sql中的命名参数替换为指定的字面量，主要用于sql语法解析校验
+func ReplaceNamedParams(sql string, literal string) string {
+	return walkNamedParams(sql, func(name string) string {
+		return literal
+	})
+}
+
+// walkNamedParams 遍历sql中的命名参数，并使用replace的返回值替换参数，返回替换后的sql
+func walkNamedParams(sql string, replace func(name string) string) string {
+	var sb strings.Builder
+	runes := []rune(sql)
+	n := len(runes)
+
+	for i := 0; i < n; i++ {
+		c := runes[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			// 字符串或引用标识符，连续两个引号为转义
+			end := i + 1
+			for end < n {
+				if runes[end] == c {
+					if end+1 < n && runes[end+1] == c {
+						end += 2
+						continue
+					}
+					break
+				}
+				if runes[end] == '\\' && c != '"' {
+					end++
+				}
+				end++
+			}
+			end = min(end, n-1)
+			sb.WriteString(string(runes[i : end+1]))
+			i = end
+		case c == '-' && i+1 < n && runes[i+1] == '-':
+			// 单行注释
+			end := i
+			for end < n && runes[end] != '\n' {
+				end++
+			}
+			sb.WriteString(string(runes[i:end]))
+			i = end - 1
+		case c == '/' && i+1 < n && runes[i+1] == '*':
+			// 多行注释
+			end := i + 2
+			for end < n && !(runes[end] == '*' && end+1 < n && runes[end+1] == '/') {
+				end++
+			}
+			end = min(end+1, n-1)
+			sb.WriteString(string(runes[i : end+1]))
+			i = end
+		case c == ':' && i+1 < n && (runes[i+1] == ':' || runes[i+1] == '='):
+			// ::type 类型转换或 := 赋值
+			sb.WriteRune(c)
+			sb.WriteRune(runes[i+1])
+			i++
+		case c == ':' && i+1 < n && isParamNameStart(runes[i+1]) && (i == 0 || !isParamNameChar(runes[i-1])):
+			end := i + 1
+			for end < n && isParamNameChar(runes[end]) {
+				end++
+			}
+			sb.WriteString(replace(string(runes[i+1 : end])))
+			i = end - 1
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+func isParamNameStart(c rune) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isParamNameChar(c rune) bool {
+	return isParamNameStart(c) || (c >= '0' && c <= '9')
+}
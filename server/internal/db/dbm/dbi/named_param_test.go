@@ -0,0 +1,66 @@
+package dbi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type placeholderDialect struct {
+	DefaultDialect
+	format string
+}
+
+func (pd *placeholderDialect) Placeholder(index int) string {
+	if pd.format == "" {
+		return "?"
+	}
+	return fmt.Sprintf(pd.format, index)
+}
+
+func (pd *placeholderDialect) CopyTable(copy *DbCopyTable) error {
+	return nil
+}
+
+func (pd *placeholderDialect) GetSQLGenerator() SQLGenerator {
+	return nil
+}
+
+func TestParseNamedParams(t *testing.T) {
+	kases := []struct {
+		sql      string
+		expected []string
+	}{
+		{"select * from t where a = :a and b > :b_1", []string{"a", "b_1"}},
+		{"select * from t where a = :a or c = :a", []string{"a"}},
+		{"select ':a', \"x:y\", `:z` from t where d = :d", []string{"d"}},
+		{"select 'it''s :a' from t -- :b\n where c = :c /* :e */", []string{"c"}},
+		{"select id::text from t where ts > :start_date", []string{"start_date"}},
+		{"select :1 from dual", []string{}},
+	}
+	for _, k := range kases {
+		assert.Equal(t, k.expected, ParseNamedParams(k.sql), k.sql)
+	}
+}
+
+func TestBindNamedParams(t *testing.T) {
+	params := map[string]any{"a": 1, "b": "x'; drop table t; --"}
+
+	sql, args, err := BindNamedParams(&placeholderDialect{}, "select * from t where a = :a and b = :b and c = :a", params)
+	assert.Nil(t, err)
+	assert.Equal(t, "select * from t where a = ? and b = ? and c = ?", sql)
+	assert.Equal(t, []any{1, "x'; drop table t; --", 1}, args)
+
+	sql, _, err = BindNamedParams(&placeholderDialect{format: "$%d"}, "select * from t where a = :a and b = :b", params)
+	assert.Nil(t, err)
+	assert.Equal(t, "select * from t where a = $1 and b = $2", sql)
+
+	_, _, err = BindNamedParams(&placeholderDialect{}, "select * from t where d = :d", params)
+	assert.NotNil(t, err)
+}
+
+func TestReplaceNamedParams(t *testing.T) {
+	sql := ReplaceNamedParams("select ':a', id::text from t where a = :a and b > :b_1 limit :size", "0")
+	assert.Equal(t, "select ':a', id::text from t where a = 0 and b > 0 limit 0", sql)
+}
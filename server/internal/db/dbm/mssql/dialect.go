@@ -124,3 +124,7 @@ func (md *MssqlDialect) GetDumpHelper() dbi.DumpHelper {
 func (md *MssqlDialect) GetSQLGenerator() dbi.SQLGenerator {
 	return &SQLGenerator{dc: md.dc}
 }
+
+func (md *MssqlDialect) Placeholder(index int) string {
+	return fmt.Sprintf("@p%d", index)
+}
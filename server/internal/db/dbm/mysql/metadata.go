@@ -18,6 +18,7 @@ const (
 	MYSQL_TABLE_INFO_KEY = "MYSQL_TABLE_INFO"
 	MYSQL_INDEX_INFO_KEY = "MYSQL_INDEX_INFO"
 	MYSQL_COLUMN_MA_KEY  = "MYSQL_COLUMN_MA"
+	MYSQL_FOREIGN_KEY    = "MYSQL_FOREIGN_KEY_INFO"
 )
 
 type MysqlMetadata struct {
@@ -176,6 +177,24 @@ func (md *MysqlMetadata) GetTableIndex(tableName string) ([]dbi.Index, error) {
 	return result, nil
 }
 
+// 获取表外键信息
+func (md *MysqlMetadata) GetForeignKeys(tableName string) ([]dbi.ForeignKey, error) {
+	_, res, err := md.dc.Query(dbi.GetLocalSql(MYSQL_META_FILE, MYSQL_FOREIGN_KEY), tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	fks := make([]dbi.ForeignKey, 0, len(res))
+	for _, re := range res {
+		fks = append(fks, dbi.ForeignKey{
+			ColumnName:    cast.ToString(re["columnName"]),
+			RefTableName:  cast.ToString(re["refTableName"]),
+			RefColumnName: cast.ToString(re["refColumnName"]),
+		})
+	}
+	return fks, nil
+}
+
 // 获取建表ddl
 func (md *MysqlMetadata) GetTableDDL(tableName string, dropBeforeCreate bool) (string, error) {
 	return dbi.GenTableDDL(md.dc.GetDialect(), md, tableName, dropBeforeCreate)
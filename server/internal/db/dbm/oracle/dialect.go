@@ -288,3 +288,7 @@ func (od *OracleDialect) genColumnBasicSql(column dbi.Column) string {
 	columnSql := fmt.Sprintf(" %s %s%s%s", colName, column.GetColumnType(), defVal, nullAble)
 	return columnSql
 }
+
+func (od *OracleDialect) Placeholder(index int) string {
+	return fmt.Sprintf(":%d", index)
+}
@@ -81,3 +81,7 @@ func (md *PgsqlDialect) GetSQLGenerator() dbi.SQLGenerator {
 		dc:      md.dc,
 	}
 }
+
+func (pd *PgsqlDialect) Placeholder(index int) string {
+	return fmt.Sprintf("$%d", index)
+}
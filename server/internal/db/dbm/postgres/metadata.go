@@ -17,6 +17,7 @@ const (
 	PGSQL_TABLE_INFO_KEY = "PGSQL_TABLE_INFO"
 	PGSQL_INDEX_INFO_KEY = "PGSQL_INDEX_INFO"
 	PGSQL_COLUMN_MA_KEY  = "PGSQL_COLUMN_MA"
+	PGSQL_FOREIGN_KEY    = "PGSQL_FOREIGN_KEY_INFO"
 )
 
 type PgsqlMetadata struct {
@@ -175,6 +176,24 @@ func (pd *PgsqlMetadata) GetTableIndex(tableName string) ([]dbi.Index, error) {
 	return result, nil
 }
 
+// 获取表外键信息
+func (pd *PgsqlMetadata) GetForeignKeys(tableName string) ([]dbi.ForeignKey, error) {
+	_, res, err := pd.dc.Query(fmt.Sprintf(dbi.GetLocalSql(PGSQL_META_FILE, PGSQL_FOREIGN_KEY), tableName))
+	if err != nil {
+		return nil, err
+	}
+
+	fks := make([]dbi.ForeignKey, 0, len(res))
+	for _, re := range res {
+		fks = append(fks, dbi.ForeignKey{
+			ColumnName:    cast.ToString(re["columnName"]),
+			RefTableName:  cast.ToString(re["refTableName"]),
+			RefColumnName: cast.ToString(re["refColumnName"]),
+		})
+	}
+	return fks, nil
+}
+
 // 获取建表ddl
 func (pd *PgsqlMetadata) GetTableDDL(tableName string, dropBeforeCreate bool) (string, error) {
 	return dbi.GenTableDDL(pd.dc.GetDialect(), pd, tableName, dropBeforeCreate)
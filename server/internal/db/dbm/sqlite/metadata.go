@@ -202,6 +202,24 @@ func (sd *SqliteMetadata) GetTableIndex(tableName string) ([]dbi.Index, error) {
 	return indexs, nil
 }
 
+// 获取表外键信息
+func (sd *SqliteMetadata) GetForeignKeys(tableName string) ([]dbi.ForeignKey, error) {
+	_, res, err := sd.dc.Query(fmt.Sprintf("PRAGMA foreign_key_list('%s')", tableName))
+	if err != nil {
+		return nil, err
+	}
+
+	fks := make([]dbi.ForeignKey, 0, len(res))
+	for _, re := range res {
+		fks = append(fks, dbi.ForeignKey{
+			ColumnName:    cast.ToString(re["from"]),
+			RefTableName:  cast.ToString(re["table"]),
+			RefColumnName: cast.ToString(re["to"]),
+		})
+	}
+	return fks, nil
+}
+
 // 获取建表ddl
 func (sd *SqliteMetadata) GetTableDDL(tableName string, dropBeforeCreate bool) (string, error) {
 	var builder strings.Builder
@@ -18,28 +18,59 @@ type DataSyncTask struct {
 	RunningState int8   `json:"runningState" gorm:"not null;default:2;comment:运行时状态 1运行中、2待运行、3已停止"` // 运行时状态 1运行中、2待运行、3已停止
 
 	// 源数据库信息
-	SrcDbId     int64  `json:"srcDbId" gorm:"not null;comment:源数据库ID"`                                                           // 源数据库ID
-	SrcDbName   string `json:"srcDbName" gorm:"size:100;comment:源数据库名"`                                                          // 源数据库名
-	SrcTagPath  string `json:"srcTagPath" gorm:"size:200;comment:源数据库tag路径"`                                                     // 源数据库tag路径
-	DataSql     string `json:"dataSql" gorm:"not null;type:text;comment:数据查询sql"`                                                // 数据源查询sql
-	PageSize    int    `json:"pageSize" gorm:"not null;comment:数据同步分页大小"`                                                        // 配置分页sql查询的条数
-	UpdField    string `json:"updField" gorm:"not null;size:100;default:'id';comment:更新字段，默认'id'"`                               // 更新字段， 选择由哪个字段为更新字段，查询数据源的时候会带上这个字段，如：where update_time > {最近更新的最大值}
-	UpdFieldVal string `json:"updFieldVal" gorm:"size:100;comment:当前更新值"`                                                        // 更新字段当前值
-	UpdFieldSrc string `json:"updFieldSrc" gorm:"comment:更新值来源, 如select name as user_name from user;  则updFieldSrc的值为user_name"` // 更新值来源, 如select name as user_name from user;  则updFieldSrc的值为user_name
+	SrcDbId    int64  `json:"srcDbId" gorm:"not null;comment:源数据库ID"`       // 源数据库ID
+	SrcDbName  string `json:"srcDbName" gorm:"size:100;comment:源数据库名"`      // 源数据库名
+	SrcTagPath string `json:"srcTagPath" gorm:"size:200;comment:源数据库tag路径"` // 源数据库tag路径
+	PageSize   int    `json:"pageSize" gorm:"not null;comment:数据同步分页大小"`    // 配置分页sql查询的条数
 
 	// 目标数据库信息
-	TargetDbId        int64  `json:"targetDbId" gorm:"not null;comment:目标数据库ID"`                                  // 目标数据库ID
-	TargetDbName      string `json:"targetDbName" gorm:"size:150;comment:目标数据库名"`                                 // 目标数据库名
-	TargetTagPath     string `json:"targetTagPath" gorm:"size:255;comment:目标数据库tag路径"`                            // 目标数据库tag路径
-	TargetTableName   string `json:"targetTableName" gorm:"size:150;comment:目标数据库表名"`                             // 目标数据库表名
-	FieldMap          string `json:"fieldMap" gorm:"type:text;comment:字段映射json"`                                  // 字段映射json
-	DuplicateStrategy int    `json:"duplicateStrategy" gorm:"not null;default:-1;comment:唯一键冲突策略 -1：无，1：忽略，2：覆盖"` // 冲突策略 -1：无，1：忽略，2：覆盖
+	TargetDbId    int64  `json:"targetDbId" gorm:"not null;comment:目标数据库ID"`       // 目标数据库ID
+	TargetDbName  string `json:"targetDbName" gorm:"size:150;comment:目标数据库名"`      // 目标数据库名
+	TargetTagPath string `json:"targetTagPath" gorm:"size:255;comment:目标数据库tag路径"` // 目标数据库tag路径
+
+	// 单表同步配置，已由DataSyncTaskTable替代，仅保留用于兼容旧版本数据
+	DataSql           string `json:"dataSql" gorm:"not null;type:text;comment:数据查询sql"`                                                // 数据源查询sql
+	UpdField          string `json:"updField" gorm:"not null;size:100;default:'id';comment:更新字段，默认'id'"`                               // 更新字段， 选择由哪个字段为更新字段，查询数据源的时候会带上这个字段，如：where update_time > {最近更新的最大值}
+	UpdFieldVal       string `json:"updFieldVal" gorm:"size:100;comment:当前更新值"`                                                        // 更新字段当前值
+	UpdFieldSrc       string `json:"updFieldSrc" gorm:"comment:更新值来源, 如select name as user_name from user;  则updFieldSrc的值为user_name"` // 更新值来源, 如select name as user_name from user;  则updFieldSrc的值为user_name
+	TargetTableName   string `json:"targetTableName" gorm:"size:150;comment:目标数据库表名"`                                                  // 目标数据库表名
+	FieldMap          string `json:"fieldMap" gorm:"type:text;comment:字段映射json"`                                                       // 字段映射json
+	DuplicateStrategy int    `json:"duplicateStrategy" gorm:"not null;default:-1;comment:唯一键冲突策略 -1：无，1：忽略，2：覆盖"`                      // 冲突策略 -1：无，1：忽略，2：覆盖
 }
 
 func (d *DataSyncTask) TableName() string {
 	return "t_db_data_sync_task"
 }
 
+// DataSyncTaskTable 数据同步任务的单表同步配置，一个任务可包含多个表，按Sort升序（依赖顺序）依次同步
+type DataSyncTaskTable struct {
+	model.IdModel
+
+	TaskId uint64 `json:"taskId" gorm:"not null;index;comment:同步任务id"` // 同步任务id
+	Sort   int    `json:"sort" gorm:"not null;default:0;comment:执行顺序"` // 执行顺序，被依赖的表需排在前面
+
+	DataSql     string `json:"dataSql" gorm:"not null;type:text;comment:数据查询sql"`         // 数据源查询sql
+	UpdField    string `json:"updField" gorm:"size:100;default:'id';comment:更新字段，默认'id'"` // 更新字段
+	UpdFieldVal string `json:"updFieldVal" gorm:"size:100;comment:当前更新值"`                 // 更新字段当前值
+	UpdFieldSrc string `json:"updFieldSrc" gorm:"size:100;comment:更新值来源"`                 // 更新值来源, 如select name as user_name from user;  则updFieldSrc的值为user_name
+
+	TargetTableName   string `json:"targetTableName" gorm:"not null;size:150;comment:目标数据库表名"`                    // 目标数据库表名
+	FieldMap          string `json:"fieldMap" gorm:"type:text;comment:字段映射json"`                                  // 字段映射json
+	DuplicateStrategy int    `json:"duplicateStrategy" gorm:"not null;default:-1;comment:唯一键冲突策略 -1：无，1：忽略，2：覆盖"` // 冲突策略 -1：无，1：忽略，2：覆盖
+}
+
+func (d *DataSyncTaskTable) TableName() string {
+	return "t_db_data_sync_task_table"
+}
+
+// DataSyncTableStat 单次执行中单表的同步结果
+type DataSyncTableStat struct {
+	TargetTableName string `json:"targetTableName"` // 目标表名
+	ResNum          int    `json:"resNum"`          // 同步条数
+	Status          int8   `json:"status"`          // 状态 1成功 2执行中 -1失败
+	ErrText         string `json:"errText"`         // 错误信息
+}
+
 type DataSyncLog struct {
 	model.IdModel
 
@@ -47,6 +78,7 @@ type DataSyncLog struct {
 	TaskId      uint64     `json:"taskId" gorm:"not null;comment:同步任务表id"`                 // 任务表id
 	DataSqlFull string     `json:"dataSqlFull" gorm:"not null;type:text;comment:执行的完整sql"` // 执行的完整sql
 	ResNum      int        `json:"resNum" gorm:"comment:收到数据条数"`                           // 收到数据条数
+	TableStats  string     `json:"tableStats" gorm:"type:text;comment:各表同步结果json"`         // 各表同步结果json，[]DataSyncTableStat
 	ErrText     string     `json:"errText" gorm:"type:text;comment:日志"`                    // 日志
 	Status      int8       `json:"status" gorm:"not null;default:1;comment:状态:1.成功  0.失败"` // 状态:1.成功  0.失败
 }
@@ -8,9 +8,57 @@ import (
 type DbSql struct {
 	model.Model `orm:"-"`
 
-	DbId uint64 `json:"dbId" gorm:"not null;"`
-	Db   string `json:"db" gorm:"size:100;not null;"`
-	Type int    `json:"type" gorm:"not null;"` // 类型
-	Sql  string `json:"sql" gorm:"type:longtext;comment:sql语句"`
-	Name string `json:"name" gorm:"size:255;not null;comment:sql模板名"`
+	DbId    uint64 `json:"dbId" gorm:"not null;"`
+	Db      string `json:"db" gorm:"size:100;not null;"`
+	Type    int    `json:"type" gorm:"not null;"` // 类型
+	Sql     string `json:"sql" gorm:"type:longtext;comment:sql语句"`
+	Name    string `json:"name" gorm:"size:255;not null;comment:sql模板名"`
+	Folder  string `json:"folder" gorm:"size:100;comment:所属文件夹"`            // 所属文件夹
+	Params  string `json:"params" gorm:"type:text;comment:命名参数定义json"`      // 命名参数定义json，[]DbSqlParam
+	Version int    `json:"version" gorm:"not null;default:1;comment:当前版本号"` // 当前版本号
+}
+
+// DbSqlParam sql命名参数定义，如sql中的 :start_date
+type DbSqlParam struct {
+	Name     string `json:"name"`     // 参数名
+	Label    string `json:"label"`    // 输入提示
+	Type     string `json:"type"`     // 参数类型
+	Default  string `json:"default"`  // 默认值
+	Required bool   `json:"required"` // 是否必填
+}
+
+const (
+	DbSqlParamTypeString   = "string"
+	DbSqlParamTypeInt      = "int"
+	DbSqlParamTypeNumber   = "number"
+	DbSqlParamTypeBool     = "bool"
+	DbSqlParamTypeDate     = "date"
+	DbSqlParamTypeDatetime = "datetime"
+)
+
+// DbSqlVersion 保存的sql历史版本
+type DbSqlVersion struct {
+	model.CreateModelNLD
+
+	DbSqlId uint64 `json:"dbSqlId" gorm:"not null;index;comment:sql id"`
+	Version int    `json:"version" gorm:"not null;comment:版本号"`
+	Sql     string `json:"sql" gorm:"type:longtext;comment:sql语句"`
+	Params  string `json:"params" gorm:"type:text;comment:命名参数定义json"`
+	Remark  string `json:"remark" gorm:"size:255;comment:版本说明"`
+}
+
+func (d *DbSqlVersion) TableName() string {
+	return "t_db_sql_version"
+}
+
+// DbSqlShare 保存的sql共享至团队
+type DbSqlShare struct {
+	model.CreateModelNLD
+
+	DbSqlId uint64 `json:"dbSqlId" gorm:"not null;index;comment:sql id"`
+	TeamId  uint64 `json:"teamId" gorm:"not null;index;comment:团队id"`
+}
+
+func (d *DbSqlShare) TableName() string {
+	return "t_db_sql_share"
 }
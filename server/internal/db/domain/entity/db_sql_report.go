@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"mayfly-go/pkg/model"
+	"time"
+)
+
+// DbSqlReport 定时sql报表，定时执行保存的查询sql并将结果通过消息渠道发送
+type DbSqlReport struct {
+	model.Model
+
+	Name    string `json:"name" gorm:"not null;size:100;comment:报表名称"`     // 报表名称
+	DbSqlId uint64 `json:"dbSqlId" gorm:"not null;comment:保存的sql id"`      // 保存的sql id
+	DbId    uint64 `json:"dbId" gorm:"not null;comment:目标数据库id"`           // 目标数据库id
+	Db      string `json:"db" gorm:"not null;size:100;comment:目标数据库名"`     // 目标数据库名
+	Cron    string `json:"cron" gorm:"not null;size:50;comment:执行cron表达式"` // 执行cron表达式
+	TaskKey string `json:"taskKey" gorm:"size:100;comment:任务唯一标识"`         // 任务唯一标识
+
+	ResultFormat string `json:"resultFormat" gorm:"not null;size:20;comment:结果格式 markdown、html、csv"` // 结果格式
+	MaxRows      int    `json:"maxRows" gorm:"not null;default:1000;comment:最大结果行数"`                 // 最大结果行数
+
+	MsgTmplId      uint64 `json:"msgTmplId" gorm:"not null;comment:报表消息模板id"`       // 报表消息模板id
+	AlertMsgTmplId uint64 `json:"alertMsgTmplId" gorm:"comment:执行失败告警消息模板id"`       // 执行失败告警消息模板id
+	ReceiverIds    string `json:"receiverIds" gorm:"size:500;comment:消息接收人id，逗号分隔"` // 消息接收人id，逗号分隔
+
+	Status       int8 `json:"status" gorm:"not null;default:1;comment:状态 1启用 -1禁用"`          // 状态 1启用 -1禁用
+	RecentState  int8 `json:"recentState" gorm:"not null;default:0;comment:最近执行状态 1成功 -1失败"` // 最近执行状态 1成功 -1失败
+	RunningState int8 `json:"runningState" gorm:"not null;default:0;comment:运行状态 1运行中 0空闲"`  // 运行状态 1运行中 0空闲
+}
+
+func (d *DbSqlReport) TableName() string {
+	return "t_db_sql_report"
+}
+
+// DbSqlReportLog sql报表执行记录
+type DbSqlReportLog struct {
+	model.IdModel
+
+	CreateTime *time.Time `json:"createTime" gorm:"not null;"`                          // 执行时间
+	ReportId   uint64     `json:"reportId" gorm:"not null;index;comment:报表id"`          // 报表id
+	Sql        string     `json:"sql" gorm:"type:text;comment:执行的sql"`                  // 执行的sql
+	RowNum     int        `json:"rowNum" gorm:"comment:结果行数"`                           // 结果行数
+	Duration   int64      `json:"duration" gorm:"comment:执行耗时(ms)"`                     // 执行耗时(ms)
+	ErrText    string     `json:"errText" gorm:"type:text;comment:错误信息"`                // 错误信息
+	Status     int8       `json:"status" gorm:"not null;default:1;comment:状态 1成功 -1失败"` // 状态 1成功 -1失败
+}
+
+func (d *DbSqlReportLog) TableName() string {
+	return "t_db_sql_report_log"
+}
+
+const (
+	DbSqlReportStatusEnable  int8 = 1  // 启用状态
+	DbSqlReportStatusDisable int8 = -1 // 禁用状态
+
+	DbSqlReportRunStateIdle    int8 = 0 // 空闲
+	DbSqlReportRunStateRunning int8 = 1 // 运行中
+
+	DbSqlReportStateSuccess int8 = 1  // 执行成功
+	DbSqlReportStateFail    int8 = -1 // 执行失败
+
+	DbSqlReportFormatMarkdown = "markdown"
+	DbSqlReportFormatHtml     = "html"
+	DbSqlReportFormatCsv      = "csv"
+)
@@ -12,7 +12,8 @@ type DbTransferTask struct {
 	CronAble         int8   `json:"cronAble" gorm:"default:-1;not null;"` // 是否定时  1是 -1否
 	Cron             string `json:"cron" gorm:"size:32;"`                 // 定时任务cron表达式
 	Mode             int8   `json:"mode"`                                 // 数据迁移方式，1、迁移到数据库  2、迁移到文件
-	TargetFileDbType string `json:"targetFileDbType" gorm:"size:32;"`     // 目标文件数据库类型
+	TargetFileType   string `json:"targetFileType" gorm:"size:32;"`       // 目标文件类型 sql、parquet、csv、jsonl，为空则为sql
+	TargetFileDbType string `json:"targetFileDbType" gorm:"size:32;"`     // 目标文件数据库类型，仅sql文件有效
 	FileSaveDays     int    `json:"fileSaveDays"`                         // 文件保存天数
 	Status           int8   `json:"status"`                               // 启用状态 1启用 -1禁用
 	RunningState     int8   `json:"runningState"`                         // 运行状态
@@ -55,3 +56,18 @@ const (
 	DbTransferTaskRunStateFail    int8 = -1 // 执行失败
 	DbTransferTaskRunStateStop    int8 = -2 // 手动终止
 )
+
+const (
+	DbTransferFileTypeSql     = "sql"     // sql文件
+	DbTransferFileTypeParquet = "parquet" // parquet文件，每个表一个文件并打包为zip
+	DbTransferFileTypeCsv     = "csv"     // csv文件，每个表一个文件并打包为zip
+	DbTransferFileTypeJsonl   = "jsonl"   // json lines文件，每个表一个文件并打包为zip
+)
+
+// GetTargetFileType 获取目标文件类型，默认为sql
+func (d *DbTransferTask) GetTargetFileType() string {
+	if d.TargetFileType == "" {
+		return DbTransferFileTypeSql
+	}
+	return d.TargetFileType
+}
@@ -13,6 +13,7 @@ type DbTransferFile struct {
 	Status     int8       `json:"status" gorm:"default:1;comment:状态 1、执行中 2、执行成功 3、执行失败"` // 状态 1、执行中 2、执行成功 3、执行失败
 	TaskId     uint64     `json:"taskId" gorm:"comment:迁移任务ID"`                           // 迁移任务ID
 	LogId      uint64     `json:"logId" gorm:"comment:日志ID"`                              // 日志ID
+	FileType   string     `json:"fileType" gorm:"size:32;comment:文件类型"`                   // 文件类型 sql、parquet、csv、jsonl
 	FileDbType string     `json:"fileDbType" gorm:"size:32;comment:sql文件数据库类型"`           // sql文件数据库类型
 	FileKey    string     `json:"fileKey" gorm:"size:50;comment:文件"`                      // 文件
 }
@@ -109,3 +109,20 @@ type DbRestoreHistoryQuery struct {
 	Id          uint64 `json:"id" form:"id"`
 	DbRestoreId uint64 `json:"dbRestoreId" form:"dbRestoreId"`
 }
+
+type DbSqlReportQuery struct {
+	model.PageParam
+
+	Name   string `json:"name" form:"name"`
+	Status int8   `json:"status" form:"status"`
+
+	CreatorId uint64   // 报表创建者，与DbIds为或关系
+	DbIds     []uint64 // 可访问的数据库id
+}
+
+type DbSqlReportLogQuery struct {
+	model.PageParam
+
+	ReportId uint64 `json:"reportId" form:"reportId"`
+	Status   int8   `json:"status" form:"status"`
+}
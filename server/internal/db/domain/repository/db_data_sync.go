@@ -13,6 +13,10 @@ type DataSyncTask interface {
 	GetTaskList(condition *entity.DataSyncTaskQuery, orderBy ...string) (*model.PageResult[*entity.DataSyncTask], error)
 }
 
+type DataSyncTaskTable interface {
+	base.Repo[*entity.DataSyncTaskTable]
+}
+
 type DataSyncLog interface {
 	base.Repo[*entity.DataSyncLog]
 
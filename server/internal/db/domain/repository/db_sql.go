@@ -8,3 +8,11 @@ import (
 type DbSql interface {
 	base.Repo[*entity.DbSql]
 }
+
+type DbSqlVersion interface {
+	base.Repo[*entity.DbSqlVersion]
+}
+
+type DbSqlShare interface {
+	base.Repo[*entity.DbSqlShare]
+}
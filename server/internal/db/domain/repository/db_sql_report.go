@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type DbSqlReport interface {
+	base.Repo[*entity.DbSqlReport]
+
+	// 分页获取sql报表列表
+	GetPageList(condition *entity.DbSqlReportQuery, orderBy ...string) (*model.PageResult[*entity.DbSqlReport], error)
+
+	// TryMarkRunning 报表未在执行时将其标记为执行中，返回是否标记成功
+	TryMarkRunning(ctx context.Context, id uint64) (bool, error)
+}
+
+type DbSqlReportLog interface {
+	base.Repo[*entity.DbSqlReportLog]
+
+	// 分页获取sql报表执行记录
+	GetPageList(condition *entity.DbSqlReportLogQuery, orderBy ...string) (*model.PageResult[*entity.DbSqlReportLog], error)
+}
@@ -37,4 +37,19 @@ var En = map[i18n.MsgId]string{
 	LogDataSyncSave:         "datasync - Save data sync task",
 	LogDataSyncDelete:       "datasync - Delete data sync task",
 	LogDataSyncChangeStatus: "datasync - Change status",
+
+	// data gen
+	LogDbDataGen:    "db - Generate table test data",
+	DataGenProgress: "data generation progress",
+	DataGenSuccess:  "test data generated successfully",
+	DataGenFail:     "test data generation failed",
+
+	// sql report
+	LogSqlReportSave:         "sqlreport - Save sql report",
+	LogSqlReportDelete:       "sqlreport - Delete sql report",
+	LogSqlReportChangeStatus: "sqlreport - Change status",
+	LogSqlReportRun:          "sqlreport - Run sql report",
+	SqlReportRunFail:         "sql report failed to execute",
+
+	LogDbSqlShare: "db - Share saved sql",
 }
@@ -47,4 +47,19 @@ const (
 	LogDataSyncSave
 	LogDataSyncDelete
 	LogDataSyncChangeStatus
+
+	// data gen
+	LogDbDataGen
+	DataGenProgress
+	DataGenSuccess
+	DataGenFail
+
+	// sql report
+	LogSqlReportSave
+	LogSqlReportDelete
+	LogSqlReportChangeStatus
+	LogSqlReportRun
+	SqlReportRunFail
+
+	LogDbSqlShare
 )
@@ -37,4 +37,19 @@ var Zh_CN = map[i18n.MsgId]string{
 	LogDataSyncSave:         "datasync-保存数据同步任务",
 	LogDataSyncDelete:       "datasync-删除数据同步任务",
 	LogDataSyncChangeStatus: "datasync-启停任务",
+
+	// data gen
+	LogDbDataGen:    "db-生成表测试数据",
+	DataGenProgress: "数据生成进度",
+	DataGenSuccess:  "测试数据生成成功",
+	DataGenFail:     "测试数据生成失败",
+
+	// sql report
+	LogSqlReportSave:         "sqlreport-保存sql报表",
+	LogSqlReportDelete:       "sqlreport-删除sql报表",
+	LogSqlReportChangeStatus: "sqlreport-启停报表",
+	LogSqlReportRun:          "sqlreport-执行sql报表",
+	SqlReportRunFail:         "sql报表执行失败",
+
+	LogDbSqlShare: "db-共享保存的sql",
 }
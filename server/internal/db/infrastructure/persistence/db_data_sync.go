@@ -23,6 +23,14 @@ func (d *dataSyncTaskRepoImpl) GetTaskList(condition *entity.DataSyncTaskQuery,
 	return d.PageByCond(qd, condition.PageParam)
 }
 
+type dataSyncTaskTableRepoImpl struct {
+	base.RepoImpl[*entity.DataSyncTaskTable]
+}
+
+func newDataSyncTaskTableRepo() repository.DataSyncTaskTable {
+	return &dataSyncTaskTableRepoImpl{}
+}
+
 type dataSyncLogRepoImpl struct {
 	base.RepoImpl[*entity.DataSyncLog]
 }
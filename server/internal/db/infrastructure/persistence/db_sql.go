@@ -13,3 +13,19 @@ type dbSqlRepoImpl struct {
 func newDbSqlRepo() repository.DbSql {
 	return &dbSqlRepoImpl{}
 }
+
+type dbSqlVersionRepoImpl struct {
+	base.RepoImpl[*entity.DbSqlVersion]
+}
+
+func newDbSqlVersionRepo() repository.DbSqlVersion {
+	return &dbSqlVersionRepoImpl{}
+}
+
+type dbSqlShareRepoImpl struct {
+	base.RepoImpl[*entity.DbSqlShare]
+}
+
+func newDbSqlShareRepo() repository.DbSqlShare {
+	return &dbSqlShareRepoImpl{}
+}
@@ -0,0 +1,59 @@
+package persistence
+
+import (
+	"context"
+	"mayfly-go/internal/db/domain/entity"
+	"mayfly-go/internal/db/domain/repository"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/global"
+	"mayfly-go/pkg/model"
+)
+
+type dbSqlReportRepoImpl struct {
+	base.RepoImpl[*entity.DbSqlReport]
+}
+
+func newDbSqlReportRepo() repository.DbSqlReport {
+	return &dbSqlReportRepoImpl{}
+}
+
+func (d *dbSqlReportRepoImpl) GetPageList(condition *entity.DbSqlReportQuery, orderBy ...string) (*model.PageResult[*entity.DbSqlReport], error) {
+	qd := model.NewCond().
+		Like("name", condition.Name).
+		Eq("status", condition.Status).
+		OrderBy(orderBy...)
+	if len(condition.DbIds) > 0 {
+		qd.And("(creator_id = ? OR db_id IN ?)", condition.CreatorId, condition.DbIds)
+	} else {
+		qd.Eq("creator_id", condition.CreatorId)
+	}
+	return d.PageByCond(qd, condition.PageParam)
+}
+
+func (d *dbSqlReportRepoImpl) TryMarkRunning(ctx context.Context, id uint64) (bool, error) {
+	db := base.GetDbFromCtx(ctx)
+	if db == nil {
+		db = global.Db
+	}
+	// 条件更新保证检查与标记的原子性，避免同一报表被并发执行
+	res := db.Model(&entity.DbSqlReport{}).
+		Where("id = ? AND running_state <> ?", id, entity.DbSqlReportRunStateRunning).
+		Update("running_state", entity.DbSqlReportRunStateRunning)
+	return res.RowsAffected > 0, res.Error
+}
+
+type dbSqlReportLogRepoImpl struct {
+	base.RepoImpl[*entity.DbSqlReportLog]
+}
+
+func newDbSqlReportLogRepo() repository.DbSqlReportLog {
+	return &dbSqlReportLogRepoImpl{}
+}
+
+func (d *dbSqlReportLogRepoImpl) GetPageList(condition *entity.DbSqlReportLogQuery, orderBy ...string) (*model.PageResult[*entity.DbSqlReportLog], error) {
+	qd := model.NewCond().
+		Eq("report_id", condition.ReportId).
+		Eq("status", condition.Status).
+		OrderByDesc("id")
+	return d.PageByCond(qd, condition.PageParam)
+}
@@ -8,9 +8,14 @@ func InitIoc() {
 	ioc.Register(NewInstanceRepo(), ioc.WithComponentName("DbInstanceRepo"))
 	ioc.Register(newDbRepo(), ioc.WithComponentName("DbRepo"))
 	ioc.Register(newDbSqlRepo(), ioc.WithComponentName("DbSqlRepo"))
+	ioc.Register(newDbSqlVersionRepo(), ioc.WithComponentName("DbSqlVersionRepo"))
+	ioc.Register(newDbSqlShareRepo(), ioc.WithComponentName("DbSqlShareRepo"))
 	ioc.Register(newDbSqlExecRepo(), ioc.WithComponentName("DbSqlExecRepo"))
 	ioc.Register(newDataSyncTaskRepo(), ioc.WithComponentName("DbDataSyncTaskRepo"))
+	ioc.Register(newDataSyncTaskTableRepo(), ioc.WithComponentName("DbDataSyncTaskTableRepo"))
 	ioc.Register(newDataSyncLogRepo(), ioc.WithComponentName("DbDataSyncLogRepo"))
 	ioc.Register(newDbTransferTaskRepo(), ioc.WithComponentName("DbTransferTaskRepo"))
 	ioc.Register(newDbTransferFileRepo(), ioc.WithComponentName("DbTransferFileRepo"))
+	ioc.Register(newDbSqlReportRepo(), ioc.WithComponentName("DbSqlReportRepo"))
+	ioc.Register(newDbSqlReportLogRepo(), ioc.WithComponentName("DbSqlReportLogRepo"))
 }
@@ -9,4 +9,8 @@ func InitIoc() {
 	ioc.Register(new(MachineScript))
 	ioc.Register(new(MachineCronJob))
 	ioc.Register(new(MachineCmdConf))
+	ioc.Register(new(MachineAlert))
+	ioc.Register(new(MachineCmdBatch))
+	ioc.Register(new(MachineFileTransfer))
+	ioc.Register(new(MachinePortForward))
 }
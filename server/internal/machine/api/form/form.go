@@ -3,6 +3,7 @@ package form
 import (
 	tagentity "mayfly-go/internal/tag/domain/entity"
 	"mayfly-go/pkg/model"
+	"time"
 )
 
 type MachineForm struct {
@@ -48,6 +49,13 @@ type MachineCronJobForm struct {
 	SaveExecResType int      `json:"saveExecResType" binding:"required"`
 	Remark          string   `json:"remark"`
 	CodePaths       []string `json:"codePaths"`
+
+	Timeout           int                 `json:"timeout"`       // 单机执行超时时间(秒)
+	RetryCount        int                 `json:"retryCount"`    // 失败重试次数
+	RetryInterval     int                 `json:"retryInterval"` // 首次重试间隔(秒)
+	ConcurrencyPolicy int8                `json:"concurrencyPolicy"`
+	MsgTmplId         uint64              `json:"msgTmplId"`
+	ReceiverIds       model.Slice[uint64] `json:"receiverIds"`
 }
 
 type MachineCmdConfForm struct {
@@ -60,3 +68,70 @@ type MachineCmdConfForm struct {
 
 	CodePaths []string `json:"codePaths"`
 }
+
+type MachineAlertRuleForm struct {
+	Id          uint64              `json:"id"`
+	Name        string              `json:"name" binding:"required"`
+	Metric      string              `json:"metric" binding:"required"`
+	MountPoint  string              `json:"mountPoint"`
+	Operator    string              `json:"operator"`
+	Threshold   float64             `json:"threshold"`
+	Duration    int                 `json:"duration"`
+	MsgTmplId   uint64              `json:"msgTmplId"`
+	ReceiverIds model.Slice[uint64] `json:"receiverIds"`
+	Status      int8                `json:"status" binding:"required"`
+	Remark      string              `json:"remark"`
+
+	CodePaths []string `json:"codePaths" binding:"required"`
+}
+
+type MachineAlertSilenceForm struct {
+	Id        uint64    `json:"id"`
+	RuleId    uint64    `json:"ruleId"`
+	MachineId uint64    `json:"machineId"`
+	StartTime time.Time `json:"startTime" binding:"required"`
+	EndTime   time.Time `json:"endTime" binding:"required"`
+	Comment   string    `json:"comment"`
+}
+
+type MachineScriptRunForm struct {
+	Params map[string]any `json:"params"` // 脚本参数值
+}
+
+type MachineCmdBatchRunForm struct {
+	Name         string         `json:"name"`
+	Cmd          string         `json:"cmd"`
+	ScriptId     uint64         `json:"scriptId"`
+	Params       map[string]any `json:"params"`
+	TagPaths     []string       `json:"tagPaths"`
+	MachineIds   []uint64       `json:"machineIds"`
+	MachineCodes []string       `json:"machineCodes"`
+	Concurrency  int            `json:"concurrency"`
+	Timeout      int            `json:"timeout"` // 单机超时时间(秒)
+	ClientId     string         `json:"clientId"`
+}
+
+type TerminalSessionKillForm struct {
+	Msg string `json:"msg"` // 展示给会话用户的断开原因
+}
+
+type MachinePortForwardForm struct {
+	MachineId  uint64 `json:"machineId" form:"machineId" binding:"required"`
+	TargetHost string `json:"targetHost" form:"targetHost" binding:"required"`
+	TargetPort int    `json:"targetPort" form:"targetPort" binding:"required"`
+	Ttl        int    `json:"ttl" form:"ttl"` // 有效时长(分钟)
+	Remark     string `json:"remark" form:"remark"`
+}
+
+type MachineServiceOpForm struct {
+	Unit string `json:"unit" binding:"required"` // systemd服务单元名称
+}
+
+type MachineImportForm struct {
+	Format         string   `json:"format" binding:"required"`  // 清单格式：csv、ssh_config、ansible_ini、ansible_yaml
+	Content        string   `json:"content" binding:"required"` // 清单内容
+	TagCodePaths   []string `json:"tagCodePaths"`               // 默认标签路径
+	AuthCertName   string   `json:"authCertName"`               // 默认公共授权凭证
+	EnableRecorder int8     `json:"enableRecorder"`
+	DryRun         bool     `json:"dryRun"` // 是否仅预览
+}
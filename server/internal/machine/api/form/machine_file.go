@@ -2,6 +2,7 @@ package form
 
 import (
 	"mayfly-go/internal/machine/application/dto"
+	"time"
 )
 
 type MachineFileForm struct {
@@ -48,3 +49,75 @@ type RenameForm struct {
 
 	Newname string `json:"newname" binding:"required"`
 }
+
+type ChunkUploadInitForm struct {
+	*dto.MachineFileOp
+
+	Filename    string `json:"filename" binding:"required"`
+	Size        int64  `json:"size" binding:"min=0"`
+	Fingerprint string `json:"fingerprint"` // 客户端文件指纹，如文件最后修改时间，用于区分同名同大小的不同文件
+}
+
+type DirArchiveDownloadForm struct {
+	*dto.MachineFileOp
+
+	Format string `json:"format" form:"format"` // 归档格式 tar.gz、zip，默认tar.gz
+}
+
+type MachineFileTransferForm struct {
+	Src       *dto.MachineFileOp `json:"src" binding:"required"`
+	Dst       *dto.MachineFileOp `json:"dst" binding:"required"`
+	Overwrite bool               `json:"overwrite"`
+	ClientId  string             `json:"clientId"`
+}
+
+type MachineFileTailForm struct {
+	*dto.MachineFileOp
+
+	Lines      int    `json:"lines" form:"lines"`
+	Filter     string `json:"filter" form:"filter"`
+	Highlight  string `json:"highlight" form:"highlight"`
+	Regexp     bool   `json:"regexp" form:"regexp"`
+	IgnoreCase bool   `json:"ignoreCase" form:"ignoreCase"`
+}
+
+type ChmodForm struct {
+	*dto.MachineFileOp
+
+	Mode      string `json:"mode" binding:"required"` // 八进制权限，如755
+	Recursive bool   `json:"recursive"`
+}
+
+type ChownForm struct {
+	*dto.MachineFileOp
+
+	Owner     string `json:"owner"` // 用户名或uid，为空则不修改
+	Group     string `json:"group"` // 组名或gid，为空则不修改
+	Recursive bool   `json:"recursive"`
+}
+
+type SymlinkForm struct {
+	*dto.MachineFileOp
+
+	Target string `json:"target" binding:"required"` // 软链接指向的路径
+}
+
+type ChtimesForm struct {
+	*dto.MachineFileOp
+
+	Atime     *time.Time `json:"atime"` // 访问时间，为空则与修改时间一致
+	Mtime     time.Time  `json:"mtime" binding:"required"`
+	Recursive bool       `json:"recursive"`
+}
+
+type MachineFileVersionDiffForm struct {
+	*dto.MachineFileOp
+
+	FromVersionId uint64 `json:"fromVersionId" form:"fromVersionId" binding:"required"`
+	ToVersionId   uint64 `json:"toVersionId" form:"toVersionId"` // 为空则与文件当前内容对比
+}
+
+type MachineFileDiffForm struct {
+	From *dto.MachineFileOp `json:"from" binding:"required"`
+	To   *dto.MachineFileOp `json:"to" binding:"required"`
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"bytes"
 	"fmt"
 	"mayfly-go/internal/event"
 	"mayfly-go/internal/machine/api/form"
@@ -32,14 +33,18 @@ import (
 )
 
 type Machine struct {
-	machineApp          application.Machine       `inject:"T"`
-	machineTermOpApp    application.MachineTermOp `inject:"T"`
-	tagTreeApp          tagapp.TagTree            `inject:"T"`
-	resourceAuthCertApp tagapp.ResourceAuthCert   `inject:"T"`
+	machineApp          application.Machine        `inject:"T"`
+	machineTermOpApp    application.MachineTermOp  `inject:"T"`
+	machineHostKeyApp   application.MachineHostKey `inject:"T"`
+	machineMonitorApp   application.MachineMonitor `inject:"T"`
+	tagTreeApp          tagapp.TagTree             `inject:"T"`
+	resourceAuthCertApp tagapp.ResourceAuthCert    `inject:"T"`
 }
 
 func (m *Machine) ReqConfs() *req.Confs {
 	saveMachineP := req.NewPermission("machine:update")
+	hostKeyP := req.NewPermission("machine:hostkey")
+	termMonitorP := req.NewPermission("machine:terminal:monitor")
 
 	reqs := [...]*req.Conf{
 		req.NewGet("", m.Machines),
@@ -48,6 +53,8 @@ func (m *Machine) ReqConfs() *req.Confs {
 
 		req.NewGet(":machineId/stats", m.MachineStats),
 
+		req.NewGet(":machineId/monitors", m.MachineMonitors),
+
 		req.NewGet(":machineId/process", m.GetProcess),
 
 		req.NewGet(":machineId/users", m.GetUsers),
@@ -60,16 +67,77 @@ func (m *Machine) ReqConfs() *req.Confs {
 
 		req.NewPost("test-conn", m.TestConn),
 
+		req.NewPost("import", m.ImportMachines).Log(req.NewLogSaveI(imsg.LogMachineImport)).RequiredPermission(saveMachineP),
+
 		req.NewPut(":machineId/:status", m.ChangeStatus).Log(req.NewLogSaveI(imsg.LogMachineChangeStatus)).RequiredPermission(saveMachineP),
 
 		req.NewDelete(":machineId/process", m.KillProcess).Log(req.NewLogSaveI(imsg.LogMachineKillProcess)).RequiredPermissionCode("machine:killprocess"),
 
+		// systemd服务管理
+		req.NewGet(":machineId/services", m.GetServices),
+
+		req.NewGet(":machineId/services/status", m.GetServiceStatus),
+
+		req.NewPost(":machineId/services/start", m.opService(mcm.SystemdActionStart)).Log(req.NewLogSaveI(imsg.LogMachineServiceStart)).RequiredPermissionCode("machine:service:start"),
+
+		req.NewPost(":machineId/services/stop", m.opService(mcm.SystemdActionStop)).Log(req.NewLogSaveI(imsg.LogMachineServiceStop)).RequiredPermissionCode("machine:service:stop"),
+
+		req.NewPost(":machineId/services/restart", m.opService(mcm.SystemdActionRestart)).Log(req.NewLogSaveI(imsg.LogMachineServiceRestart)).RequiredPermissionCode("machine:service:restart"),
+
+		req.NewPost(":machineId/services/enable", m.opService(mcm.SystemdActionEnable)).Log(req.NewLogSaveI(imsg.LogMachineServiceEnable)).RequiredPermissionCode("machine:service:enable"),
+
+		req.NewPost(":machineId/services/disable", m.opService(mcm.SystemdActionDisable)).Log(req.NewLogSaveI(imsg.LogMachineServiceDisable)).RequiredPermissionCode("machine:service:disable"),
+
+		// docker容器管理
+		req.NewGet(":machineId/containers", m.GetContainers),
+
+		req.NewGet(":machineId/containers/stats", m.GetContainerStats),
+
+		req.NewGet(":machineId/containers/:containerId/inspect", m.InspectContainer),
+
+		req.NewGet(":machineId/containers/:containerId/logs", m.WsContainerLogs).NoRes(),
+
+		req.NewPost(":machineId/containers/:containerId/start", m.opContainer(mcm.DockerActionStart)).Log(req.NewLogSaveI(imsg.LogMachineContainerStart)).RequiredPermissionCode("machine:container:op"),
+
+		req.NewPost(":machineId/containers/:containerId/stop", m.opContainer(mcm.DockerActionStop)).Log(req.NewLogSaveI(imsg.LogMachineContainerStop)).RequiredPermissionCode("machine:container:op"),
+
+		req.NewPost(":machineId/containers/:containerId/restart", m.opContainer(mcm.DockerActionRestart)).Log(req.NewLogSaveI(imsg.LogMachineContainerRestart)).RequiredPermissionCode("machine:container:op"),
+
+		req.NewDelete(":machineId/containers/:containerId", m.opContainer(mcm.DockerActionRemove)).Log(req.NewLogSaveI(imsg.LogMachineContainerRemove)).RequiredPermissionCode("machine:container:remove"),
+
 		// 获取机器终端回放记录列表,目前具有保存机器信息的权限标识才有权限查看终端回放
 		req.NewGet(":machineId/term-recs", m.MachineTermOpRecords).RequiredPermission(saveMachineP),
 
+		// 跨终端回放记录检索执行的命令
+		req.NewGet("term-recs/cmds", m.SearchTermOpCmds).RequiredPermission(saveMachineP),
+
+		req.NewGet(":machineId/term-recs/:recId", m.MachineTermOpRecord).RequiredPermission(saveMachineP),
+
+		req.NewGet(":machineId/term-recs/:recId/cmds", m.MachineTermOpCmds).RequiredPermission(saveMachineP),
+
+		req.NewGet(":machineId/term-recs/:recId/transcript", m.MachineTermOpTranscript).NoRes().RequiredPermission(saveMachineP),
+
+		// rdp、vnc会话录像文件，Guacamole session dump格式
+		req.NewGet(":machineId/term-recs/:recId/guac-rec", m.MachineGuacRecording).NoRes().RequiredPermission(saveMachineP),
+
+		// 主机公钥
+		req.NewGet(":machineId/host-key", m.GetHostKey).RequiredPermission(hostKeyP),
+
+		req.NewPost(":machineId/host-key/accept", m.AcceptHostKey).Log(req.NewLogSaveI(imsg.LogMachineHostKeyAccept)).RequiredPermission(hostKeyP),
+
+		req.NewDelete(":machineId/host-key", m.ResetHostKey).Log(req.NewLogSaveI(imsg.LogMachineHostKeyReset)).RequiredPermission(hostKeyP),
+
 		// 终端操作
 		req.NewGet("terminal/:ac", m.WsSSH).NoRes(),
+		req.NewGet("container-terminal/:ac", m.WsContainerExec).NoRes(),
 		req.NewGet("rdp/:ac", m.WsGuacamole).NoRes(),
+
+		// 活跃终端会话
+		req.NewGet("terminal-sessions", m.TerminalSessions).RequiredPermission(termMonitorP),
+
+		req.NewGet("terminal-sessions/:sessionId/shadow", m.WsShadowTerminalSession).NoRes(),
+
+		req.NewPost("terminal-sessions/:sessionId/kill", m.KillTerminalSession).Log(req.NewLogSaveI(imsg.LogMachineTerminalKill)).RequiredPermissionCode("machine:terminal:kill"),
 	}
 
 	return req.NewConfs("machines", reqs[:]...)
@@ -154,6 +222,25 @@ func (m *Machine) SaveMachine(rc *req.Ctx) {
 	}))
 }
 
+// ImportMachines 从csv、ssh config、ansible清单批量导入机器
+func (m *Machine) ImportMachines(rc *req.Ctx) {
+	importForm := req.BindJsonAndValid[*form.MachineImportForm](rc)
+	// 清单内容可能包含密码，不记录至操作日志
+	rc.ReqParam = collx.Kvs("format", importForm.Format, "dryRun", importForm.DryRun)
+
+	res, err := m.machineApp.ImportMachines(rc.MetaCtx, &dto.MachineImport{
+		Format:         importForm.Format,
+		Content:        importForm.Content,
+		TagCodePaths:   importForm.TagCodePaths,
+		AuthCertName:   importForm.AuthCertName,
+		EnableRecorder: importForm.EnableRecorder,
+		DryRun:         importForm.DryRun,
+	})
+	biz.ErrIsNil(err)
+	rc.ReqParam = collx.Kvs("format", importForm.Format, "dryRun", res.DryRun, "total", res.Total, "created", res.Created, "existed", res.Existed, "failed", res.Failed)
+	rc.ResData = res
+}
+
 func (m *Machine) TestConn(rc *req.Ctx) {
 	machineForm, me := req.BindJsonAndCopyTo[*form.MachineForm, *entity.Machine](rc)
 	// 测试连接
@@ -167,6 +254,27 @@ func (m *Machine) ChangeStatus(rc *req.Ctx) {
 	biz.ErrIsNil(m.machineApp.ChangeStatus(rc.MetaCtx, id, status))
 }
 
+// 获取机器已信任的主机公钥，不存在则返回空
+func (m *Machine) GetHostKey(rc *req.Ctx) {
+	hostKey, err := m.machineHostKeyApp.GetByMachineId(uint64(rc.PathParamInt("machineId")))
+	if err != nil {
+		return
+	}
+	rc.ResData = hostKey
+}
+
+func (m *Machine) AcceptHostKey(rc *req.Ctx) {
+	machineId := uint64(rc.PathParamInt("machineId"))
+	rc.ReqParam = collx.Kvs("machineId", machineId)
+	biz.ErrIsNil(m.machineHostKeyApp.Accept(rc.MetaCtx, machineId))
+}
+
+func (m *Machine) ResetHostKey(rc *req.Ctx) {
+	machineId := uint64(rc.PathParamInt("machineId"))
+	rc.ReqParam = collx.Kvs("machineId", machineId)
+	biz.ErrIsNil(m.machineHostKeyApp.Reset(rc.MetaCtx, machineId))
+}
+
 func (m *Machine) DeleteMachine(rc *req.Ctx) {
 	idsStr := rc.PathParam("machineId")
 	rc.ReqParam = idsStr
@@ -178,6 +286,15 @@ func (m *Machine) DeleteMachine(rc *req.Ctx) {
 }
 
 // 获取进程列表信息
+// 获取机器指定时间范围内的监控数据
+func (m *Machine) MachineMonitors(rc *req.Ctx) {
+	query := req.BindQuery[*entity.MachineMonitorQuery](rc)
+	query.MachineId = GetMachineId(rc)
+	monitors, err := m.machineMonitorApp.GetMonitors(query)
+	biz.ErrIsNil(err)
+	rc.ResData = monitors
+}
+
 func (m *Machine) GetProcess(rc *req.Ctx) {
 	cmd := "ps -aux "
 	sortType := rc.Query("sortType")
@@ -273,6 +390,107 @@ func (m *Machine) WsSSH(rc *req.Ctx) {
 	biz.ErrIsNilAppendErr(err, mcm.GetErrorContentRn("connect fail: %s"))
 }
 
+func (m *Machine) SearchTermOpCmds(rc *req.Ctx) {
+	cond := req.BindQuery[*entity.MachineTermOpCmdQuery](rc)
+	if la := rc.GetLoginAccount(); la.Id != consts.AdminId {
+		machineCodes := m.tagTreeApp.GetAccountTags(la.Id, &tagentity.TagTreeQuery{Types: collx.AsArray(tagentity.TagTypeMachine)}).GetCodes()
+		if len(machineCodes) == 0 {
+			rc.ResData = model.NewEmptyPageResult[any]()
+			return
+		}
+		machines, err := m.machineApp.ListByCond(model.NewCond().In("code", machineCodes), "id")
+		biz.ErrIsNil(err)
+		cond.MachineIds = collx.ArrayMap(machines, func(machine *entity.Machine) uint64 { return machine.Id })
+	}
+
+	res, err := m.machineTermOpApp.GetCmdPageList(cond, "exec_time DESC")
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+func (m *Machine) MachineTermOpRecord(rc *req.Ctx) {
+	rc.ResData = m.getMachineTermOp(rc)
+}
+
+func (m *Machine) MachineTermOpCmds(rc *req.Ctx) {
+	termOp := m.getMachineTermOp(rc)
+	cond := req.BindQuery[*entity.MachineTermOpCmdQuery](rc)
+	cond.TermOpId = termOp.Id
+	res, err := m.machineTermOpApp.GetCmdPageList(cond, "id ASC")
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+func (m *Machine) MachineTermOpTranscript(rc *req.Ctx) {
+	termOp := m.getMachineTermOp(rc)
+
+	biz.NotEmpty(termOp.FileKey, "the terminal playback file does not exist")
+
+	// 先完整生成文本记录，避免导出失败时仍响应空或截断的文件
+	transcript := new(bytes.Buffer)
+	biz.ErrIsNilAppendErr(m.machineTermOpApp.WriteTranscript(rc.MetaCtx, termOp, transcript), "failed to export the terminal transcript: %s")
+	rc.Download(transcript, fmt.Sprintf("mto_%d_%d.txt", termOp.MachineId, termOp.Id))
+}
+
+func (m *Machine) MachineGuacRecording(rc *req.Ctx) {
+	termOp := m.getMachineTermOp(rc)
+	reader, err := m.machineTermOpApp.GetGuacRecReader(termOp)
+	biz.ErrIsNil(err)
+	defer reader.Close()
+	rc.Download(reader, fmt.Sprintf("mto_%d_%d.guac", termOp.MachineId, termOp.Id))
+}
+
+// getMachineTermOp 获取路径参数指定的机器终端回放记录，并校验机器访问权限
+func (m *Machine) getMachineTermOp(rc *req.Ctx) *entity.MachineTermOp {
+	machineId := GetMachineId(rc)
+	termOp := &entity.MachineTermOp{MachineId: machineId}
+	termOp.Id = uint64(rc.PathParamInt("recId"))
+	biz.ErrIsNil(m.machineTermOpApp.GetByCond(termOp), "terminal record not found")
+
+	machine, err := m.machineApp.GetById(machineId, "code")
+	biz.ErrIsNil(err, "machine not found")
+	codePaths := m.tagTreeApp.ListTagPathByTypeAndCode(consts.ResourceTypeMachine, machine.Code)
+	biz.ErrIsNilAppendErr(m.tagTreeApp.CanAccess(rc.GetLoginAccount().Id, codePaths...), "%s")
+	return termOp
+}
+
+func (m *Machine) TerminalSessions(rc *req.Ctx) {
+	rc.ResData = m.machineTermOpApp.GetTerminalSessions(rc.MetaCtx)
+}
+
+func (m *Machine) WsShadowTerminalSession(rc *req.Ctx) {
+	wsConn, err := ws.Upgrader.Upgrade(rc.GetWriter(), rc.GetRequest(), nil)
+	defer func() {
+		if wsConn != nil {
+			if err := recover(); err != nil {
+				wsConn.WriteMessage(websocket.TextMessage, []byte(anyx.ToString(err)))
+			}
+			wsConn.Close()
+		}
+	}()
+	biz.ErrIsNilAppendErr(err, "Upgrade websocket fail: %s")
+
+	// 权限校验
+	rc = rc.WithRequiredPermission(req.NewPermission("machine:terminal:monitor"))
+	err = req.PermissionHandler(rc)
+	biz.ErrIsNil(err, mcm.GetErrorContentRn("You do not have permission to monitor the machine terminal session"))
+
+	sessionId := rc.PathParam("sessionId")
+	// 记录系统操作日志
+	rc.WithLog(req.NewLogSaveI(imsg.LogMachineTerminalShadow))
+	rc.ReqParam = sessionId
+
+	err = m.machineTermOpApp.ShadowTerminalSession(rc.MetaCtx, sessionId, wsConn)
+	biz.ErrIsNilAppendErr(err, mcm.GetErrorContentRn("%s"))
+}
+
+func (m *Machine) KillTerminalSession(rc *req.Ctx) {
+	killForm := req.BindJsonAndValid[*form.TerminalSessionKillForm](rc)
+	info, err := m.machineTermOpApp.KillTerminalSession(rc.MetaCtx, rc.PathParam("sessionId"), killForm.Msg)
+	biz.ErrIsNil(err)
+	rc.ReqParam = collx.Kvs("session", info, "msg", killForm.Msg)
+}
+
 func (m *Machine) MachineTermOpRecords(rc *req.Ctx) {
 	mid := GetMachineId(rc)
 	res, err := m.machineTermOpApp.GetPageList(&entity.MachineTermOp{MachineId: mid}, rc.GetPageParam())
@@ -335,9 +553,27 @@ func (m *Machine) WsGuacamole(rc *req.Ctx) {
 		params["scheme"] = "vnc"
 	}
 
+	var termOp *entity.MachineTermOp
 	if mi.EnableRecorder == 1 {
+		// 记录会话信息，并关联guacd生成的录像文件
+		var recName string
+		termOp, recName, err = m.machineTermOpApp.CreateGuacTermOp(rc.MetaCtx, mi, ac)
+		if err != nil {
+			logx.Errorf("failed to create the machine graphical session record: %s", err.Error())
+			return
+		}
+		defer func() {
+			if termOp == nil {
+				return
+			}
+			if err := m.machineTermOpApp.EndGuacTermOp(termOp); err != nil {
+				logx.Errorf("failed to update the machine graphical session record: %s", err.Error())
+			}
+		}()
+
 		// 操作记录 查看文档：https://guacamole.apache.org/doc/gug/configuring-guacamole.html#graphical-recording
 		params["recording-path"] = fmt.Sprintf("/rdp-rec/%s", ac)
+		params["recording-name"] = recName
 		params["create-recording-path"] = "true"
 		params["recording-include-keys"] = "true"
 	}
@@ -360,6 +596,13 @@ func (m *Machine) WsGuacamole(rc *req.Ctx) {
 
 	tunnel, err := guac.DoConnect(request.URL.Query(), params, rc.GetLoginAccount().Username)
 	if err != nil {
+		// 未成功建立连接则不会产生录像，删除已创建的会话记录
+		if termOp != nil {
+			if err := m.machineTermOpApp.DeleteById(rc.MetaCtx, termOp.Id); err != nil {
+				logx.Errorf("failed to delete the machine graphical session record: %s", err.Error())
+			}
+			termOp = nil
+		}
 		return
 	}
 	defer func() {
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"mayfly-go/internal/machine/api/form"
+	"mayfly-go/internal/machine/api/vo"
+	"mayfly-go/internal/machine/application"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/imsg"
+	tagapp "mayfly-go/internal/tag/application"
+	tagentity "mayfly-go/internal/tag/domain/entity"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/collx"
+)
+
+type MachineAlert struct {
+	machineAlertRuleApp application.MachineAlertRule `inject:"T"`
+	tagTreeRelateApp    tagapp.TagTreeRelate         `inject:"T"`
+}
+
+func (ma *MachineAlert) ReqConfs() *req.Confs {
+	saveP := req.NewPermission("machine:alert:save")
+
+	reqs := [...]*req.Conf{
+		req.NewGet("rules", ma.AlertRules),
+
+		req.NewPost("rules", ma.SaveRule).Log(req.NewLogSaveI(imsg.LogMachineAlertRuleSave)).RequiredPermission(saveP),
+
+		req.NewDelete("rules/:id", ma.DeleteRule).Log(req.NewLogSaveI(imsg.LogMachineAlertRuleDelete)).RequiredPermissionCode("machine:alert:del"),
+
+		req.NewGet("alerts", ma.Alerts),
+
+		req.NewGet("silences", ma.Silences),
+
+		req.NewPost("silences", ma.SaveSilence).Log(req.NewLogSaveI(imsg.LogMachineAlertSilenceSave)).RequiredPermission(saveP),
+
+		req.NewDelete("silences/:id", ma.DeleteSilence).Log(req.NewLogSaveI(imsg.LogMachineAlertSilenceDelete)).RequiredPermission(saveP),
+	}
+
+	return req.NewConfs("machine/alert", reqs[:]...)
+}
+
+func (m *MachineAlert) AlertRules(rc *req.Ctx) {
+	cond := req.BindQuery[*entity.MachineAlertRuleQuery](rc)
+
+	pageRes, err := m.machineAlertRuleApp.GetPageList(cond)
+	biz.ErrIsNil(err)
+	resVo := model.PageResultConv[*entity.MachineAlertRule, *vo.MachineAlertRuleVO](pageRes)
+
+	m.tagTreeRelateApp.FillTagInfo(tagentity.TagRelateTypeMachineAlert, collx.ArrayMap(resVo.List, func(mvo *vo.MachineAlertRuleVO) tagentity.IRelateTag {
+		return mvo
+	})...)
+
+	rc.ResData = resVo
+}
+
+func (m *MachineAlert) SaveRule(rc *req.Ctx) {
+	ruleForm, rule := req.BindJsonAndCopyTo[*form.MachineAlertRuleForm, *entity.MachineAlertRule](rc)
+	rc.ReqParam = ruleForm
+
+	biz.ErrIsNil(m.machineAlertRuleApp.SaveRule(rc.MetaCtx, &dto.SaveMachineAlertRule{
+		AlertRule: rule,
+		CodePaths: ruleForm.CodePaths,
+	}))
+}
+
+func (m *MachineAlert) DeleteRule(rc *req.Ctx) {
+	id := uint64(rc.PathParamInt("id"))
+	rc.ReqParam = id
+	biz.ErrIsNil(m.machineAlertRuleApp.DeleteRule(rc.MetaCtx, id))
+}
+
+func (m *MachineAlert) Alerts(rc *req.Ctx) {
+	cond := req.BindQuery[*entity.MachineAlertQuery](rc)
+	res, err := m.machineAlertRuleApp.GetAlertPageList(cond, "id DESC")
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+func (m *MachineAlert) Silences(rc *req.Ctx) {
+	cond := req.BindQuery[*entity.MachineAlertSilenceQuery](rc)
+	res, err := m.machineAlertRuleApp.GetSilencePageList(cond, "id DESC")
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+func (m *MachineAlert) SaveSilence(rc *req.Ctx) {
+	silenceForm, silence := req.BindJsonAndCopyTo[*form.MachineAlertSilenceForm, *entity.MachineAlertSilence](rc)
+	rc.ReqParam = silenceForm
+	biz.ErrIsNil(m.machineAlertRuleApp.SaveSilence(rc.MetaCtx, silence))
+}
+
+func (m *MachineAlert) DeleteSilence(rc *req.Ctx) {
+	id := uint64(rc.PathParamInt("id"))
+	rc.ReqParam = id
+	biz.ErrIsNil(m.machineAlertRuleApp.DeleteSilence(rc.MetaCtx, id))
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"mayfly-go/internal/machine/api/form"
+	"mayfly-go/internal/machine/application"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/imsg"
+	"mayfly-go/internal/pkg/consts"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/req"
+)
+
+type MachineCmdBatch struct {
+	machineCmdBatchApp application.MachineCmdBatch `inject:"T"`
+}
+
+func (mcb *MachineCmdBatch) ReqConfs() *req.Confs {
+	reqs := [...]*req.Conf{
+		req.NewGet("", mcb.CmdBatches),
+
+		req.NewPost("", mcb.RunCmdBatch).Log(req.NewLogSaveI(imsg.LogMachineCmdBatchRun)).RequiredPermissionCode("machine:cmd:batch"),
+
+		req.NewGet(":id/results", mcb.CmdBatchResults),
+	}
+
+	return req.NewConfs("machine/cmd-batches", reqs[:]...)
+}
+
+func (m *MachineCmdBatch) CmdBatches(rc *req.Ctx) {
+	cond := req.BindQuery[*entity.MachineCmdBatchQuery](rc)
+	// 非管理员只能查看自己执行的批量命令
+	if la := rc.GetLoginAccount(); la.Id != consts.AdminId {
+		cond.CreatorId = la.Id
+	}
+	res, err := m.machineCmdBatchApp.GetPageList(cond, "id DESC")
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+func (m *MachineCmdBatch) RunCmdBatch(rc *req.Ctx) {
+	runForm := req.BindJsonAndValid[*form.MachineCmdBatchRunForm](rc)
+	// 脚本参数中可能包含secret类参数，故不记录参数值
+	logForm := *runForm
+	logForm.Params = nil
+	rc.ReqParam = &logForm
+
+	batch, err := m.machineCmdBatchApp.Run(rc.MetaCtx, &dto.MachineCmdBatchRun{
+		Name:         runForm.Name,
+		Cmd:          runForm.Cmd,
+		ScriptId:     runForm.ScriptId,
+		Params:       runForm.Params,
+		TagPaths:     runForm.TagPaths,
+		MachineIds:   runForm.MachineIds,
+		MachineCodes: runForm.MachineCodes,
+		Concurrency:  runForm.Concurrency,
+		Timeout:      runForm.Timeout,
+		ClientId:     runForm.ClientId,
+	})
+	biz.ErrIsNil(err)
+	rc.ResData = batch
+}
+
+func (m *MachineCmdBatch) CmdBatchResults(rc *req.Ctx) {
+	batchId := uint64(rc.PathParamInt("id"))
+	batch, err := m.machineCmdBatchApp.GetById(batchId)
+	biz.ErrIsNil(err, "batch not found")
+	if la := rc.GetLoginAccount(); la.Id != consts.AdminId {
+		biz.IsTrue(batch.CreatorId == la.Id, "batch not found")
+	}
+
+	cond := req.BindQuery[*entity.MachineCmdBatchResultQuery](rc)
+	cond.BatchId = batchId
+	res, err := m.machineCmdBatchApp.GetResultPageList(cond, "id ASC")
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"mayfly-go/internal/event"
+	"mayfly-go/internal/machine/imsg"
+	"mayfly-go/internal/machine/mcm"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/global"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/anyx"
+	"mayfly-go/pkg/utils/collx"
+	"mayfly-go/pkg/ws"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	containerLogsDefaultTail = 200
+	containerLogsMaxTail     = 5000
+)
+
+// 获取docker容器列表
+func (m *Machine) GetContainers(rc *req.Ctx) {
+	cli := m.getAccessibleCli(rc)
+	containers, err := cli.GetDockerContainers()
+	biz.ErrIsNil(err)
+	rc.ResData = containers
+}
+
+// 获取运行中容器的资源使用情况
+func (m *Machine) GetContainerStats(rc *req.Ctx) {
+	cli := m.getAccessibleCli(rc)
+	stats, err := cli.GetDockerContainerStats()
+	biz.ErrIsNil(err)
+	rc.ResData = stats
+}
+
+// 获取容器的docker inspect信息
+func (m *Machine) InspectContainer(rc *req.Ctx) {
+	cli := m.getAccessibleCli(rc)
+	res, err := cli.InspectDockerContainer(rc.PathParam("containerId"))
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+// opContainer 容器操作，删除容器使用单独的权限码
+func (m *Machine) opContainer(action string) req.HandlerFunc {
+	return func(rc *req.Ctx) {
+		containerId := rc.PathParam("containerId")
+		cli := m.getAccessibleCli(rc)
+		rc.ReqParam = collx.Kvs("machine", cli.Info, "container", containerId, "action", action)
+
+		res, err := cli.OpDockerContainer(containerId, action)
+		biz.ErrIsNil(err)
+		global.EventBus.Publish(rc.MetaCtx, event.EventTopicResourceOp, cli.Info.CodePath[0])
+		rc.ResData = res
+	}
+}
+
+// WsContainerLogs 通过websocket实时推送容器日志(docker logs --follow)
+func (m *Machine) WsContainerLogs(rc *req.Ctx) {
+	wsConn, err := ws.Upgrader.Upgrade(rc.GetWriter(), rc.GetRequest(), nil)
+	defer func() {
+		if wsConn != nil {
+			if err := recover(); err != nil {
+				wsConn.WriteMessage(websocket.TextMessage, []byte(anyx.ToString(err)))
+			}
+			wsConn.Close()
+		}
+	}()
+	biz.ErrIsNilAppendErr(err, "Upgrade websocket fail: %s")
+
+	// 权限校验
+	rc = rc.WithRequiredPermission(req.NewPermission("machine:container:logs"))
+	biz.ErrIsNil(req.PermissionHandler(rc), "You do not have permission to view the container logs")
+
+	cli := m.getAccessibleCli(rc)
+	tail := min(rc.QueryIntDefault("tail", containerLogsDefaultTail), containerLogsMaxTail)
+
+	ctx, cancel := context.WithCancel(rc.MetaCtx)
+	defer cancel()
+	// 客户端关闭连接时结束日志跟踪
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := wsConn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	err = cli.FollowDockerLogs(ctx, rc.PathParam("containerId"), tail, &wsTextWriter{wsConn: wsConn})
+	biz.ErrIsNil(err)
+}
+
+// WsContainerExec 通过docker exec连接容器终端
+func (m *Machine) WsContainerExec(rc *req.Ctx) {
+	wsConn, err := ws.Upgrader.Upgrade(rc.GetWriter(), rc.GetRequest(), nil)
+	defer func() {
+		if wsConn != nil {
+			if err := recover(); err != nil {
+				wsConn.WriteMessage(websocket.TextMessage, []byte(anyx.ToString(err)))
+			}
+			wsConn.Close()
+		}
+	}()
+	biz.ErrIsNilAppendErr(err, "Upgrade websocket fail: %s")
+	wsConn.WriteMessage(websocket.TextMessage, []byte("Connecting to container..."))
+
+	// 权限校验
+	rc = rc.WithRequiredPermission(req.NewPermission("machine:container:exec"))
+	err = req.PermissionHandler(rc)
+	biz.ErrIsNil(err, mcm.GetErrorContentRn("You do not have permission to operate the container terminal, please log in again and try again ~"))
+
+	containerId := rc.Query("containerId")
+	biz.ErrIsNilAppendErr(mcm.CheckDockerContainerId(containerId), mcm.GetErrorContentRn("%s"))
+
+	cli, err := m.machineApp.NewCli(rc.MetaCtx, GetMachineAc(rc))
+	biz.ErrIsNilAppendErr(err, mcm.GetErrorContentRn("connection error: %s"))
+	defer cli.Close()
+	biz.ErrIsNilAppendErr(m.tagTreeApp.CanAccess(rc.GetLoginAccount().Id, cli.Info.CodePath...), mcm.GetErrorContentRn("%s"))
+
+	global.EventBus.Publish(rc.MetaCtx, event.EventTopicResourceOp, cli.Info.CodePath[0])
+
+	cols := rc.QueryIntDefault("cols", 80)
+	rows := rc.QueryIntDefault("rows", 32)
+
+	// 记录系统操作日志
+	rc.WithLog(req.NewLogSaveI(imsg.LogMachineContainerExec))
+	rc.ReqParam = collx.Kvs("machine", cli.Info, "container", containerId)
+
+	err = m.machineTermOpApp.ContainerTermConn(rc.MetaCtx, cli, wsConn, containerId, rows, cols)
+	biz.ErrIsNilAppendErr(err, mcm.GetErrorContentRn("connect fail: %s"))
+}
+
+// wsTextWriter 将写入的内容以文本消息发送至websocket
+type wsTextWriter struct {
+	wsConn *websocket.Conn
+	mutex  sync.Mutex
+}
+
+func (w *wsTextWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if err := w.wsConn.WriteMessage(websocket.TextMessage, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
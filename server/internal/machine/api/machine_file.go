@@ -23,6 +23,7 @@ import (
 	"mayfly-go/pkg/utils/anyx"
 	"mayfly-go/pkg/utils/collx"
 	"mayfly-go/pkg/utils/timex"
+	"mayfly-go/pkg/ws"
 	"mime/multipart"
 	"os"
 	"path/filepath"
@@ -66,6 +67,19 @@ func (mf *MachineFile) ReqConfs() *req.Confs {
 
 		req.NewPost(":machineId/files/:fileId/upload-folder", mf.UploadFolder).Log(req.NewLogSaveI(imsg.LogMachineFileUploadFolder)).RequiredPermissionCode("machine:file:upload"),
 
+		req.NewPost(":machineId/files/:fileId/chunk-upload", mf.InitChunkUpload).Log(req.NewLogSaveI(imsg.LogMachineFileChunkUploadInit)).RequiredPermissionCode("machine:file:upload"),
+
+		req.NewPut(":machineId/files/:fileId/chunk-upload/:uploadId", mf.UploadChunk).RequiredPermissionCode("machine:file:upload"),
+
+		req.NewPost(":machineId/files/:fileId/chunk-upload/:uploadId/complete", mf.CompleteChunkUpload).Log(req.NewLogSaveI(imsg.LogMachineFileUpload)).RequiredPermissionCode("machine:file:upload"),
+
+		req.NewDelete(":machineId/files/:fileId/chunk-upload/:uploadId", mf.AbortChunkUpload).Log(req.NewLogSaveI(imsg.LogMachineFileChunkUploadAbort)).RequiredPermissionCode("machine:file:upload"),
+
+		req.NewGet(":machineId/files/:fileId/download-dir", mf.DownloadDir).NoRes().Log(req.NewLogSaveI(imsg.LogMachineFileDownloadDir)),
+
+		// websocket跟踪文件新增内容
+		req.NewGet(":machineId/files/:fileId/tail", mf.WsTailFile).NoRes(),
+
 		req.NewPost(":machineId/files/:fileId/remove", mf.RemoveFile).Log(req.NewLogSaveI(imsg.LogMachineFileDelete)).RequiredPermissionCode("machine:file:rm"),
 
 		req.NewPost(":machineId/files/:fileId/cp", mf.CopyFile).Log(req.NewLogSaveI(imsg.LogMachineFileCopy)).RequiredPermissionCode("machine:file:rm"),
@@ -73,6 +87,26 @@ func (mf *MachineFile) ReqConfs() *req.Confs {
 		req.NewPost(":machineId/files/:fileId/mv", mf.MvFile).Log(req.NewLogSaveI(imsg.LogMachineFileMove)).RequiredPermissionCode("machine:file:rm"),
 
 		req.NewPost(":machineId/files/:fileId/rename", mf.Rename).Log(req.NewLogSaveI(imsg.LogMachineFileRename)).RequiredPermissionCode("machine:file:write"),
+
+		req.NewPost(":machineId/files/:fileId/chmod", mf.Chmod).Log(req.NewLogSaveI(imsg.LogMachineFileChmod)).RequiredPermissionCode("machine:file:write"),
+
+		req.NewPost(":machineId/files/:fileId/chown", mf.Chown).Log(req.NewLogSaveI(imsg.LogMachineFileChown)).RequiredPermissionCode("machine:file:write"),
+
+		req.NewPost(":machineId/files/:fileId/symlink", mf.Symlink).Log(req.NewLogSaveI(imsg.LogMachineFileSymlink)).RequiredPermissionCode("machine:file:write"),
+
+		req.NewPost(":machineId/files/:fileId/chtimes", mf.Chtimes).Log(req.NewLogSaveI(imsg.LogMachineFileChtimes)).RequiredPermissionCode("machine:file:write"),
+
+		// 文件历史版本
+		req.NewGet(":machineId/files/:fileId/versions", mf.FileVersions),
+
+		req.NewGet(":machineId/files/:fileId/versions/diff", mf.DiffFileVersion),
+
+		req.NewGet(":machineId/files/:fileId/versions/:versionId/content", mf.FileVersionContent),
+
+		req.NewPost(":machineId/files/:fileId/versions/:versionId/restore", mf.RestoreFileVersion).Log(req.NewLogSaveI(imsg.LogMachineFileVersionRestore)).RequiredPermissionCode("machine:file:write"),
+
+		// 对比不同机器上的文件
+		req.NewPost("files/diff", mf.DiffMachineFile),
 	}
 
 	return req.NewConfs("machines", reqs[:]...)
@@ -399,6 +433,81 @@ func (m *MachineFile) UploadFolder(rc *req.Ctx) {
 	}
 }
 
+func (m *MachineFile) InitChunkUpload(rc *req.Ctx) {
+	opForm := req.BindJsonAndValid[*form.ChunkUploadInitForm](rc)
+	upload, err := m.machineFileApp.InitChunkUpload(rc.MetaCtx, opForm.MachineFileOp, opForm.Filename, opForm.Size, opForm.Fingerprint)
+	rc.ReqParam = collx.Kvs("machineId", opForm.MachineId, "ac", opForm.AuthCertName, "path", opForm.Path, "filename", opForm.Filename, "size", opForm.Size)
+	biz.ErrIsNilAppendErr(err, "init chunk upload error: %s")
+	rc.ResData = upload
+}
+
+// UploadChunk 请求体为分片的原始二进制数据，offset为分片在文件中的偏移量
+func (m *MachineFile) UploadChunk(rc *req.Ctx) {
+	uploadId := rc.PathParam("uploadId")
+	offset := cast.ToInt64(rc.Query("offset"))
+
+	body := rc.GetRequest().Body
+	defer body.Close()
+	newOffset, err := m.machineFileApp.UploadChunk(rc.MetaCtx, uploadId, offset, body)
+	biz.ErrIsNilAppendErr(err, "upload chunk error: %s")
+	rc.ResData = collx.Kvs("offset", newOffset)
+}
+
+func (m *MachineFile) CompleteChunkUpload(rc *req.Ctx) {
+	ctx := rc.MetaCtx
+	mi, upload, err := m.machineFileApp.CompleteChunkUpload(ctx, rc.PathParam("uploadId"))
+	if upload != nil {
+		rc.ReqParam = collx.Kvs("machine", mi, "path", upload.Path, "size", upload.Size)
+	}
+	biz.ErrIsNilAppendErr(err, "upload file error: %s")
+	// 保存消息并发送文件上传成功通知
+	m.msgApp.CreateAndSend(rc.GetLoginAccount(), msgdto.SuccessSysMsg(i18n.TC(ctx, imsg.MsgUploadFileSuccess), fmt.Sprintf("[%s] -> %s[%s:%s]", filepath.Base(upload.Path), mi.Name, mi.Ip, filepath.Dir(upload.Path))))
+}
+
+func (m *MachineFile) AbortChunkUpload(rc *req.Ctx) {
+	uploadId := rc.PathParam("uploadId")
+	mi, err := m.machineFileApp.AbortChunkUpload(rc.MetaCtx, uploadId)
+	rc.ReqParam = collx.Kvs("machine", mi, "uploadId", uploadId)
+	biz.ErrIsNilAppendErr(err, "abort chunk upload error: %s")
+}
+
+func (m *MachineFile) DownloadDir(rc *req.Ctx) {
+	opForm := req.BindQuery[*form.DirArchiveDownloadForm](rc)
+	format := opForm.Format
+	if format == "" {
+		format = application.ArchiveFormatTarGz
+	}
+
+	w := &archiveDownloadWriter{rc: rc, filename: fmt.Sprintf("%s.%s", application.ArchiveBaseName(opForm.Path), format)}
+	mi, err := m.machineFileApp.WriteDirArchive(rc.MetaCtx, opForm.MachineFileOp, format, w)
+	rc.ReqParam = collx.Kvs("machine", mi, "path", opForm.Path, "format", format)
+	if err == nil {
+		return
+	}
+	// 已开始输出归档数据时无法再返回错误信息，仅记录日志
+	if w.started {
+		logx.Errorf("machine dir archive download error: %s", err.Error())
+		return
+	}
+	biz.ErrIsNilAppendErr(err, "download dir error: %s")
+}
+
+// archiveDownloadWriter 首次写入归档数据时才设置下载响应头，以便归档前的校验失败时可正常返回错误信息
+type archiveDownloadWriter struct {
+	rc       *req.Ctx
+	filename string
+	started  bool
+}
+
+func (w *archiveDownloadWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.rc.Header("Content-Type", "application/octet-stream")
+		w.rc.Header("Content-Disposition", "attachment; filename="+w.filename)
+	}
+	return w.rc.GetWriter().Write(p)
+}
+
 func (m *MachineFile) RemoveFile(rc *req.Ctx) {
 	opForm := req.BindJsonAndValid[*form.RemoveFileForm](rc)
 
@@ -428,6 +537,38 @@ func (m *MachineFile) Rename(rc *req.Ctx) {
 	biz.ErrIsNilAppendErr(err, "file rename error: %s")
 }
 
+func (m *MachineFile) Chmod(rc *req.Ctx) {
+	opForm := req.BindJsonAndValid[*form.ChmodForm](rc)
+	mi, err := m.machineFileApp.Chmod(rc.MetaCtx, opForm.MachineFileOp, opForm.Mode, opForm.Recursive)
+	rc.ReqParam = collx.Kvs("machine", mi, "chmod", opForm)
+	biz.ErrIsNilAppendErr(err, "file chmod error: %s")
+}
+
+func (m *MachineFile) Chown(rc *req.Ctx) {
+	opForm := req.BindJsonAndValid[*form.ChownForm](rc)
+	mi, err := m.machineFileApp.Chown(rc.MetaCtx, opForm.MachineFileOp, opForm.Owner, opForm.Group, opForm.Recursive)
+	rc.ReqParam = collx.Kvs("machine", mi, "chown", opForm)
+	biz.ErrIsNilAppendErr(err, "file chown error: %s")
+}
+
+func (m *MachineFile) Symlink(rc *req.Ctx) {
+	opForm := req.BindJsonAndValid[*form.SymlinkForm](rc)
+	mi, err := m.machineFileApp.Symlink(rc.MetaCtx, opForm.MachineFileOp, opForm.Target)
+	rc.ReqParam = collx.Kvs("machine", mi, "symlink", opForm)
+	biz.ErrIsNilAppendErr(err, "create symlink error: %s")
+}
+
+func (m *MachineFile) Chtimes(rc *req.Ctx) {
+	opForm := req.BindJsonAndValid[*form.ChtimesForm](rc)
+	atime := opForm.Mtime
+	if opForm.Atime != nil {
+		atime = *opForm.Atime
+	}
+	mi, err := m.machineFileApp.Chtimes(rc.MetaCtx, opForm.MachineFileOp, atime, opForm.Mtime, opForm.Recursive)
+	rc.ReqParam = collx.Kvs("machine", mi, "chtimes", opForm)
+	biz.ErrIsNilAppendErr(err, "file chtimes error: %s")
+}
+
 func getFileType(fm fs.FileMode) string {
 	if fm.IsDir() {
 		return dir
@@ -438,8 +579,77 @@ func getFileType(fm fs.FileMode) string {
 	return dir
 }
 
+func (m *MachineFile) WsTailFile(rc *req.Ctx) {
+	wsConn, err := ws.Upgrader.Upgrade(rc.GetWriter(), rc.GetRequest(), nil)
+	defer func() {
+		if wsConn != nil {
+			if err := recover(); err != nil {
+				wsConn.WriteJSON(collx.Kvs("type", "error", "content", anyx.ToString(err)))
+			}
+			wsConn.Close()
+		}
+	}()
+	biz.ErrIsNilAppendErr(err, "Upgrade websocket fail: %s")
+
+	// 权限校验
+	rc = rc.WithRequiredPermission(req.NewPermission("machine:file:tail"))
+	biz.ErrIsNil(req.PermissionHandler(rc), "You do not have permission to tail the machine file")
+
+	opForm := req.BindQuery[*form.MachineFileTailForm](rc)
+	// 记录系统操作日志
+	rc.WithLog(req.NewLogSaveI(imsg.LogMachineFileTail))
+
+	mi, err := m.machineFileApp.TailFile(rc.MetaCtx, opForm.MachineFileOp, &dto.MachineFileTail{
+		Lines:      opForm.Lines,
+		Filter:     opForm.Filter,
+		Highlight:  opForm.Highlight,
+		Regexp:     opForm.Regexp,
+		IgnoreCase: opForm.IgnoreCase,
+	}, wsConn)
+	rc.ReqParam = collx.Kvs("machine", mi, "path", opForm.Path, "filter", opForm.Filter)
+	biz.ErrIsNil(err)
+}
+
 func GetMachineFileId(rc *req.Ctx) uint64 {
 	fileId := rc.PathParamInt("fileId")
 	biz.IsTrue(fileId != 0, "fileId error")
 	return uint64(fileId)
 }
+
+func (m *MachineFile) FileVersions(rc *req.Ctx) {
+	cond := req.BindQuery[*entity.MachineFileVersionQuery](rc)
+	cond.MachineId = uint64(rc.PathParamInt("machineId"))
+	biz.NotEmpty(cond.Path, "path cannot be empty")
+
+	res, err := m.machineFileApp.GetFileVersions(rc.MetaCtx, cond, "version desc")
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+func (m *MachineFile) FileVersionContent(rc *req.Ctx) {
+	opForm := req.BindQuery[*dto.MachineFileOp](rc)
+	version, content, err := m.machineFileApp.GetFileVersionContent(rc.MetaCtx, opForm, uint64(rc.PathParamInt("versionId")))
+	biz.ErrIsNil(err)
+	rc.ResData = collx.Kvs("version", version, "content", content)
+}
+
+func (m *MachineFile) DiffFileVersion(rc *req.Ctx) {
+	opForm := req.BindQuery[*form.MachineFileVersionDiffForm](rc)
+	diff, err := m.machineFileApp.DiffFileVersion(rc.MetaCtx, opForm.MachineFileOp, opForm.FromVersionId, opForm.ToVersionId)
+	biz.ErrIsNil(err)
+	rc.ResData = diff
+}
+
+func (m *MachineFile) RestoreFileVersion(rc *req.Ctx) {
+	opForm := req.BindJsonAndValid[*dto.MachineFileOp](rc)
+	mi, version, err := m.machineFileApp.RestoreFileVersion(rc.MetaCtx, opForm, uint64(rc.PathParamInt("versionId")))
+	rc.ReqParam = collx.Kvs("machine", mi, "version", version)
+	biz.ErrIsNilAppendErr(err, "restore file version error: %s")
+}
+
+func (m *MachineFile) DiffMachineFile(rc *req.Ctx) {
+	diffForm := req.BindJsonAndValid[*form.MachineFileDiffForm](rc)
+	diff, err := m.machineFileApp.DiffMachineFile(rc.MetaCtx, diffForm.From, diffForm.To)
+	biz.ErrIsNil(err)
+	rc.ResData = diff
+}
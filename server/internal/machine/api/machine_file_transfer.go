@@ -0,0 +1,70 @@
+package api
+
+import (
+	"mayfly-go/internal/machine/api/form"
+	"mayfly-go/internal/machine/application"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/imsg"
+	"mayfly-go/internal/pkg/consts"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/collx"
+)
+
+type MachineFileTransfer struct {
+	machineFileTransferApp application.MachineFileTransfer `inject:"T"`
+}
+
+func (mft *MachineFileTransfer) ReqConfs() *req.Confs {
+	reqs := [...]*req.Conf{
+		req.NewGet("", mft.FileTransfers),
+
+		req.NewPost("", mft.StartFileTransfer).Log(req.NewLogSaveI(imsg.LogMachineFileTransferStart)).RequiredPermissionCode("machine:file:transfer"),
+
+		req.NewPost(":id/cancel", mft.CancelFileTransfer).Log(req.NewLogSaveI(imsg.LogMachineFileTransferCancel)),
+	}
+
+	return req.NewConfs("machine/file-transfers", reqs[:]...)
+}
+
+func (m *MachineFileTransfer) FileTransfers(rc *req.Ctx) {
+	cond := req.BindQuery[*entity.MachineFileTransferQuery](rc)
+	// 非管理员只能查看自己发起的传输任务
+	if la := rc.GetLoginAccount(); la.Id != consts.AdminId {
+		cond.CreatorId = la.Id
+	}
+	res, err := m.machineFileTransferApp.GetPageList(cond, "id DESC")
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+func (m *MachineFileTransfer) StartFileTransfer(rc *req.Ctx) {
+	transferForm := req.BindJsonAndValid[*form.MachineFileTransferForm](rc)
+	rc.ReqParam = transferForm
+
+	transfer, err := m.machineFileTransferApp.Start(rc.MetaCtx, &dto.MachineFileTransferStart{
+		Src:       transferForm.Src,
+		Dst:       transferForm.Dst,
+		Overwrite: transferForm.Overwrite,
+		ClientId:  transferForm.ClientId,
+	})
+	biz.ErrIsNil(err)
+	rc.ReqParam = collx.Kvs("transferId", transfer.Id,
+		"src", collx.Kvs("machine", transfer.SrcMachineName, "ac", transfer.SrcAuthCertName, "path", transfer.SrcPath),
+		"dst", collx.Kvs("machine", transfer.DstMachineName, "ac", transfer.DstAuthCertName, "path", transfer.DstPath),
+		"size", transfer.Size)
+	rc.ResData = transfer
+}
+
+func (m *MachineFileTransfer) CancelFileTransfer(rc *req.Ctx) {
+	transferId := uint64(rc.PathParamInt("id"))
+	transfer, err := m.machineFileTransferApp.GetById(transferId)
+	biz.ErrIsNil(err, "transfer not found")
+	if la := rc.GetLoginAccount(); la.Id != consts.AdminId {
+		biz.IsTrue(transfer.CreatorId == la.Id, "transfer not found")
+	}
+	rc.ReqParam = collx.Kvs("transferId", transfer.Id, "src", transfer.SrcPath, "dst", transfer.DstPath)
+
+	biz.ErrIsNil(m.machineFileTransferApp.Cancel(rc.MetaCtx, transferId))
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"mayfly-go/internal/machine/api/form"
+	"mayfly-go/internal/machine/application"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/imsg"
+	"mayfly-go/internal/pkg/consts"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/anyx"
+	"mayfly-go/pkg/utils/collx"
+	"mayfly-go/pkg/ws"
+
+	"github.com/gorilla/websocket"
+)
+
+type MachinePortForward struct {
+	machinePortForwardApp application.MachinePortForward `inject:"T"`
+}
+
+func (mpf *MachinePortForward) ReqConfs() *req.Confs {
+	reqs := [...]*req.Conf{
+		req.NewGet("", mpf.PortForwards),
+
+		req.NewPost("", mpf.OpenPortForward).Log(req.NewLogSaveI(imsg.LogMachinePortForwardOpen)).RequiredPermissionCode("machine:port-forward"),
+
+		// websocket方式的端口转发，websocket连接即为转发的tcp流
+		req.NewGet("ws", mpf.WsPortForward).NoRes().RequiredPermissionCode("machine:port-forward"),
+
+		req.NewPost(":id/close", mpf.ClosePortForward).Log(req.NewLogSaveI(imsg.LogMachinePortForwardClose)),
+	}
+
+	return req.NewConfs("machine/port-forwards", reqs[:]...)
+}
+
+func (m *MachinePortForward) PortForwards(rc *req.Ctx) {
+	cond := req.BindQuery[*entity.MachinePortForwardQuery](rc)
+	// 非管理员只能查看自己开启的端口转发
+	if la := rc.GetLoginAccount(); la.Id != consts.AdminId {
+		cond.CreatorId = la.Id
+	}
+	res, err := m.machinePortForwardApp.GetPageList(cond, "id DESC")
+	biz.ErrIsNil(err)
+	rc.ResData = res
+}
+
+func (m *MachinePortForward) OpenPortForward(rc *req.Ctx) {
+	pfForm := req.BindJsonAndValid[*form.MachinePortForwardForm](rc)
+	rc.ReqParam = pfForm
+
+	openParam := toPortForwardOpen(pfForm)
+	openParam.ClientIp = rc.ClientIP()
+	pf, err := m.machinePortForwardApp.Open(rc.MetaCtx, openParam)
+	biz.ErrIsNil(err)
+	rc.ReqParam = collx.Kvs("portForwardId", pf.Id, "machine", pf.MachineName, "listen", pf.ListenAddr,
+		"target", collx.Kvs("host", pf.TargetHost, "port", pf.TargetPort), "ttl", pf.Ttl)
+	rc.ResData = pf
+}
+
+func (m *MachinePortForward) WsPortForward(rc *req.Ctx) {
+	wsConn, err := ws.Upgrader.Upgrade(rc.GetWriter(), rc.GetRequest(), nil)
+	defer func() {
+		if wsConn != nil {
+			// 转发的为原始字节流，错误信息通过关闭帧返回
+			if err := recover(); err != nil {
+				wsConn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, anyx.ToString(err)))
+			}
+			wsConn.Close()
+		}
+	}()
+	biz.ErrIsNilAppendErr(err, "Upgrade websocket fail: %s")
+
+	pfForm := req.BindQuery[*form.MachinePortForwardForm](rc)
+	// 记录系统操作日志
+	rc.WithLog(req.NewLogSaveI(imsg.LogMachinePortForwardWs))
+	rc.ReqParam = pfForm
+
+	pf, err := m.machinePortForwardApp.ServeWs(rc.MetaCtx, toPortForwardOpen(pfForm), wsConn)
+	biz.ErrIsNil(err)
+	rc.ReqParam = collx.Kvs("portForwardId", pf.Id, "machine", pf.MachineName,
+		"target", collx.Kvs("host", pf.TargetHost, "port", pf.TargetPort), "bytesIn", pf.BytesIn, "bytesOut", pf.BytesOut)
+}
+
+func (m *MachinePortForward) ClosePortForward(rc *req.Ctx) {
+	pfId := uint64(rc.PathParamInt("id"))
+	pf, err := m.machinePortForwardApp.GetById(pfId)
+	biz.ErrIsNil(err, "port forward not found")
+	if la := rc.GetLoginAccount(); la.Id != consts.AdminId {
+		biz.IsTrue(pf.CreatorId == la.Id, "port forward not found")
+	}
+	rc.ReqParam = collx.Kvs("portForwardId", pf.Id, "machine", pf.MachineName, "listen", pf.ListenAddr,
+		"target", collx.Kvs("host", pf.TargetHost, "port", pf.TargetPort))
+
+	biz.ErrIsNil(m.machinePortForwardApp.Close(rc.MetaCtx, pfId))
+}
+
+func toPortForwardOpen(pfForm *form.MachinePortForwardForm) *dto.MachinePortForwardOpen {
+	return &dto.MachinePortForwardOpen{
+		MachineId:  pfForm.MachineId,
+		TargetHost: pfForm.TargetHost,
+		TargetPort: pfForm.TargetPort,
+		Ttl:        pfForm.Ttl,
+		Remark:     pfForm.Remark,
+	}
+}
@@ -10,17 +10,16 @@ import (
 	"mayfly-go/pkg/model"
 	"mayfly-go/pkg/req"
 	"mayfly-go/pkg/utils/collx"
-	"mayfly-go/pkg/utils/jsonx"
-	"mayfly-go/pkg/utils/stringx"
 	"strings"
 
 	"github.com/may-fly/cast"
 )
 
 type MachineScript struct {
-	machineScriptApp application.MachineScript `inject:"T"`
-	machineApp       application.Machine       `inject:"T"`
-	tagApp           tagapp.TagTree            `inject:"T"`
+	machineScriptApp   application.MachineScript   `inject:"T"`
+	machineApp         application.Machine         `inject:"T"`
+	machineCmdBatchApp application.MachineCmdBatch `inject:"T"`
+	tagApp             tagapp.TagTree              `inject:"T"`
 }
 
 func (ms *MachineScript) ReqConfs() *req.Confs {
@@ -34,7 +33,10 @@ func (ms *MachineScript) ReqConfs() *req.Confs {
 
 		req.NewDelete(":machineId/scripts/:scriptId", ms.DeleteMachineScript).Log(req.NewLogSave("机器-删除脚本")).RequiredPermissionCode("machine:script:del"),
 
-		req.NewGet("scripts/:scriptId/:ac/run", ms.RunMachineScript).Log(req.NewLogSave("机器-执行脚本")).RequiredPermissionCode("machine:script:run"),
+		req.NewPost("scripts/:scriptId/:ac/run", ms.RunMachineScript).Log(req.NewLogSave("机器-执行脚本")).RequiredPermissionCode("machine:script:run"),
+
+		// 渲染实时类脚本，由前端终端执行
+		req.NewPost("scripts/:scriptId/:ac/render", ms.RenderMachineScript).RequiredPermissionCode("machine:script:run"),
 	}
 
 	return req.NewConfs("machines", reqs[:]...)
@@ -73,31 +75,40 @@ func (m *MachineScript) DeleteMachineScript(rc *req.Ctx) {
 func (m *MachineScript) RunMachineScript(rc *req.Ctx) {
 	scriptId := GetMachineScriptId(rc)
 	ac := GetMachineAc(rc)
-	ms, err := m.machineScriptApp.GetById(scriptId, "MachineId", "Name", "Script")
+	runForm := req.BindJsonAndValid[*form.MachineScriptRunForm](rc)
+	ms, err := m.machineScriptApp.GetById(scriptId, "Id", "MachineId", "Name", "Script", "Params")
 	biz.ErrIsNil(err, "script not found")
 
-	script := ms.Script
-	// 如果有脚本参数，则用脚本参数替换脚本中的模板占位符参数
-	if params := rc.Query("params"); params != "" {
-		p, err := jsonx.ToMap(params)
-		biz.ErrIsNil(err)
-		script, err = stringx.TemplateParse(ms.Script, p)
-		biz.ErrIsNilAppendErr(err, "failed to parse the script template parameter: %s")
-	}
 	cli, err := m.machineApp.GetCliByAc(rc.MetaCtx, ac)
 	biz.ErrIsNilAppendErr(err, "connection error: %s")
 
 	biz.ErrIsNilAppendErr(m.tagApp.CanAccess(rc.GetLoginAccount().Id, cli.Info.CodePath...), "%s")
 
-	res, err := cli.Run(script)
-	// 记录请求参数
+	// 记录请求参数，参数中可能包含secret类参数，故不记录参数值
 	rc.ReqParam = collx.Kvs("machine", cli.Info, "scriptId", scriptId, "name", ms.Name)
+	res, err := m.machineCmdBatchApp.RunScript(rc.MetaCtx, cli, ms, runForm.Params)
 	if res == "" {
 		biz.ErrIsNilAppendErr(err, "failed to execute: %s")
 	}
 	rc.ResData = res
 }
 
+func (m *MachineScript) RenderMachineScript(rc *req.Ctx) {
+	scriptId := GetMachineScriptId(rc)
+	ac := GetMachineAc(rc)
+	runForm := req.BindJsonAndValid[*form.MachineScriptRunForm](rc)
+	ms, err := m.machineScriptApp.GetById(scriptId, "Id", "Script", "Params")
+	biz.ErrIsNil(err, "script not found")
+
+	cli, err := m.machineApp.GetCliByAc(rc.MetaCtx, ac)
+	biz.ErrIsNilAppendErr(err, "connection error: %s")
+	biz.ErrIsNilAppendErr(m.tagApp.CanAccess(rc.GetLoginAccount().Id, cli.Info.CodePath...), "%s")
+
+	cmd, _, err := m.machineScriptApp.RenderScript(ms, runForm.Params)
+	biz.ErrIsNilAppendErr(err, "%s")
+	rc.ResData = cmd
+}
+
 func GetMachineScriptId(rc *req.Ctx) uint64 {
 	scriptId := rc.PathParamInt("scriptId")
 	biz.IsTrue(scriptId > 0, "scriptId error")
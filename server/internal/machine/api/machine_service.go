@@ -0,0 +1,64 @@
+package api
+
+import (
+	"mayfly-go/internal/event"
+	"mayfly-go/internal/machine/api/form"
+	"mayfly-go/internal/machine/mcm"
+	"mayfly-go/pkg/biz"
+	"mayfly-go/pkg/global"
+	"mayfly-go/pkg/req"
+	"mayfly-go/pkg/utils/collx"
+)
+
+const (
+	serviceJournalDefaultLines = 100
+	serviceJournalMaxLines     = 2000
+)
+
+// 获取systemd服务列表
+func (m *Machine) GetServices(rc *req.Ctx) {
+	cli := m.getAccessibleCli(rc)
+	units, err := cli.GetSystemdUnits()
+	biz.ErrIsNil(err)
+	rc.ResData = units
+}
+
+// 获取服务的systemctl status及最近的journalctl日志
+func (m *Machine) GetServiceStatus(rc *req.Ctx) {
+	unit := rc.Query("unit")
+	biz.ErrIsNil(mcm.CheckSystemdUnitName(unit))
+	lines := min(rc.QueryIntDefault("lines", serviceJournalDefaultLines), serviceJournalMaxLines)
+
+	cli := m.getAccessibleCli(rc)
+	status, err := cli.GetSystemdUnitStatus(unit)
+	biz.ErrIsNil(err)
+
+	// 无权限查看日志等情况不影响状态的展示
+	journal, err := cli.GetSystemdUnitJournal(unit, lines)
+	if err != nil {
+		journal = err.Error()
+	}
+	rc.ResData = collx.Kvs("status", status, "journal", journal)
+}
+
+// opService 服务操作，不同操作使用不同的权限码
+func (m *Machine) opService(action string) req.HandlerFunc {
+	return func(rc *req.Ctx) {
+		serviceForm := req.BindJsonAndValid[*form.MachineServiceOpForm](rc)
+		cli := m.getAccessibleCli(rc)
+		rc.ReqParam = collx.Kvs("machine", cli.Info, "unit", serviceForm.Unit, "action", action)
+
+		res, err := cli.OpSystemdUnit(serviceForm.Unit, action)
+		biz.ErrIsNil(err)
+		global.EventBus.Publish(rc.MetaCtx, event.EventTopicResourceOp, cli.Info.CodePath[0])
+		rc.ResData = res
+	}
+}
+
+// getAccessibleCli 获取机器连接，并校验当前账号是否拥有该机器的访问权限
+func (m *Machine) getAccessibleCli(rc *req.Ctx) *mcm.Cli {
+	cli, err := m.machineApp.GetCli(rc.MetaCtx, GetMachineId(rc))
+	biz.ErrIsNilAppendErr(err, "connection error: %s")
+	biz.ErrIsNilAppendErr(m.tagTreeApp.CanAccess(rc.GetLoginAccount().Id, cli.Info.CodePath...), "%s")
+	return cli
+}
@@ -68,6 +68,13 @@ type MachineCronJobVO struct {
 	SaveExecResType int    `json:"saveExecResType"`
 	Remark          string `json:"remark"`
 	Running         bool   `json:"running" gorm:"-"` // 是否运行中
+
+	Timeout           int                 `json:"timeout"`
+	RetryCount        int                 `json:"retryCount"`
+	RetryInterval     int                 `json:"retryInterval"`
+	ConcurrencyPolicy int8                `json:"concurrencyPolicy"`
+	MsgTmplId         uint64              `json:"msgTmplId"`
+	ReceiverIds       model.Slice[uint64] `json:"receiverIds"`
 }
 
 func (mcj *MachineCronJobVO) GetRelateId() uint64 {
@@ -121,3 +128,23 @@ type MachineCmdConfVO struct {
 func (mcc *MachineCmdConfVO) GetRelateId() uint64 {
 	return mcc.Id
 }
+
+type MachineAlertRuleVO struct {
+	tagentity.RelateTags // 标签信息
+	model.Model
+
+	Name        string              `json:"name"`
+	Metric      string              `json:"metric"`
+	MountPoint  string              `json:"mountPoint"`
+	Operator    string              `json:"operator"`
+	Threshold   float64             `json:"threshold"`
+	Duration    int                 `json:"duration"`
+	MsgTmplId   uint64              `json:"msgTmplId"`
+	ReceiverIds model.Slice[uint64] `json:"receiverIds" gorm:"type:varchar"`
+	Status      int8                `json:"status"`
+	Remark      string              `json:"remark"`
+}
+
+func (mar *MachineAlertRuleVO) GetRelateId() uint64 {
+	return mar.Id
+}
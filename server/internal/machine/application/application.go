@@ -1,6 +1,7 @@
 package application
 
 import (
+	"mayfly-go/internal/machine/mcm"
 	"mayfly-go/pkg/ioc"
 	"sync"
 )
@@ -12,6 +13,12 @@ func InitIoc() {
 	ioc.Register(new(machineCronJobAppImpl), ioc.WithComponentName("MachineCronJobApp"))
 	ioc.Register(new(machineTermOpAppImpl), ioc.WithComponentName("MachineTermOpApp"))
 	ioc.Register(new(machineCmdConfAppImpl), ioc.WithComponentName("MachineCmdConfApp"))
+	ioc.Register(new(machineHostKeyAppImpl), ioc.WithComponentName("MachineHostKeyApp"))
+	ioc.Register(new(machineMonitorAppImpl), ioc.WithComponentName("MachineMonitorApp"))
+	ioc.Register(new(machineAlertRuleAppImpl), ioc.WithComponentName("MachineAlertRuleApp"))
+	ioc.Register(new(machineCmdBatchAppImpl), ioc.WithComponentName("MachineCmdBatchApp"))
+	ioc.Register(new(machineFileTransferAppImpl), ioc.WithComponentName("MachineFileTransferApp"))
+	ioc.Register(new(machinePortForwardAppImpl), ioc.WithComponentName("MachinePortForwardApp"))
 }
 
 func Init() {
@@ -20,7 +27,15 @@ func Init() {
 
 		GetMachineApp().TimerUpdateStats()
 
+		GetMachineMonitorApp().TimerDownsample()
+
 		GetMachineTermOpApp().TimerDeleteTermOp()
+
+		mcm.SetHostKeyVerifyFunc(GetMachineHostKeyApp().VerifyHostKey)
+
+		GetMachinePortForwardApp().InitPortForward()
+
+		InitMachineFlowHandler()
 	})()
 }
 
@@ -43,3 +58,15 @@ func GetMachineCronJobApp() MachineCronJob {
 func GetMachineTermOpApp() MachineTermOp {
 	return ioc.Get[MachineTermOp]("MachineTermOpApp")
 }
+
+func GetMachineHostKeyApp() MachineHostKey {
+	return ioc.Get[MachineHostKey]("MachineHostKeyApp")
+}
+
+func GetMachineMonitorApp() MachineMonitor {
+	return ioc.Get[MachineMonitor]("MachineMonitorApp")
+}
+
+func GetMachinePortForwardApp() MachinePortForward {
+	return ioc.Get[MachinePortForward]("MachinePortForwardApp")
+}
@@ -27,3 +27,113 @@ type SaveMachineCronJob struct {
 	CronJob   *entity.MachineCronJob
 	CodePaths []string
 }
+
+type SaveMachineAlertRule struct {
+	AlertRule *entity.MachineAlertRule
+	CodePaths []string
+}
+
+type MachineCmdBatchRun struct {
+	Name         string
+	Cmd          string         // 执行的命令，与脚本id二选一
+	ScriptId     uint64         // 脚本id
+	Params       map[string]any // 脚本参数
+	TagPaths     []string       // 目标标签路径
+	MachineIds   []uint64       // 目标机器id
+	MachineCodes []string       // 目标机器编号
+	Concurrency  int            // 并发数
+	Timeout      int            // 单机超时时间(秒)
+	ClientId     string         // 客户端id，若存在则会向其发送执行进度消息
+}
+
+// MachineFileChunkUpload 机器文件分片上传信息
+type MachineFileChunkUpload struct {
+	UploadId     string `json:"uploadId"`     // 上传id，相同账号、机器授权凭证、目标文件、大小及文件指纹的上传id相同，以支持断点续传
+	AccountId    uint64 `json:"accountId"`    // 上传账号id
+	MachineId    uint64 `json:"machineId"`    // 机器id
+	AuthCertName string `json:"authCertName"` // 授权凭证
+	Path         string `json:"path"`         // 目标文件路径
+	TmpPath      string `json:"tmpPath"`      // 上传中的临时文件路径
+	Size         int64  `json:"size"`         // 文件总大小
+	Offset       int64  `json:"offset"`       // 已上传的偏移量
+}
+
+func (u *MachineFileChunkUpload) ToMachineFileOp() *MachineFileOp {
+	return &MachineFileOp{
+		MachineId:    u.MachineId,
+		Protocol:     entity.MachineProtocolSsh,
+		AuthCertName: u.AuthCertName,
+		Path:         u.Path,
+	}
+}
+
+// MachineFileTransferStart 机器间文件传输参数
+type MachineFileTransferStart struct {
+	Src       *MachineFileOp // 源机器及文件路径
+	Dst       *MachineFileOp // 目标机器及路径，路径为已存在的目录时传输至该目录下
+	Overwrite bool           // 目标文件已存在时是否覆盖
+	ClientId  string         // 客户端id，若存在则会向其发送传输进度消息
+}
+
+// MachineFileTail 远程文件跟踪参数
+type MachineFileTail struct {
+	Lines      int    `json:"lines"`      // 初始显示的末尾行数
+	Filter     string `json:"filter"`     // 过滤规则，仅推送命中的行
+	Highlight  string `json:"highlight"`  // 高亮规则，为空则使用过滤规则
+	Regexp     bool   `json:"regexp"`     // 规则是否为正则表达式，否则为关键字
+	IgnoreCase bool   `json:"ignoreCase"` // 是否忽略大小写
+}
+
+// MachinePortForwardOpen 开启端口转发参数
+type MachinePortForwardOpen struct {
+	MachineId  uint64 // 用于转发的机器id
+	TargetHost string // 机器可访问的目标地址
+	TargetPort int    // 目标端口
+	Ttl        int    // 有效时长(分钟)，为0则使用配置的最大有效时长
+	Remark     string
+	ClientIp   string // 开启转发的客户端ip，监听非回环地址时仅允许该ip连接监听端口
+}
+
+// MachineImport 批量导入机器参数
+type MachineImport struct {
+	Format         string   // 清单格式，如csv、ssh_config、ansible_ini、ansible_yaml
+	Content        string   // 清单内容
+	TagCodePaths   []string // 默认标签路径，主机未指定标签且其分组未匹配到标签时使用
+	AuthCertName   string   // 默认公共授权凭证，主机未指定密码、私钥等凭证时使用
+	EnableRecorder int8     // 是否启用终端回放记录
+	DryRun         bool     // 是否仅预览导入结果，不实际导入
+}
+
+const (
+	MachineImportStatusCreate  = "create"  // 待新增，仅预览时
+	MachineImportStatusCreated = "created" // 已新增
+	MachineImportStatusExist   = "exist"   // 机器已存在，跳过
+	MachineImportStatusError   = "error"   // 存在错误
+)
+
+// MachineImportRow 单个主机的导入结果
+type MachineImportRow struct {
+	Line         int      `json:"line"` // 主机在清单中的行号
+	Name         string   `json:"name"`
+	Ip           string   `json:"ip"`
+	Port         int      `json:"port"`
+	Protocol     int      `json:"protocol"`
+	Username     string   `json:"username"`
+	AuthCert     string   `json:"authCert"` // 授权凭证，公共授权凭证名或password、privateKey
+	TagCodePaths []string `json:"tagCodePaths"`
+	SshTunnel    string   `json:"sshTunnel"` // 跳板机名称
+	Status       string   `json:"status"`
+	MachineId    uint64   `json:"machineId"` // 新增或已存在的机器id
+	Warnings     []string `json:"warnings"`
+	Error        string   `json:"error"`
+}
+
+// MachineImportResult 批量导入机器结果
+type MachineImportResult struct {
+	DryRun  bool                `json:"dryRun"`
+	Total   int                 `json:"total"`
+	Created int                 `json:"created"` // 新增或待新增的数量
+	Existed int                 `json:"existed"`
+	Failed  int                 `json:"failed"`
+	Rows    []*MachineImportRow `json:"rows"`
+}
@@ -0,0 +1,14 @@
+package application
+
+import (
+	flowapp "mayfly-go/internal/flow/application"
+	"mayfly-go/pkg/ioc"
+)
+
+const (
+	MachineTermCmdFlowBizType = "machine_term_cmd_flow" // 机器终端命令审批流程业务类型
+)
+
+func InitMachineFlowHandler() {
+	flowapp.RegisterBizHandler(MachineTermCmdFlowBizType, ioc.Get[MachineTermOp]("MachineTermOpApp"))
+}
@@ -56,6 +56,12 @@ type Machine interface {
 	GetMachineStats(machineId uint64) (*mcm.Stats, error)
 
 	ToMachineInfoByAc(ac string) (*mcm.MachineInfo, error)
+
+	// 根据机器id获取机器信息，使用机器的默认授权凭证
+	ToMachineInfoById(machineId uint64) (*mcm.MachineInfo, error)
+
+	// ImportMachines 从csv、ssh config、ansible清单批量导入机器，DryRun时仅返回预览结果
+	ImportMachines(ctx context.Context, param *dto.MachineImport) (*dto.MachineImportResult, error)
 }
 
 type machineAppImpl struct {
@@ -64,8 +70,13 @@ type machineAppImpl struct {
 	tagApp              tagapp.TagTree          `inject:"T"`
 	resourceAuthCertApp tagapp.ResourceAuthCert `inject:"T"`
 
-	machineScriptApp MachineScript `inject:"T"`
-	machineFileApp   MachineFile   `inject:"T"`
+	machineScriptApp  MachineScript  `inject:"T"`
+	machineFileApp    MachineFile    `inject:"T"`
+	machineHostKeyApp MachineHostKey `inject:"T"`
+	machineMonitorApp MachineMonitor `inject:"T"`
+
+	machineAlertRuleApp MachineAlertRule        `inject:"T"`
+	machineAlertRepo    repository.MachineAlert `inject:"T"`
 }
 
 var _ (Machine) = (*machineAppImpl)(nil)
@@ -140,6 +151,12 @@ func (m *machineAppImpl) SaveMachine(ctx context.Context, param *dto.SaveMachine
 	me.Code = ""
 	return m.Tx(ctx, func(ctx context.Context) error {
 		return m.UpdateById(ctx, me)
+	}, func(ctx context.Context) error {
+		// 机器地址变更后原主机公钥已不可信，下次连接时重新信任
+		if oldMachine.Ip == me.Ip && oldMachine.Port == me.Port {
+			return nil
+		}
+		return m.machineHostKeyApp.Reset(ctx, me.Id)
 	}, func(ctx context.Context) error {
 		return m.resourceAuthCertApp.RelateAuthCert(ctx, &tagdto.RelateAuthCert{
 			ResourceCode: oldMachine.Code,
@@ -208,6 +225,15 @@ func (m *machineAppImpl) Delete(ctx context.Context, id uint64) error {
 			if err := m.machineScriptApp.DeleteByCond(ctx, &entity.MachineScript{MachineId: id}); err != nil {
 				return err
 			}
+			if err := m.machineHostKeyApp.DeleteByCond(ctx, &entity.MachineHostKey{MachineId: id}); err != nil {
+				return err
+			}
+			if err := m.machineMonitorApp.DeleteByCond(ctx, &entity.MachineMonitor{MachineId: id}); err != nil {
+				return err
+			}
+			if err := m.machineAlertRepo.DeleteByCond(ctx, &entity.MachineAlert{MachineId: id}); err != nil {
+				return err
+			}
 			return m.DeleteById(ctx, id)
 		}, func(ctx context.Context) error {
 			return m.tagApp.SaveResourceTag(ctx, &tagdto.SaveResourceTag{
@@ -255,9 +281,10 @@ func (m *machineAppImpl) GetSshTunnelMachine(ctx context.Context, machineId int)
 func (m *machineAppImpl) TimerUpdateStats() {
 	logx.Debug("start collecting and caching machine state information periodically...")
 	scheduler.AddFun("@every 2m", func() {
-		machineIds, _ := m.ListByCond(model.NewModelCond(&entity.Machine{Status: entity.MachineStatusEnable, Protocol: entity.MachineProtocolSsh}).Columns("id"))
-		for _, ma := range machineIds {
-			go func(mid uint64) {
+		machines, _ := m.ListByCond(model.NewModelCond(&entity.Machine{Status: entity.MachineStatusEnable, Protocol: entity.MachineProtocolSsh}).Columns("id", "code", "name", "ip"))
+		for _, ma := range machines {
+			go func(machine *entity.Machine) {
+				mid := machine.Id
 				defer func() {
 					if err := recover(); err != nil {
 						logx.ErrorTrace(fmt.Sprintf("failed to get machine [id=%d] status information on time", mid), err.(error))
@@ -269,11 +296,17 @@ func (m *machineAppImpl) TimerUpdateStats() {
 				cli, err := m.GetCli(ctx, mid)
 				if err != nil {
 					logx.Errorf("failed to get machine [id=%d] status information periodically, failed to get machine cli: %s", mid, err.Error())
+					m.machineAlertRuleApp.Evaluate(ctx, machine, nil, err)
 					return
 				}
-				cache.SaveMachineStats(mid, cli.GetAllStats())
+				stats := cli.GetAllStats()
+				cache.SaveMachineStats(mid, stats)
+				if err := m.machineMonitorApp.SaveStats(mid, stats); err != nil {
+					logx.Errorf("failed to save machine [id=%d] monitor data: %s", mid, err.Error())
+				}
+				m.machineAlertRuleApp.Evaluate(ctx, machine, stats, nil)
 				logx.Debugf("time to get the machine [id=%d] status information end", mid)
-			}(ma.Id)
+			}(ma)
 		}
 	})
 }
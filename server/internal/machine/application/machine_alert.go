@@ -0,0 +1,389 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/internal/machine/mcm"
+	msgapp "mayfly-go/internal/msg/application"
+	"mayfly-go/internal/msg/msgx"
+	tagapp "mayfly-go/internal/tag/application"
+	tagentity "mayfly-go/internal/tag/domain/entity"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/collx"
+	"mayfly-go/pkg/utils/stringx"
+	"sync"
+	"time"
+
+	"github.com/may-fly/cast"
+)
+
+type MachineAlertRule interface {
+	base.App[*entity.MachineAlertRule]
+
+	GetPageList(condition *entity.MachineAlertRuleQuery, orderBy ...string) (*model.PageResult[*entity.MachineAlertRule], error)
+
+	SaveRule(ctx context.Context, param *dto.SaveMachineAlertRule) error
+
+	// DeleteRule 删除告警规则及其告警记录
+	DeleteRule(ctx context.Context, id uint64) error
+
+	GetAlertPageList(condition *entity.MachineAlertQuery, orderBy ...string) (*model.PageResult[*entity.MachineAlert], error)
+
+	GetSilencePageList(condition *entity.MachineAlertSilenceQuery, orderBy ...string) (*model.PageResult[*entity.MachineAlertSilence], error)
+
+	SaveSilence(ctx context.Context, silence *entity.MachineAlertSilence) error
+
+	DeleteSilence(ctx context.Context, id uint64) error
+
+	// Evaluate 根据机器采集的运行状态评估适用的告警规则，statsErr不为空则表示获取状态失败(机器不可达)
+	Evaluate(ctx context.Context, machine *entity.Machine, stats *mcm.Stats, statsErr error)
+}
+
+type machineAlertRuleAppImpl struct {
+	base.AppImpl[*entity.MachineAlertRule, repository.MachineAlertRule]
+
+	machineAlertRepo        repository.MachineAlert        `inject:"T"`
+	machineAlertSilenceRepo repository.MachineAlertSilence `inject:"T"`
+
+	tagApp           tagapp.TagTree       `inject:"T"`
+	tagTreeRelateApp tagapp.TagTreeRelate `inject:"T"`
+	msgTmplApp       msgapp.MsgTmpl       `inject:"T"`
+
+	// 告警指纹 -> 首次满足条件的时间，用于判断是否持续满足条件
+	pendings sync.Map
+	// 机器id -> 连续不可达次数
+	unreachableCounts sync.Map
+}
+
+var _ (MachineAlertRule) = (*machineAlertRuleAppImpl)(nil)
+
+var machineAlertMetrics = []string{
+	entity.MachineAlertMetricCpu,
+	entity.MachineAlertMetricMem,
+	entity.MachineAlertMetricLoad1,
+	entity.MachineAlertMetricLoad5,
+	entity.MachineAlertMetricLoad15,
+	entity.MachineAlertMetricDisk,
+	entity.MachineAlertMetricUnreachable,
+}
+
+func (m *machineAlertRuleAppImpl) GetPageList(condition *entity.MachineAlertRuleQuery, orderBy ...string) (*model.PageResult[*entity.MachineAlertRule], error) {
+	return m.GetRepo().GetPageList(condition, orderBy...)
+}
+
+func (m *machineAlertRuleAppImpl) SaveRule(ctx context.Context, param *dto.SaveMachineAlertRule) error {
+	rule := param.AlertRule
+	if !collx.ArrayContains(machineAlertMetrics, rule.Metric) {
+		return errorx.NewBiz("unsupported metric: %s", rule.Metric)
+	}
+	if rule.Operator == "" {
+		rule.Operator = ">"
+	}
+	if _, err := compareMetric(0, rule.Operator, 0); err != nil {
+		return err
+	}
+	if rule.Metric == entity.MachineAlertMetricUnreachable && rule.Threshold < 1 {
+		return errorx.NewBiz("the threshold of unreachable metric is the number of consecutive polls and must be greater than 0")
+	}
+
+	return m.Tx(ctx, func(ctx context.Context) error {
+		return m.Save(ctx, rule)
+	}, func(ctx context.Context) error {
+		return m.tagTreeRelateApp.RelateTag(ctx, tagentity.TagRelateTypeMachineAlert, rule.Id, param.CodePaths...)
+	})
+}
+
+func (m *machineAlertRuleAppImpl) DeleteRule(ctx context.Context, id uint64) error {
+	if _, err := m.GetById(id); err != nil {
+		return errorx.NewBiz("alert rule not found")
+	}
+
+	return m.Tx(ctx, func(ctx context.Context) error {
+		return m.DeleteById(ctx, id)
+	}, func(ctx context.Context) error {
+		return m.machineAlertRepo.DeleteByCond(ctx, &entity.MachineAlert{RuleId: id})
+	}, func(ctx context.Context) error {
+		return m.tagTreeRelateApp.DeleteByCond(ctx, &tagentity.TagTreeRelate{
+			RelateType: tagentity.TagRelateTypeMachineAlert,
+			RelateId:   id,
+		})
+	})
+}
+
+func (m *machineAlertRuleAppImpl) GetAlertPageList(condition *entity.MachineAlertQuery, orderBy ...string) (*model.PageResult[*entity.MachineAlert], error) {
+	return m.machineAlertRepo.GetPageList(condition, orderBy...)
+}
+
+func (m *machineAlertRuleAppImpl) GetSilencePageList(condition *entity.MachineAlertSilenceQuery, orderBy ...string) (*model.PageResult[*entity.MachineAlertSilence], error) {
+	return m.machineAlertSilenceRepo.GetPageList(condition, orderBy...)
+}
+
+func (m *machineAlertRuleAppImpl) SaveSilence(ctx context.Context, silence *entity.MachineAlertSilence) error {
+	if !silence.StartTime.Before(silence.EndTime) {
+		return errorx.NewBiz("the start time must be before the end time")
+	}
+	return m.machineAlertSilenceRepo.Save(ctx, silence)
+}
+
+func (m *machineAlertRuleAppImpl) DeleteSilence(ctx context.Context, id uint64) error {
+	return m.machineAlertSilenceRepo.DeleteById(ctx, id)
+}
+
+func (m *machineAlertRuleAppImpl) Evaluate(ctx context.Context, machine *entity.Machine, stats *mcm.Stats, statsErr error) {
+	// 获取状态信息失败时，内存总量为0
+	reachable := statsErr == nil && stats != nil && stats.MemInfo.Total > 0
+	unreachableCount := 0
+	if reachable {
+		m.unreachableCounts.Delete(machine.Id)
+	} else {
+		count, _ := m.unreachableCounts.Load(machine.Id)
+		unreachableCount = cast.ToInt(count) + 1
+		m.unreachableCounts.Store(machine.Id, unreachableCount)
+	}
+
+	rules := m.getMachineRules(ctx, machine)
+	now := time.Now()
+	// 本次评估的告警指纹，以及未能评估需保持原有告警状态的规则
+	evaluated := make(map[string]bool)
+	keptRuleIds := make(map[uint64]bool)
+	for _, rule := range rules {
+		var values map[string]float64
+		if rule.Metric == entity.MachineAlertMetricUnreachable {
+			values = map[string]float64{"": float64(unreachableCount)}
+		} else {
+			// 机器不可达时无法获取指标值，保持原有告警状态
+			if !reachable {
+				keptRuleIds[rule.Id] = true
+				continue
+			}
+			values = getMetricValues(rule, stats)
+		}
+
+		for target, value := range values {
+			firing, err := m.isFiring(rule, value)
+			if err != nil {
+				logx.Errorf("machine alert rule [%s] evaluation failed: %s", rule.Name, err.Error())
+				keptRuleIds[rule.Id] = true
+				break
+			}
+			evaluated[machineAlertFingerprint(rule.Id, machine.Id, target)] = true
+			m.transition(ctx, rule, machine, target, value, firing, now)
+		}
+	}
+
+	m.resolveStaleAlerts(ctx, machine, rules, func(ruleId uint64, fingerprint string) bool {
+		return !evaluated[fingerprint] && !keptRuleIds[ruleId]
+	}, now)
+}
+
+// resolveStaleAlerts 恢复机器上不再评估的触发中告警(如规则被禁用、删除或取消关联，磁盘挂载点已不存在)，并清理其待触发记录
+func (m *machineAlertRuleAppImpl) resolveStaleAlerts(ctx context.Context, machine *entity.Machine, rules []*entity.MachineAlertRule, isStale func(ruleId uint64, fingerprint string) bool, now time.Time) {
+	m.pendings.Range(func(key, _ any) bool {
+		fingerprint := key.(string)
+		var ruleId, machineId uint64
+		if _, err := fmt.Sscanf(fingerprint, "%d:%d:", &ruleId, &machineId); err == nil && machineId == machine.Id && isStale(ruleId, fingerprint) {
+			m.pendings.Delete(key)
+		}
+		return true
+	})
+
+	alerts, err := m.machineAlertRepo.SelectByCond(model.NewCond().Eq("machine_id", machine.Id).Eq("status", entity.MachineAlertStatusFiring))
+	if err != nil {
+		logx.Errorf("failed to get firing machine alerts: %s", err.Error())
+		return
+	}
+	ruleMap := collx.ArrayToMap(rules, func(rule *entity.MachineAlertRule) uint64 {
+		return rule.Id
+	})
+	for _, alert := range alerts {
+		if !isStale(alert.RuleId, alert.Fingerprint) {
+			continue
+		}
+		alert.Status = entity.MachineAlertStatusResolved
+		alert.ResolvedTime = &now
+		// 规则仍适用于该机器(如磁盘挂载点已不存在)，则发送恢复通知
+		if rule := ruleMap[alert.RuleId]; rule != nil {
+			m.notify(ctx, rule, machine, alert)
+		}
+		if err := m.machineAlertRepo.UpdateById(ctx, alert); err != nil {
+			logx.Errorf("failed to resolve machine alert [%s]: %s", alert.Fingerprint, err.Error())
+		}
+	}
+}
+
+// getMachineRules 获取机器关联标签所适用的已启用告警规则
+func (m *machineAlertRuleAppImpl) getMachineRules(ctx context.Context, machine *entity.Machine) []*entity.MachineAlertRule {
+	tagPaths := m.tagApp.ListTagPathByTypeAndCode(int8(tagentity.TagTypeMachine), machine.Code)
+	if len(tagPaths) == 0 {
+		return nil
+	}
+	ruleIds, err := m.tagTreeRelateApp.GetRelateIds(ctx, tagentity.TagRelateTypeMachineAlert, tagPaths...)
+	if err != nil || len(ruleIds) == 0 {
+		return nil
+	}
+	rules, err := m.GetByIds(ruleIds)
+	if err != nil {
+		logx.Errorf("failed to get machine alert rules: %s", err.Error())
+		return nil
+	}
+	return collx.ArrayFilter(rules, func(rule *entity.MachineAlertRule) bool {
+		return rule.Status == entity.MachineAlertRuleStatusEnable
+	})
+}
+
+func (m *machineAlertRuleAppImpl) isFiring(rule *entity.MachineAlertRule, value float64) (bool, error) {
+	if rule.Metric == entity.MachineAlertMetricUnreachable {
+		return value >= rule.Threshold, nil
+	}
+	return compareMetric(value, rule.Operator, rule.Threshold)
+}
+
+// transition 根据本次评估结果变更告警状态，触发或恢复时发送通知
+func (m *machineAlertRuleAppImpl) transition(ctx context.Context, rule *entity.MachineAlertRule, machine *entity.Machine, target string, value float64, firing bool, now time.Time) {
+	fingerprint := machineAlertFingerprint(rule.Id, machine.Id, target)
+
+	alert := &entity.MachineAlert{Fingerprint: fingerprint, Status: entity.MachineAlertStatusFiring}
+	exist := m.machineAlertRepo.GetByCond(alert) == nil
+
+	if !firing {
+		m.pendings.Delete(fingerprint)
+		if !exist {
+			return
+		}
+		alert.Status = entity.MachineAlertStatusResolved
+		alert.ResolvedTime = &now
+		alert.Value = value
+		m.notify(ctx, rule, machine, alert)
+		if err := m.machineAlertRepo.UpdateById(ctx, alert); err != nil {
+			logx.Errorf("failed to resolve machine alert [%s]: %s", fingerprint, err.Error())
+		}
+		return
+	}
+
+	// 已存在触发中的告警，则不重复通知
+	if exist {
+		return
+	}
+
+	since, _ := m.pendings.LoadOrStore(fingerprint, now)
+	startTime := since.(time.Time)
+	if now.Sub(startTime) < time.Duration(rule.Duration)*time.Minute {
+		return
+	}
+	m.pendings.Delete(fingerprint)
+
+	alert = &entity.MachineAlert{
+		Fingerprint: fingerprint,
+		RuleId:      rule.Id,
+		RuleName:    rule.Name,
+		MachineId:   machine.Id,
+		MachineName: machine.Name,
+		Metric:      rule.Metric,
+		Target:      target,
+		Value:       value,
+		Threshold:   rule.Threshold,
+		Status:      entity.MachineAlertStatusFiring,
+		StartTime:   startTime,
+	}
+	m.notify(ctx, rule, machine, alert)
+	if err := m.machineAlertRepo.Insert(ctx, alert); err != nil {
+		logx.Errorf("failed to save machine alert [%s]: %s", fingerprint, err.Error())
+	}
+}
+
+// notify 发送告警通知，静默期间内不发送
+func (m *machineAlertRuleAppImpl) notify(ctx context.Context, rule *entity.MachineAlertRule, machine *entity.Machine, alert *entity.MachineAlert) {
+	alert.Silenced = m.isSilenced(rule.Id, machine.Id)
+	if alert.Silenced || rule.MsgTmplId == 0 {
+		return
+	}
+
+	status := "firing"
+	resolvedTime := ""
+	if alert.Status == entity.MachineAlertStatusResolved {
+		status = "resolved"
+		resolvedTime = alert.ResolvedTime.Format(time.DateTime)
+	}
+	msg := &msgx.Msg{
+		Params: collx.M{
+			"ruleName":     rule.Name,
+			"machineName":  machine.Name,
+			"machineIp":    machine.Ip,
+			"metric":       rule.Metric,
+			"target":       alert.Target,
+			"value":        fmt.Sprintf("%.2f", alert.Value),
+			"operator":     rule.Operator,
+			"threshold":    rule.Threshold,
+			"status":       status,
+			"startTime":    alert.StartTime.Format(time.DateTime),
+			"resolvedTime": resolvedTime,
+		},
+	}
+	if err := m.msgTmplApp.SendSync(ctx, rule.MsgTmplId, msg, rule.ReceiverIds...); err != nil {
+		alert.NotifyErr = stringx.Truncate(err.Error(), 1000, 10, "...")
+		logx.Errorf("failed to send machine alert notification [%s]: %s", alert.Fingerprint, err.Error())
+	}
+}
+
+// isSilenced 判断当前是否存在匹配的生效中的静默
+func (m *machineAlertRuleAppImpl) isSilenced(ruleId, machineId uint64) bool {
+	now := time.Now()
+	return m.machineAlertSilenceRepo.CountByCond(model.NewCond().
+		Le("start_time", now).
+		Ge("end_time", now).
+		And("(rule_id = 0 OR rule_id = ?)", ruleId).
+		And("(machine_id = 0 OR machine_id = ?)", machineId)) > 0
+}
+
+// machineAlertFingerprint 告警指纹，规则id:机器id:告警对象
+func machineAlertFingerprint(ruleId, machineId uint64, target string) string {
+	return fmt.Sprintf("%d:%d:%s", ruleId, machineId, target)
+}
+
+// getMetricValues 获取规则对应的指标值，key为告警对象(如磁盘挂载点)
+func getMetricValues(rule *entity.MachineAlertRule, stats *mcm.Stats) map[string]float64 {
+	switch rule.Metric {
+	case entity.MachineAlertMetricCpu:
+		return map[string]float64{"": float64(100 - stats.CPU.Idle)}
+	case entity.MachineAlertMetricMem:
+		return map[string]float64{"": float64(stats.MemInfo.Total-stats.MemInfo.Available) / float64(stats.MemInfo.Total) * 100}
+	case entity.MachineAlertMetricLoad1:
+		return map[string]float64{"": cast.ToFloat64(stats.Load1)}
+	case entity.MachineAlertMetricLoad5:
+		return map[string]float64{"": cast.ToFloat64(stats.Load5)}
+	case entity.MachineAlertMetricLoad15:
+		return map[string]float64{"": cast.ToFloat64(stats.Load10)}
+	case entity.MachineAlertMetricDisk:
+		values := make(map[string]float64)
+		for _, fs := range stats.FSInfos {
+			if rule.MountPoint != "" && fs.MountPoint != rule.MountPoint {
+				continue
+			}
+			if total := fs.Used + fs.Free; total > 0 {
+				values[fs.MountPoint] = float64(fs.Used) / float64(total) * 100
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func compareMetric(value float64, operator string, threshold float64) (bool, error) {
+	switch operator {
+	case ">":
+		return value > threshold, nil
+	case ">=":
+		return value >= threshold, nil
+	case "<":
+		return value < threshold, nil
+	case "<=":
+		return value <= threshold, nil
+	}
+	return false, errorx.NewBiz("unsupported operator: %s", operator)
+}
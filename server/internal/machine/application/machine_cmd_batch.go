@@ -0,0 +1,411 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/internal/machine/imsg"
+	"mayfly-go/internal/machine/mcm"
+	msgapp "mayfly-go/internal/msg/application"
+	msgdto "mayfly-go/internal/msg/application/dto"
+	tagapp "mayfly-go/internal/tag/application"
+	tagentity "mayfly-go/internal/tag/domain/entity"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/contextx"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/i18n"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/collx"
+	"mayfly-go/pkg/utils/stringx"
+	"mayfly-go/pkg/ws"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	machineCmdBatchDefaultConcurrency = 10
+	machineCmdBatchMaxConcurrency     = 50
+	machineCmdBatchDefaultTimeout     = 60   // 秒
+	machineCmdBatchMaxTimeout         = 3600 // 秒
+	machineCmdBatchMaxOutputLen       = 64 * 1024
+
+	machineCmdBatchProgressCategory = "machineCmdBatchProgress"
+)
+
+type MachineCmdBatch interface {
+	base.App[*entity.MachineCmdBatch]
+
+	GetPageList(condition *entity.MachineCmdBatchQuery, orderBy ...string) (*model.PageResult[*entity.MachineCmdBatch], error)
+
+	// GetResultPageList 获取批量命令在各机器上的执行结果
+	GetResultPageList(condition *entity.MachineCmdBatchResultQuery, orderBy ...string) (*model.PageResult[*entity.MachineCmdBatchResult], error)
+
+	// Run 在选定的多台机器上并发执行命令或脚本，异步执行并通过websocket推送各机器执行进度
+	Run(ctx context.Context, param *dto.MachineCmdBatchRun) (*entity.MachineCmdBatch, error)
+
+	// RunScript 在指定机器上同步执行脚本，并记录为单机的批量执行记录，以便统一查看脚本执行历史
+	RunScript(ctx context.Context, cli *mcm.Cli, script *entity.MachineScript, params map[string]any) (string, error)
+}
+
+type machineCmdBatchAppImpl struct {
+	base.AppImpl[*entity.MachineCmdBatch, repository.MachineCmdBatch]
+
+	machineCmdBatchResultRepo repository.MachineCmdBatchResult `inject:"T"`
+
+	machineApp        Machine        `inject:"T"`
+	machineScriptApp  MachineScript  `inject:"T"`
+	machineCmdConfApp MachineCmdConf `inject:"T"`
+	tagApp            tagapp.TagTree `inject:"T"`
+	msgApp            msgapp.Msg     `inject:"T"`
+}
+
+var _ (MachineCmdBatch) = (*machineCmdBatchAppImpl)(nil)
+
+type cmdBatchProgressMsg struct {
+	BatchId     uint64 `json:"batchId"`
+	Title       string `json:"title"`
+	MachineId   uint64 `json:"machineId"`
+	MachineName string `json:"machineName"`
+	Status      int8   `json:"status"`
+	Output      string `json:"output"`
+	ErrorMsg    string `json:"errorMsg"`
+	Total       int    `json:"total"`
+	Finished    int    `json:"finished"`
+	Terminated  bool   `json:"terminated"`
+}
+
+func (m *machineCmdBatchAppImpl) GetPageList(condition *entity.MachineCmdBatchQuery, orderBy ...string) (*model.PageResult[*entity.MachineCmdBatch], error) {
+	return m.GetRepo().GetPageList(condition, orderBy...)
+}
+
+func (m *machineCmdBatchAppImpl) GetResultPageList(condition *entity.MachineCmdBatchResultQuery, orderBy ...string) (*model.PageResult[*entity.MachineCmdBatchResult], error) {
+	return m.machineCmdBatchResultRepo.GetPageList(condition, orderBy...)
+}
+
+func (m *machineCmdBatchAppImpl) Run(ctx context.Context, param *dto.MachineCmdBatchRun) (*entity.MachineCmdBatch, error) {
+	la := contextx.GetLoginAccount(ctx)
+	if la == nil {
+		return nil, errorx.NewBiz("login account not found")
+	}
+
+	cmd := param.Cmd
+	// 记录的命令，脚本中secret类参数会脱敏
+	recordCmd := cmd
+	name := param.Name
+	if param.ScriptId != 0 {
+		script, err := m.machineScriptApp.GetById(param.ScriptId, "Name", "Script", "Params")
+		if err != nil {
+			return nil, errorx.NewBiz("script not found")
+		}
+		// 校验脚本参数并替换脚本中的模板占位符参数
+		if cmd, recordCmd, err = m.machineScriptApp.RenderScript(script, param.Params); err != nil {
+			return nil, err
+		}
+		if name == "" {
+			name = script.Name
+		}
+	}
+	if strings.TrimSpace(cmd) == "" {
+		return nil, errorx.NewBiz("the command cannot be empty")
+	}
+	if name == "" {
+		name = stringx.Truncate(strings.TrimSpace(recordCmd), 50, 40, "...")
+	}
+
+	machines, err := m.resolveMachines(la.Id, param)
+	if err != nil {
+		return nil, err
+	}
+	if len(machines) == 0 {
+		return nil, errorx.NewBizI(ctx, imsg.ErrCmdBatchNoMachine)
+	}
+
+	concurrency := param.Concurrency
+	if concurrency <= 0 {
+		concurrency = machineCmdBatchDefaultConcurrency
+	}
+	timeout := param.Timeout
+	if timeout <= 0 {
+		timeout = machineCmdBatchDefaultTimeout
+	}
+
+	batch := &entity.MachineCmdBatch{
+		Name:         name,
+		ScriptId:     param.ScriptId,
+		Cmd:          recordCmd,
+		TagPaths:     param.TagPaths,
+		MachineIds:   param.MachineIds,
+		MachineCodes: param.MachineCodes,
+		Concurrency:  min(concurrency, machineCmdBatchMaxConcurrency),
+		Timeout:      min(timeout, machineCmdBatchMaxTimeout),
+		Status:       entity.MachineCmdBatchStatusRunning,
+		Total:        len(machines),
+	}
+	if err := m.Insert(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	results := collx.ArrayMap(machines, func(machine *entity.Machine) *entity.MachineCmdBatchResult {
+		return &entity.MachineCmdBatchResult{
+			BatchId:     batch.Id,
+			MachineId:   machine.Id,
+			MachineCode: machine.Code,
+			MachineName: machine.Name,
+			Status:      entity.MachineCmdBatchResultStatusWaiting,
+		}
+	})
+	if err := m.machineCmdBatchResultRepo.BatchInsert(ctx, results); err != nil {
+		return nil, err
+	}
+
+	go m.doRun(contextx.NewLoginAccount(la), batch, cmd, results, param.ClientId)
+	return batch, nil
+}
+
+func (m *machineCmdBatchAppImpl) RunScript(ctx context.Context, cli *mcm.Cli, script *entity.MachineScript, params map[string]any) (string, error) {
+	cmd, recordCmd, err := m.machineScriptApp.RenderScript(script, params)
+	if err != nil {
+		return "", err
+	}
+
+	mi := cli.Info
+	batch := &entity.MachineCmdBatch{
+		Name:         script.Name,
+		ScriptId:     script.Id,
+		Cmd:          recordCmd,
+		MachineIds:   []uint64{mi.Id},
+		MachineCodes: []string{mi.Code},
+		Concurrency:  1,
+		Status:       entity.MachineCmdBatchStatusRunning,
+		Total:        1,
+	}
+	if err := m.Insert(ctx, batch); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	result := &entity.MachineCmdBatchResult{
+		BatchId:     batch.Id,
+		MachineId:   mi.Id,
+		MachineCode: mi.Code,
+		MachineName: mi.Name,
+		Status:      entity.MachineCmdBatchResultStatusRunning,
+		StartTime:   &now,
+	}
+	if err := m.machineCmdBatchResultRepo.Insert(ctx, result); err != nil {
+		return "", err
+	}
+
+	var output string
+	if err = m.machineCmdConfApp.CheckCmd(ctx, cmd, mi.CodePath...); err != nil {
+		result.Status = entity.MachineCmdBatchResultStatusRejected
+		result.ErrorMsg = stringx.Truncate(err.Error(), 1000, 900, "...")
+	} else {
+		output, err = cli.Run(cmd)
+		fillCmdBatchResult(result, output, err, 0)
+	}
+	endTime := time.Now()
+	result.EndTime = &endTime
+	m.saveResult(result)
+
+	update := &entity.MachineCmdBatch{Status: entity.MachineCmdBatchStatusDone, EndTime: &endTime}
+	update.Id = batch.Id
+	if result.Status == entity.MachineCmdBatchResultStatusSuccess {
+		update.SuccessNum = 1
+	} else {
+		update.FailNum = 1
+	}
+	if err := m.UpdateById(context.Background(), update); err != nil {
+		logx.Errorf("failed to update machine cmd batch [%d]: %s", batch.Id, err.Error())
+	}
+	return output, err
+}
+
+// resolveMachines 根据标签路径、机器id及机器编号获取当前账号可访问的启用状态ssh机器，多种条件取并集
+func (m *machineCmdBatchAppImpl) resolveMachines(accountId uint64, param *dto.MachineCmdBatchRun) ([]*entity.Machine, error) {
+	tagPaths := collx.ArrayRemoveBlank(param.TagPaths)
+	if len(tagPaths) == 0 && len(param.MachineIds) == 0 && len(param.MachineCodes) == 0 {
+		return nil, errorx.NewBiz("please select the target machines")
+	}
+
+	machineTypes := []tagentity.TagType{tagentity.TagTypeMachine}
+	accessibleCodes := m.tagApp.GetAccountTags(accountId, &tagentity.TagTreeQuery{Types: machineTypes}).GetCodes()
+	if len(accessibleCodes) == 0 {
+		return nil, nil
+	}
+
+	targetCodes := collx.ArrayToMap(param.MachineCodes, func(code string) string { return code })
+	if len(tagPaths) > 0 {
+		for _, code := range m.tagApp.GetAccountTags(accountId, &tagentity.TagTreeQuery{Types: machineTypes, CodePathLikes: tagPaths}).GetCodes() {
+			targetCodes[code] = code
+		}
+	}
+	targetIds := collx.ArrayToMap(param.MachineIds, func(id uint64) uint64 { return id })
+
+	machines, err := m.machineApp.ListByCond(model.NewModelCond(&entity.Machine{Status: entity.MachineStatusEnable, Protocol: entity.MachineProtocolSsh}).
+		In("code", accessibleCodes).
+		Columns("id", "code", "name"))
+	if err != nil {
+		return nil, err
+	}
+
+	return collx.ArrayFilter(machines, func(machine *entity.Machine) bool {
+		_, codeOk := targetCodes[machine.Code]
+		_, idOk := targetIds[machine.Id]
+		return codeOk || idOk
+	}), nil
+}
+
+func (m *machineCmdBatchAppImpl) doRun(ctx context.Context, batch *entity.MachineCmdBatch, cmd string, results []*entity.MachineCmdBatchResult, clientId string) {
+	la := contextx.GetLoginAccount(ctx)
+	needSendMsg := clientId != ""
+
+	var mutex sync.Mutex
+	finished, successNum, failNum := 0, 0, 0
+
+	sendProgress := func(result *entity.MachineCmdBatchResult) {
+		if !needSendMsg {
+			return
+		}
+		mutex.Lock()
+		progress := &cmdBatchProgressMsg{
+			BatchId:     batch.Id,
+			Title:       batch.Name,
+			MachineId:   result.MachineId,
+			MachineName: result.MachineName,
+			Status:      result.Status,
+			Output:      result.Output,
+			ErrorMsg:    result.ErrorMsg,
+			Total:       batch.Total,
+			Finished:    finished,
+		}
+		mutex.Unlock()
+		ws.SendJsonMsg(ws.UserId(la.Id), clientId, msgdto.InfoSysMsg(i18n.T(imsg.MsgCmdBatchProgress), progress).WithCategory(machineCmdBatchProgressCategory))
+	}
+
+	sem := make(chan struct{}, batch.Concurrency)
+	var wg sync.WaitGroup
+	for _, result := range results {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(result *entity.MachineCmdBatchResult) {
+			defer func() {
+				if err := recover(); err != nil {
+					logx.Errorf("machine cmd batch [%d] run on machine [%s] panic: %v", batch.Id, result.MachineName, err)
+					result.Status = entity.MachineCmdBatchResultStatusFail
+					result.ErrorMsg = fmt.Sprintf("%v", err)
+					m.saveResult(result)
+				}
+				<-sem
+				wg.Done()
+			}()
+
+			now := time.Now()
+			result.StartTime = &now
+			result.Status = entity.MachineCmdBatchResultStatusRunning
+			m.saveResult(result)
+			sendProgress(result)
+
+			m.runOnMachine(ctx, batch, cmd, result)
+			m.saveResult(result)
+
+			mutex.Lock()
+			finished++
+			if result.Status == entity.MachineCmdBatchResultStatusSuccess {
+				successNum++
+			} else {
+				failNum++
+			}
+			mutex.Unlock()
+			sendProgress(result)
+		}(result)
+	}
+	wg.Wait()
+
+	endTime := time.Now()
+	update := &entity.MachineCmdBatch{
+		Status:     entity.MachineCmdBatchStatusDone,
+		SuccessNum: successNum,
+		FailNum:    failNum,
+		EndTime:    &endTime,
+	}
+	update.Id = batch.Id
+	if err := m.UpdateById(context.Background(), update); err != nil {
+		logx.Errorf("failed to update machine cmd batch [%d]: %s", batch.Id, err.Error())
+	}
+
+	if needSendMsg {
+		ws.SendJsonMsg(ws.UserId(la.Id), clientId, msgdto.InfoSysMsg(i18n.T(imsg.MsgCmdBatchProgress), &cmdBatchProgressMsg{
+			BatchId:    batch.Id,
+			Title:      batch.Name,
+			Total:      batch.Total,
+			Finished:   finished,
+			Terminated: true,
+		}).WithCategory(machineCmdBatchProgressCategory))
+
+		content := i18n.T(imsg.MsgCmdBatchFinished, "name", batch.Name, "total", batch.Total, "success", successNum, "fail", failNum)
+		sysMsg := msgdto.SuccessSysMsg(i18n.T(imsg.MsgCmdBatchProgress), content)
+		if failNum > 0 {
+			sysMsg = msgdto.ErrSysMsg(i18n.T(imsg.MsgCmdBatchProgress), content)
+		}
+		m.msgApp.CreateAndSend(la, sysMsg.WithClientId(clientId))
+	}
+}
+
+// runOnMachine 在单台机器上执行命令，并将执行结果填充至result
+func (m *machineCmdBatchAppImpl) runOnMachine(ctx context.Context, batch *entity.MachineCmdBatch, cmd string, result *entity.MachineCmdBatchResult) {
+	defer func() {
+		now := time.Now()
+		result.EndTime = &now
+	}()
+
+	// 校验机器命令配置，命中则拒绝在该机器上执行
+	tagPaths := m.tagApp.ListTagPathByTypeAndCode(int8(tagentity.TagTypeMachine), result.MachineCode)
+	if err := m.machineCmdConfApp.CheckCmd(ctx, cmd, tagPaths...); err != nil {
+		result.Status = entity.MachineCmdBatchResultStatusRejected
+		result.ErrorMsg = stringx.Truncate(err.Error(), 1000, 900, "...")
+		return
+	}
+
+	cli, err := m.machineApp.GetCli(ctx, result.MachineId)
+	if err != nil {
+		result.Status = entity.MachineCmdBatchResultStatusFail
+		result.ErrorMsg = stringx.Truncate(err.Error(), 1000, 900, "...")
+		return
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, time.Duration(batch.Timeout)*time.Second)
+	defer cancel()
+	output, err := cli.RunWithContext(runCtx, cmd)
+	fillCmdBatchResult(result, output, err, batch.Timeout)
+}
+
+// fillCmdBatchResult 根据命令输出及执行错误填充执行结果，输出过长时仅保留末尾部分
+func fillCmdBatchResult(result *entity.MachineCmdBatchResult, output string, err error, timeout int) {
+	if len(output) > machineCmdBatchMaxOutputLen {
+		output = strings.ToValidUTF8(output[len(output)-machineCmdBatchMaxOutputLen:], "")
+	}
+	result.Output = output
+
+	if err == nil {
+		result.Status = entity.MachineCmdBatchResultStatusSuccess
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		result.Status = entity.MachineCmdBatchResultStatusTimeout
+		result.ErrorMsg = fmt.Sprintf("execution timed out after %d seconds", timeout)
+		return
+	}
+	result.Status = entity.MachineCmdBatchResultStatusFail
+	result.ErrorMsg = stringx.Truncate(err.Error(), 1000, 900, "...")
+}
+
+func (m *machineCmdBatchAppImpl) saveResult(result *entity.MachineCmdBatchResult) {
+	if err := m.machineCmdBatchResultRepo.UpdateById(context.Background(), result); err != nil {
+		logx.Errorf("failed to save machine cmd batch result [%d]: %s", result.Id, err.Error())
+	}
+}
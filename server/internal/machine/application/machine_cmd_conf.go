@@ -5,17 +5,34 @@ import (
 	"mayfly-go/internal/machine/application/dto"
 	"mayfly-go/internal/machine/domain/entity"
 	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/internal/machine/imsg"
+	"mayfly-go/internal/machine/mcm"
 	tagapp "mayfly-go/internal/tag/application"
 	tagentity "mayfly-go/internal/tag/domain/entity"
 	"mayfly-go/pkg/base"
 	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/i18n"
 	"mayfly-go/pkg/logx"
-	"regexp"
+	"mayfly-go/pkg/utils/shellx"
 )
 
 type MachineCmd struct {
-	CmdRegexp *regexp.Regexp // 命令正则表达式
-	Stratege  string         // 策略（拒绝或审批等）
+	Matcher  *mcm.CmdMatcher // 命令匹配器
+	Stratege string          // 策略（拒绝或审批等）
+}
+
+// MatchMachineCmd 返回命令行命中的首个机器命令配置，未命中则返回nil
+func MatchMachineCmd(cmdline string, cmdConfs ...*MachineCmd) *MachineCmd {
+	if len(cmdConfs) == 0 {
+		return nil
+	}
+	cmds := shellx.ParseCommands(cmdline)
+	for _, cmdConf := range cmdConfs {
+		if cmdConf.Matcher.MatchCommands(cmdline, cmds) {
+			return cmdConf
+		}
+	}
+	return nil
 }
 
 type MachineCmdConf interface {
@@ -26,6 +43,9 @@ type MachineCmdConf interface {
 	DeleteCmdConf(ctx context.Context, id uint64) error
 
 	GetCmdConfsByMachineTags(ctx context.Context, tagPaths ...string) []*MachineCmd
+
+	// CheckCmd 校验脚本、计划任务等非交互方式执行的命令，命中任一命令配置(包括需审批的)则返回错误
+	CheckCmd(ctx context.Context, cmd string, tagPaths ...string) error
 }
 
 type machineCmdConfAppImpl struct {
@@ -38,6 +58,11 @@ var _ (MachineCmdConf) = (*machineCmdConfAppImpl)(nil)
 
 func (m *machineCmdConfAppImpl) SaveCmdConf(ctx context.Context, cmdConfParam *dto.SaveMachineCmdConf) error {
 	cmdConf := cmdConfParam.CmdConf
+	for _, cmd := range cmdConf.Cmds {
+		if _, err := mcm.NewCmdMatcher(cmd); err != nil {
+			return errorx.NewBiz("invalid command rule [%s]: %s", cmd, err.Error())
+		}
+	}
 
 	return m.Tx(ctx, func(ctx context.Context) error {
 		return m.Save(ctx, cmdConf)
@@ -76,12 +101,19 @@ func (m *machineCmdConfAppImpl) GetCmdConfsByMachineTags(ctx context.Context, ta
 	cmdConfs, _ := m.GetByIds(cmdConfIds)
 	for _, cmdConf := range cmdConfs {
 		for _, cmd := range cmdConf.Cmds {
-			if p, err := regexp.Compile(cmd); err != nil {
-				logx.Errorf("cmd config [%s], regex compilation failed", cmd)
+			if matcher, err := mcm.NewCmdMatcher(cmd); err != nil {
+				logx.Errorf("cmd config [%s], rule compilation failed: %s", cmd, err.Error())
 			} else {
-				cmds = append(cmds, &MachineCmd{CmdRegexp: p})
+				cmds = append(cmds, &MachineCmd{Matcher: matcher, Stratege: cmdConf.Stratege})
 			}
 		}
 	}
 	return cmds
 }
+
+func (m *machineCmdConfAppImpl) CheckCmd(ctx context.Context, cmd string, tagPaths ...string) error {
+	if cmdConf := MatchMachineCmd(cmd, m.GetCmdConfsByMachineTags(ctx, tagPaths...)...); cmdConf != nil {
+		return errorx.NewBiz("%s: %s", i18n.TC(ctx, imsg.TerminalCmdDisable), cmdConf.Matcher.Rule)
+	}
+	return nil
+}
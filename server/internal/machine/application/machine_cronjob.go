@@ -2,9 +2,13 @@ package application
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"mayfly-go/internal/machine/application/dto"
 	"mayfly-go/internal/machine/domain/entity"
 	"mayfly-go/internal/machine/domain/repository"
+	msgapp "mayfly-go/internal/msg/application"
+	"mayfly-go/internal/msg/msgx"
 	tagapp "mayfly-go/internal/tag/application"
 	tagentity "mayfly-go/internal/tag/domain/entity"
 	"mayfly-go/pkg/base"
@@ -15,6 +19,7 @@ import (
 	"mayfly-go/pkg/scheduler"
 	"mayfly-go/pkg/utils/collx"
 	"mayfly-go/pkg/utils/stringx"
+	"sync"
 	"time"
 )
 
@@ -44,9 +49,25 @@ type machineCronJobAppImpl struct {
 
 	machineCronJobExecRepo repository.MachineCronJobExec `inject:"T"`
 	machineApp             Machine                       `inject:"T"`
+	machineCmdConfApp      MachineCmdConf                `inject:"T"`
 
 	tagTreeApp       tagapp.TagTree       `inject:"T"`
 	tagTreeRelateApp tagapp.TagTreeRelate `inject:"T"`
+	msgTmplApp       msgapp.MsgTmpl       `inject:"T"`
+
+	runnings   sync.Map // cronJobId:machineId -> *cronJobRun，执行中的任务
+	lastFailed sync.Map // cronJobId:machineId -> bool，上次执行是否失败，用于发送恢复通知
+}
+
+const (
+	machineCronJobMaxRetryCount        = 10
+	machineCronJobDefaultRetryInterval = 10 // 秒
+	machineCronJobMaxRetryBackoff      = 10 * time.Minute
+)
+
+// cronJobRun 执行中的任务，用于替换执行时终止上次执行
+type cronJobRun struct {
+	cancel context.CancelFunc
 }
 
 var _ (MachineCronJob) = (*machineCronJobAppImpl)(nil)
@@ -76,8 +97,23 @@ func (m *machineCronJobAppImpl) SaveMachineCronJob(ctx context.Context, param *d
 		mcj.Key = oldMcj.Key
 	}
 
+	if mcj.RetryCount < 0 || mcj.RetryCount > machineCronJobMaxRetryCount {
+		return errorx.NewBiz("the retry count must be between 0 and %d", machineCronJobMaxRetryCount)
+	}
+	if mcj.Timeout < 0 || mcj.RetryInterval < 0 {
+		return errorx.NewBiz("the timeout and retry interval cannot be negative")
+	}
+	if mcj.ConcurrencyPolicy == 0 {
+		mcj.ConcurrencyPolicy = entity.MachineCronJobConcurrencyAllow
+	}
+
 	err := m.Tx(ctx, func(ctx context.Context) error {
-		return m.Save(ctx, mcj)
+		if mcj.Id == 0 {
+			return m.Insert(ctx, mcj)
+		}
+		// 指定更新列，以便可将超时、重试等配置更新为零值
+		return m.GetRepo().UpdateById(ctx, mcj, "name", "cron", "script", "status", "remark", "save_exec_res_type",
+			"timeout", "retry_count", "retry_interval", "concurrency_policy", "msg_tmpl_id", "receiver_ids")
 	}, func(ctx context.Context) error {
 		return m.tagTreeRelateApp.RelateTag(ctx, tagentity.TagRelateTypeMachineCronJob, mcj.Id, param.CodePaths...)
 	})
@@ -156,42 +192,157 @@ func (m *machineCronJobAppImpl) addCronJob(mcj *entity.MachineCronJob) {
 }
 
 func (m *machineCronJobAppImpl) runCronJob0(mid uint64, cronJob *entity.MachineCronJob) {
+	runKey := fmt.Sprintf("%d:%d", cronJob.Id, mid)
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	// 根据并发策略处理同一机器上次执行未结束的情况
+	run := &cronJobRun{cancel: cancelFunc}
+	switch cronJob.ConcurrencyPolicy {
+	case entity.MachineCronJobConcurrencySkip:
+		if _, loaded := m.runnings.LoadOrStore(runKey, run); loaded {
+			logx.Warnf("machine[%d] cronjob[%s] is still running, skip this execution", mid, cronJob.Name)
+			return
+		}
+	case entity.MachineCronJobConcurrencyReplace:
+		if last, loaded := m.runnings.Swap(runKey, run); loaded {
+			logx.Warnf("machine[%d] cronjob[%s] is still running, terminate it and start a new execution", mid, cronJob.Name)
+			last.(*cronJobRun).cancel()
+		}
+	default:
+		m.runnings.Store(runKey, run)
+	}
+	defer m.runnings.CompareAndDelete(runKey, run)
+
 	execRes := &entity.MachineCronJobExec{
 		CronJobId: cronJob.Id,
 		ExecTime:  time.Now(),
 	}
 
-	ctx, cancelFunc := context.WithCancel(context.Background())
-	defer cancelFunc()
-	machineCli, err := m.machineApp.GetCli(ctx, mid)
 	res := ""
-	if err != nil {
-		machine, _ := m.machineApp.GetById(mid)
-		execRes.MachineCode = machine.Code
-	} else {
-		execRes.MachineCode = machineCli.Info.Code
-		res, err = machineCli.Run(cronJob.Script)
-		if err != nil {
-			if res == "" {
-				res = err.Error()
+	var err error
+	for attempt := 0; attempt <= cronJob.RetryCount; attempt++ {
+		if attempt > 0 {
+			backoff := retryBackoff(cronJob.RetryInterval, attempt)
+			logx.Warnf("machine[%d] cronjob[%s] failed, retry after %s (%d/%d)", mid, cronJob.Name, backoff, attempt, cronJob.RetryCount)
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+			}
+			// 被替换执行时不再重试
+			if ctx.Err() != nil {
+				break
 			}
-			logx.Errorf("machine[%d] failed to execute cronjob[%s]: %s", mid, cronJob.Name, res)
-		} else {
-			logx.Debugf("machine[%d] successfully executed cronjob[%s], execution result: %s", mid, cronJob.Name, res)
+		}
+
+		execRes.Attempts = attempt + 1
+		res, err = m.runOnce(ctx, mid, cronJob, execRes)
+		if err == nil || ctx.Err() != nil {
+			break
 		}
 	}
-	execRes.Res = res
 
-	if cronJob.SaveExecResType == entity.SaveExecResTypeNo ||
-		(cronJob.SaveExecResType == entity.SaveExecResTypeOnError && err == nil) {
-		return
+	if err != nil {
+		if res == "" {
+			res = err.Error()
+		}
+		logx.Errorf("machine[%d] failed to execute cronjob[%s]: %s", mid, cronJob.Name, res)
+	} else {
+		logx.Debugf("machine[%d] successfully executed cronjob[%s], execution result: %s", mid, cronJob.Name, res)
 	}
+	execRes.Res = res
 
-	if err == nil {
+	switch {
+	case err == nil:
 		execRes.Status = entity.MachineCronJobExecStatusSuccess
-	} else {
+	case errors.Is(ctx.Err(), context.Canceled):
+		// 按替换策略被新的执行终止，不视为失败
+		execRes.Status = entity.MachineCronJobExecStatusCancel
+		execRes.Res = fmt.Sprintf("%s\nexecution cancelled by a new execution", res)
+	case errors.Is(err, context.DeadlineExceeded):
+		execRes.Status = entity.MachineCronJobExecStatusTimeout
+	default:
 		execRes.Status = entity.MachineCronJobExecStatusError
 	}
+	if execRes.Status != entity.MachineCronJobExecStatusCancel {
+		m.notify(cronJob, execRes, runKey)
+	}
+
+	if cronJob.SaveExecResType == entity.SaveExecResTypeNo ||
+		(cronJob.SaveExecResType == entity.SaveExecResTypeOnError && err == nil) {
+		return
+	}
 	// 保存执行记录
 	m.machineCronJobExecRepo.Insert(context.TODO(), execRes)
 }
+
+// runOnce 在指定机器上执行一次任务脚本，任务配置了超时时间则超时后终止执行
+func (m *machineCronJobAppImpl) runOnce(ctx context.Context, mid uint64, cronJob *entity.MachineCronJob, execRes *entity.MachineCronJobExec) (string, error) {
+	machineCli, err := m.machineApp.GetCli(ctx, mid)
+	if err != nil {
+		if execRes.MachineCode == "" {
+			if machine, err := m.machineApp.GetById(mid); err == nil {
+				execRes.MachineCode = machine.Code
+			}
+		}
+		return "", err
+	}
+	execRes.MachineCode = machineCli.Info.Code
+
+	if err := m.machineCmdConfApp.CheckCmd(ctx, cronJob.Script, machineCli.Info.CodePath...); err != nil {
+		return "", err
+	}
+
+	if cronJob.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cronJob.Timeout)*time.Second)
+		defer cancel()
+	}
+	res, err := machineCli.RunWithContext(ctx, cronJob.Script)
+	if errors.Is(err, context.DeadlineExceeded) {
+		res = fmt.Sprintf("%s\nexecution timed out after %d seconds", res, cronJob.Timeout)
+	}
+	return res, err
+}
+
+// notify 任务在机器上执行失败或由失败恢复成功时，发送通知消息
+func (m *machineCronJobAppImpl) notify(cronJob *entity.MachineCronJob, execRes *entity.MachineCronJobExec, runKey string) {
+	failed := execRes.Status != entity.MachineCronJobExecStatusSuccess
+	lastFailed, _ := m.lastFailed.Swap(runKey, failed)
+	recovered := !failed && lastFailed == true
+	if cronJob.MsgTmplId == 0 || (!failed && !recovered) {
+		return
+	}
+
+	status := "failed"
+	if execRes.Status == entity.MachineCronJobExecStatusTimeout {
+		status = "timeout"
+	} else if recovered {
+		status = "recovered"
+	}
+	msg := &msgx.Msg{
+		Params: collx.M{
+			"cronJobName": cronJob.Name,
+			"machineCode": execRes.MachineCode,
+			"status":      status,
+			"attempts":    execRes.Attempts,
+			"execTime":    execRes.ExecTime.Format(time.DateTime),
+			"res":         stringx.Truncate(execRes.Res, 500, 200, "..."),
+		},
+	}
+	if err := m.msgTmplApp.SendSync(context.Background(), cronJob.MsgTmplId, msg, cronJob.ReceiverIds...); err != nil {
+		logx.Errorf("failed to send machine cronjob [%s] notification: %s", cronJob.Name, err.Error())
+	}
+}
+
+// retryBackoff 计算第attempt次重试前的等待时间，按指数递增且不超过最大值
+func retryBackoff(interval int, attempt int) time.Duration {
+	if interval <= 0 {
+		interval = machineCronJobDefaultRetryInterval
+	}
+	backoff := time.Duration(interval) * time.Second << (attempt - 1)
+	if backoff <= 0 || backoff > machineCronJobMaxRetryBackoff {
+		return machineCronJobMaxRetryBackoff
+	}
+	return backoff
+}
@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	fileapp "mayfly-go/internal/file/application"
 	"mayfly-go/internal/machine/application/dto"
 	"mayfly-go/internal/machine/config"
 	"mayfly-go/internal/machine/domain/entity"
 	"mayfly-go/internal/machine/domain/repository"
 	"mayfly-go/internal/machine/mcm"
+	tagapp "mayfly-go/internal/tag/application"
 	"mayfly-go/pkg/base"
 	"mayfly-go/pkg/contextx"
 	"mayfly-go/pkg/errorx"
@@ -22,7 +24,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/pkg/sftp"
 )
 
@@ -40,6 +44,9 @@ type MachineFile interface {
 	// 获取机器cli
 	GetMachineCli(ctx context.Context, authCertName string) (*mcm.Cli, error)
 
+	// 获取机器sftp cli
+	GetMachineSftpCli(ctx context.Context, opParam *dto.MachineFileOp) (*mcm.MachineInfo, *sftp.Client, error)
+
 	GetRdpFilePath(ua *model.LoginAccount, path string) string
 
 	/**  sftp 相关操作 **/
@@ -78,12 +85,60 @@ type MachineFile interface {
 	Mv(ctx context.Context, opParam *dto.MachineFileOp, toPath string, path ...string) (*mcm.MachineInfo, error)
 
 	Rename(ctx context.Context, opParam *dto.MachineFileOp, newname string) (*mcm.MachineInfo, error)
+
+	// InitChunkUpload 初始化分片上传，若存在相同文件未完成的上传则返回已上传的偏移量以便续传
+	InitChunkUpload(ctx context.Context, opParam *dto.MachineFileOp, filename string, size int64, fingerprint string) (*dto.MachineFileChunkUpload, error)
+
+	// UploadChunk 从指定偏移量写入分片数据，返回写入后的偏移量
+	UploadChunk(ctx context.Context, uploadId string, offset int64, reader io.Reader) (int64, error)
+
+	// CompleteChunkUpload 完成分片上传，校验文件大小后将临时文件重命名为目标文件
+	CompleteChunkUpload(ctx context.Context, uploadId string) (*mcm.MachineInfo, *dto.MachineFileChunkUpload, error)
+
+	// AbortChunkUpload 取消分片上传并删除已上传的临时文件
+	AbortChunkUpload(ctx context.Context, uploadId string) (*mcm.MachineInfo, error)
+
+	// WriteDirArchive 通过sftp将远程目录以tar.gz或zip格式流式写入w
+	WriteDirArchive(ctx context.Context, opParam *dto.MachineFileOp, format string, w io.Writer) (*mcm.MachineInfo, error)
+
+	// Chmod 修改文件权限，mode为八进制权限如755，recursive为true时通过chmod -R递归修改
+	Chmod(ctx context.Context, opParam *dto.MachineFileOp, mode string, recursive bool) (*mcm.MachineInfo, error)
+
+	// Chown 修改文件所属用户及组(名称或id，为空则不修改)，recursive为true时通过chown -R递归修改
+	Chown(ctx context.Context, opParam *dto.MachineFileOp, owner, group string, recursive bool) (*mcm.MachineInfo, error)
+
+	// Symlink 创建软链接，opParam.Path为软链接路径
+	Symlink(ctx context.Context, opParam *dto.MachineFileOp, target string) (*mcm.MachineInfo, error)
+
+	// Chtimes 修改文件访问及修改时间，recursive为true时递归修改目录下所有文件
+	Chtimes(ctx context.Context, opParam *dto.MachineFileOp, atime, mtime time.Time, recursive bool) (*mcm.MachineInfo, error)
+
+	// TailFile 类似tail -F持续跟踪远程文件，并将过滤后的新增行推送至websocket，直至连接关闭。非管理员仅可跟踪机器文件配置中的路径
+	TailFile(ctx context.Context, opParam *dto.MachineFileOp, param *dto.MachineFileTail, wsConn *websocket.Conn) (*mcm.MachineInfo, error)
+
+	// GetFileVersions 分页获取文件历史版本
+	GetFileVersions(ctx context.Context, condition *entity.MachineFileVersionQuery, orderBy ...string) (*model.PageResult[*entity.MachineFileVersion], error)
+
+	// GetFileVersionContent 获取历史版本的文件内容
+	GetFileVersionContent(ctx context.Context, opParam *dto.MachineFileOp, versionId uint64) (*entity.MachineFileVersion, string, error)
+
+	// DiffFileVersion 对比两个历史版本，toVersionId为0时与远程文件当前内容对比，返回unified diff
+	DiffFileVersion(ctx context.Context, opParam *dto.MachineFileOp, fromVersionId, toVersionId uint64) (string, error)
+
+	// DiffMachineFile 对比两台机器上的文件内容，返回unified diff
+	DiffMachineFile(ctx context.Context, from, to *dto.MachineFileOp) (string, error)
+
+	// RestoreFileVersion 将文件恢复至指定历史版本，恢复前会保存当前内容为新的历史版本
+	RestoreFileVersion(ctx context.Context, opParam *dto.MachineFileOp, versionId uint64) (*mcm.MachineInfo, *entity.MachineFileVersion, error)
 }
 
 type machineFileAppImpl struct {
 	base.AppImpl[*entity.MachineFile, repository.MachineFile]
 
-	machineApp Machine `inject:"T"`
+	machineApp             Machine                       `inject:"T"`
+	tagApp                 tagapp.TagTree                `inject:"T"`
+	fileApp                fileapp.File                  `inject:"T"`
+	machineFileVersionRepo repository.MachineFileVersion `inject:"T"`
 }
 
 var _ MachineFile = (*machineFileAppImpl)(nil)
@@ -283,16 +338,23 @@ func (m *machineFileAppImpl) WriteFileContent(ctx context.Context, opParam *dto.
 		return nil, err
 	}
 
+	// 写入前保存原文件内容为历史版本，保存失败则不写入以免原内容丢失
+	path = filepath.ToSlash(filepath.Clean(path))
+	if err := m.snapshotFile(ctx, mi, opParam.AuthCertName, sftpCli, path, content, "write"); err != nil {
+		return mi, err
+	}
+	return mi, writeRemoteFile(sftpCli, path, content)
+}
+
+func writeRemoteFile(sftpCli *sftp.Client, path string, content []byte) error {
 	f, err := sftpCli.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_TRUNC)
 	if err != nil {
-		return mi, err
+		return err
 	}
 
 	defer f.Close()
-	if _, err := f.Write(content); err != nil {
-		return mi, err
-	}
-	return mi, err
+	_, err = f.Write(content)
+	return err
 }
 
 // 上传文件
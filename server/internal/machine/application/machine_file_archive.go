@@ -0,0 +1,191 @@
+package application
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/fs"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/mcm"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"path"
+	"strings"
+
+	"github.com/pkg/sftp"
+)
+
+const (
+	ArchiveFormatTarGz = "tar.gz"
+	ArchiveFormatZip   = "zip"
+)
+
+// archiveEntryWriter 写入单个归档条目，linkTarget为软链接指向的路径
+type archiveEntryWriter func(name string, fi fs.FileInfo, linkTarget string, content io.Reader) error
+
+func (m *machineFileAppImpl) WriteDirArchive(ctx context.Context, opParam *dto.MachineFileOp, format string, w io.Writer) (*mcm.MachineInfo, error) {
+	if opParam.Protocol != entity.MachineProtocolSsh {
+		return nil, errorx.NewBiz("directory download only supports ssh machines")
+	}
+	if format == "" {
+		format = ArchiveFormatTarGz
+	}
+	if format != ArchiveFormatTarGz && format != ArchiveFormatZip {
+		return nil, errorx.NewBiz("unsupported archive format: %s", format)
+	}
+
+	mi, sftpCli, err := m.GetMachineSftpCli(ctx, opParam)
+	if err != nil {
+		return nil, err
+	}
+
+	root := path.Clean(opParam.Path)
+	fi, err := sftpCli.Stat(root)
+	if err != nil {
+		return mi, err
+	}
+	if !fi.IsDir() {
+		return mi, errorx.NewBiz("%s is not a directory", root)
+	}
+
+	// 以上校验通过后才开始写入数据
+	if format == ArchiveFormatZip {
+		zw := zip.NewWriter(w)
+		if err := walkDirArchive(ctx, sftpCli, root, zipEntryWriter(zw)); err != nil {
+			return mi, err
+		}
+		return mi, zw.Close()
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	if err := walkDirArchive(ctx, sftpCli, root, tarEntryWriter(tw)); err != nil {
+		return mi, err
+	}
+	if err := tw.Close(); err != nil {
+		return mi, err
+	}
+	return mi, gw.Close()
+}
+
+// ArchiveBaseName 目录归档内的根目录名称
+func ArchiveBaseName(dirPath string) string {
+	base := path.Base(path.Clean(dirPath))
+	if base == "/" || base == "." {
+		return "root"
+	}
+	return base
+}
+
+// walkDirArchive 通过sftp遍历远程目录，并逐个写入归档条目，不会在本服务落盘
+func walkDirArchive(ctx context.Context, sftpCli *sftp.Client, root string, writeEntry archiveEntryWriter) error {
+	base := ArchiveBaseName(root)
+	walker := sftpCli.Walk(root)
+	for walker.Step() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := walker.Err(); err != nil {
+			// 无权限等文件跳过，不中断整个归档
+			logx.Warnf("machine dir archive skip [%s]: %s", walker.Path(), err.Error())
+			continue
+		}
+
+		filePath := walker.Path()
+		fi := walker.Stat()
+		name := path.Join(base, strings.TrimPrefix(filePath, root))
+
+		switch {
+		case fi.IsDir():
+			if err := writeEntry(name+"/", fi, "", nil); err != nil {
+				return err
+			}
+		case fi.Mode()&fs.ModeSymlink != 0:
+			target, err := sftpCli.ReadLink(filePath)
+			if err != nil {
+				logx.Warnf("machine dir archive skip [%s]: %s", filePath, err.Error())
+				continue
+			}
+			if err := writeEntry(name, fi, target, nil); err != nil {
+				return err
+			}
+		case fi.Mode().IsRegular():
+			f, err := sftpCli.Open(filePath)
+			if err != nil {
+				logx.Warnf("machine dir archive skip [%s]: %s", filePath, err.Error())
+				continue
+			}
+			err = writeEntry(name, fi, "", f)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		}
+		// 设备、管道、socket等特殊文件不归档
+	}
+	return nil
+}
+
+func tarEntryWriter(tw *tar.Writer) archiveEntryWriter {
+	return func(name string, fi fs.FileInfo, linkTarget string, content io.Reader) error {
+		hdr, err := tar.FileInfoHeader(fi, linkTarget)
+		if err != nil {
+			return err
+		}
+		hdr.Name = name
+		if stat, ok := fi.Sys().(*sftp.FileStat); ok {
+			hdr.Uid = int(stat.UID)
+			hdr.Gid = int(stat.GID)
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if content == nil {
+			return nil
+		}
+		// 按header中的大小写入，避免归档过程中文件被修改导致大小不一致
+		n, err := io.Copy(tw, io.LimitReader(content, hdr.Size))
+		if err != nil {
+			return err
+		}
+		if n < hdr.Size {
+			_, err = io.CopyN(tw, zeroReader{}, hdr.Size-n)
+		}
+		return err
+	}
+}
+
+func zipEntryWriter(zw *zip.Writer) archiveEntryWriter {
+	return func(name string, fi fs.FileInfo, linkTarget string, content io.Reader) error {
+		hdr, err := zip.FileInfoHeader(fi)
+		if err != nil {
+			return err
+		}
+		hdr.Name = name
+		if fi.Mode().IsRegular() {
+			hdr.Method = zip.Deflate
+		}
+		ew, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		if linkTarget != "" {
+			_, err = io.WriteString(ew, linkTarget)
+			return err
+		}
+		if content != nil {
+			_, err = io.Copy(ew, content)
+		}
+		return err
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
@@ -0,0 +1,223 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/mcm"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/shellx"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+var (
+	fileModeRegexp  = regexp.MustCompile(`^[0-7]{3,4}$`)
+	ownerNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_.][a-zA-Z0-9_.-]*\$?$`)
+)
+
+func (m *machineFileAppImpl) Chmod(ctx context.Context, opParam *dto.MachineFileOp, mode string, recursive bool) (*mcm.MachineInfo, error) {
+	if !fileModeRegexp.MatchString(mode) {
+		return nil, errorx.NewBiz("invalid file mode: %s", mode)
+	}
+	filePath, err := checkAttrOpPath(opParam, recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	mcli, err := m.GetMachineCli(ctx, opParam.AuthCertName)
+	if err != nil {
+		return nil, err
+	}
+	mi := mcli.Info
+
+	if recursive {
+		// 递归修改时不跟随目录中的软链接
+		return mi, runAttrCmd(mcli, fmt.Sprintf("chmod -R %s -- %s", mode, shellx.Quote(filePath)))
+	}
+
+	sftpCli, err := mcli.GetSftpCli()
+	if err != nil {
+		return mi, err
+	}
+	perm, _ := strconv.ParseUint(mode, 8, 32)
+	return mi, sftpCli.Chmod(filePath, toFileMode(uint32(perm)))
+}
+
+func (m *machineFileAppImpl) Chown(ctx context.Context, opParam *dto.MachineFileOp, owner, group string, recursive bool) (*mcm.MachineInfo, error) {
+	if owner == "" && group == "" {
+		return nil, errorx.NewBiz("owner and group cannot both be empty")
+	}
+	for _, name := range []string{owner, group} {
+		if name != "" && !ownerNameRegexp.MatchString(name) {
+			return nil, errorx.NewBiz("invalid owner or group: %s", name)
+		}
+	}
+	filePath, err := checkAttrOpPath(opParam, recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	mcli, err := m.GetMachineCli(ctx, opParam.AuthCertName)
+	if err != nil {
+		return nil, err
+	}
+	mi := mcli.Info
+
+	if recursive {
+		ownerGroup := owner
+		if group != "" {
+			ownerGroup += ":" + group
+		}
+		// -h 修改软链接本身而非其指向的文件
+		return mi, runAttrCmd(mcli, fmt.Sprintf("chown -R -h %s -- %s", shellx.Quote(ownerGroup), shellx.Quote(filePath)))
+	}
+
+	sftpCli, err := mcli.GetSftpCli()
+	if err != nil {
+		return mi, err
+	}
+	fi, err := sftpCli.Lstat(filePath)
+	if err != nil {
+		return mi, err
+	}
+	// 仅修改用户或组时，另一项保持不变
+	uid, gid := -1, -1
+	if stat, ok := fi.Sys().(*sftp.FileStat); ok {
+		uid, gid = int(stat.UID), int(stat.GID)
+	}
+	if owner != "" {
+		if uid, err = resolveUid(mcli, owner); err != nil {
+			return mi, err
+		}
+	}
+	if group != "" {
+		if gid, err = resolveGid(mcli, group); err != nil {
+			return mi, err
+		}
+	}
+	if uid < 0 || gid < 0 {
+		return mi, errorx.NewBiz("failed to get the current owner of %s", filePath)
+	}
+	return mi, sftpCli.Chown(filePath, uid, gid)
+}
+
+func (m *machineFileAppImpl) Symlink(ctx context.Context, opParam *dto.MachineFileOp, target string) (*mcm.MachineInfo, error) {
+	if opParam.Protocol != entity.MachineProtocolSsh {
+		return nil, errorx.NewBiz("symlink only supports ssh machines")
+	}
+	if target == "" {
+		return nil, errorx.NewBiz("the link target cannot be empty")
+	}
+
+	mi, sftpCli, err := m.GetMachineSftpCli(ctx, opParam)
+	if err != nil {
+		return nil, err
+	}
+	return mi, sftpCli.Symlink(target, path.Clean(opParam.Path))
+}
+
+func (m *machineFileAppImpl) Chtimes(ctx context.Context, opParam *dto.MachineFileOp, atime, mtime time.Time, recursive bool) (*mcm.MachineInfo, error) {
+	filePath, err := checkAttrOpPath(opParam, recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	mcli, err := m.GetMachineCli(ctx, opParam.AuthCertName)
+	if err != nil {
+		return nil, err
+	}
+	mi := mcli.Info
+
+	if recursive {
+		// touch -t 为POSIX格式，统一使用UTC时区避免受远程机器时区影响；跳过软链接以免修改目录外的文件
+		touch := func(opt string, t time.Time) string {
+			return fmt.Sprintf("find %s ! -type l -exec env TZ=UTC touch -c %s -t %s {} +", shellx.Quote(filePath), opt, t.UTC().Format("200601021504.05"))
+		}
+		return mi, runAttrCmd(mcli, touch("-a", atime)+" && "+touch("-m", mtime))
+	}
+
+	sftpCli, err := mcli.GetSftpCli()
+	if err != nil {
+		return mi, err
+	}
+	return mi, sftpCli.Chtimes(filePath, atime, mtime)
+}
+
+// checkAttrOpPath 校验属性修改的文件路径，递归修改时要求为绝对路径且不能为根目录
+func checkAttrOpPath(opParam *dto.MachineFileOp, recursive bool) (string, error) {
+	if opParam.Protocol != entity.MachineProtocolSsh {
+		return "", errorx.NewBiz("file attribute modification only supports ssh machines")
+	}
+	filePath := path.Clean(opParam.Path)
+	if recursive && (!path.IsAbs(filePath) || filePath == "/") {
+		return "", errorx.NewBiz("recursive modification requires an absolute path other than /")
+	}
+	return filePath, nil
+}
+
+func runAttrCmd(mcli *mcm.Cli, cmd string) error {
+	if res, err := mcli.Run(cmd); err != nil {
+		if res = strings.TrimSpace(res); res == "" {
+			res = err.Error()
+		}
+		return errorx.NewBiz("%s", res)
+	}
+	return nil
+}
+
+// toFileMode 将包含setuid、setgid、sticky位的八进制权限转为os.FileMode
+func toFileMode(perm uint32) os.FileMode {
+	mode := os.FileMode(perm & 0o777)
+	if perm&0o4000 != 0 {
+		mode |= os.ModeSetuid
+	}
+	if perm&0o2000 != 0 {
+		mode |= os.ModeSetgid
+	}
+	if perm&0o1000 != 0 {
+		mode |= os.ModeSticky
+	}
+	return mode
+}
+
+// resolveUid 获取用户名对应的uid，数字则直接作为uid
+func resolveUid(mcli *mcm.Cli, owner string) (int, error) {
+	if uid, err := strconv.Atoi(owner); err == nil {
+		return uid, nil
+	}
+	users, err := mcli.GetUsers()
+	if err != nil {
+		return -1, err
+	}
+	for _, user := range users {
+		if user.Username == owner {
+			return int(user.UID), nil
+		}
+	}
+	return -1, errorx.NewBiz("user not found: %s", owner)
+}
+
+// resolveGid 获取组名对应的gid，数字则直接作为gid
+func resolveGid(mcli *mcm.Cli, group string) (int, error) {
+	if gid, err := strconv.Atoi(group); err == nil {
+		return gid, nil
+	}
+	groups, err := mcli.GetGroups()
+	if err != nil {
+		return -1, err
+	}
+	for _, g := range groups {
+		if g.Groupname == group {
+			return int(g.GID), nil
+		}
+	}
+	return -1, errorx.NewBiz("group not found: %s", group)
+}
@@ -0,0 +1,269 @@
+package application
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/mcm"
+	"mayfly-go/internal/pkg/consts"
+	"mayfly-go/pkg/contextx"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/shellx"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	fileTailDefaultLines  = 100
+	fileTailMaxLines      = 5000
+	fileTailMaxLineSize   = 64 * 1024 // 单行最大长度，超出部分截断
+	fileTailFlushInterval = 200 * time.Millisecond
+	fileTailMaxBatchLines = 500
+
+	fileTailMsgLines = "lines" // 新增的行
+	fileTailMsgInfo  = "info"  // tail提示信息，如文件已轮转、文件不可访问等
+	fileTailMsgError = "error" // 错误信息，如过滤规则有误
+)
+
+type fileTailMsg struct {
+	Type    string          `json:"type"`
+	Lines   []*fileTailLine `json:"lines,omitempty"`
+	Content string          `json:"content,omitempty"`
+}
+
+type fileTailLine struct {
+	Content  string            `json:"content"`
+	Segments []mcm.LineSegment `json:"segments,omitempty"` // 高亮片段，无命中内容则为空
+}
+
+// fileTailRule 行过滤及高亮规则
+type fileTailRule struct {
+	filter    *mcm.LineMatcher
+	highlight *mcm.LineMatcher
+}
+
+func newFileTailRule(param *dto.MachineFileTail) (*fileTailRule, error) {
+	filter, err := mcm.NewLineMatcher(param.Filter, param.Regexp, param.IgnoreCase)
+	if err != nil {
+		return nil, errorx.NewBiz("invalid filter: %s", err.Error())
+	}
+	rule := &fileTailRule{filter: filter, highlight: filter}
+	if param.Highlight != "" {
+		if rule.highlight, err = mcm.NewLineMatcher(param.Highlight, param.Regexp, param.IgnoreCase); err != nil {
+			return nil, errorx.NewBiz("invalid highlight: %s", err.Error())
+		}
+	}
+	return rule, nil
+}
+
+func (m *machineFileAppImpl) TailFile(ctx context.Context, opParam *dto.MachineFileOp, param *dto.MachineFileTail, wsConn *websocket.Conn) (*mcm.MachineInfo, error) {
+	if opParam.Protocol != entity.MachineProtocolSsh {
+		return nil, errorx.NewBiz("file tail only supports ssh machines")
+	}
+	la := contextx.GetLoginAccount(ctx)
+	if la == nil {
+		return nil, errorx.NewBiz("no login")
+	}
+	rule, err := newFileTailRule(param)
+	if err != nil {
+		return nil, err
+	}
+
+	cli, err := m.GetMachineCli(ctx, opParam.AuthCertName)
+	if err != nil {
+		return nil, err
+	}
+	mi := cli.Info
+	if err := m.tagApp.CanAccess(la.Id, mi.CodePath...); err != nil {
+		return mi, err
+	}
+
+	filePath := path.Clean(opParam.Path)
+	if la.Id != consts.AdminId {
+		if err := m.checkConfiguredPath(cli, filePath); err != nil {
+			return mi, err
+		}
+	}
+
+	lines := param.Lines
+	if lines <= 0 {
+		lines = fileTailDefaultLines
+	}
+
+	session, err := cli.GetSession()
+	if err != nil {
+		return mi, err
+	}
+	defer session.Close()
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return mi, err
+	}
+	stderr, err := session.StderrPipe()
+	if err != nil {
+		return mi, err
+	}
+	// -F 按文件名跟踪且文件不存在时持续重试，文件轮转(重命名或删除后重建)后会自动跟踪新文件
+	if err := session.Start(fmt.Sprintf("tail -n %d -F -- %s", min(lines, fileTailMaxLines), shellx.Quote(filePath))); err != nil {
+		return mi, err
+	}
+	defer session.Signal(ssh.SIGTERM)
+
+	tailCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var writeMutex sync.Mutex
+	send := func(msg *fileTailMsg) error {
+		writeMutex.Lock()
+		defer writeMutex.Unlock()
+		return wsConn.WriteJSON(msg)
+	}
+
+	var currentRule atomic.Pointer[fileTailRule]
+	currentRule.Store(rule)
+
+	// 读取客户端消息以动态修改过滤规则，连接关闭时结束跟踪
+	go func() {
+		defer cancel()
+		for {
+			_, data, err := wsConn.ReadMessage()
+			if err != nil {
+				return
+			}
+			ctrl := new(dto.MachineFileTail)
+			if err := json.Unmarshal(data, ctrl); err != nil {
+				continue
+			}
+			newRule, err := newFileTailRule(ctrl)
+			if err != nil {
+				send(&fileTailMsg{Type: fileTailMsgError, Content: err.Error()})
+				continue
+			}
+			currentRule.Store(newRule)
+		}
+	}()
+
+	go readTailLines(stderr, func(line string) {
+		send(&fileTailMsg{Type: fileTailMsgInfo, Content: line})
+	})
+
+	lineCh := make(chan string, 1024)
+	go func() {
+		defer close(lineCh)
+		readTailLines(stdout, func(line string) {
+			select {
+			case lineCh <- line:
+			case <-tailCtx.Done():
+			}
+		})
+	}()
+
+	ticker := time.NewTicker(fileTailFlushInterval)
+	defer ticker.Stop()
+	var batch []*fileTailLine
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := send(&fileTailMsg{Type: fileTailMsgLines, Lines: batch})
+		batch = nil
+		return err
+	}
+
+	for {
+		select {
+		case <-tailCtx.Done():
+			return mi, nil
+		case line, ok := <-lineCh:
+			if !ok {
+				flush()
+				if err := session.Wait(); err != nil {
+					return mi, errorx.NewBiz("the tail process exited: %s", err.Error())
+				}
+				return mi, nil
+			}
+			r := currentRule.Load()
+			if !r.filter.Match(line) {
+				continue
+			}
+			batch = append(batch, &fileTailLine{Content: line, Segments: r.highlight.Segments(line)})
+			if len(batch) >= fileTailMaxBatchLines {
+				if err := flush(); err != nil {
+					return mi, nil
+				}
+			}
+		case <-ticker.C:
+			if err := flush(); err != nil {
+				return mi, nil
+			}
+		}
+	}
+}
+
+// checkConfiguredPath 校验文件路径是否位于机器文件配置的目录下或为配置的文件，软链接会校验其实际路径
+func (m *machineFileAppImpl) checkConfiguredPath(cli *mcm.Cli, filePath string) error {
+	machineFiles, err := m.ListByCond(model.NewModelCond(&entity.MachineFile{MachineId: cli.Info.Id}).Columns("type", "path"))
+	if err != nil {
+		return err
+	}
+
+	paths := []string{filePath}
+	if sftpCli, err := cli.GetSftpCli(); err == nil {
+		if realPath, err := sftpCli.RealPath(filePath); err == nil && realPath != filePath {
+			paths = append(paths, realPath)
+		}
+	}
+
+	for _, p := range paths {
+		allowed := false
+		for _, mf := range machineFiles {
+			if isPathInMachineFile(mf, p) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return errorx.NewBiz("%s is not in the configured machine file directories", p)
+		}
+	}
+	return nil
+}
+
+func isPathInMachineFile(mf *entity.MachineFile, filePath string) bool {
+	mfPath := path.Clean(mf.Path)
+	if filePath == mfPath {
+		return true
+	}
+	return mf.Type == entity.MachineFileTypeDir && strings.HasPrefix(filePath, strings.TrimSuffix(mfPath, "/")+"/")
+}
+
+// readTailLines 按行读取，超出最大长度的部分会被截断
+func readTailLines(r io.Reader, fn func(line string)) error {
+	br := bufio.NewReader(r)
+	var buf []byte
+	for {
+		part, isPrefix, err := br.ReadLine()
+		if err != nil {
+			return err
+		}
+		if len(buf) < fileTailMaxLineSize {
+			buf = append(buf, part[:min(len(part), fileTailMaxLineSize-len(buf))]...)
+		}
+		if isPrefix {
+			continue
+		}
+		fn(strings.ToValidUTF8(string(buf), ""))
+		buf = buf[:0]
+	}
+}
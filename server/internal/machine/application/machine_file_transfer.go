@@ -0,0 +1,383 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/internal/machine/imsg"
+	"mayfly-go/internal/machine/mcm"
+	msgapp "mayfly-go/internal/msg/application"
+	msgdto "mayfly-go/internal/msg/application/dto"
+	tagapp "mayfly-go/internal/tag/application"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/contextx"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/i18n"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/shellx"
+	"mayfly-go/pkg/utils/stringx"
+	"mayfly-go/pkg/ws"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+const (
+	machineFileTransferProgressCategory = "machineFileTransferProgress"
+	machineFileTransferProgressInterval = 2 * time.Second
+)
+
+type MachineFileTransfer interface {
+	base.App[*entity.MachineFileTransfer]
+
+	GetPageList(condition *entity.MachineFileTransferQuery, orderBy ...string) (*model.PageResult[*entity.MachineFileTransfer], error)
+
+	// Start 通过两台机器的sftp连接直接传输文件(数据经由本服务中转但不落盘)，异步执行并通过websocket推送传输进度
+	Start(ctx context.Context, param *dto.MachineFileTransferStart) (*entity.MachineFileTransfer, error)
+
+	// Cancel 取消传输中的任务
+	Cancel(ctx context.Context, id uint64) error
+}
+
+type machineFileTransferAppImpl struct {
+	base.AppImpl[*entity.MachineFileTransfer, repository.MachineFileTransfer]
+
+	machineFileApp MachineFile    `inject:"T"`
+	tagApp         tagapp.TagTree `inject:"T"`
+	msgApp         msgapp.Msg     `inject:"T"`
+}
+
+var _ (MachineFileTransfer) = (*machineFileTransferAppImpl)(nil)
+
+// 传输中任务的取消函数 transferId -> context.CancelFunc
+var fileTransferCancels sync.Map
+
+type fileTransferProgressMsg struct {
+	TransferId  uint64 `json:"transferId"`
+	SrcPath     string `json:"srcPath"`
+	DstPath     string `json:"dstPath"`
+	Size        int64  `json:"size"`
+	Transferred int64  `json:"transferred"`
+	Status      int8   `json:"status"`
+	ErrorMsg    string `json:"errorMsg"`
+	Terminated  bool   `json:"terminated"`
+}
+
+// fileTransferEnd 传输结束信息
+type fileTransferEnd struct {
+	checksum string
+	err      error
+}
+
+func (m *machineFileTransferAppImpl) GetPageList(condition *entity.MachineFileTransferQuery, orderBy ...string) (*model.PageResult[*entity.MachineFileTransfer], error) {
+	return m.GetRepo().GetPageList(condition, orderBy...)
+}
+
+func (m *machineFileTransferAppImpl) Start(ctx context.Context, param *dto.MachineFileTransferStart) (*entity.MachineFileTransfer, error) {
+	la := contextx.GetLoginAccount(ctx)
+	if la == nil {
+		return nil, errorx.NewBiz("login account not found")
+	}
+	if param.Src.Protocol != entity.MachineProtocolSsh || param.Dst.Protocol != entity.MachineProtocolSsh {
+		return nil, errorx.NewBiz("file transfer only supports ssh machines")
+	}
+
+	srcMi, srcSftp, err := m.getAccessibleSftpCli(ctx, la.Id, param.Src)
+	if err != nil {
+		return nil, err
+	}
+	dstMi, dstSftp, err := m.getAccessibleSftpCli(ctx, la.Id, param.Dst)
+	if err != nil {
+		return nil, err
+	}
+
+	srcPath := path.Clean(param.Src.Path)
+	srcFi, err := srcSftp.Stat(srcPath)
+	if err != nil {
+		return nil, errorx.NewBiz("failed to stat the source file: %s", err.Error())
+	}
+	if !srcFi.Mode().IsRegular() {
+		return nil, errorx.NewBiz("%s is not a regular file, please archive the directory before transferring", srcPath)
+	}
+
+	dstPath, err := resolveTransferDstPath(dstSftp, path.Clean(param.Dst.Path), path.Base(srcPath), param.Overwrite)
+	if err != nil {
+		return nil, err
+	}
+	if srcMi.Id == dstMi.Id && srcPath == dstPath {
+		return nil, errorx.NewBiz("the source and destination files cannot be the same")
+	}
+
+	transfer := &entity.MachineFileTransfer{
+		SrcMachineId:    srcMi.Id,
+		SrcMachineName:  srcMi.Name,
+		SrcAuthCertName: srcMi.AuthCertName,
+		SrcPath:         srcPath,
+		DstMachineId:    dstMi.Id,
+		DstMachineName:  dstMi.Name,
+		DstAuthCertName: dstMi.AuthCertName,
+		DstPath:         dstPath,
+		Size:            srcFi.Size(),
+		Status:          entity.MachineFileTransferStatusRunning,
+	}
+	if err := m.Insert(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	transferCtx, cancel := context.WithCancel(contextx.NewLoginAccount(la))
+	fileTransferCancels.Store(transfer.Id, cancel)
+	go m.doTransfer(transferCtx, transfer, param.ClientId)
+	return transfer, nil
+}
+
+func (m *machineFileTransferAppImpl) Cancel(ctx context.Context, id uint64) error {
+	transfer, err := m.GetById(id)
+	if err != nil {
+		return errorx.NewBiz("transfer not found")
+	}
+	if transfer.Status != entity.MachineFileTransferStatusRunning {
+		return errorx.NewBiz("the transfer is not running")
+	}
+
+	if cancel, ok := fileTransferCancels.Load(id); ok {
+		cancel.(context.CancelFunc)()
+		return nil
+	}
+
+	// 不存在于当前服务中(如服务已重启)的传输任务，直接标记为已取消
+	now := time.Now()
+	transfer.Status = entity.MachineFileTransferStatusCancelled
+	transfer.EndTime = &now
+	return m.GetRepo().UpdateById(ctx, transfer, "Status", "EndTime")
+}
+
+// getAccessibleSftpCli 获取机器sftp连接，并校验当前账号是否有该机器的标签权限
+func (m *machineFileTransferAppImpl) getAccessibleSftpCli(ctx context.Context, accountId uint64, opParam *dto.MachineFileOp) (*mcm.MachineInfo, *sftp.Client, error) {
+	mi, sftpCli, err := m.machineFileApp.GetMachineSftpCli(ctx, opParam)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := m.tagApp.CanAccess(accountId, mi.CodePath...); err != nil {
+		return nil, nil, err
+	}
+	return mi, sftpCli, nil
+}
+
+// resolveTransferDstPath 解析目标文件路径，目标路径为已存在的目录时传输至该目录下的同名文件
+func resolveTransferDstPath(sftpCli *sftp.Client, dstPath, srcName string, overwrite bool) (string, error) {
+	fi, err := sftpCli.Stat(dstPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	if err == nil && fi.IsDir() {
+		dstPath = path.Join(dstPath, srcName)
+		if fi, err = sftpCli.Stat(dstPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+	if err != nil {
+		// 目标文件不存在
+		return dstPath, nil
+	}
+	if fi.IsDir() {
+		return "", errorx.NewBiz("%s is a directory", dstPath)
+	}
+	if !overwrite {
+		return "", errorx.NewBiz("%s already exists", dstPath)
+	}
+	return dstPath, nil
+}
+
+func (m *machineFileTransferAppImpl) doTransfer(ctx context.Context, transfer *entity.MachineFileTransfer, clientId string) {
+	la := contextx.GetLoginAccount(ctx)
+	var transferred atomic.Int64
+
+	sendProgress := func(status int8, errMsg string, terminated bool) {
+		if clientId == "" {
+			return
+		}
+		ws.SendJsonMsg(ws.UserId(la.Id), clientId, msgdto.InfoSysMsg(i18n.T(imsg.MsgFileTransfer), &fileTransferProgressMsg{
+			TransferId:  transfer.Id,
+			SrcPath:     fmt.Sprintf("%s:%s", transfer.SrcMachineName, transfer.SrcPath),
+			DstPath:     fmt.Sprintf("%s:%s", transfer.DstMachineName, transfer.DstPath),
+			Size:        transfer.Size,
+			Transferred: transferred.Load(),
+			Status:      status,
+			ErrorMsg:    errMsg,
+			Terminated:  terminated,
+		}).WithCategory(machineFileTransferProgressCategory))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(machineFileTransferProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				update := &entity.MachineFileTransfer{Transferred: transferred.Load()}
+				update.Id = transfer.Id
+				if err := m.UpdateById(context.Background(), update); err != nil {
+					logx.Errorf("failed to update machine file transfer [%d] progress: %s", transfer.Id, err.Error())
+				}
+				sendProgress(entity.MachineFileTransferStatusRunning, "", false)
+			}
+		}
+	}()
+
+	end := m.transfer(ctx, transfer, &transferred)
+	close(done)
+	fileTransferCancels.Delete(transfer.Id)
+
+	now := time.Now()
+	transfer.Transferred = transferred.Load()
+	transfer.Checksum = end.checksum
+	transfer.EndTime = &now
+	switch {
+	case end.err == nil:
+		transfer.Status = entity.MachineFileTransferStatusSuccess
+	case ctx.Err() != nil:
+		transfer.Status = entity.MachineFileTransferStatusCancelled
+	default:
+		transfer.Status = entity.MachineFileTransferStatusFail
+		transfer.ErrorMsg = stringx.Truncate(end.err.Error(), 1000, 900, "...")
+	}
+	if err := m.GetRepo().UpdateById(context.Background(), transfer, "Transferred", "Checksum", "Status", "ErrorMsg", "EndTime"); err != nil {
+		logx.Errorf("failed to update machine file transfer [%d]: %s", transfer.Id, err.Error())
+	}
+
+	sendProgress(transfer.Status, transfer.ErrorMsg, true)
+	if transfer.Status == entity.MachineFileTransferStatusCancelled {
+		return
+	}
+
+	src := fmt.Sprintf("%s:%s", transfer.SrcMachineName, transfer.SrcPath)
+	dst := fmt.Sprintf("%s:%s", transfer.DstMachineName, transfer.DstPath)
+	sysMsg := msgdto.SuccessSysMsg(i18n.T(imsg.MsgFileTransfer), i18n.T(imsg.MsgFileTransferSuccess, "src", src, "dst", dst, "checksum", transfer.Checksum))
+	if transfer.Status == entity.MachineFileTransferStatusFail {
+		sysMsg = msgdto.ErrSysMsg(i18n.T(imsg.MsgFileTransfer), i18n.T(imsg.MsgFileTransferFail, "src", src, "dst", dst, "err", transfer.ErrorMsg))
+	}
+	m.msgApp.CreateAndSend(la, sysMsg.WithClientId(clientId))
+}
+
+// transfer 将源文件流式写入目标机器的临时文件，校验sha256一致后再重命名为目标文件
+func (m *machineFileTransferAppImpl) transfer(ctx context.Context, transfer *entity.MachineFileTransfer, transferred *atomic.Int64) (end fileTransferEnd) {
+	defer func() {
+		if err := recover(); err != nil {
+			logx.Errorf("machine file transfer [%d] panic: %v", transfer.Id, err)
+			end.err = fmt.Errorf("%v", err)
+		}
+	}()
+
+	srcOp := &dto.MachineFileOp{MachineId: transfer.SrcMachineId, Protocol: entity.MachineProtocolSsh, AuthCertName: transfer.SrcAuthCertName}
+	_, srcSftp, err := m.machineFileApp.GetMachineSftpCli(ctx, srcOp)
+	if err != nil {
+		return fileTransferEnd{err: err}
+	}
+	dstOp := &dto.MachineFileOp{MachineId: transfer.DstMachineId, Protocol: entity.MachineProtocolSsh, AuthCertName: transfer.DstAuthCertName}
+	_, dstSftp, err := m.machineFileApp.GetMachineSftpCli(ctx, dstOp)
+	if err != nil {
+		return fileTransferEnd{err: err}
+	}
+
+	srcFile, err := srcSftp.Open(transfer.SrcPath)
+	if err != nil {
+		return fileTransferEnd{err: err}
+	}
+	defer srcFile.Close()
+
+	dir, name := path.Split(transfer.DstPath)
+	tmpPath := path.Join(dir, fmt.Sprintf(".%s.transfer-%d.part", name, transfer.Id))
+	dstFile, err := dstSftp.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return fileTransferEnd{err: err}
+	}
+	// 传输失败或取消时清理临时文件
+	defer func() {
+		if end.err != nil {
+			dstFile.Close()
+			dstSftp.Remove(tmpPath)
+		}
+	}()
+
+	hash := sha256.New()
+	reader := &transferReader{ctx: ctx, reader: io.TeeReader(srcFile, hash), transferred: transferred}
+	if _, err := io.Copy(dstFile, reader); err != nil {
+		return fileTransferEnd{err: err}
+	}
+	if err := dstFile.Close(); err != nil {
+		return fileTransferEnd{err: err}
+	}
+
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	dstChecksum, err := m.remoteSha256(ctx, dstOp, dstSftp, tmpPath)
+	if err != nil {
+		return fileTransferEnd{err: fmt.Errorf("failed to calculate the checksum of the destination file: %w", err)}
+	}
+	if dstChecksum != checksum {
+		return fileTransferEnd{err: fmt.Errorf("checksum mismatch, source: %s, destination: %s", checksum, dstChecksum)}
+	}
+
+	if err := replaceFile(dstSftp, tmpPath, transfer.DstPath); err != nil {
+		return fileTransferEnd{err: err}
+	}
+	return fileTransferEnd{checksum: checksum}
+}
+
+// remoteSha256 计算远程文件sha256，优先在远程机器执行sha256sum，不可用时回读文件计算
+func (m *machineFileTransferAppImpl) remoteSha256(ctx context.Context, opParam *dto.MachineFileOp, sftpCli *sftp.Client, filePath string) (string, error) {
+	if cli, err := m.machineFileApp.GetMachineCli(ctx, opParam.AuthCertName); err == nil {
+		if res, err := cli.RunWithContext(ctx, "sha256sum "+shellx.Quote(filePath)); err == nil {
+			if fields := strings.Fields(res); len(fields) > 0 && len(fields[0]) == sha256.Size*2 {
+				return strings.ToLower(fields[0]), nil
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	f, err := sftpCli.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, &transferReader{ctx: ctx, reader: f}); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// transferReader 统计已读取字节数，并在ctx取消时中断读取
+type transferReader struct {
+	ctx         context.Context
+	reader      io.Reader
+	transferred *atomic.Int64
+}
+
+func (r *transferReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.reader.Read(p)
+	if r.transferred != nil {
+		r.transferred.Add(int64(n))
+	}
+	return n, err
+}
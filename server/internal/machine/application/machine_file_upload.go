@@ -0,0 +1,203 @@
+package application
+
+import (
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/config"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/imsg"
+	"mayfly-go/internal/machine/mcm"
+	"mayfly-go/pkg/cache"
+	"mayfly-go/pkg/contextx"
+	"mayfly-go/pkg/errorx"
+	"os"
+	"path"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+const (
+	chunkUploadCacheKey = "machine:file:chunk-upload:" // 分片上传信息缓存key前缀
+	chunkUploadExpire   = 24 * time.Hour               // 分片上传信息有效期，超过后需重新上传
+
+	MaxUploadChunkSize int64 = 32 * 1024 * 1024 // 单个分片最大大小
+)
+
+func (m *machineFileAppImpl) InitChunkUpload(ctx context.Context, opParam *dto.MachineFileOp, filename string, size int64, fingerprint string) (*dto.MachineFileChunkUpload, error) {
+	if opParam.Protocol != entity.MachineProtocolSsh {
+		return nil, errorx.NewBiz("chunked upload only supports ssh machines")
+	}
+	if filename == "" || filename != path.Base(filename) || filename == "." || filename == ".." {
+		return nil, errorx.NewBiz("invalid filename")
+	}
+	if size < 0 {
+		return nil, errorx.NewBiz("invalid file size")
+	}
+	if maxUploadFileSize := config.GetMachine().UploadMaxFileSize; size > maxUploadFileSize {
+		return nil, errorx.NewBizI(ctx, imsg.ErrUploadFileOutOfLimit, "size", maxUploadFileSize)
+	}
+	la := contextx.GetLoginAccount(ctx)
+	if la == nil {
+		return nil, errorx.NewBiz("no login")
+	}
+
+	mi, sftpCli, err := m.GetMachineSftpCli(ctx, opParam)
+	if err != nil {
+		return nil, err
+	}
+
+	targetPath := path.Join(opParam.Path, filename)
+	uploadId := fmt.Sprintf("%x", md5.Sum(fmt.Appendf(nil, "%d|%s|%s|%d|%s", la.Id, opParam.AuthCertName, targetPath, size, fingerprint)))
+
+	upload := new(dto.MachineFileChunkUpload)
+	if !cache.Get(chunkUploadCacheKey+uploadId, upload) {
+		upload = &dto.MachineFileChunkUpload{
+			UploadId:     uploadId,
+			AccountId:    la.Id,
+			MachineId:    mi.Id,
+			AuthCertName: opParam.AuthCertName,
+			Path:         targetPath,
+			TmpPath:      path.Join(opParam.Path, fmt.Sprintf(".%s.%s.part", filename, uploadId[:16])),
+			Size:         size,
+		}
+	}
+
+	// 已上传的偏移量以远程临时文件大小为准，以便断开连接后续传
+	upload.Offset = 0
+	if fi, err := sftpCli.Stat(upload.TmpPath); err == nil {
+		upload.Offset = min(fi.Size(), upload.Size)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	if err := cache.Set(chunkUploadCacheKey+uploadId, upload, chunkUploadExpire); err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+func (m *machineFileAppImpl) UploadChunk(ctx context.Context, uploadId string, offset int64, reader io.Reader) (int64, error) {
+	upload, err := m.getChunkUpload(ctx, uploadId)
+	if err != nil {
+		return 0, err
+	}
+	if offset < 0 || offset > upload.Size {
+		return 0, errorx.NewBiz("invalid offset")
+	}
+
+	_, sftpCli, err := m.GetMachineSftpCli(ctx, upload.ToMachineFileOp())
+	if err != nil {
+		return 0, err
+	}
+
+	// 不允许跳过未上传的部分写入，避免文件出现空洞
+	var uploaded int64
+	if fi, err := sftpCli.Stat(upload.TmpPath); err == nil {
+		uploaded = fi.Size()
+	}
+	if offset > uploaded {
+		return uploaded, errorx.NewBiz("offset %d exceeds the uploaded size %d", offset, uploaded)
+	}
+
+	f, err := sftpCli.OpenFile(upload.TmpPath, os.O_WRONLY|os.O_CREATE)
+	if err != nil {
+		return offset, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return offset, err
+	}
+
+	limit := min(MaxUploadChunkSize, upload.Size-offset)
+	n, err := io.Copy(f, io.LimitReader(reader, limit))
+	if err != nil {
+		return offset + n, err
+	}
+	// 分片数据超出限制或文件大小
+	if extra, _ := reader.Read(make([]byte, 1)); extra > 0 {
+		return offset + n, errorx.NewBiz("the chunk exceeds the size limit or the file size")
+	}
+	return offset + n, nil
+}
+
+func (m *machineFileAppImpl) CompleteChunkUpload(ctx context.Context, uploadId string) (*mcm.MachineInfo, *dto.MachineFileChunkUpload, error) {
+	upload, err := m.getChunkUpload(ctx, uploadId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mi, sftpCli, err := m.GetMachineSftpCli(ctx, upload.ToMachineFileOp())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var uploaded int64
+	if fi, err := sftpCli.Stat(upload.TmpPath); err == nil {
+		uploaded = fi.Size()
+	} else if upload.Size > 0 {
+		return mi, upload, err
+	} else {
+		// 空文件无需上传分片
+		f, err := sftpCli.Create(upload.TmpPath)
+		if err != nil {
+			return mi, upload, err
+		}
+		f.Close()
+	}
+	if uploaded != upload.Size {
+		return mi, upload, errorx.NewBiz("the upload is incomplete: %d/%d", uploaded, upload.Size)
+	}
+
+	if err := replaceFile(sftpCli, upload.TmpPath, upload.Path); err != nil {
+		return mi, upload, err
+	}
+
+	cache.Del(chunkUploadCacheKey + uploadId)
+	upload.Offset = upload.Size
+	return mi, upload, nil
+}
+
+func (m *machineFileAppImpl) AbortChunkUpload(ctx context.Context, uploadId string) (*mcm.MachineInfo, error) {
+	upload, err := m.getChunkUpload(ctx, uploadId)
+	if err != nil {
+		return nil, err
+	}
+	cache.Del(chunkUploadCacheKey + uploadId)
+
+	mi, sftpCli, err := m.GetMachineSftpCli(ctx, upload.ToMachineFileOp())
+	if err != nil {
+		return nil, err
+	}
+	if err := sftpCli.Remove(upload.TmpPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return mi, err
+	}
+	return mi, nil
+}
+
+// getChunkUpload 获取当前账号的分片上传信息
+func (m *machineFileAppImpl) getChunkUpload(ctx context.Context, uploadId string) (*dto.MachineFileChunkUpload, error) {
+	upload := new(dto.MachineFileChunkUpload)
+	if !cache.Get(chunkUploadCacheKey+uploadId, upload) {
+		return nil, errorx.NewBiz("the upload does not exist or has expired, please re-initialize")
+	}
+	if la := contextx.GetLoginAccount(ctx); la == nil || la.Id != upload.AccountId {
+		return nil, errorx.NewBiz("the upload does not exist or has expired, please re-initialize")
+	}
+	return upload, nil
+}
+
+// replaceFile 将临时文件重命名为目标文件，目标文件已存在则覆盖
+func replaceFile(sftpCli *sftp.Client, tmpPath, targetPath string) error {
+	if err := sftpCli.PosixRename(tmpPath, targetPath); err != nil {
+		// 服务端不支持posix-rename扩展时，先删除目标文件再重命名
+		sftpCli.Remove(targetPath)
+		return sftpCli.Rename(tmpPath, targetPath)
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/mcm"
+	tagentity "mayfly-go/internal/tag/domain/entity"
+	"mayfly-go/pkg/contextx"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/diffx"
+	"path"
+
+	"github.com/pkg/sftp"
+)
+
+const (
+	fileVersionMaxSize  = 10 * 1024 * 1024 // 超出该大小的文件写入前不保存历史版本
+	fileVersionMaxCount = 30               // 每个文件最多保留的历史版本数，超出则删除最早的版本
+)
+
+func (m *machineFileAppImpl) GetFileVersions(ctx context.Context, condition *entity.MachineFileVersionQuery, orderBy ...string) (*model.PageResult[*entity.MachineFileVersion], error) {
+	la := contextx.GetLoginAccount(ctx)
+	if la == nil {
+		return nil, errorx.NewBiz("no login")
+	}
+	machine, err := m.machineApp.GetById(condition.MachineId, "code")
+	if err != nil {
+		return nil, errorx.NewBiz("machine not found")
+	}
+	if err := m.tagApp.CanAccess(la.Id, m.tagApp.ListTagPathByTypeAndCode(int8(tagentity.TagTypeMachine), machine.Code)...); err != nil {
+		return nil, err
+	}
+
+	if condition.Path != "" {
+		condition.Path = path.Clean(condition.Path)
+	}
+	return m.machineFileVersionRepo.GetPageList(condition, orderBy...)
+}
+
+func (m *machineFileAppImpl) GetFileVersionContent(ctx context.Context, opParam *dto.MachineFileOp, versionId uint64) (*entity.MachineFileVersion, string, error) {
+	mi, _, err := m.getAccessibleSftpCli(ctx, opParam)
+	if err != nil {
+		return nil, "", err
+	}
+	version, err := m.getFileVersion(mi, versionId)
+	if err != nil {
+		return nil, "", err
+	}
+	content, err := m.readFileVersion(ctx, version)
+	return version, content, err
+}
+
+func (m *machineFileAppImpl) DiffFileVersion(ctx context.Context, opParam *dto.MachineFileOp, fromVersionId, toVersionId uint64) (string, error) {
+	mi, sftpCli, err := m.getAccessibleSftpCli(ctx, opParam)
+	if err != nil {
+		return "", err
+	}
+
+	from, err := m.getFileVersion(mi, fromVersionId)
+	if err != nil {
+		return "", err
+	}
+	fromContent, err := m.readFileVersion(ctx, from)
+	if err != nil {
+		return "", err
+	}
+
+	// 未指定目标版本则与远程文件当前内容对比
+	if toVersionId == 0 {
+		toContent, err := readRemoteFileContent(sftpCli, from.Path)
+		if err != nil {
+			return "", err
+		}
+		return diffx.Unified(fmt.Sprintf("v%d", from.Version), "current", fromContent, toContent, diffx.DefaultContext)
+	}
+
+	to, err := m.getFileVersion(mi, toVersionId)
+	if err != nil {
+		return "", err
+	}
+	if to.Path != from.Path {
+		return "", errorx.NewBiz("the versions to be compared belong to different files")
+	}
+	toContent, err := m.readFileVersion(ctx, to)
+	if err != nil {
+		return "", err
+	}
+	return diffx.Unified(fmt.Sprintf("v%d", from.Version), fmt.Sprintf("v%d", to.Version), fromContent, toContent, diffx.DefaultContext)
+}
+
+func (m *machineFileAppImpl) DiffMachineFile(ctx context.Context, from, to *dto.MachineFileOp) (string, error) {
+	if from.Protocol != entity.MachineProtocolSsh || to.Protocol != entity.MachineProtocolSsh {
+		return "", errorx.NewBiz("file diff only supports ssh machines")
+	}
+
+	fromMi, fromSftpCli, err := m.getAccessibleSftpCli(ctx, from)
+	if err != nil {
+		return "", err
+	}
+	toMi, toSftpCli, err := m.getAccessibleSftpCli(ctx, to)
+	if err != nil {
+		return "", err
+	}
+
+	fromContent, err := readRemoteFileContent(fromSftpCli, path.Clean(from.Path))
+	if err != nil {
+		return "", err
+	}
+	toContent, err := readRemoteFileContent(toSftpCli, path.Clean(to.Path))
+	if err != nil {
+		return "", err
+	}
+	return diffx.Unified(fmt.Sprintf("%s:%s", fromMi.Name, from.Path), fmt.Sprintf("%s:%s", toMi.Name, to.Path), fromContent, toContent, diffx.DefaultContext)
+}
+
+func (m *machineFileAppImpl) RestoreFileVersion(ctx context.Context, opParam *dto.MachineFileOp, versionId uint64) (*mcm.MachineInfo, *entity.MachineFileVersion, error) {
+	mi, sftpCli, err := m.getAccessibleSftpCli(ctx, opParam)
+	if err != nil {
+		return nil, nil, err
+	}
+	version, err := m.getFileVersion(mi, versionId)
+	if err != nil {
+		return mi, nil, err
+	}
+	content, err := m.readFileVersion(ctx, version)
+	if err != nil {
+		return mi, version, err
+	}
+
+	// 恢复前同样保存当前内容，便于撤销恢复操作
+	if err := m.snapshotFile(ctx, mi, opParam.AuthCertName, sftpCli, version.Path, []byte(content), fmt.Sprintf("restore v%d", version.Version)); err != nil {
+		return mi, version, err
+	}
+	return mi, version, writeRemoteFile(sftpCli, version.Path, []byte(content))
+}
+
+// snapshotFile 将远程文件当前内容保存为历史版本。文件不存在、非普通文件、超出大小限制或内容未变更时不保存
+func (m *machineFileAppImpl) snapshotFile(ctx context.Context, mi *mcm.MachineInfo, authCertName string, sftpCli *sftp.Client, filePath string, newContent []byte, remark string) error {
+	fi, err := sftpCli.Stat(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if !fi.Mode().IsRegular() {
+		return nil
+	}
+	if fi.Size() > fileVersionMaxSize {
+		logx.Warnf("the file [%s:%s] is too large to save a history version", mi.Name, filePath)
+		return nil
+	}
+
+	f, err := sftpCli.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, fileVersionMaxSize))
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(data, newContent) {
+		return nil
+	}
+
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	versions, err := m.machineFileVersionRepo.SelectByCond(model.NewCond().Eq("machine_id", mi.Id).Eq("path", filePath).OrderByDesc("version"), "id", "version", "checksum", "file_key")
+	if err != nil {
+		return err
+	}
+	// 当前内容与最新版本一致则无需重复保存
+	if len(versions) > 0 && versions[0].Checksum == checksum {
+		return nil
+	}
+
+	fileKey, err := m.fileApp.Upload(ctx, "", fmt.Sprintf("mfv_%d_%s", mi.Id, path.Base(filePath)), bytes.NewReader(data))
+	if err != nil {
+		return errorx.NewBiz("failed to save the history version: %s", err.Error())
+	}
+	version := &entity.MachineFileVersion{
+		MachineId:    mi.Id,
+		AuthCertName: authCertName,
+		Path:         filePath,
+		Version:      1,
+		FileKey:      fileKey,
+		Size:         int64(len(data)),
+		Checksum:     checksum,
+		Remark:       remark,
+	}
+	if len(versions) > 0 {
+		version.Version = versions[0].Version + 1
+	}
+	if err := m.machineFileVersionRepo.Insert(ctx, version); err != nil {
+		m.fileApp.Remove(ctx, fileKey)
+		return err
+	}
+
+	// 清理超出保留数量的最早版本
+	if len(versions) >= fileVersionMaxCount {
+		for _, expired := range versions[fileVersionMaxCount-1:] {
+			if err := m.machineFileVersionRepo.DeleteById(ctx, expired.Id); err != nil {
+				logx.Errorf("failed to delete the machine file version [%d]: %s", expired.Id, err.Error())
+				continue
+			}
+			m.fileApp.Remove(ctx, expired.FileKey)
+		}
+	}
+	return nil
+}
+
+// getAccessibleSftpCli 获取sftp cli，并校验当前账号是否拥有该机器的访问权限
+func (m *machineFileAppImpl) getAccessibleSftpCli(ctx context.Context, opParam *dto.MachineFileOp) (*mcm.MachineInfo, *sftp.Client, error) {
+	if opParam.Protocol != entity.MachineProtocolSsh {
+		return nil, nil, errorx.NewBiz("file version only supports ssh machines")
+	}
+	la := contextx.GetLoginAccount(ctx)
+	if la == nil {
+		return nil, nil, errorx.NewBiz("no login")
+	}
+	mi, sftpCli, err := m.GetMachineSftpCli(ctx, opParam)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := m.tagApp.CanAccess(la.Id, mi.CodePath...); err != nil {
+		return nil, nil, err
+	}
+	return mi, sftpCli, nil
+}
+
+// getFileVersion 获取历史版本，并校验其是否属于该机器
+func (m *machineFileAppImpl) getFileVersion(mi *mcm.MachineInfo, versionId uint64) (*entity.MachineFileVersion, error) {
+	version, err := m.machineFileVersionRepo.GetById(versionId)
+	if err != nil || version.MachineId != mi.Id {
+		return nil, errorx.NewBiz("file version not found")
+	}
+	return version, nil
+}
+
+func (m *machineFileAppImpl) readFileVersion(ctx context.Context, version *entity.MachineFileVersion) (string, error) {
+	_, reader, err := m.fileApp.GetReader(ctx, version.FileKey)
+	if err != nil {
+		return "", errorx.NewBiz("failed to read the file version v%d: %s", version.Version, err.Error())
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	return string(data), err
+}
+
+// readRemoteFileContent 读取远程文件内容用于对比，文件不存在时视为空内容
+func readRemoteFileContent(sftpCli *sftp.Client, filePath string) (string, error) {
+	fi, err := sftpCli.Stat(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+	if !fi.Mode().IsRegular() {
+		return "", errorx.NewBiz("%s is not a regular file", filePath)
+	}
+	if fi.Size() > fileVersionMaxSize {
+		return "", errorx.NewBiz("%s is too large to compare", filePath)
+	}
+
+	f, err := sftpCli.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, fileVersionMaxSize))
+	return string(data), err
+}
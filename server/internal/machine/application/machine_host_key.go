@@ -0,0 +1,165 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"mayfly-go/internal/machine/config"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/internal/machine/imsg"
+	"mayfly-go/internal/machine/mcm"
+	msgapp "mayfly-go/internal/msg/application"
+	msgdto "mayfly-go/internal/msg/application/dto"
+	"mayfly-go/internal/pkg/consts"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/i18n"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/collx"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+type MachineHostKey interface {
+	base.App[*entity.MachineHostKey]
+
+	// VerifyHostKey 校验机器主机公钥，首次连接则信任并保存，不匹配则阻止连接并通知告警接收人
+	VerifyHostKey(mi *mcm.MachineInfo, key ssh.PublicKey) error
+
+	// GetByMachineId 获取机器已信任的主机公钥信息
+	GetByMachineId(machineId uint64) (*entity.MachineHostKey, error)
+
+	// Accept 接受最近一次不匹配的主机公钥，替换为新的信任公钥
+	Accept(ctx context.Context, machineId uint64) error
+
+	// Reset 重置机器主机公钥，下次连接时重新信任
+	Reset(ctx context.Context, machineId uint64) error
+}
+
+type machineHostKeyAppImpl struct {
+	base.AppImpl[*entity.MachineHostKey, repository.MachineHostKey]
+
+	msgApp     msgapp.Msg     `inject:"T"`
+	msgTmplApp msgapp.MsgTmpl `inject:"T"`
+
+	mutex sync.Mutex
+}
+
+var _ (MachineHostKey) = (*machineHostKeyAppImpl)(nil)
+
+func (m *machineHostKeyAppImpl) VerifyHostKey(mi *mcm.MachineInfo, key ssh.PublicKey) error {
+	// 防止同一机器并发首次连接时重复保存
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	fingerprint := ssh.FingerprintSHA256(key)
+	hostKey, err := m.GetByMachineId(mi.Id)
+	if err != nil {
+		logx.Infof("trust the host key of machine [%s] on first use: %s %s", mi.Name, key.Type(), fingerprint)
+		return m.Insert(context.Background(), &entity.MachineHostKey{
+			MachineId:   mi.Id,
+			KeyType:     key.Type(),
+			Fingerprint: fingerprint,
+			PublicKey:   marshalPublicKey(key),
+		})
+	}
+
+	if hostKey.Fingerprint == fingerprint {
+		return nil
+	}
+
+	logx.Warnf("the host key of machine [%s] does not match, expected: %s, actual: %s", mi.Name, hostKey.Fingerprint, fingerprint)
+	now := time.Now()
+	// 相同的不匹配公钥只通知一次，避免重复连接时频繁告警
+	notify := hostKey.PendingFingerprint != fingerprint
+	update := &entity.MachineHostKey{
+		PendingKeyType:     key.Type(),
+		PendingFingerprint: fingerprint,
+		PendingPublicKey:   marshalPublicKey(key),
+		MismatchTime:       &now,
+	}
+	update.Id = hostKey.Id
+	if err := m.UpdateById(context.Background(), update); err != nil {
+		logx.Errorf("failed to save the mismatched host key of machine [%s]: %s", mi.Name, err.Error())
+	}
+
+	errMsg := i18n.T(imsg.ErrHostKeyMismatch, "name", mi.Name, "expected", hostKey.Fingerprint, "actual", fingerprint)
+	if notify {
+		m.sendMismatchAlert(mi, errMsg)
+	}
+	return errorx.NewBiz("%s", errMsg)
+}
+
+// sendMismatchAlert 通知配置的告警接收人主机公钥不匹配，未配置则通知管理员
+func (m *machineHostKeyAppImpl) sendMismatchAlert(mi *mcm.MachineInfo, errMsg string) {
+	mc := config.GetMachine()
+	receiverIds := mc.HostKeyAlertReceiverIds
+	if len(receiverIds) == 0 {
+		receiverIds = []uint64{consts.AdminId}
+	}
+
+	content := fmt.Sprintf("[%s][%s:%d] %s", mi.Code, mi.Ip, mi.Port, errMsg)
+	for _, receiverId := range receiverIds {
+		m.msgApp.CreateAndSend(&model.LoginAccount{Id: receiverId}, msgdto.ErrSysMsg(i18n.T(imsg.MsgHostKeyMismatch), content))
+	}
+
+	if mc.HostKeyAlertMsgTmplCode == "" {
+		return
+	}
+	if err := m.msgTmplApp.Send(context.Background(), mc.HostKeyAlertMsgTmplCode, collx.M{
+		"machineName": mi.Name,
+		"machineCode": mi.Code,
+		"ip":          mi.Ip,
+		"port":        mi.Port,
+		"error":       errMsg,
+	}, receiverIds...); err != nil {
+		logx.Errorf("failed to send the host key mismatch alert of machine [%s]: %s", mi.Name, err.Error())
+	}
+}
+
+func (m *machineHostKeyAppImpl) GetByMachineId(machineId uint64) (*entity.MachineHostKey, error) {
+	hostKey := &entity.MachineHostKey{MachineId: machineId}
+	return hostKey, m.GetByCond(hostKey)
+}
+
+func (m *machineHostKeyAppImpl) Accept(ctx context.Context, machineId uint64) error {
+	hostKey, err := m.GetByMachineId(machineId)
+	if err != nil {
+		return errorx.NewBiz("host key not found")
+	}
+	if hostKey.PendingFingerprint == "" {
+		return errorx.NewBiz("there is no mismatched host key to accept")
+	}
+
+	// 使用map更新，以便清空待确认公钥信息
+	if err := m.UpdateByCond(ctx, map[string]any{
+		"key_type":            hostKey.PendingKeyType,
+		"fingerprint":         hostKey.PendingFingerprint,
+		"public_key":          hostKey.PendingPublicKey,
+		"pending_key_type":    "",
+		"pending_fingerprint": "",
+		"pending_public_key":  "",
+		"mismatch_time":       nil,
+	}, model.NewCond().Eq("id", hostKey.Id)); err != nil {
+		return err
+	}
+	mcm.DeleteCli(machineId)
+	return nil
+}
+
+func (m *machineHostKeyAppImpl) Reset(ctx context.Context, machineId uint64) error {
+	if err := m.DeleteByCond(ctx, &entity.MachineHostKey{MachineId: machineId}); err != nil {
+		return err
+	}
+	mcm.DeleteCli(machineId)
+	return nil
+}
+
+// marshalPublicKey 将公钥转为authorized_keys格式
+func marshalPublicKey(key ssh.PublicKey) string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+}
@@ -0,0 +1,481 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/mcm"
+	tagentity "mayfly-go/internal/tag/domain/entity"
+	"mayfly-go/pkg/contextx"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/collx"
+	"net"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	machineImportMaxHosts   = 1000
+	machineImportMaxNameLen = 32
+)
+
+// machineImportItem 导入过程中的单个主机
+type machineImportItem struct {
+	row     *dto.MachineImportRow
+	host    *mcm.InventoryHost
+	machine *entity.Machine
+
+	authCert     *tagentity.ResourceAuthCert
+	tagCodePaths []string
+	jump         *machineImportItem // 跳板机为本次导入的主机
+}
+
+func (it *machineImportItem) fail(format string, args ...any) {
+	if it.row.Error == "" {
+		it.row.Error = fmt.Sprintf(format, args...)
+	}
+}
+
+func (m *machineAppImpl) ImportMachines(ctx context.Context, param *dto.MachineImport) (*dto.MachineImportResult, error) {
+	la := contextx.GetLoginAccount(ctx)
+	if la == nil {
+		return nil, errorx.NewBiz("no login")
+	}
+
+	hosts, err := mcm.ParseInventory(param.Format, param.Content)
+	if err != nil {
+		return nil, errorx.NewBiz("%s", err.Error())
+	}
+	if len(hosts) == 0 {
+		return nil, errorx.NewBiz("no hosts found in the inventory")
+	}
+	if len(hosts) > machineImportMaxHosts {
+		return nil, errorx.NewBiz("at most %d hosts can be imported at a time", machineImportMaxHosts)
+	}
+
+	var defaultAuthCert *tagentity.ResourceAuthCert
+	if param.AuthCertName != "" {
+		if defaultAuthCert, err = m.getImportPublicAuthCert(param.AuthCertName); err != nil {
+			return nil, err
+		}
+	}
+	defaultTagCodePaths := normalizeTagCodePaths(param.TagCodePaths)
+
+	items := make([]*machineImportItem, 0, len(hosts))
+	for _, host := range hosts {
+		it := m.newMachineImportItem(host, param.EnableRecorder)
+		m.resolveImportAuthCert(it, defaultAuthCert)
+		items = append(items, it)
+	}
+
+	m.resolveImportTags(la.Id, items, defaultTagCodePaths)
+	m.resolveImportProxyJumps(items)
+	checkImportDuplicates(items)
+
+	// 跳板机需先于依赖其的主机处理
+	for _, it := range sortImportItems(items) {
+		m.importMachine(ctx, it, param.DryRun)
+	}
+
+	res := &dto.MachineImportResult{DryRun: param.DryRun, Total: len(items)}
+	for _, it := range items {
+		switch it.row.Status {
+		case dto.MachineImportStatusCreate, dto.MachineImportStatusCreated:
+			res.Created++
+		case dto.MachineImportStatusExist:
+			res.Existed++
+		default:
+			res.Failed++
+		}
+		res.Rows = append(res.Rows, it.row)
+	}
+	return res, nil
+}
+
+func (m *machineAppImpl) newMachineImportItem(host *mcm.InventoryHost, enableRecorder int8) *machineImportItem {
+	it := &machineImportItem{
+		host: host,
+		row: &dto.MachineImportRow{
+			Line:     host.Line,
+			Name:     host.Name,
+			Ip:       host.Host,
+			Port:     host.Port,
+			Username: host.Username,
+			Warnings: host.Warnings,
+			Error:    host.Error,
+		},
+		machine: &entity.Machine{
+			Name:           host.Name,
+			Ip:             host.Host,
+			Port:           host.Port,
+			Remark:         host.Remark,
+			EnableRecorder: enableRecorder,
+		},
+	}
+
+	switch host.Protocol {
+	case "", "ssh":
+		it.machine.Protocol = entity.MachineProtocolSsh
+	case "rdp":
+		it.machine.Protocol = entity.MachineProtocolRdp
+	case "vnc":
+		it.machine.Protocol = entity.MachineProtocolVnc
+	default:
+		it.fail("unsupported protocol: %s", host.Protocol)
+	}
+	if it.machine.Port == 0 {
+		switch it.machine.Protocol {
+		case entity.MachineProtocolRdp:
+			it.machine.Port = 3389
+		case entity.MachineProtocolVnc:
+			it.machine.Port = 5900
+		default:
+			it.machine.Port = 22
+		}
+	}
+	it.row.Protocol = it.machine.Protocol
+	it.row.Port = it.machine.Port
+
+	if it.machine.Ip == "" {
+		it.fail("the ip cannot be empty")
+	}
+	if it.machine.Name == "" {
+		it.fail("the name cannot be empty")
+	} else if utf8.RuneCountInString(it.machine.Name) > machineImportMaxNameLen {
+		it.fail("the name cannot exceed %d characters", machineImportMaxNameLen)
+	}
+	if it.machine.Port <= 0 || it.machine.Port > 65535 {
+		it.fail("invalid port: %d", it.machine.Port)
+	}
+	return it
+}
+
+// resolveImportAuthCert 解析主机的授权凭证，优先使用主机指定的公共授权凭证、私钥、密码，否则使用默认公共授权凭证
+func (m *machineAppImpl) resolveImportAuthCert(it *machineImportItem, defaultAuthCert *tagentity.ResourceAuthCert) {
+	host := it.host
+	switch {
+	case host.AuthCertName != "":
+		publicAc, err := m.getImportPublicAuthCert(host.AuthCertName)
+		if err != nil {
+			it.fail("%s", err.Error())
+			return
+		}
+		it.authCert = newImportPublicAuthCert(publicAc)
+	case host.PrivateKey != "" || host.Password != "":
+		if host.Username == "" {
+			it.fail("the username cannot be empty")
+			return
+		}
+		it.authCert = &tagentity.ResourceAuthCert{
+			Type:           tagentity.AuthCertTypePrivate,
+			Username:       host.Username,
+			Ciphertext:     host.Password,
+			CiphertextType: tagentity.AuthCertCiphertextTypePassword,
+		}
+		if host.PrivateKey != "" {
+			it.authCert.Ciphertext = host.PrivateKey
+			it.authCert.CiphertextType = tagentity.AuthCertCiphertextTypePrivateKey
+		}
+	case defaultAuthCert != nil:
+		it.authCert = newImportPublicAuthCert(defaultAuthCert)
+		if host.Username != "" && host.Username != defaultAuthCert.Username {
+			it.row.Warnings = append(it.row.Warnings, fmt.Sprintf("the username %s is ignored, the public auth cert %s is used", host.Username, defaultAuthCert.Name))
+		}
+	default:
+		it.fail("no auth cert, please specify the password, private key or a public auth cert")
+		return
+	}
+
+	it.row.Username = it.authCert.Username
+	switch it.authCert.CiphertextType {
+	case tagentity.AuthCertCiphertextTypePublic:
+		it.row.AuthCert = it.authCert.Ciphertext
+	case tagentity.AuthCertCiphertextTypePrivateKey:
+		it.row.AuthCert = "privateKey"
+	default:
+		it.row.AuthCert = "password"
+	}
+}
+
+func (m *machineAppImpl) getImportPublicAuthCert(name string) (*tagentity.ResourceAuthCert, error) {
+	ac := &tagentity.ResourceAuthCert{Name: name}
+	if err := m.resourceAuthCertApp.GetByCond(ac); err != nil || ac.Type != tagentity.AuthCertTypePublic {
+		return nil, errorx.NewBiz("the public auth cert [%s] not found", name)
+	}
+	return ac, nil
+}
+
+// newImportPublicAuthCert 使用公共授权凭证，密文即为公共授权凭证名
+func newImportPublicAuthCert(publicAc *tagentity.ResourceAuthCert) *tagentity.ResourceAuthCert {
+	return &tagentity.ResourceAuthCert{
+		Type:           tagentity.AuthCertTypePrivate,
+		Username:       publicAc.Name,
+		Ciphertext:     publicAc.Name,
+		CiphertextType: tagentity.AuthCertCiphertextTypePublic,
+	}
+}
+
+// resolveImportTags 解析主机的标签路径：主机指定的标签 > 与主机分组同名(编码)的标签 > 默认标签，并校验标签存在且当前账号可访问
+func (m *machineAppImpl) resolveImportTags(accountId uint64, items []*machineImportItem, defaultTagCodePaths []string) {
+	var groups []string
+	for _, it := range items {
+		groups = append(groups, it.host.Groups...)
+	}
+	group2TagPaths := map[string][]string{}
+	if groups = collx.ArrayDeduplicate(groups); len(groups) > 0 {
+		var tags []*tagentity.TagTree
+		m.tagApp.ListByQuery(&tagentity.TagTreeQuery{Codes: groups, Types: []tagentity.TagType{tagentity.TagTypeTag}}, &tags)
+		for _, tag := range tags {
+			group2TagPaths[tag.Code] = append(group2TagPaths[tag.Code], tag.CodePath)
+		}
+	}
+
+	var allTagPaths []string
+	for _, it := range items {
+		tagPaths := normalizeTagCodePaths(it.host.TagCodePaths)
+		if len(tagPaths) == 0 {
+			for _, g := range it.host.Groups {
+				tagPaths = append(tagPaths, group2TagPaths[g]...)
+			}
+			tagPaths = collx.ArrayDeduplicate(tagPaths)
+		}
+		if len(tagPaths) == 0 {
+			tagPaths = defaultTagCodePaths
+		}
+		it.tagCodePaths = tagPaths
+		it.row.TagCodePaths = tagPaths
+		allTagPaths = append(allTagPaths, tagPaths...)
+	}
+
+	existTagPaths := map[string]bool{}
+	if allTagPaths = collx.ArrayDeduplicate(allTagPaths); len(allTagPaths) > 0 {
+		var tags []*tagentity.TagTree
+		m.tagApp.ListByQuery(&tagentity.TagTreeQuery{CodePaths: allTagPaths, Types: []tagentity.TagType{tagentity.TagTypeTag}}, &tags)
+		for _, tag := range tags {
+			existTagPaths[tag.CodePath] = true
+		}
+	}
+
+	for _, it := range items {
+		if len(it.tagCodePaths) == 0 {
+			it.fail("the tag cannot be empty")
+			continue
+		}
+		for _, tagPath := range it.tagCodePaths {
+			if !existTagPaths[tagPath] {
+				it.fail("the tag %s not found", tagPath)
+				break
+			}
+			if err := m.tagApp.CanAccess(accountId, tagPath); err != nil {
+				it.fail("no permission to the tag %s", tagPath)
+				break
+			}
+		}
+	}
+}
+
+// resolveImportProxyJumps 解析主机的跳板机，优先匹配本次导入的主机(名称或ip[:端口])，其次为已存在的机器
+func (m *machineAppImpl) resolveImportProxyJumps(items []*machineImportItem) {
+	for _, it := range items {
+		proxyJump := it.host.ProxyJump
+		if proxyJump == "" {
+			continue
+		}
+		if strings.Contains(proxyJump, ",") {
+			it.fail("multiple ProxyJump hops are not supported, please configure the ProxyJump of the jump host instead")
+			continue
+		}
+		jumpHost, jumpPort, err := splitProxyJump(proxyJump)
+		if err != nil {
+			it.fail("%s", err.Error())
+			continue
+		}
+
+		if jump := findImportJumpItem(items, jumpHost, jumpPort); jump != nil {
+			if jump == it {
+				it.fail("the jump host cannot be itself")
+				continue
+			}
+			if jump.machine.Protocol != entity.MachineProtocolSsh {
+				it.fail("the jump host %s is not a ssh machine", proxyJump)
+				continue
+			}
+			it.jump = jump
+			it.row.SshTunnel = jump.machine.Name
+			continue
+		}
+
+		jumpMachine, err := m.findExistJumpMachine(jumpHost, jumpPort)
+		if err != nil {
+			it.fail("%s", err.Error())
+			continue
+		}
+		it.machine.SshTunnelMachineId = int(jumpMachine.Id)
+		it.row.SshTunnel = jumpMachine.Name
+	}
+}
+
+func findImportJumpItem(items []*machineImportItem, host string, port int) *machineImportItem {
+	for _, it := range items {
+		if it.host.Name == host && (port == 0 || it.machine.Port == port) {
+			return it
+		}
+	}
+	for _, it := range items {
+		if it.machine.Ip == host && (port == 0 || it.machine.Port == port) {
+			return it
+		}
+	}
+	return nil
+}
+
+func (m *machineAppImpl) findExistJumpMachine(host string, port int) (*entity.Machine, error) {
+	filter := func(machines []*entity.Machine) []*entity.Machine {
+		return collx.ArrayFilter(machines, func(me *entity.Machine) bool {
+			return me.Protocol == entity.MachineProtocolSsh && (port == 0 || me.Port == port)
+		})
+	}
+
+	machines, _ := m.ListByCond(&entity.Machine{Name: host})
+	if machines = filter(machines); len(machines) == 0 {
+		machines, _ = m.ListByCond(&entity.Machine{Ip: host})
+		machines = filter(machines)
+	}
+	switch len(machines) {
+	case 0:
+		return nil, errorx.NewBiz("the jump host %s not found", host)
+	case 1:
+		return machines[0], nil
+	default:
+		return nil, errorx.NewBiz("multiple machines match the jump host %s, please specify the port", host)
+	}
+}
+
+// splitProxyJump 解析[user@]host[:port]格式的跳板机
+func splitProxyJump(proxyJump string) (string, int, error) {
+	if idx := strings.LastIndex(proxyJump, "@"); idx >= 0 {
+		proxyJump = proxyJump[idx+1:]
+	}
+	if strings.HasPrefix(proxyJump, "[") || strings.Count(proxyJump, ":") == 1 {
+		host, portStr, err := net.SplitHostPort(proxyJump)
+		if err != nil {
+			return "", 0, errorx.NewBiz("invalid ProxyJump: %s", proxyJump)
+		}
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			return "", 0, errorx.NewBiz("invalid ProxyJump: %s", proxyJump)
+		}
+		return host, port, nil
+	}
+	return proxyJump, 0, nil
+}
+
+// checkImportDuplicates 校验本次导入的主机是否重复(地址、端口及跳板机均相同)
+func checkImportDuplicates(items []*machineImportItem) {
+	exists := map[string]*machineImportItem{}
+	for _, it := range items {
+		if it.row.Error != "" {
+			continue
+		}
+		key := fmt.Sprintf("%s:%d:%d:%p", it.machine.Ip, it.machine.Port, it.machine.SshTunnelMachineId, it.jump)
+		if first, ok := exists[key]; ok {
+			it.fail("duplicated with line %d", first.row.Line)
+			continue
+		}
+		exists[key] = it
+	}
+}
+
+// sortImportItems 按跳板机依赖排序，跳板机在前。存在循环依赖的主机标记为错误
+func sortImportItems(items []*machineImportItem) []*machineImportItem {
+	sorted := make([]*machineImportItem, 0, len(items))
+	state := map[*machineImportItem]int{} // 1.处理中 2.已处理
+
+	var visit func(it *machineImportItem) bool
+	visit = func(it *machineImportItem) bool {
+		switch state[it] {
+		case 1:
+			return false
+		case 2:
+			return true
+		}
+		state[it] = 1
+		if it.jump != nil && !visit(it.jump) {
+			it.fail("circular ProxyJump")
+			state[it] = 2
+			sorted = append(sorted, it)
+			return false
+		}
+		state[it] = 2
+		sorted = append(sorted, it)
+		return true
+	}
+
+	for _, it := range items {
+		visit(it)
+	}
+	return sorted
+}
+
+func (m *machineAppImpl) importMachine(ctx context.Context, it *machineImportItem, dryRun bool) {
+	if it.jump != nil && it.row.Error == "" {
+		if it.jump.row.Error != "" {
+			it.fail("the jump host %s cannot be imported", it.jump.machine.Name)
+		} else {
+			it.machine.SshTunnelMachineId = int(it.jump.machine.Id)
+		}
+	}
+	if it.row.Error != "" {
+		it.row.Status = dto.MachineImportStatusError
+		return
+	}
+
+	// 跳板机为预览中待新增的主机时，无法校验是否已存在
+	if it.jump == nil || it.jump.machine.Id != 0 {
+		// 结构体条件会忽略零值，未使用跳板机(ssh_tunnel_machine_id=0)时需显式指定条件
+		old := new(entity.Machine)
+		cond := model.NewCond().Eq0("ip", it.machine.Ip).Eq0("port", it.machine.Port).Eq0("ssh_tunnel_machine_id", it.machine.SshTunnelMachineId)
+		if err := m.GetByCond(cond.Dest(old)); err == nil {
+			it.machine.Id = old.Id
+			it.row.MachineId = old.Id
+			it.row.Status = dto.MachineImportStatusExist
+			return
+		}
+	}
+
+	if dryRun {
+		it.row.Status = dto.MachineImportStatusCreate
+		return
+	}
+
+	if err := m.SaveMachine(ctx, &dto.SaveMachine{
+		Machine:      it.machine,
+		TagCodePaths: it.tagCodePaths,
+		AuthCerts:    []*tagentity.ResourceAuthCert{it.authCert},
+	}); err != nil {
+		it.machine.Id = 0
+		it.fail("%s", err.Error())
+		it.row.Status = dto.MachineImportStatusError
+		return
+	}
+	it.row.MachineId = it.machine.Id
+	it.row.Status = dto.MachineImportStatusCreated
+}
+
+// normalizeTagCodePaths 标签路径统一以/结尾
+func normalizeTagCodePaths(tagCodePaths []string) []string {
+	var res []string
+	for _, tagPath := range tagCodePaths {
+		if tagPath = strings.TrimSpace(tagPath); tagPath == "" {
+			continue
+		}
+		if !strings.HasSuffix(tagPath, tagentity.CodePathSeparator) {
+			tagPath += tagentity.CodePathSeparator
+		}
+		res = append(res, tagPath)
+	}
+	return collx.ArrayDeduplicate(res)
+}
@@ -0,0 +1,240 @@
+package application
+
+import (
+	"context"
+	"mayfly-go/internal/machine/config"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/internal/machine/mcm"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/scheduler"
+	"mayfly-go/pkg/utils/collx"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/may-fly/cast"
+)
+
+// 范围查询最多返回的数据点数，超过则按时间桶聚合
+const machineMonitorMaxPoints = 720
+
+type MachineMonitor interface {
+	base.App[*entity.MachineMonitor]
+
+	// SaveStats 保存机器运行状态采样
+	SaveStats(machineId uint64, stats *mcm.Stats) error
+
+	// GetMonitors 获取机器指定时间范围内的监控数据，数据点过多时按时间桶聚合
+	GetMonitors(query *entity.MachineMonitorQuery) ([]*entity.MachineMonitor, error)
+
+	// TimerDownsample 定时将过期的原始采样聚合为小时数据，并删除超过保存天数的数据
+	TimerDownsample()
+}
+
+type machineMonitorAppImpl struct {
+	base.AppImpl[*entity.MachineMonitor, repository.MachineMonitor]
+
+	// 机器上次采样的网络累计流量，用于计算速率
+	lastNetStats sync.Map // machineId -> *netCounter
+}
+
+type netCounter struct {
+	rx   uint64
+	tx   uint64
+	time time.Time
+}
+
+var _ (MachineMonitor) = (*machineMonitorAppImpl)(nil)
+
+func (m *machineMonitorAppImpl) SaveStats(machineId uint64, stats *mcm.Stats) error {
+	// 获取状态信息失败时，内存总量为0
+	if stats == nil || stats.MemInfo.Total == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	monitor := &entity.MachineMonitor{
+		MachineId:   machineId,
+		Granularity: entity.MachineMonitorGranularityRaw,
+		CpuRate:     100 - stats.CPU.Idle,
+		MemRate:     float32(stats.MemInfo.Total-stats.MemInfo.Available) / float32(stats.MemInfo.Total) * 100,
+		Load1:       cast.ToFloat32(stats.Load1),
+		Load5:       cast.ToFloat32(stats.Load5),
+		Load15:      cast.ToFloat32(stats.Load10),
+		FsRates:     make(model.Map[string, float32]),
+		CreateTime:  now,
+	}
+
+	for _, fs := range stats.FSInfos {
+		if total := fs.Used + fs.Free; total > 0 {
+			monitor.FsRates[fs.MountPoint] = float32(fs.Used) / float32(total) * 100
+		}
+	}
+
+	// 网络速率，根据与上次采样的累计流量差值计算
+	var rx, tx uint64
+	for name, intf := range stats.NetIntf {
+		if name == "lo" {
+			continue
+		}
+		rx += intf.Rx
+		tx += intf.Tx
+	}
+	current := &netCounter{rx: rx, tx: tx, time: now}
+	if last, ok := m.lastNetStats.Swap(machineId, current); ok {
+		lastCounter := last.(*netCounter)
+		// 计数器重置(如机器重启)时忽略
+		if seconds := now.Sub(lastCounter.time).Seconds(); seconds > 0 && rx >= lastCounter.rx && tx >= lastCounter.tx {
+			monitor.NetRxRate = float64(rx-lastCounter.rx) / seconds
+			monitor.NetTxRate = float64(tx-lastCounter.tx) / seconds
+		}
+	}
+
+	return m.Insert(context.Background(), monitor)
+}
+
+func (m *machineMonitorAppImpl) GetMonitors(query *entity.MachineMonitorQuery) ([]*entity.MachineMonitor, error) {
+	endTime := time.Now()
+	if query.EndTime != nil {
+		endTime = *query.EndTime
+	}
+	startTime := endTime.Add(-24 * time.Hour)
+	if query.StartTime != nil {
+		startTime = *query.StartTime
+	}
+	if !startTime.Before(endTime) {
+		return nil, errorx.NewBiz("the start time must be before the end time")
+	}
+
+	monitors, err := m.ListByCond(model.NewCond().
+		Eq("machine_id", query.MachineId).
+		Ge("create_time", startTime).
+		Le("create_time", endTime).
+		OrderByAsc("create_time"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(monitors) <= machineMonitorMaxPoints {
+		return monitors, nil
+	}
+	bucket := endTime.Sub(startTime) / machineMonitorMaxPoints
+	return aggregateMachineMonitors(monitors, bucket, entity.MachineMonitorGranularityRaw), nil
+}
+
+func (m *machineMonitorAppImpl) TimerDownsample() {
+	logx.Debug("start downsampling machine monitor data every hour...")
+	scheduler.AddFun("@every 60m", func() {
+		mc := config.GetMachine()
+		// 仅聚合完整的小时数据
+		rawCutoff := time.Now().AddDate(0, 0, -mc.MonitorRawDays).Truncate(time.Hour)
+		if err := m.downsample(rawCutoff); err != nil {
+			logx.Errorf("failed to downsample machine monitor data: %s", err.Error())
+		}
+
+		saveCutoff := time.Now().AddDate(0, 0, -mc.MonitorSaveDays)
+		if err := m.DeleteByCond(context.Background(), model.NewCond().Lt("create_time", saveCutoff)); err != nil {
+			logx.Errorf("failed to delete expired machine monitor data: %s", err.Error())
+		}
+	})
+}
+
+// downsample 将指定时间之前的原始采样按机器聚合为小时数据
+func (m *machineMonitorAppImpl) downsample(cutoff time.Time) error {
+	rawCond := model.NewCond().Eq("granularity", entity.MachineMonitorGranularityRaw).Lt("create_time", cutoff)
+	machines, err := m.ListByCond(rawCond.Columns("machine_id"))
+	if err != nil {
+		return err
+	}
+	machineIds := collx.ArrayDeduplicate(collx.ArrayMap(machines, func(mm *entity.MachineMonitor) uint64 {
+		return mm.MachineId
+	}))
+
+	for _, machineId := range machineIds {
+		machineRawCond := model.NewCond().Eq("machine_id", machineId).Eq("granularity", entity.MachineMonitorGranularityRaw).Lt("create_time", cutoff)
+		raws, err := m.ListByCond(machineRawCond)
+		if err != nil {
+			return err
+		}
+		hours := aggregateMachineMonitors(raws, time.Hour, entity.MachineMonitorGranularityHour)
+
+		if err := m.Tx(context.Background(), func(ctx context.Context) error {
+			return m.BatchInsert(ctx, hours)
+		}, func(ctx context.Context) error {
+			return m.DeleteByCond(ctx, machineRawCond)
+		}); err != nil {
+			return err
+		}
+		logx.Debugf("machine [id=%d] monitor data downsampled: %d raw -> %d hourly", machineId, len(raws), len(hours))
+	}
+	return nil
+}
+
+// aggregateMachineMonitors 将监控数据按时间桶取平均值聚合，返回按时间升序的聚合结果
+func aggregateMachineMonitors(monitors []*entity.MachineMonitor, bucket time.Duration, granularity int8) []*entity.MachineMonitor {
+	if bucket <= 0 {
+		return monitors
+	}
+
+	type aggregation struct {
+		sum     *entity.MachineMonitor
+		count   int
+		fsCount map[string]int
+	}
+	aggs := make(map[int64]*aggregation)
+	for _, monitor := range monitors {
+		bucketTime := monitor.CreateTime.Truncate(bucket)
+		key := bucketTime.Unix()
+		agg := aggs[key]
+		if agg == nil {
+			agg = &aggregation{
+				sum: &entity.MachineMonitor{
+					MachineId:   monitor.MachineId,
+					Granularity: granularity,
+					FsRates:     make(model.Map[string, float32]),
+					CreateTime:  bucketTime,
+				},
+				fsCount: make(map[string]int),
+			}
+			aggs[key] = agg
+		}
+
+		sum := agg.sum
+		sum.CpuRate += monitor.CpuRate
+		sum.MemRate += monitor.MemRate
+		sum.Load1 += monitor.Load1
+		sum.Load5 += monitor.Load5
+		sum.Load15 += monitor.Load15
+		sum.NetRxRate += monitor.NetRxRate
+		sum.NetTxRate += monitor.NetTxRate
+		for mount, rate := range monitor.FsRates {
+			sum.FsRates[mount] += rate
+			agg.fsCount[mount]++
+		}
+		agg.count++
+	}
+
+	res := make([]*entity.MachineMonitor, 0, len(aggs))
+	for _, agg := range aggs {
+		sum, count := agg.sum, float32(agg.count)
+		sum.CpuRate /= count
+		sum.MemRate /= count
+		sum.Load1 /= count
+		sum.Load5 /= count
+		sum.Load15 /= count
+		sum.NetRxRate /= float64(agg.count)
+		sum.NetTxRate /= float64(agg.count)
+		for mount := range sum.FsRates {
+			sum.FsRates[mount] /= float32(agg.fsCount[mount])
+		}
+		res = append(res, sum)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreateTime.Before(res[j].CreateTime)
+	})
+	return res
+}
@@ -0,0 +1,521 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"mayfly-go/internal/machine/application/dto"
+	"mayfly-go/internal/machine/config"
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/internal/machine/mcm"
+	tagapp "mayfly-go/internal/tag/application"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/contextx"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/model"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	portForwardCloseByUser       = "closed by user"
+	portForwardCloseExpired      = "expired"
+	portForwardCloseRestarted    = "server restarted"
+	portForwardCloseDisconnected = "disconnected"
+)
+
+type MachinePortForward interface {
+	base.App[*entity.MachinePortForward]
+
+	// GetPageList 分页获取端口转发记录，转发中的记录会填充实时的连接数及流量
+	GetPageList(condition *entity.MachinePortForwardQuery, orderBy ...string) (*model.PageResult[*entity.MachinePortForward], error)
+
+	// Open 开启监听端口方式的端口转发，连接至本服务监听端口的tcp连接会经机器ssh连接转发至目标地址
+	Open(ctx context.Context, param *dto.MachinePortForwardOpen) (*entity.MachinePortForward, error)
+
+	// ServeWs 将websocket连接作为tcp流经机器ssh连接转发至目标地址，直至任意一端关闭或转发过期
+	ServeWs(ctx context.Context, param *dto.MachinePortForwardOpen, wsConn *websocket.Conn) (*entity.MachinePortForward, error)
+
+	// Close 关闭端口转发
+	Close(ctx context.Context, id uint64) error
+
+	// InitPortForward 将服务重启前未关闭的端口转发标记为已关闭，并注册ssh隧道机器的使用检测
+	InitPortForward()
+}
+
+type machinePortForwardAppImpl struct {
+	base.AppImpl[*entity.MachinePortForward, repository.MachinePortForward]
+
+	machineApp Machine        `inject:"T"`
+	tagApp     tagapp.TagTree `inject:"T"`
+}
+
+var _ (MachinePortForward) = (*machinePortForwardAppImpl)(nil)
+
+var (
+	// 当前服务中转发中的端口转发 id -> portForward
+	portForwards     = make(map[uint64]*portForward)
+	portForwardMutex sync.Mutex
+)
+
+// portForward 转发中的端口转发
+type portForward struct {
+	record   *entity.MachinePortForward
+	listener net.Listener // 监听端口方式的监听器
+	onClose  func()       // 关闭时的回调，如关闭websocket连接
+	timer    *time.Timer
+
+	mutex     sync.Mutex
+	conns     map[io.Closer]struct{}
+	closed    bool
+	connCount atomic.Int64
+	bytesIn   atomic.Int64
+	bytesOut  atomic.Int64
+}
+
+func (m *machinePortForwardAppImpl) GetPageList(condition *entity.MachinePortForwardQuery, orderBy ...string) (*model.PageResult[*entity.MachinePortForward], error) {
+	res, err := m.GetRepo().GetPageList(condition, orderBy...)
+	if err != nil {
+		return nil, err
+	}
+
+	portForwardMutex.Lock()
+	defer portForwardMutex.Unlock()
+	for _, record := range res.List {
+		if pf := portForwards[record.Id]; pf != nil {
+			pf.fillStats(record)
+		}
+	}
+	return res, nil
+}
+
+func (m *machinePortForwardAppImpl) Open(ctx context.Context, param *dto.MachinePortForwardOpen) (*entity.MachinePortForward, error) {
+	pf, err := m.newPortForward(ctx, param, entity.MachinePortForwardModeListen)
+	if err != nil {
+		return nil, err
+	}
+
+	mc := config.GetMachine()
+	// 监听非回环地址时，其他主机也可访问该端口，故仅允许开启转发的客户端连接
+	if !isLoopbackHost(mc.PortForwardBindHost) {
+		if param.ClientIp == "" {
+			return nil, errorx.NewBiz("failed to get the client ip")
+		}
+		pf.record.AllowIp = param.ClientIp
+	}
+
+	// 未配置访问地址时展示监听地址，监听所有地址(如0.0.0.0)时无法确定用户可访问的地址，需配置访问地址
+	host := mc.PortForwardHost
+	if host == "" {
+		if isUnspecifiedHost(mc.PortForwardBindHost) {
+			return nil, errorx.NewBiz("the port forward access address must be configured when listening on all addresses")
+		}
+		host = mc.PortForwardBindHost
+	}
+
+	listener, err := listenPortForward(mc)
+	if err != nil {
+		return nil, errorx.NewBiz("failed to listen the forward port: %s", err.Error())
+	}
+	pf.listener = listener
+	pf.record.ListenAddr = net.JoinHostPort(host, strconv.Itoa(listener.Addr().(*net.TCPAddr).Port))
+
+	if err := m.start(ctx, pf); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	logx.Infof("machine port forward [%d] opened: %s -> [%s] %s:%d", pf.record.Id, pf.record.ListenAddr, pf.record.MachineName, pf.record.TargetHost, pf.record.TargetPort)
+
+	go m.acceptConns(pf)
+	return pf.record, nil
+}
+
+func (m *machinePortForwardAppImpl) ServeWs(ctx context.Context, param *dto.MachinePortForwardOpen, wsConn *websocket.Conn) (*entity.MachinePortForward, error) {
+	pf, err := m.newPortForward(ctx, param, entity.MachinePortForwardModeWs)
+	if err != nil {
+		return nil, err
+	}
+	// websocket方式在连接建立时即连接目标地址，以便尽早返回错误
+	remoteConn, err := m.dialTarget(ctx, pf.record)
+	if err != nil {
+		return nil, errorx.NewBiz("failed to connect %s:%d: %s", pf.record.TargetHost, pf.record.TargetPort, err.Error())
+	}
+	pf.onClose = func() { wsConn.Close() }
+
+	if err := m.start(ctx, pf); err != nil {
+		remoteConn.Close()
+		return nil, err
+	}
+	logx.Infof("machine port forward [%d] opened: websocket -> [%s] %s:%d", pf.record.Id, pf.record.MachineName, pf.record.TargetHost, pf.record.TargetPort)
+
+	stream := &wsStream{conn: wsConn}
+	if pf.trackConn(stream) && pf.trackConn(remoteConn) {
+		pf.pipe(stream, remoteConn)
+	} else {
+		remoteConn.Close()
+	}
+	m.closePortForward(pf, portForwardCloseDisconnected)
+	return pf.record, nil
+}
+
+func (m *machinePortForwardAppImpl) Close(ctx context.Context, id uint64) error {
+	record, err := m.GetById(id)
+	if err != nil {
+		return errorx.NewBiz("port forward not found")
+	}
+	if record.Status != entity.MachinePortForwardStatusActive {
+		return errorx.NewBiz("the port forward has been closed")
+	}
+
+	portForwardMutex.Lock()
+	pf := portForwards[id]
+	portForwardMutex.Unlock()
+	if pf != nil {
+		m.closePortForward(pf, portForwardCloseByUser)
+		return nil
+	}
+
+	// 不存在于当前服务中(如服务已重启)的端口转发，直接标记为已关闭
+	now := time.Now()
+	record.Status = entity.MachinePortForwardStatusClosed
+	record.CloseReason = portForwardCloseByUser
+	record.CloseTime = &now
+	return m.GetRepo().UpdateById(ctx, record, "Status", "CloseReason", "CloseTime")
+}
+
+func (m *machinePortForwardAppImpl) InitPortForward() {
+	now := time.Now()
+	if err := m.UpdateByCond(context.Background(), &entity.MachinePortForward{
+		Status:      entity.MachinePortForwardStatusClosed,
+		CloseReason: portForwardCloseRestarted,
+		CloseTime:   &now,
+	}, &entity.MachinePortForward{Status: entity.MachinePortForwardStatusActive}); err != nil {
+		logx.Errorf("failed to close the machine port forwards before restarting: %s", err.Error())
+	}
+
+	// 存在转发中的端口转发时，不关闭对应的ssh隧道机器连接
+	mcm.AddCheckSshTunnelMachineUseFunc(func(machineId int) bool {
+		portForwardMutex.Lock()
+		defer portForwardMutex.Unlock()
+		for _, pf := range portForwards {
+			if pf.record.MachineId == uint64(machineId) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// newPortForward 校验参数及机器权限，并生成端口转发记录
+func (m *machinePortForwardAppImpl) newPortForward(ctx context.Context, param *dto.MachinePortForwardOpen, mode int8) (*portForward, error) {
+	la := contextx.GetLoginAccount(ctx)
+	if la == nil {
+		return nil, errorx.NewBiz("login account not found")
+	}
+	if param.TargetHost == "" {
+		return nil, errorx.NewBiz("the target host cannot be empty")
+	}
+	if param.TargetPort <= 0 || param.TargetPort > 65535 {
+		return nil, errorx.NewBiz("invalid target port: %d", param.TargetPort)
+	}
+
+	mc := config.GetMachine()
+	ttl := param.Ttl
+	if ttl <= 0 || ttl > mc.PortForwardMaxTtl {
+		ttl = mc.PortForwardMaxTtl
+	}
+
+	mi, err := m.machineApp.ToMachineInfoById(param.MachineId)
+	if err != nil {
+		return nil, err
+	}
+	if mi.Protocol != entity.MachineProtocolSsh {
+		return nil, errorx.NewBiz("port forward only supports ssh machines")
+	}
+	if err := m.tagApp.CanAccess(la.Id, mi.CodePath...); err != nil {
+		return nil, err
+	}
+
+	return &portForward{
+		record: &entity.MachinePortForward{
+			MachineId:   mi.Id,
+			MachineName: mi.Name,
+			Mode:        mode,
+			TargetHost:  param.TargetHost,
+			TargetPort:  param.TargetPort,
+			Ttl:         ttl,
+			Status:      entity.MachinePortForwardStatusActive,
+			Remark:      param.Remark,
+		},
+		conns: make(map[io.Closer]struct{}),
+	}, nil
+}
+
+// start 校验用户转发数上限后保存记录，并在过期后自动关闭
+func (m *machinePortForwardAppImpl) start(ctx context.Context, pf *portForward) error {
+	la := contextx.GetLoginAccount(ctx)
+
+	portForwardMutex.Lock()
+	defer portForwardMutex.Unlock()
+
+	// 以数据库中转发中的记录统计，服务重启时会将其全部标记为已关闭，故与实际转发数一致
+	limit := config.GetMachine().PortForwardUserLimit
+	count := m.CountByCond(model.NewCond().Eq("creator_id", la.Id).Eq("status", entity.MachinePortForwardStatusActive))
+	if count >= int64(limit) {
+		return errorx.NewBiz("the number of port forwards has reached the limit: %d", limit)
+	}
+
+	ttl := time.Duration(pf.record.Ttl) * time.Minute
+	expireTime := time.Now().Add(ttl)
+	pf.record.ExpireTime = &expireTime
+	if err := m.Insert(ctx, pf.record); err != nil {
+		return err
+	}
+
+	portForwards[pf.record.Id] = pf
+	pf.timer = time.AfterFunc(ttl, func() {
+		m.closePortForward(pf, portForwardCloseExpired)
+	})
+	return nil
+}
+
+func (m *machinePortForwardAppImpl) acceptConns(pf *portForward) {
+	for {
+		clientConn, err := pf.listener.Accept()
+		if err != nil {
+			m.closePortForward(pf, fmt.Sprintf("listener closed: %s", err.Error()))
+			return
+		}
+		if allowIp := pf.record.AllowIp; allowIp != "" {
+			if remoteIp, _, _ := net.SplitHostPort(clientConn.RemoteAddr().String()); !isSameIp(remoteIp, allowIp) {
+				logx.Warnf("machine port forward [%d] rejected the connection from %s, only %s is allowed", pf.record.Id, remoteIp, allowIp)
+				clientConn.Close()
+				continue
+			}
+		}
+		go m.forwardConn(pf, clientConn)
+	}
+}
+
+func (m *machinePortForwardAppImpl) forwardConn(pf *portForward, clientConn net.Conn) {
+	if !pf.trackConn(clientConn) {
+		clientConn.Close()
+		return
+	}
+
+	remoteConn, err := m.dialTarget(context.Background(), pf.record)
+	if err != nil {
+		logx.Warnf("machine port forward [%d] failed to connect %s:%d: %s", pf.record.Id, pf.record.TargetHost, pf.record.TargetPort, err.Error())
+		pf.untrackConn(clientConn)
+		return
+	}
+	if !pf.trackConn(remoteConn) {
+		remoteConn.Close()
+		pf.untrackConn(clientConn)
+		return
+	}
+	pf.pipe(clientConn, remoteConn)
+}
+
+// dialTarget 经机器ssh连接访问目标地址
+func (m *machinePortForwardAppImpl) dialTarget(ctx context.Context, record *entity.MachinePortForward) (net.Conn, error) {
+	stm, err := m.machineApp.GetSshTunnelMachine(ctx, int(record.MachineId))
+	if err != nil {
+		return nil, err
+	}
+	return stm.GetDialConn("tcp", net.JoinHostPort(record.TargetHost, strconv.Itoa(record.TargetPort)))
+}
+
+func (m *machinePortForwardAppImpl) closePortForward(pf *portForward, reason string) {
+	pf.mutex.Lock()
+	if pf.closed {
+		pf.mutex.Unlock()
+		return
+	}
+	pf.closed = true
+	conns := pf.conns
+	pf.conns = nil
+	pf.mutex.Unlock()
+
+	if pf.timer != nil {
+		pf.timer.Stop()
+	}
+	if pf.listener != nil {
+		pf.listener.Close()
+	}
+	for conn := range conns {
+		conn.Close()
+	}
+	if pf.onClose != nil {
+		pf.onClose()
+	}
+
+	portForwardMutex.Lock()
+	delete(portForwards, pf.record.Id)
+	record := *pf.record
+	portForwardMutex.Unlock()
+
+	now := time.Now()
+	pf.fillStats(&record)
+	record.Status = entity.MachinePortForwardStatusClosed
+	record.CloseReason = reason
+	record.CloseTime = &now
+	if err := m.GetRepo().UpdateById(context.Background(), &record, "Status", "CloseReason", "CloseTime", "ConnCount", "BytesIn", "BytesOut"); err != nil {
+		logx.Errorf("failed to update the machine port forward [%d]: %s", record.Id, err.Error())
+	}
+	logx.Infof("machine port forward [%d] closed: %s", record.Id, reason)
+}
+
+// isLoopbackHost 是否为仅本机可访问的回环地址
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// isUnspecifiedHost 是否为监听所有地址的空地址，如0.0.0.0、::
+func isUnspecifiedHost(host string) bool {
+	if host == "" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsUnspecified()
+}
+
+// isSameIp 比较两个ip是否相同，兼容ipv4映射的ipv6地址
+func isSameIp(ip1, ip2 string) bool {
+	parsedIp1, parsedIp2 := net.ParseIP(ip1), net.ParseIP(ip2)
+	return parsedIp1 != nil && parsedIp1.Equal(parsedIp2)
+}
+
+// listenPortForward 在配置的端口范围内监听，未配置端口范围则由系统随机分配端口
+func listenPortForward(mc *config.Machine) (net.Listener, error) {
+	start, end := mc.PortForwardPortStart, mc.PortForwardPortEnd
+	if start <= 0 || end < start {
+		return net.Listen("tcp", net.JoinHostPort(mc.PortForwardBindHost, "0"))
+	}
+
+	// 从随机位置开始尝试，减少并发开启时的端口冲突
+	n := end - start + 1
+	offset := rand.IntN(n)
+	for i := range n {
+		port := start + (offset+i)%n
+		if listener, err := net.Listen("tcp", net.JoinHostPort(mc.PortForwardBindHost, strconv.Itoa(port))); err == nil {
+			return listener, nil
+		}
+	}
+	return nil, errors.New("no available port in the configured range")
+}
+
+// trackConn 记录连接以便关闭转发时一并关闭，转发已关闭则返回false
+func (pf *portForward) trackConn(conn io.Closer) bool {
+	pf.mutex.Lock()
+	defer pf.mutex.Unlock()
+	if pf.closed {
+		return false
+	}
+	pf.conns[conn] = struct{}{}
+	return true
+}
+
+func (pf *portForward) untrackConn(conn io.Closer) {
+	conn.Close()
+	pf.mutex.Lock()
+	defer pf.mutex.Unlock()
+	delete(pf.conns, conn)
+}
+
+// pipe 双向转发数据，直至任意一端关闭
+func (pf *portForward) pipe(client, remote io.ReadWriteCloser) {
+	pf.connCount.Add(1)
+	defer pf.untrackConn(client)
+	defer pf.untrackConn(remote)
+
+	var wg sync.WaitGroup
+	var closeOnce sync.Once
+	closeBoth := func() {
+		client.Close()
+		remote.Close()
+	}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		io.Copy(remote, &countingReader{reader: client, count: &pf.bytesIn})
+		closeOnce.Do(closeBoth)
+	}()
+	go func() {
+		defer wg.Done()
+		io.Copy(client, &countingReader{reader: remote, count: &pf.bytesOut})
+		closeOnce.Do(closeBoth)
+	}()
+	wg.Wait()
+}
+
+func (pf *portForward) fillStats(record *entity.MachinePortForward) {
+	record.ConnCount = pf.connCount.Load()
+	record.BytesIn = pf.bytesIn.Load()
+	record.BytesOut = pf.bytesOut.Load()
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  *atomic.Int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count.Add(int64(n))
+	return n, err
+}
+
+// wsStream 将websocket连接的消息作为连续的字节流读写
+type wsStream struct {
+	conn   *websocket.Conn
+	reader io.Reader
+	wmutex sync.Mutex
+}
+
+func (s *wsStream) Read(p []byte) (int, error) {
+	for {
+		if s.reader == nil {
+			_, reader, err := s.conn.NextReader()
+			if err != nil {
+				return 0, err
+			}
+			s.reader = reader
+		}
+		n, err := s.reader.Read(p)
+		if errors.Is(err, io.EOF) {
+			s.reader = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (s *wsStream) Write(p []byte) (int, error) {
+	s.wmutex.Lock()
+	defer s.wmutex.Unlock()
+	if err := s.conn.WriteMessage(websocket.BinaryMessage, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (s *wsStream) Close() error {
+	return s.conn.Close()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"mayfly-go/internal/machine/domain/entity"
 	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/internal/machine/mcm"
 	"mayfly-go/pkg/base"
 	"mayfly-go/pkg/errorx"
 	"mayfly-go/pkg/model"
@@ -22,6 +23,9 @@ type MachineScript interface {
 	Save(ctx context.Context, entity *entity.MachineScript) error
 
 	Delete(ctx context.Context, id uint64)
+
+	// RenderScript 校验参数值并替换脚本中的参数占位符，返回实际执行的脚本及secret类参数脱敏后的脚本
+	RenderScript(ms *entity.MachineScript, params map[string]any) (string, string, error)
 }
 
 var _ (MachineScript) = (*machineScriptAppImpl)(nil)
@@ -58,6 +62,15 @@ func (m *machineScriptAppImpl) Save(ctx context.Context, ms *entity.MachineScrip
 		}
 	}
 
+	// 校验参数定义，如参数名、类型、默认值等
+	scriptParams, err := mcm.ParseScriptParams(ms.Params)
+	if err != nil {
+		return err
+	}
+	if err := mcm.CheckScriptParamModels(scriptParams); err != nil {
+		return err
+	}
+
 	if ms.Id != 0 {
 		return m.UpdateById(ctx, ms)
 	}
@@ -68,3 +81,11 @@ func (m *machineScriptAppImpl) Save(ctx context.Context, ms *entity.MachineScrip
 func (m *machineScriptAppImpl) Delete(ctx context.Context, id uint64) {
 	m.DeleteById(ctx, id)
 }
+
+func (m *machineScriptAppImpl) RenderScript(ms *entity.MachineScript, params map[string]any) (string, string, error) {
+	scriptParams, err := mcm.ParseScriptParams(ms.Params)
+	if err != nil {
+		return "", "", err
+	}
+	return mcm.RenderScript(ms.Script, scriptParams, params)
+}
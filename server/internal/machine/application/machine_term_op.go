@@ -3,21 +3,33 @@ package application
 import (
 	"context"
 	"fmt"
+	"io"
 	fileapp "mayfly-go/internal/file/application"
+	flowapp "mayfly-go/internal/flow/application"
+	flowdto "mayfly-go/internal/flow/application/dto"
+	flowentity "mayfly-go/internal/flow/domain/entity"
 	"mayfly-go/internal/machine/config"
 	"mayfly-go/internal/machine/domain/entity"
 	"mayfly-go/internal/machine/domain/repository"
 	"mayfly-go/internal/machine/imsg"
 	"mayfly-go/internal/machine/mcm"
+	tagapp "mayfly-go/internal/tag/application"
 	"mayfly-go/pkg/base"
 	"mayfly-go/pkg/contextx"
 	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/i18n"
 	"mayfly-go/pkg/logx"
 	"mayfly-go/pkg/model"
 	"mayfly-go/pkg/scheduler"
+	"mayfly-go/pkg/utils/collx"
 	"mayfly-go/pkg/utils/jsonx"
 	"mayfly-go/pkg/utils/stringx"
 	"mayfly-go/pkg/utils/timex"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -25,32 +37,81 @@ import (
 
 type MachineTermOp interface {
 	base.App[*entity.MachineTermOp]
+	flowapp.FlowBizHandler
 
 	// 终端连接操作
 	TermConn(ctx context.Context, cli *mcm.Cli, wsConn *websocket.Conn, rows, cols int) error
 
+	// ContainerTermConn 通过docker exec连接容器终端，同样支持命令过滤、审批及终端回放
+	ContainerTermConn(ctx context.Context, cli *mcm.Cli, wsConn *websocket.Conn, containerId string, rows, cols int) error
+
 	GetPageList(condition *entity.MachineTermOp, pageParam model.PageParam, orderBy ...string) (*model.PageResult[*entity.MachineTermOp], error)
 
 	// 定时删除终端文件回放记录
 	TimerDeleteTermOp()
+
+	// GetTerminalSessions 获取当前账号可访问机器的活跃终端会话
+	GetTerminalSessions(ctx context.Context) []*mcm.TerminalSessionInfo
+
+	// ShadowTerminalSession 只读旁观活跃终端会话的输出
+	ShadowTerminalSession(ctx context.Context, sessionId string, wsConn *websocket.Conn) error
+
+	// KillTerminalSession 强制断开活跃终端会话，并向会话用户展示断开原因
+	KillTerminalSession(ctx context.Context, sessionId string, msg string) (*mcm.TerminalSessionInfo, error)
+
+	// GetCmdPageList 分页检索终端会话中执行的命令
+	GetCmdPageList(condition *entity.MachineTermOpCmdQuery, orderBy ...string) (*model.PageResult[*entity.MachineTermOpCmd], error)
+
+	// WriteTranscript 将终端回放记录导出为纯文本记录
+	WriteTranscript(ctx context.Context, termOp *entity.MachineTermOp, w io.Writer) error
+
+	// CreateGuacTermOp 创建rdp、vnc图形化会话的操作记录，并返回guacd录像文件名
+	CreateGuacTermOp(ctx context.Context, mi *mcm.MachineInfo, ac string) (*entity.MachineTermOp, string, error)
+
+	// EndGuacTermOp 结束图形化会话的操作记录
+	EndGuacTermOp(termOp *entity.MachineTermOp) error
+
+	// GetGuacRecReader 获取图形化会话的guacd录像文件(Guacamole session dump格式)
+	GetGuacRecReader(termOp *entity.MachineTermOp) (io.ReadCloser, error)
 }
 
 type machineTermOpAppImpl struct {
 	base.AppImpl[*entity.MachineTermOp, repository.MachineTermOp]
 
+	machineTermOpCmdRepo repository.MachineTermOpCmd `inject:"T"`
+
 	machineCmdConfApp MachineCmdConf `inject:"T"`
 	fileApp           fileapp.File   `inject:"T"`
+	tagApp            tagapp.TagTree `inject:"T"`
+
+	procdefApp  flowapp.Procdef  `inject:"T"`
+	procinstApp flowapp.Procinst `inject:"T"`
 }
 
+// 等待审批结果的终端命令，bizKey -> chan struct{}
+var termCmdApproveWaiters sync.Map
+
 func (m *machineTermOpAppImpl) TermConn(ctx context.Context, cli *mcm.Cli, wsConn *websocket.Conn, rows, cols int) error {
+	return m.termConn(ctx, cli, wsConn, "", rows, cols)
+}
+
+func (m *machineTermOpAppImpl) ContainerTermConn(ctx context.Context, cli *mcm.Cli, wsConn *websocket.Conn, containerId string, rows, cols int) error {
+	if err := mcm.CheckDockerContainerId(containerId); err != nil {
+		return err
+	}
+	return m.termConn(ctx, cli, wsConn, containerId, rows, cols)
+}
+
+// termConn 连接终端，containerId不为空时通过docker exec进入容器
+func (m *machineTermOpAppImpl) termConn(ctx context.Context, cli *mcm.Cli, wsConn *websocket.Conn, containerId string, rows, cols int) error {
 	var recorder *mcm.Recorder
 	var termOpRecord *entity.MachineTermOp
 	var err error
+	la := contextx.GetLoginAccount(ctx)
 
 	// 开启终端操作记录
 	if cli.Info.EnableRecorder == 1 {
 		now := time.Now()
-		la := contextx.GetLoginAccount(ctx)
 
 		termOpRecord = new(entity.MachineTermOp)
 
@@ -60,6 +121,7 @@ func (m *machineTermOpAppImpl) TermConn(ctx context.Context, cli *mcm.Cli, wsCon
 
 		termOpRecord.MachineId = cli.Info.Id
 		termOpRecord.Username = cli.Info.Username
+		termOpRecord.Container = containerId
 
 		fileKey, wc, saveFileFunc, err := m.fileApp.NewWriter(ctx, "", fmt.Sprintf("mto_%d_%s.cast", termOpRecord.MachineId, timex.TimeNo()))
 		if err != nil {
@@ -79,19 +141,39 @@ func (m *machineTermOpAppImpl) TermConn(ctx context.Context, cli *mcm.Cli, wsCon
 		Cols:      cols,
 		Recorder:  recorder,
 		LogCmd:    cli.Info.EnableRecorder == 1,
+		CreatorId: la.Id,
+		Creator:   la.Username,
+	}
+	if containerId != "" {
+		createTsParam.Cmd = mcm.DockerExecCmd(containerId)
 	}
 
-	cmdConfs := m.machineCmdConfApp.GetCmdConfsByMachineTags(ctx, cli.Info.CodePath...)
-	if len(cmdConfs) > 0 {
+	var denyCmdConfs, approveCmdConfs []*MachineCmd
+	for _, cmdConf := range m.machineCmdConfApp.GetCmdConfsByMachineTags(ctx, cli.Info.CodePath...) {
+		if cmdConf.Stratege == entity.MachineCmdStrategeApprove {
+			approveCmdConfs = append(approveCmdConfs, cmdConf)
+		} else {
+			denyCmdConfs = append(denyCmdConfs, cmdConf)
+		}
+	}
+	if len(denyCmdConfs) > 0 {
 		createTsParam.CmdFilterFuncs = []mcm.CmdFilterFunc{func(cmd string) error {
-			for _, cmdConf := range cmdConfs {
-				if cmdConf.CmdRegexp.Match([]byte(cmd)) {
-					return errorx.NewBizI(ctx, imsg.TerminalCmdDisable)
-				}
+			if MatchMachineCmd(cmd, denyCmdConfs...) != nil {
+				return errorx.NewBizI(ctx, imsg.TerminalCmdDisable)
 			}
 			return nil
 		}}
 	}
+	if len(approveCmdConfs) > 0 {
+		createTsParam.CmdApproveFuncs = []mcm.CmdApproveFunc{func(cmd string) mcm.CmdApproveWaitFunc {
+			if MatchMachineCmd(cmd, approveCmdConfs...) == nil {
+				return nil
+			}
+			return func(waitCtx context.Context, notify func(msg string)) error {
+				return m.approveTermCmd(ctx, waitCtx, cli, cmd, notify)
+			}
+		}}
+	}
 
 	mts, err := mcm.NewTerminalSession(createTsParam)
 	if err != nil {
@@ -104,16 +186,256 @@ func (m *machineTermOpAppImpl) TermConn(ctx context.Context, cli *mcm.Cli, wsCon
 	if termOpRecord != nil {
 		now := time.Now()
 		termOpRecord.EndTime = &now
-		termOpRecord.ExecCmds = jsonx.ToStr(mts.GetExecCmds())
-		return m.Insert(ctx, termOpRecord)
+		execCmds := mts.GetExecCmds()
+		termOpRecord.ExecCmds = jsonx.ToStr(execCmds)
+		if err := m.Insert(ctx, termOpRecord); err != nil {
+			return err
+		}
+		return m.saveExecCmds(ctx, termOpRecord, execCmds)
 	}
 	return nil
 }
 
+type FlowMachineTermCmdBizForm struct {
+	MachineId   uint64 `json:"machineId"`   // 机器id
+	MachineCode string `json:"machineCode"` // 机器编号
+	MachineName string `json:"machineName"` // 机器名称
+	Username    string `json:"username"`    // 终端登录的机器用户名
+	Cmd         string `json:"cmd"`         // 待执行的命令
+}
+
+// approveTermCmd 提交终端命令审批流程，并等待至审批通过、拒绝、退回、取消或超时
+func (m *machineTermOpAppImpl) approveTermCmd(ctx context.Context, waitCtx context.Context, cli *mcm.Cli, cmd string, notify func(msg string)) error {
+	procdef := m.procdefApp.GetProcdefByCodePath(ctx, cli.Info.CodePath...)
+	if procdef == nil {
+		return errorx.NewBizI(ctx, imsg.ErrTermCmdNoApproveFlow)
+	}
+
+	bizKey := stringx.RandUUID()
+	waiter := make(chan struct{}, 1)
+	termCmdApproveWaiters.Store(bizKey, waiter)
+	defer termCmdApproveWaiters.Delete(bizKey)
+
+	procinst, err := m.procinstApp.StartProc(ctx, procdef.Id, &flowdto.StarProc{
+		BizType: MachineTermCmdFlowBizType,
+		BizKey:  bizKey,
+		BizForm: jsonx.ToStr(&FlowMachineTermCmdBizForm{
+			MachineId:   cli.Info.Id,
+			MachineCode: cli.Info.Code,
+			MachineName: cli.Info.Name,
+			Username:    cli.Info.Username,
+			Cmd:         cmd,
+		}),
+		Remark: cmd,
+	})
+	if err != nil {
+		return err
+	}
+
+	timeout := config.GetMachine().CmdApproveTimeout
+	notify(mcm.GetWarnContentRn(i18n.TC(ctx, imsg.MsgTermCmdWaitApprove, "procinstId", procinst.Id, "timeout", timeout)))
+
+	timer := time.NewTimer(time.Duration(timeout) * time.Second)
+	defer timer.Stop()
+	// 审批拒绝或取消不会回调业务处理，故需定时检查流程状态
+	ticker := time.NewTicker(3 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-waiter:
+			notify(mcm.GetWarnContentRn(i18n.TC(ctx, imsg.MsgTermCmdApproved)))
+			return nil
+		case <-ticker.C:
+			pi, err := m.procinstApp.GetById(procinst.Id)
+			if err != nil {
+				continue
+			}
+			switch pi.Status {
+			case flowentity.ProcinstStatusCompleted:
+				notify(mcm.GetWarnContentRn(i18n.TC(ctx, imsg.MsgTermCmdApproved)))
+				return nil
+			case flowentity.ProcinstStatusTerminated, flowentity.ProcinstStatusCancelled:
+				return errorx.NewBizI(ctx, imsg.ErrTermCmdApproveRejected)
+			case flowentity.ProcinstStatusSuspended:
+				// 被退回的流程无法在终端中修改后重新提交，视为拒绝并取消流程
+				m.cancelTermCmdProc(ctx, procinst.Id)
+				return errorx.NewBizI(ctx, imsg.ErrTermCmdApproveRejected)
+			}
+		case <-timer.C:
+			m.cancelTermCmdProc(ctx, procinst.Id)
+			return errorx.NewBizI(ctx, imsg.ErrTermCmdApproveTimeout)
+		case <-waitCtx.Done():
+			m.cancelTermCmdProc(ctx, procinst.Id)
+			return waitCtx.Err()
+		}
+	}
+}
+
+// cancelTermCmdProc 取消超时或会话已结束的命令审批流程，避免审批人继续处理
+func (m *machineTermOpAppImpl) cancelTermCmdProc(ctx context.Context, procinstId uint64) {
+	if err := m.procinstApp.CancelProc(ctx, procinstId); err != nil {
+		logx.Warnf("failed to cancel the machine terminal cmd approval flow [%d]: %s", procinstId, err.Error())
+	}
+}
+
+func (m *machineTermOpAppImpl) FlowBizHandle(ctx context.Context, bizHandleParam *flowapp.BizHandleParam) (any, error) {
+	procinst := bizHandleParam.Procinst
+	bizKey := procinst.BizKey
+	procinstStatus := procinst.Status
+
+	logx.Debugf("MachineTermCmd FlowBizHandle -> bizKey: %s, procinstStatus: %s", bizKey, flowentity.ProcinstStatusEnum.GetDesc(procinstStatus))
+	// 流程非完成状态，不处理
+	if procinstStatus != flowentity.ProcinstStatusCompleted {
+		return nil, nil
+	}
+
+	waiter, ok := termCmdApproveWaiters.LoadAndDelete(bizKey)
+	if !ok {
+		return nil, errorx.NewBiz("the terminal session has ended or the approval has timed out, the command was not executed")
+	}
+	// 放行终端会话中等待审批的命令
+	waiter.(chan struct{}) <- struct{}{}
+	return "the command has been released to the terminal session", nil
+}
+
+// saveExecCmds 保存终端会话执行的命令索引
+func (m *machineTermOpAppImpl) saveExecCmds(ctx context.Context, termOp *entity.MachineTermOp, execCmds []*mcm.ExecutedCmd) error {
+	if len(execCmds) == 0 {
+		return nil
+	}
+	return m.machineTermOpCmdRepo.BatchInsert(ctx, collx.ArrayMap(execCmds, func(execCmd *mcm.ExecutedCmd) *entity.MachineTermOpCmd {
+		return &entity.MachineTermOpCmd{
+			TermOpId:  termOp.Id,
+			MachineId: termOp.MachineId,
+			Cmd:       stringx.Truncate(execCmd.Cmd, 2000, 1900, "..."),
+			Offset:    execCmd.Offset,
+			ExecTime:  time.Unix(execCmd.Time, 0),
+			CreatorId: termOp.CreatorId,
+			Creator:   termOp.Creator,
+		}
+	}))
+}
+
+func (m *machineTermOpAppImpl) GetCmdPageList(condition *entity.MachineTermOpCmdQuery, orderBy ...string) (*model.PageResult[*entity.MachineTermOpCmd], error) {
+	return m.machineTermOpCmdRepo.GetPageList(condition, orderBy...)
+}
+
+func (m *machineTermOpAppImpl) WriteTranscript(ctx context.Context, termOp *entity.MachineTermOp, w io.Writer) error {
+	if termOp.FileKey == "" {
+		return errorx.NewBiz("the terminal playback file does not exist")
+	}
+	_, reader, err := m.fileApp.GetReader(ctx, termOp.FileKey)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return mcm.WriteTranscript(reader, w)
+}
+
 func (m *machineTermOpAppImpl) GetPageList(condition *entity.MachineTermOp, pageParam model.PageParam, orderBy ...string) (*model.PageResult[*entity.MachineTermOp], error) {
 	return m.GetRepo().GetPageList(condition, pageParam)
 }
 
+func (m *machineTermOpAppImpl) CreateGuacTermOp(ctx context.Context, mi *mcm.MachineInfo, ac string) (*entity.MachineTermOp, string, error) {
+	now := time.Now()
+	la := contextx.GetLoginAccount(ctx)
+	recName := fmt.Sprintf("%s_%s", timex.TimeNo(), stringx.Rand(8))
+
+	termOp := &entity.MachineTermOp{
+		MachineId:  mi.Id,
+		Username:   mi.Username,
+		Protocol:   int8(mi.Protocol),
+		RecFile:    path.Join(ac, recName),
+		CreateTime: &now,
+		CreatorId:  la.Id,
+		Creator:    la.Username,
+	}
+	if err := m.Insert(ctx, termOp); err != nil {
+		return nil, "", err
+	}
+	return termOp, recName, nil
+}
+
+func (m *machineTermOpAppImpl) EndGuacTermOp(termOp *entity.MachineTermOp) error {
+	now := time.Now()
+	update := &entity.MachineTermOp{EndTime: &now}
+	update.Id = termOp.Id
+	return m.UpdateById(context.Background(), update)
+}
+
+func (m *machineTermOpAppImpl) GetGuacRecReader(termOp *entity.MachineTermOp) (io.ReadCloser, error) {
+	recFilePath, err := getGuacRecFilePath(termOp.RecFile)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(recFilePath)
+	if err != nil {
+		return nil, errorx.NewBiz("failed to open the recording file: %s", err.Error())
+	}
+	return file, nil
+}
+
+// getGuacRecFilePath 获取guacd录像文件在本服务中的路径
+func getGuacRecFilePath(recFile string) (string, error) {
+	recPath := config.GetMachine().GuacdRecPath
+	if recFile == "" || recPath == "" {
+		return "", errorx.NewBiz("the recording file does not exist or the guacd recording path is not configured")
+	}
+	// 防止路径穿越
+	recFilePath := filepath.Join(recPath, filepath.Clean("/"+recFile))
+	return recFilePath, nil
+}
+
+func (m *machineTermOpAppImpl) GetTerminalSessions(ctx context.Context) []*mcm.TerminalSessionInfo {
+	la := contextx.GetLoginAccount(ctx)
+	infos := make([]*mcm.TerminalSessionInfo, 0)
+	for _, ts := range mcm.GetTerminalSessions() {
+		info := ts.GetInfo()
+		if m.tagApp.CanAccess(la.Id, info.CodePath...) != nil {
+			continue
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].StartTime.Before(infos[j].StartTime)
+	})
+	return infos
+}
+
+func (m *machineTermOpAppImpl) ShadowTerminalSession(ctx context.Context, sessionId string, wsConn *websocket.Conn) error {
+	ts, err := m.getAccessibleTerminalSession(ctx, sessionId)
+	if err != nil {
+		return err
+	}
+	ts.Shadow(wsConn)
+	return nil
+}
+
+func (m *machineTermOpAppImpl) KillTerminalSession(ctx context.Context, sessionId string, msg string) (*mcm.TerminalSessionInfo, error) {
+	ts, err := m.getAccessibleTerminalSession(ctx, sessionId)
+	if err != nil {
+		return nil, err
+	}
+	info := ts.GetInfo()
+	if msg == "" {
+		msg = i18n.TC(ctx, imsg.MsgTerminalSessionKilled)
+	}
+	ts.Kill(msg)
+	return info, nil
+}
+
+func (m *machineTermOpAppImpl) getAccessibleTerminalSession(ctx context.Context, sessionId string) (*mcm.TerminalSession, error) {
+	ts := mcm.GetTerminalSession(sessionId)
+	if ts == nil {
+		return nil, errorx.NewBizI(ctx, imsg.ErrTerminalSessionNotFound)
+	}
+	if err := m.tagApp.CanAccess(contextx.GetLoginAccount(ctx).Id, ts.GetInfo().CodePath...); err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
 func (m *machineTermOpAppImpl) TimerDeleteTermOp() {
 	logx.Debug("start deleting machine terminal playback records every hour...")
 	scheduler.AddFun("@every 60m", func() {
@@ -139,6 +461,21 @@ func (m *machineTermOpAppImpl) DeleteTermOp(termOp *entity.MachineTermOp) error
 	if err := m.DeleteById(context.Background(), termOp.Id); err != nil {
 		return err
 	}
+	if err := m.machineTermOpCmdRepo.DeleteByCond(context.Background(), &entity.MachineTermOpCmd{TermOpId: termOp.Id}); err != nil {
+		return err
+	}
+
+	// rdp、vnc会话的录像文件由guacd生成
+	if termOp.RecFile != "" {
+		recFilePath, err := getGuacRecFilePath(termOp.RecFile)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(recFilePath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
 
 	return m.fileApp.Remove(context.TODO(), termOp.FileKey)
 }
@@ -4,6 +4,7 @@ import (
 	sysapp "mayfly-go/internal/sys/application"
 	"mayfly-go/pkg/logx"
 	"mayfly-go/pkg/utils/bytex"
+	"strings"
 
 	"github.com/may-fly/cast"
 )
@@ -18,6 +19,20 @@ type Machine struct {
 	GuacdHost         string // guacd服务地址 默认 127.0.0.1
 	GuacdPort         int    // guacd服务端口  默认 4822
 	GuacdFilePath     string // guacd服务文件存储位置，用于挂载RDP文件夹
+	GuacdRecPath      string // guacd服务录像文件存储位置，即guacd容器/rdp-rec目录挂载至本服务的路径
+	MonitorRawDays    int    // 监控原始采样保存天数，超过后聚合为小时数据
+	MonitorSaveDays   int    // 监控数据保存天数
+	CmdApproveTimeout int    // 终端命令审批等待超时时间(秒)
+
+	HostKeyAlertReceiverIds []uint64 // 主机公钥不匹配告警接收人id，为空则通知管理员
+	HostKeyAlertMsgTmplCode string   // 主机公钥不匹配告警消息模板编号，为空则仅发送站内消息
+
+	PortForwardBindHost  string // 端口转发监听地址，默认 127.0.0.1；监听非回环地址时仅允许开启转发的客户端ip连接
+	PortForwardHost      string // 端口转发展示给用户的访问地址，默认为监听地址，监听所有地址(如0.0.0.0)时必须配置
+	PortForwardPortStart int    // 端口转发监听端口范围起始值，为0则由系统随机分配
+	PortForwardPortEnd   int    // 端口转发监听端口范围结束值
+	PortForwardMaxTtl    int    // 端口转发最大有效时长(分钟)
+	PortForwardUserLimit int    // 每个用户同时开启的端口转发数上限
 }
 
 // 获取机器相关配置
@@ -43,6 +58,32 @@ func GetMachine() *Machine {
 	mc.GuacdHost = cast.ToString(jm["guacdHost"])
 	mc.GuacdPort = cast.ToIntD(jm["guacdPort"], 4822)
 	mc.GuacdFilePath = cast.ToStringD(jm["guacdFilePath"], "")
+	mc.GuacdRecPath = cast.ToStringD(jm["guacdRecPath"], "")
+	// monitor
+	mc.MonitorRawDays = max(cast.ToIntD(jm["monitorRawDays"], 7), 1)
+	// 聚合数据由原始采样生成，保存天数不能小于原始采样保存天数
+	mc.MonitorSaveDays = max(cast.ToIntD(jm["monitorSaveDays"], 90), mc.MonitorRawDays)
+	// 终端命令审批
+	mc.CmdApproveTimeout = cast.ToIntD(jm["cmdApproveTimeout"], 300)
+	if mc.CmdApproveTimeout <= 0 {
+		mc.CmdApproveTimeout = 300
+	}
+	// 主机公钥告警
+	for _, id := range strings.Split(cast.ToString(jm["hostKeyAlertReceiverIds"]), ",") {
+		if receiverId := cast.ToUint64(strings.TrimSpace(id)); receiverId > 0 {
+			mc.HostKeyAlertReceiverIds = append(mc.HostKeyAlertReceiverIds, receiverId)
+		}
+	}
+	mc.HostKeyAlertMsgTmplCode = cast.ToString(jm["hostKeyAlertMsgTmplCode"])
+	// 端口转发
+	mc.PortForwardBindHost = cast.ToStringD(jm["portForwardBindHost"], "127.0.0.1")
+	mc.PortForwardHost = cast.ToString(jm["portForwardHost"])
+	if ports := strings.Split(cast.ToString(jm["portForwardPorts"]), "-"); len(ports) == 2 {
+		mc.PortForwardPortStart = cast.ToInt(strings.TrimSpace(ports[0]))
+		mc.PortForwardPortEnd = cast.ToInt(strings.TrimSpace(ports[1]))
+	}
+	mc.PortForwardMaxTtl = cast.ToIntD(jm["portForwardMaxTtl"], 120)
+	mc.PortForwardUserLimit = cast.ToIntD(jm["portForwardUserLimit"], 3)
 
 	return mc
 }
@@ -0,0 +1,71 @@
+package entity
+
+import (
+	"mayfly-go/pkg/model"
+	"time"
+)
+
+// MachineAlertRule 机器监控告警规则，通过标签关联适用的机器
+type MachineAlertRule struct {
+	model.Model
+
+	Name        string              `json:"name" gorm:"size:100;not null;comment:名称"`
+	Metric      string              `json:"metric" gorm:"size:20;not null;comment:监控指标"`          // 监控指标
+	MountPoint  string              `json:"mountPoint" gorm:"size:255;comment:磁盘挂载点"`             // 磁盘挂载点，仅disk指标有效，空则检测所有挂载点
+	Operator    string              `json:"operator" gorm:"size:5;not null;comment:比较运算符"`        // 比较运算符
+	Threshold   float64             `json:"threshold" gorm:"not null;comment:阈值"`                 // 阈值，unreachable指标为连续不可达次数
+	Duration    int                 `json:"duration" gorm:"comment:持续时长(分钟)"`                     // 持续满足条件的时长(分钟)才触发告警，0则立即触发
+	MsgTmplId   uint64              `json:"msgTmplId" gorm:"comment:通知消息模板id"`                    // 通知消息模板id
+	ReceiverIds model.Slice[uint64] `json:"receiverIds" gorm:"type:varchar(500);comment:通知接收人id"` // 通知接收人id
+	Status      int8                `json:"status" gorm:"not null;comment:状态 1.启用 -1.禁用"`         // 状态
+	Remark      string              `json:"remark" gorm:"size:255;comment:备注"`
+}
+
+const (
+	MachineAlertMetricCpu         = "cpu"         // cpu使用率
+	MachineAlertMetricMem         = "mem"         // 内存使用率
+	MachineAlertMetricLoad1       = "load1"       // 1分钟负载
+	MachineAlertMetricLoad5       = "load5"       // 5分钟负载
+	MachineAlertMetricLoad15      = "load15"      // 15分钟负载
+	MachineAlertMetricDisk        = "disk"        // 磁盘使用率
+	MachineAlertMetricUnreachable = "unreachable" // 连续不可达次数
+
+	MachineAlertRuleStatusEnable  int8 = 1
+	MachineAlertRuleStatusDisable int8 = -1
+)
+
+// MachineAlert 机器告警，同一规则、机器及挂载点同时只存在一条触发中的告警
+type MachineAlert struct {
+	model.IdModel
+
+	Fingerprint  string     `json:"fingerprint" gorm:"size:255;not null;index;comment:告警指纹"` // 告警指纹，用于去重
+	RuleId       uint64     `json:"ruleId" gorm:"not null;index;comment:规则id"`
+	RuleName     string     `json:"ruleName" gorm:"size:100;comment:规则名称"`
+	MachineId    uint64     `json:"machineId" gorm:"not null;index;comment:机器id"`
+	MachineName  string     `json:"machineName" gorm:"size:100;comment:机器名称"`
+	Metric       string     `json:"metric" gorm:"size:20;comment:监控指标"`
+	Target       string     `json:"target" gorm:"size:255;comment:告警对象"` // 告警对象，如磁盘挂载点
+	Value        float64    `json:"value" gorm:"comment:触发时的指标值"`
+	Threshold    float64    `json:"threshold" gorm:"comment:阈值"`
+	Status       int8       `json:"status" gorm:"not null;comment:状态 1.触发中 2.已恢复"`
+	Silenced     bool       `json:"silenced" gorm:"comment:是否被静默"` // 是否被静默，静默的告警不发送通知
+	StartTime    time.Time  `json:"startTime" gorm:"not null;comment:开始时间"`
+	ResolvedTime *time.Time `json:"resolvedTime" gorm:"comment:恢复时间"`
+	NotifyErr    string     `json:"notifyErr" gorm:"size:1000;comment:通知失败信息"`
+}
+
+const (
+	MachineAlertStatusFiring   int8 = 1
+	MachineAlertStatusResolved int8 = 2
+)
+
+// MachineAlertSilence 告警静默，生效时间内匹配的告警不发送通知
+type MachineAlertSilence struct {
+	model.Model
+
+	RuleId    uint64    `json:"ruleId" gorm:"comment:规则id，0则匹配所有规则"`
+	MachineId uint64    `json:"machineId" gorm:"comment:机器id，0则匹配所有机器"`
+	StartTime time.Time `json:"startTime" gorm:"not null;comment:开始时间"`
+	EndTime   time.Time `json:"endTime" gorm:"not null;comment:结束时间"`
+	Comment   string    `json:"comment" gorm:"size:255;comment:说明"`
+}
@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"mayfly-go/pkg/model"
+	"time"
+)
+
+// MachineCmdBatch 机器批量命令执行任务
+type MachineCmdBatch struct {
+	model.Model
+
+	Name         string              `json:"name" gorm:"size:100;comment:名称"`
+	ScriptId     uint64              `json:"scriptId" gorm:"comment:脚本id"`                    // 脚本id，为0则直接执行命令
+	Cmd          string              `json:"cmd" gorm:"type:text;comment:执行的命令"`              // 实际执行的命令(脚本参数已替换)
+	TagPaths     model.Slice[string] `json:"tagPaths" gorm:"type:varchar(2000);comment:目标标签"` // 目标标签路径
+	MachineIds   model.Slice[uint64] `json:"machineIds" gorm:"type:varchar(2000);comment:目标机器id"`
+	MachineCodes model.Slice[string] `json:"machineCodes" gorm:"type:varchar(2000);comment:目标机器编号"`
+	Concurrency  int                 `json:"concurrency" gorm:"comment:并发数"`
+	Timeout      int                 `json:"timeout" gorm:"comment:单机超时时间(秒)"`
+	Status       int8                `json:"status" gorm:"not null;comment:状态 1.执行中 2.已完成"`
+	Total        int                 `json:"total" gorm:"comment:机器总数"`
+	SuccessNum   int                 `json:"successNum" gorm:"comment:成功数"`
+	FailNum      int                 `json:"failNum" gorm:"comment:失败数"` // 失败数，包含拒绝执行及超时
+	EndTime      *time.Time          `json:"endTime" gorm:"comment:结束时间"`
+}
+
+const (
+	MachineCmdBatchStatusRunning int8 = 1
+	MachineCmdBatchStatusDone    int8 = 2
+)
+
+// MachineCmdBatchResult 批量命令在单台机器上的执行结果
+type MachineCmdBatchResult struct {
+	model.IdModel
+
+	BatchId     uint64     `json:"batchId" gorm:"not null;index;comment:批量任务id"`
+	MachineId   uint64     `json:"machineId" gorm:"not null;comment:机器id"`
+	MachineCode string     `json:"machineCode" gorm:"size:50;comment:机器编号"`
+	MachineName string     `json:"machineName" gorm:"size:100;comment:机器名称"`
+	Status      int8       `json:"status" gorm:"not null;comment:状态 1.等待执行 2.执行中 3.成功 -1.失败 -2.拒绝执行 -3.超时"`
+	Output      string     `json:"output" gorm:"type:text;comment:执行输出"`
+	ErrorMsg    string     `json:"errorMsg" gorm:"size:1000;comment:错误信息"`
+	StartTime   *time.Time `json:"startTime" gorm:"comment:开始时间"`
+	EndTime     *time.Time `json:"endTime" gorm:"comment:结束时间"`
+}
+
+const (
+	MachineCmdBatchResultStatusWaiting  int8 = 1
+	MachineCmdBatchResultStatusRunning  int8 = 2
+	MachineCmdBatchResultStatusSuccess  int8 = 3
+	MachineCmdBatchResultStatusFail     int8 = -1
+	MachineCmdBatchResultStatusRejected int8 = -2 // 命中机器命令配置而拒绝执行
+	MachineCmdBatchResultStatusTimeout  int8 = -3
+)
@@ -14,3 +14,7 @@ type MachineCmdConf struct {
 	Stratege string              `json:"stratege" gorm:"size:100;comment:策略"`        // 策略，空禁用
 	Remark   string              `json:"remark" gorm:"size:50;comment:备注"`           // 备注
 }
+
+const (
+	MachineCmdStrategeApprove = "approve" // 命中命令需审批通过后执行
+)
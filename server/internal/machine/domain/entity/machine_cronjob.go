@@ -17,6 +17,13 @@ type MachineCronJob struct {
 	Remark          string     `json:"remark" gorm:"size:255;comment:备注"`                    // 备注
 	LastExecTime    *time.Time `json:"lastExecTime" gorm:"comment:最后执行时间"`                   // 最后执行时间
 	SaveExecResType int        `json:"saveExecResType" gorm:"comment:保存执行记录类型"`              // 记录执行结果类型
+
+	Timeout           int                 `json:"timeout" gorm:"comment:单机执行超时时间(秒)"`                   // 单机执行超时时间(秒)，0则不限制
+	RetryCount        int                 `json:"retryCount" gorm:"comment:失败重试次数"`                     // 失败重试次数
+	RetryInterval     int                 `json:"retryInterval" gorm:"comment:重试间隔(秒)"`                 // 首次重试间隔(秒)，后续重试间隔按指数递增
+	ConcurrencyPolicy int8                `json:"concurrencyPolicy" gorm:"comment:并发策略 1.允许 2.跳过 3.替换"` // 同一机器上次执行未结束时的处理策略
+	MsgTmplId         uint64              `json:"msgTmplId" gorm:"comment:通知消息模板id"`                    // 执行失败或恢复时的通知消息模板id
+	ReceiverIds       model.Slice[uint64] `json:"receiverIds" gorm:"type:varchar(500);comment:通知接收人id"`
 }
 
 // MachineCronJobExec 机器任务执行记录
@@ -27,6 +34,7 @@ type MachineCronJobExec struct {
 	MachineCode string    `json:"machineCode" form:"machineCode" gorm:"size:50;"`
 	Status      int       `json:"status" form:"status"`  // 执行状态
 	Res         string    `json:"res" gorm:"size:4000;"` // 执行结果
+	Attempts    int       `json:"attempts"`              // 执行次数，包含重试
 	ExecTime    time.Time `json:"execTime"`
 }
 
@@ -36,8 +44,16 @@ const (
 
 	MachineCronJobExecStatusSuccess = 1
 	MachineCronJobExecStatusError   = -1
+	MachineCronJobExecStatusTimeout = -2
+	MachineCronJobExecStatusCancel  = -3 // 被新的执行替换而终止
 
 	SaveExecResTypeNo      = -1 // 不记录执行日志
 	SaveExecResTypeOnError = 1  // 执行错误时记录日志
 	SaveExecResTypeYes     = 2  // 记录日志
 )
+
+const (
+	MachineCronJobConcurrencyAllow   int8 = 1 // 允许并发执行
+	MachineCronJobConcurrencySkip    int8 = 2 // 上次执行未结束则跳过本次执行
+	MachineCronJobConcurrencyReplace int8 = 3 // 终止上次执行并开始本次执行
+)
@@ -10,3 +10,8 @@ type MachineFile struct {
 	Type      int8   `json:"type" gorm:"not null;comment:1：目录；2：文件"`                           // 1：目录；2：文件
 	Path      string `json:"path" gorm:"not null;size:150;comment:路径"`                         // 路径
 }
+
+const (
+	MachineFileTypeDir  int8 = 1
+	MachineFileTypeFile int8 = 2
+)
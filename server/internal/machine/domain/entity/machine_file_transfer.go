@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"mayfly-go/pkg/model"
+	"time"
+)
+
+// MachineFileTransfer 机器间文件传输任务
+type MachineFileTransfer struct {
+	model.Model
+
+	SrcMachineId    uint64     `json:"srcMachineId" gorm:"not null;comment:源机器id"`
+	SrcMachineName  string     `json:"srcMachineName" gorm:"size:100;comment:源机器名称"`
+	SrcAuthCertName string     `json:"srcAuthCertName" gorm:"size:100;comment:源机器授权凭证"`
+	SrcPath         string     `json:"srcPath" gorm:"size:1000;comment:源文件路径"`
+	DstMachineId    uint64     `json:"dstMachineId" gorm:"not null;comment:目标机器id"`
+	DstMachineName  string     `json:"dstMachineName" gorm:"size:100;comment:目标机器名称"`
+	DstAuthCertName string     `json:"dstAuthCertName" gorm:"size:100;comment:目标机器授权凭证"`
+	DstPath         string     `json:"dstPath" gorm:"size:1000;comment:目标文件路径"`
+	Size            int64      `json:"size" gorm:"comment:文件大小"`
+	Transferred     int64      `json:"transferred" gorm:"comment:已传输大小"`
+	Checksum        string     `json:"checksum" gorm:"size:64;comment:sha256校验值"`
+	Status          int8       `json:"status" gorm:"not null;comment:状态 1.传输中 2.成功 -1.失败 -2.已取消"`
+	ErrorMsg        string     `json:"errorMsg" gorm:"size:1000;comment:错误信息"`
+	EndTime         *time.Time `json:"endTime" gorm:"comment:结束时间"`
+}
+
+const (
+	MachineFileTransferStatusRunning   int8 = 1
+	MachineFileTransferStatusSuccess   int8 = 2
+	MachineFileTransferStatusFail      int8 = -1
+	MachineFileTransferStatusCancelled int8 = -2
+)
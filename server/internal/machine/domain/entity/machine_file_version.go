@@ -0,0 +1,17 @@
+package entity
+
+import "mayfly-go/pkg/model"
+
+// MachineFileVersion 机器文件历史版本，通过文件编辑器写入文件前保存的原文件内容
+type MachineFileVersion struct {
+	model.CreateModel
+
+	MachineId    uint64 `json:"machineId" gorm:"not null;index;comment:机器id"`
+	AuthCertName string `json:"authCertName" gorm:"size:100;comment:机器授权凭证"`
+	Path         string `json:"path" gorm:"size:1000;not null;comment:文件路径"`
+	Version      int    `json:"version" gorm:"not null;comment:版本号"`
+	FileKey      string `json:"fileKey" gorm:"size:32;not null;comment:文件内容对应的文件key"`
+	Size         int64  `json:"size" gorm:"comment:文件大小"`
+	Checksum     string `json:"checksum" gorm:"size:64;comment:sha256校验值"`
+	Remark       string `json:"remark" gorm:"size:255;comment:版本说明"`
+}
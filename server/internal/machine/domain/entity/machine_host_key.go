@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"mayfly-go/pkg/model"
+	"time"
+)
+
+// MachineHostKey 机器ssh主机公钥，首次连接时信任并固定(trust on first use)
+type MachineHostKey struct {
+	model.Model
+
+	MachineId   uint64 `json:"machineId" gorm:"not null;index;comment:机器id"`
+	KeyType     string `json:"keyType" gorm:"size:50;comment:公钥类型"`
+	Fingerprint string `json:"fingerprint" gorm:"size:100;comment:公钥指纹(SHA256)"`
+	PublicKey   string `json:"publicKey" gorm:"type:text;comment:公钥(authorized_keys格式)"`
+
+	PendingKeyType     string     `json:"pendingKeyType" gorm:"size:50;comment:不匹配的公钥类型"`
+	PendingFingerprint string     `json:"pendingFingerprint" gorm:"size:100;comment:不匹配的公钥指纹"` // 最近一次连接时不匹配的公钥指纹，需管理员确认后才可替换
+	PendingPublicKey   string     `json:"pendingPublicKey" gorm:"type:text;comment:不匹配的公钥"`
+	MismatchTime       *time.Time `json:"mismatchTime" gorm:"comment:最近不匹配时间"`
+}
@@ -1,14 +1,28 @@
 package entity
 
 import (
+	"mayfly-go/pkg/model"
 	"time"
 )
 
+// MachineMonitor 机器监控指标采样
 type MachineMonitor struct {
-	Id         uint64    `json:"id"`
-	MachineId  uint64    `json:"machineId"`
-	CpuRate    float32   `json:"cpuRate"`
-	MemRate    float32   `json:"memRate"`
-	SysLoad    string    `json:"sysLoad"`
-	CreateTime time.Time `json:"createTime"`
+	model.IdModel
+
+	MachineId   uint64                     `json:"machineId" gorm:"not null;index:idx_mm_machine_time,priority:1;comment:机器id"`
+	Granularity int8                       `json:"granularity" gorm:"not null;default:1;comment:粒度 1.原始采样 2.小时聚合"` // 粒度
+	CpuRate     float32                    `json:"cpuRate" gorm:"comment:cpu使用率"`
+	MemRate     float32                    `json:"memRate" gorm:"comment:内存使用率"`
+	Load1       float32                    `json:"load1" gorm:"comment:1分钟负载"`
+	Load5       float32                    `json:"load5" gorm:"comment:5分钟负载"`
+	Load15      float32                    `json:"load15" gorm:"comment:15分钟负载"`
+	NetRxRate   float64                    `json:"netRxRate" gorm:"comment:网络接收速率(byte/s)"`
+	NetTxRate   float64                    `json:"netTxRate" gorm:"comment:网络发送速率(byte/s)"`
+	FsRates     model.Map[string, float32] `json:"fsRates" gorm:"type:varchar(2000);comment:各挂载点磁盘使用率"` // 挂载点 -> 使用率
+	CreateTime  time.Time                  `json:"createTime" gorm:"not null;index:idx_mm_machine_time,priority:2;comment:采样时间"`
 }
+
+const (
+	MachineMonitorGranularityRaw  int8 = 1 // 原始采样
+	MachineMonitorGranularityHour int8 = 2 // 小时聚合
+)
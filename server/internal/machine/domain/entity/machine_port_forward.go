@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"mayfly-go/pkg/model"
+	"time"
+)
+
+// MachinePortForward 用户开启的临时端口转发，经机器ssh连接将本服务监听端口或websocket连接转发至机器可访问的目标地址
+type MachinePortForward struct {
+	model.Model
+
+	MachineId   uint64     `json:"machineId" gorm:"not null;index;comment:机器id"`
+	MachineName string     `json:"machineName" gorm:"size:100;comment:机器名称"`
+	Mode        int8       `json:"mode" gorm:"not null;comment:转发方式 1.监听端口 2.websocket"`
+	TargetHost  string     `json:"targetHost" gorm:"size:255;not null;comment:目标地址"`
+	TargetPort  int        `json:"targetPort" gorm:"not null;comment:目标端口"`
+	ListenAddr  string     `json:"listenAddr" gorm:"size:100;comment:本服务监听地址，websocket方式为空"`
+	AllowIp     string     `json:"allowIp" gorm:"size:50;comment:允许连接监听端口的客户端ip，为空则不限制"`
+	Ttl         int        `json:"ttl" gorm:"comment:有效时长(分钟)"`
+	ExpireTime  *time.Time `json:"expireTime" gorm:"comment:过期时间"`
+	Status      int8       `json:"status" gorm:"not null;comment:状态 1.转发中 2.已关闭"`
+	ConnCount   int64      `json:"connCount" gorm:"comment:累计连接数"`
+	BytesIn     int64      `json:"bytesIn" gorm:"comment:客户端发送至目标的字节数"`
+	BytesOut    int64      `json:"bytesOut" gorm:"comment:目标返回至客户端的字节数"`
+	CloseReason string     `json:"closeReason" gorm:"size:255;comment:关闭原因"`
+	CloseTime   *time.Time `json:"closeTime" gorm:"comment:关闭时间"`
+	Remark      string     `json:"remark" gorm:"size:255;comment:备注"`
+}
+
+const (
+	MachinePortForwardModeListen int8 = 1
+	MachinePortForwardModeWs     int8 = 2
+
+	MachinePortForwardStatusActive int8 = 1
+	MachinePortForwardStatusClosed int8 = 2
+)
@@ -10,6 +10,6 @@ type MachineScript struct {
 	Type        int    `json:"type" gorm:"comment:脚本类型[1: 有结果；2：无结果；3：实时交互]"` // 脚本类型[1: 有结果；2：无结果；3：实时交互]
 	Category    string `json:"category" gorm:"size:20;comment:分类"`
 	Description string `json:"description" gorm:"size:255;comment:脚本描述"` // 脚本描述
-	Params      string `json:"params" gorm:"size:500;comment:脚本入参"`      // 参数列表json
+	Params      string `json:"params" gorm:"size:2000;comment:脚本入参"`     // 参数定义列表json，见mcm.ScriptParam
 	Script      string `json:"script" gorm:"type:text;comment:脚本内容"`     // 脚本内容
 }
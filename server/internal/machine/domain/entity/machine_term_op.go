@@ -8,13 +8,29 @@ import (
 type MachineTermOp struct {
 	model.DeletedModel
 
-	MachineId uint64 `json:"machineId" gorm:"not null;comment:机器id"`    // 机器id
-	Username  string `json:"username" gorm:"size:60;comment:登录用户名"`     // 登录用户名
-	FileKey   string `json:"fileKey" gorm:"size:36;comment:文件"`         // 文件key
-	ExecCmds  string `json:"execCmds" gorm:"type:text;comment:执行的命令记录"` // 执行的命令
+	MachineId uint64 `json:"machineId" gorm:"not null;comment:机器id"`                   // 机器id
+	Username  string `json:"username" gorm:"size:60;comment:登录用户名"`                    // 登录用户名
+	FileKey   string `json:"fileKey" gorm:"size:36;comment:文件"`                        // 文件key
+	ExecCmds  string `json:"execCmds" gorm:"type:text;comment:执行的命令记录"`                // 执行的命令
+	Protocol  int8   `json:"protocol" gorm:"default:1;comment:连接协议 1.ssh 2.rdp 3.vnc"` // 连接协议
+	RecFile   string `json:"recFile" gorm:"size:255;comment:guacd录像文件"`                // guacd录像文件相对路径，仅rdp、vnc会话
+	Container string `json:"container" gorm:"size:100;comment:docker exec的容器"`         // docker exec进入的容器，为空则为机器终端
 
 	CreateTime *time.Time `json:"createTime" gorm:"not null;comment:创建时间"` // 创建时间
 	CreatorId  uint64     `json:"creatorId" gorm:"comment:创建人ID"`
 	Creator    string     `json:"creator" gorm:"size:50;comment:创建人"` // 创建人
 	EndTime    *time.Time `json:"endTime" gorm:"comment:结束时间"`        // 结束时间
 }
+
+// MachineTermOpCmd 终端会话中执行的命令索引，用于跨会话检索命令及定位回放位置
+type MachineTermOpCmd struct {
+	model.IdModel
+
+	TermOpId  uint64    `json:"termOpId" gorm:"not null;index;comment:终端操作记录id"`
+	MachineId uint64    `json:"machineId" gorm:"not null;index;comment:机器id"`
+	Cmd       string    `json:"cmd" gorm:"size:2000;comment:执行的命令"`
+	Offset    float64   `json:"offset" gorm:"comment:命令在回放中的偏移时间(秒)"` // 命令在回放中的偏移时间(秒)
+	ExecTime  time.Time `json:"execTime" gorm:"index;comment:执行时间"`
+	CreatorId uint64    `json:"creatorId" gorm:"comment:执行人ID"`
+	Creator   string    `json:"creator" gorm:"size:50;comment:执行人"`
+}
@@ -29,3 +29,83 @@ type AuthCertQuery struct {
 type MachineTermOpQuery struct {
 	StartCreateTime *time.Time
 }
+
+type MachineTermOpCmdQuery struct {
+	model.PageParam
+
+	TermOpId   uint64     `json:"termOpId" form:"termOpId"`
+	MachineId  uint64     `json:"machineId" form:"machineId"`
+	Cmd        string     `json:"cmd" form:"cmd"`
+	Creator    string     `json:"creator" form:"creator"`
+	StartTime  *time.Time `json:"startTime" form:"startTime" time_format:"2006-01-02 15:04:05"`
+	EndTime    *time.Time `json:"endTime" form:"endTime" time_format:"2006-01-02 15:04:05"`
+	MachineIds []uint64   `json:"-" form:"-"` // 可访问的机器id，为nil则不限制
+}
+
+type MachineMonitorQuery struct {
+	MachineId uint64     `json:"machineId" form:"machineId"`
+	StartTime *time.Time `json:"startTime" form:"startTime" time_format:"2006-01-02 15:04:05"`
+	EndTime   *time.Time `json:"endTime" form:"endTime" time_format:"2006-01-02 15:04:05"`
+}
+
+type MachineAlertRuleQuery struct {
+	model.PageParam
+
+	Name   string `json:"name" form:"name"`
+	Metric string `json:"metric" form:"metric"`
+	Status int8   `json:"status" form:"status"`
+}
+
+type MachineAlertQuery struct {
+	model.PageParam
+
+	RuleId    uint64 `json:"ruleId" form:"ruleId"`
+	MachineId uint64 `json:"machineId" form:"machineId"`
+	Status    int8   `json:"status" form:"status"`
+}
+
+type MachineAlertSilenceQuery struct {
+	model.PageParam
+
+	RuleId    uint64 `json:"ruleId" form:"ruleId"`
+	MachineId uint64 `json:"machineId" form:"machineId"`
+}
+
+type MachineCmdBatchQuery struct {
+	model.PageParam
+
+	Name      string `json:"name" form:"name"`
+	ScriptId  uint64 `json:"scriptId" form:"scriptId"`
+	Status    int8   `json:"status" form:"status"`
+	CreatorId uint64 `json:"creatorId" form:"creatorId"`
+}
+
+type MachineCmdBatchResultQuery struct {
+	model.PageParam
+
+	BatchId uint64 `json:"batchId" form:"batchId"`
+	Status  int8   `json:"status" form:"status"`
+}
+
+type MachineFileTransferQuery struct {
+	model.PageParam
+
+	MachineId uint64 `json:"machineId" form:"machineId"` // 源或目标机器id
+	Status    int8   `json:"status" form:"status"`
+	CreatorId uint64 `json:"creatorId" form:"creatorId"`
+}
+
+type MachineFileVersionQuery struct {
+	model.PageParam
+
+	MachineId uint64 `json:"machineId" form:"machineId"`
+	Path      string `json:"path" form:"path"`
+}
+
+type MachinePortForwardQuery struct {
+	model.PageParam
+
+	MachineId uint64 `json:"machineId" form:"machineId"`
+	Status    int8   `json:"status" form:"status"`
+	CreatorId uint64 `json:"creatorId" form:"creatorId"`
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type MachineAlertRule interface {
+	base.Repo[*entity.MachineAlertRule]
+
+	GetPageList(condition *entity.MachineAlertRuleQuery, orderBy ...string) (*model.PageResult[*entity.MachineAlertRule], error)
+}
+
+type MachineAlert interface {
+	base.Repo[*entity.MachineAlert]
+
+	GetPageList(condition *entity.MachineAlertQuery, orderBy ...string) (*model.PageResult[*entity.MachineAlert], error)
+}
+
+type MachineAlertSilence interface {
+	base.Repo[*entity.MachineAlertSilence]
+
+	GetPageList(condition *entity.MachineAlertSilenceQuery, orderBy ...string) (*model.PageResult[*entity.MachineAlertSilence], error)
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type MachineCmdBatch interface {
+	base.Repo[*entity.MachineCmdBatch]
+
+	GetPageList(condition *entity.MachineCmdBatchQuery, orderBy ...string) (*model.PageResult[*entity.MachineCmdBatch], error)
+}
+
+type MachineCmdBatchResult interface {
+	base.Repo[*entity.MachineCmdBatchResult]
+
+	GetPageList(condition *entity.MachineCmdBatchResultQuery, orderBy ...string) (*model.PageResult[*entity.MachineCmdBatchResult], error)
+}
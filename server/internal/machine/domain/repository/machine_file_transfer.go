@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type MachineFileTransfer interface {
+	base.Repo[*entity.MachineFileTransfer]
+
+	GetPageList(condition *entity.MachineFileTransferQuery, orderBy ...string) (*model.PageResult[*entity.MachineFileTransfer], error)
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type MachineFileVersion interface {
+	base.Repo[*entity.MachineFileVersion]
+
+	GetPageList(condition *entity.MachineFileVersionQuery, orderBy ...string) (*model.PageResult[*entity.MachineFileVersion], error)
+}
@@ -0,0 +1,10 @@
+package repository
+
+import (
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/pkg/base"
+)
+
+type MachineMonitor interface {
+	base.Repo[*entity.MachineMonitor]
+}
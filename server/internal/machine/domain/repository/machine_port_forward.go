@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type MachinePortForward interface {
+	base.Repo[*entity.MachinePortForward]
+
+	GetPageList(condition *entity.MachinePortForwardQuery, orderBy ...string) (*model.PageResult[*entity.MachinePortForward], error)
+}
@@ -15,3 +15,10 @@ type MachineTermOp interface {
 	// 根据条件获取记录列表
 	SelectByQuery(cond *entity.MachineTermOpQuery) ([]*entity.MachineTermOp, error)
 }
+
+type MachineTermOpCmd interface {
+	base.Repo[*entity.MachineTermOpCmd]
+
+	// 分页检索终端会话执行的命令
+	GetPageList(condition *entity.MachineTermOpCmdQuery, orderBy ...string) (*model.PageResult[*entity.MachineTermOpCmd], error)
+}
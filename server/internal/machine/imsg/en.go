@@ -38,4 +38,61 @@ var En = map[i18n.MsgId]string{
 	LogMachineSecurityCmdSave:   "Machine - Security - Save command configuration",
 	LogMachineSecurityCmdDelete: "Machine - Security - Delete command configuration",
 	TerminalCmdDisable:          "This command has been disabled...",
+
+	LogMachineHostKeyAccept: "Machine - Accept host key",
+	LogMachineHostKeyReset:  "Machine - Reset host key",
+	ErrHostKeyMismatch:      "The host key of machine [{{.name}}] does not match the trusted key, the connection has been blocked. expected: {{.expected}}, actual: {{.actual}}",
+	MsgHostKeyMismatch:      "Machine host key mismatch",
+
+	LogMachineAlertRuleSave:      "Machine - Alert - Save alert rule",
+	LogMachineAlertRuleDelete:    "Machine - Alert - Delete alert rule",
+	LogMachineAlertSilenceSave:   "Machine - Alert - Save silence",
+	LogMachineAlertSilenceDelete: "Machine - Alert - Delete silence",
+
+	LogMachineCmdBatchRun: "Machine - Batch run command",
+	ErrCmdBatchNoMachine:  "There are no accessible and enabled SSH machines matching the selection",
+	MsgCmdBatchProgress:   "Batch command execution",
+	MsgCmdBatchFinished:   "[{{.name}}] finished, total: {{.total}}, success: {{.success}}, failed: {{.fail}}",
+
+	LogMachineTerminalShadow:   "Machine - Shadow terminal session",
+	LogMachineTerminalKill:     "Machine - Terminate terminal session",
+	ErrTerminalSessionNotFound: "The terminal session does not exist or has ended",
+	MsgTerminalSessionKilled:   "The terminal session has been terminated by the administrator",
+
+	ErrTermCmdNoApproveFlow:   "This command requires approval, but the machine is not associated with an approval flow",
+	ErrTermCmdApproveRejected: "The command approval was rejected, execution cancelled",
+	ErrTermCmdApproveTimeout:  "The command approval timed out, execution cancelled",
+	MsgTermCmdWaitApprove:     "This command requires approval, approval flow [{{.procinstId}}] submitted, waiting for approval (times out in {{.timeout}}s)...",
+	MsgTermCmdApproved:        "The command has been approved, executing",
+
+	LogMachineFileChunkUploadInit:  "Machine - File - Init chunked upload",
+	LogMachineFileChunkUploadAbort: "Machine - File - Abort chunked upload",
+	LogMachineFileDownloadDir:      "Machine - File - Download directory",
+
+	LogMachineFileTransferStart:  "Machine - Transfer file between machines",
+	LogMachineFileTransferCancel: "Machine - Cancel file transfer",
+	MsgFileTransfer:              "Machine file transfer",
+	MsgFileTransferSuccess:       "[{{.src}}] -> [{{.dst}}] transfer completed, sha256: {{.checksum}}",
+	MsgFileTransferFail:          "[{{.src}}] -> [{{.dst}}] transfer failed: {{.err}}",
+
+	LogMachineFileTail:           "Machine - File - Tail file",
+	LogMachineFileChmod:          "Machine - File - Change mode",
+	LogMachineFileChown:          "Machine - File - Change owner",
+	LogMachineFileSymlink:        "Machine - File - Create symlink",
+	LogMachineFileChtimes:        "Machine - File - Change times",
+	LogMachineFileVersionRestore: "Machine - File - Restore version",
+	LogMachinePortForwardOpen:    "Machine - Open port forward",
+	LogMachinePortForwardWs:      "Machine - Websocket port forward",
+	LogMachinePortForwardClose:   "Machine - Close port forward",
+	LogMachineServiceStart:       "Machine - Start service",
+	LogMachineServiceStop:        "Machine - Stop service",
+	LogMachineServiceRestart:     "Machine - Restart service",
+	LogMachineServiceEnable:      "Machine - Enable service",
+	LogMachineServiceDisable:     "Machine - Disable service",
+	LogMachineContainerStart:     "Machine - Start container",
+	LogMachineContainerStop:      "Machine - Stop container",
+	LogMachineContainerRestart:   "Machine - Restart container",
+	LogMachineContainerRemove:    "Machine - Remove container",
+	LogMachineContainerExec:      "Machine - Container terminal",
+	LogMachineImport:             "Machine - Import",
 }
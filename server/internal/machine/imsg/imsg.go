@@ -48,4 +48,67 @@ const (
 	LogMachineSecurityCmdDelete
 
 	TerminalCmdDisable
+
+	// host key
+	LogMachineHostKeyAccept
+	LogMachineHostKeyReset
+	ErrHostKeyMismatch
+	MsgHostKeyMismatch
+
+	// alert
+	LogMachineAlertRuleSave
+	LogMachineAlertRuleDelete
+	LogMachineAlertSilenceSave
+	LogMachineAlertSilenceDelete
+
+	// cmd batch
+	LogMachineCmdBatchRun
+	ErrCmdBatchNoMachine
+	MsgCmdBatchProgress
+	MsgCmdBatchFinished
+
+	// terminal session
+	LogMachineTerminalShadow
+	LogMachineTerminalKill
+	ErrTerminalSessionNotFound
+	MsgTerminalSessionKilled
+
+	// terminal cmd approve
+	ErrTermCmdNoApproveFlow
+	ErrTermCmdApproveRejected
+	ErrTermCmdApproveTimeout
+	MsgTermCmdWaitApprove
+	MsgTermCmdApproved
+
+	LogMachineFileChunkUploadInit
+	LogMachineFileChunkUploadAbort
+	LogMachineFileDownloadDir
+
+	// file transfer
+	LogMachineFileTransferStart
+	LogMachineFileTransferCancel
+	MsgFileTransfer
+	MsgFileTransferSuccess
+	MsgFileTransferFail
+
+	LogMachineFileTail
+	LogMachineFileChmod
+	LogMachineFileChown
+	LogMachineFileSymlink
+	LogMachineFileChtimes
+	LogMachineFileVersionRestore
+	LogMachinePortForwardOpen
+	LogMachinePortForwardWs
+	LogMachinePortForwardClose
+	LogMachineServiceStart
+	LogMachineServiceStop
+	LogMachineServiceRestart
+	LogMachineServiceEnable
+	LogMachineServiceDisable
+	LogMachineContainerStart
+	LogMachineContainerStop
+	LogMachineContainerRestart
+	LogMachineContainerRemove
+	LogMachineContainerExec
+	LogMachineImport
 )
@@ -38,4 +38,61 @@ var Zh_CN = map[i18n.MsgId]string{
 	LogMachineSecurityCmdSave:   "机器-安全-保存命令配置",
 	LogMachineSecurityCmdDelete: "机器-安全-删除命令配置",
 	TerminalCmdDisable:          "该命令已被禁用...",
+
+	LogMachineHostKeyAccept: "机器-接受主机公钥",
+	LogMachineHostKeyReset:  "机器-重置主机公钥",
+	ErrHostKeyMismatch:      "机器[{{.name}}]的主机公钥与已信任的公钥不匹配，已阻止连接。期望: {{.expected}}，实际: {{.actual}}",
+	MsgHostKeyMismatch:      "机器主机公钥不匹配",
+
+	LogMachineAlertRuleSave:      "机器-告警-保存告警规则",
+	LogMachineAlertRuleDelete:    "机器-告警-删除告警规则",
+	LogMachineAlertSilenceSave:   "机器-告警-保存静默",
+	LogMachineAlertSilenceDelete: "机器-告警-删除静默",
+
+	LogMachineCmdBatchRun: "机器-批量执行命令",
+	ErrCmdBatchNoMachine:  "不存在符合条件且可访问的已启用SSH机器",
+	MsgCmdBatchProgress:   "批量命令执行",
+	MsgCmdBatchFinished:   "[{{.name}}]执行完成，总数: {{.total}}，成功: {{.success}}，失败: {{.fail}}",
+
+	LogMachineTerminalShadow:   "机器-旁观终端会话",
+	LogMachineTerminalKill:     "机器-强制断开终端会话",
+	ErrTerminalSessionNotFound: "终端会话不存在或已结束",
+	MsgTerminalSessionKilled:   "该终端会话已被管理员强制断开",
+
+	ErrTermCmdNoApproveFlow:   "该命令需审批后执行，但机器未关联审批流程",
+	ErrTermCmdApproveRejected: "命令审批未通过，已取消执行",
+	ErrTermCmdApproveTimeout:  "命令审批超时，已取消执行",
+	MsgTermCmdWaitApprove:     "该命令需审批后执行，已提交审批流程[{{.procinstId}}]，等待审批中（{{.timeout}}秒后超时）...",
+	MsgTermCmdApproved:        "命令审批已通过，开始执行",
+
+	LogMachineFileChunkUploadInit:  "机器-文件-初始化分片上传",
+	LogMachineFileChunkUploadAbort: "机器-文件-取消分片上传",
+	LogMachineFileDownloadDir:      "机器-文件-下载目录",
+
+	LogMachineFileTransferStart:  "机器-机器间文件传输",
+	LogMachineFileTransferCancel: "机器-取消文件传输",
+	MsgFileTransfer:              "机器文件传输",
+	MsgFileTransferSuccess:       "[{{.src}}] -> [{{.dst}}] 传输完成，sha256: {{.checksum}}",
+	MsgFileTransferFail:          "[{{.src}}] -> [{{.dst}}] 传输失败: {{.err}}",
+
+	LogMachineFileTail:           "机器-文件-跟踪文件",
+	LogMachineFileChmod:          "机器-文件-修改权限",
+	LogMachineFileChown:          "机器-文件-修改所属用户及组",
+	LogMachineFileSymlink:        "机器-文件-创建软链接",
+	LogMachineFileChtimes:        "机器-文件-修改时间",
+	LogMachineFileVersionRestore: "机器-文件-恢复历史版本",
+	LogMachinePortForwardOpen:    "机器-开启端口转发",
+	LogMachinePortForwardWs:      "机器-websocket端口转发",
+	LogMachinePortForwardClose:   "机器-关闭端口转发",
+	LogMachineServiceStart:       "机器-启动服务",
+	LogMachineServiceStop:        "机器-停止服务",
+	LogMachineServiceRestart:     "机器-重启服务",
+	LogMachineServiceEnable:      "机器-服务开机启动",
+	LogMachineServiceDisable:     "机器-服务禁止开机启动",
+	LogMachineContainerStart:     "机器-启动容器",
+	LogMachineContainerStop:      "机器-停止容器",
+	LogMachineContainerRestart:   "机器-重启容器",
+	LogMachineContainerRemove:    "机器-删除容器",
+	LogMachineContainerExec:      "机器-容器终端",
+	LogMachineImport:             "机器-批量导入",
 }
@@ -0,0 +1,47 @@
+package persistence
+
+import (
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type machineAlertRuleRepoImpl struct {
+	base.RepoImpl[*entity.MachineAlertRule]
+}
+
+func newMachineAlertRuleRepo() repository.MachineAlertRule {
+	return &machineAlertRuleRepoImpl{}
+}
+
+func (m *machineAlertRuleRepoImpl) GetPageList(condition *entity.MachineAlertRuleQuery, orderBy ...string) (*model.PageResult[*entity.MachineAlertRule], error) {
+	qd := model.NewCond().Like("name", condition.Name).Eq("metric", condition.Metric).Eq("status", condition.Status).OrderBy(orderBy...)
+	return m.PageByCond(qd, condition.PageParam)
+}
+
+type machineAlertRepoImpl struct {
+	base.RepoImpl[*entity.MachineAlert]
+}
+
+func newMachineAlertRepo() repository.MachineAlert {
+	return &machineAlertRepoImpl{}
+}
+
+func (m *machineAlertRepoImpl) GetPageList(condition *entity.MachineAlertQuery, orderBy ...string) (*model.PageResult[*entity.MachineAlert], error) {
+	qd := model.NewCond().Eq("rule_id", condition.RuleId).Eq("machine_id", condition.MachineId).Eq("status", condition.Status).OrderBy(orderBy...)
+	return m.PageByCond(qd, condition.PageParam)
+}
+
+type machineAlertSilenceRepoImpl struct {
+	base.RepoImpl[*entity.MachineAlertSilence]
+}
+
+func newMachineAlertSilenceRepo() repository.MachineAlertSilence {
+	return &machineAlertSilenceRepoImpl{}
+}
+
+func (m *machineAlertSilenceRepoImpl) GetPageList(condition *entity.MachineAlertSilenceQuery, orderBy ...string) (*model.PageResult[*entity.MachineAlertSilence], error) {
+	qd := model.NewCond().Eq("rule_id", condition.RuleId).Eq("machine_id", condition.MachineId).OrderBy(orderBy...)
+	return m.PageByCond(qd, condition.PageParam)
+}
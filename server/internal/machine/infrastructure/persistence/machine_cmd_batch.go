@@ -0,0 +1,34 @@
+package persistence
+
+import (
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type machineCmdBatchRepoImpl struct {
+	base.RepoImpl[*entity.MachineCmdBatch]
+}
+
+func newMachineCmdBatchRepo() repository.MachineCmdBatch {
+	return &machineCmdBatchRepoImpl{}
+}
+
+func (m *machineCmdBatchRepoImpl) GetPageList(condition *entity.MachineCmdBatchQuery, orderBy ...string) (*model.PageResult[*entity.MachineCmdBatch], error) {
+	qd := model.NewCond().Like("name", condition.Name).Eq("script_id", condition.ScriptId).Eq("status", condition.Status).Eq("creator_id", condition.CreatorId).OrderBy(orderBy...)
+	return m.PageByCond(qd, condition.PageParam)
+}
+
+type machineCmdBatchResultRepoImpl struct {
+	base.RepoImpl[*entity.MachineCmdBatchResult]
+}
+
+func newMachineCmdBatchResultRepo() repository.MachineCmdBatchResult {
+	return &machineCmdBatchResultRepoImpl{}
+}
+
+func (m *machineCmdBatchResultRepoImpl) GetPageList(condition *entity.MachineCmdBatchResultQuery, orderBy ...string) (*model.PageResult[*entity.MachineCmdBatchResult], error) {
+	qd := model.NewCond().Eq("batch_id", condition.BatchId).Eq("status", condition.Status).OrderBy(orderBy...)
+	return m.PageByCond(qd, condition.PageParam)
+}
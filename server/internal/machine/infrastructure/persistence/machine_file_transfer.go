@@ -0,0 +1,24 @@
+package persistence
+
+import (
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type machineFileTransferRepoImpl struct {
+	base.RepoImpl[*entity.MachineFileTransfer]
+}
+
+func newMachineFileTransferRepo() repository.MachineFileTransfer {
+	return &machineFileTransferRepoImpl{}
+}
+
+func (m *machineFileTransferRepoImpl) GetPageList(condition *entity.MachineFileTransferQuery, orderBy ...string) (*model.PageResult[*entity.MachineFileTransfer], error) {
+	qd := model.NewCond().Eq("status", condition.Status).Eq("creator_id", condition.CreatorId).OrderBy(orderBy...)
+	if condition.MachineId != 0 {
+		qd.And("(src_machine_id = ? OR dst_machine_id = ?)", condition.MachineId, condition.MachineId)
+	}
+	return m.PageByCond(qd, condition.PageParam)
+}
@@ -0,0 +1,21 @@
+package persistence
+
+import (
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type machineFileVersionRepoImpl struct {
+	base.RepoImpl[*entity.MachineFileVersion]
+}
+
+func newMachineFileVersionRepo() repository.MachineFileVersion {
+	return &machineFileVersionRepoImpl{}
+}
+
+func (m *machineFileVersionRepoImpl) GetPageList(condition *entity.MachineFileVersionQuery, orderBy ...string) (*model.PageResult[*entity.MachineFileVersion], error) {
+	qd := model.NewCond().Eq("machine_id", condition.MachineId).Eq("path", condition.Path).OrderBy(orderBy...)
+	return m.PageByCond(qd, condition.PageParam)
+}
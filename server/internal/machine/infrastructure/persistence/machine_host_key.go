@@ -0,0 +1,15 @@
+package persistence
+
+import (
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/pkg/base"
+)
+
+type machineHostKeyRepoImpl struct {
+	base.RepoImpl[*entity.MachineHostKey]
+}
+
+func newMachineHostKeyRepo() repository.MachineHostKey {
+	return &machineHostKeyRepoImpl{}
+}
@@ -0,0 +1,15 @@
+package persistence
+
+import (
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/pkg/base"
+)
+
+type machineMonitorRepoImpl struct {
+	base.RepoImpl[*entity.MachineMonitor]
+}
+
+func newMachineMonitorRepo() repository.MachineMonitor {
+	return &machineMonitorRepoImpl{}
+}
@@ -0,0 +1,21 @@
+package persistence
+
+import (
+	"mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/domain/repository"
+	"mayfly-go/pkg/base"
+	"mayfly-go/pkg/model"
+)
+
+type machinePortForwardRepoImpl struct {
+	base.RepoImpl[*entity.MachinePortForward]
+}
+
+func newMachinePortForwardRepo() repository.MachinePortForward {
+	return &machinePortForwardRepoImpl{}
+}
+
+func (m *machinePortForwardRepoImpl) GetPageList(condition *entity.MachinePortForwardQuery, orderBy ...string) (*model.PageResult[*entity.MachinePortForward], error) {
+	qd := model.NewCond().Eq("machine_id", condition.MachineId).Eq("status", condition.Status).Eq("creator_id", condition.CreatorId).OrderBy(orderBy...)
+	return m.PageByCond(qd, condition.PageParam)
+}
@@ -25,3 +25,26 @@ func (m *machineTermOpRepoImpl) SelectByQuery(cond *entity.MachineTermOpQuery) (
 	qd := model.NewCond().Le("create_time", cond.StartCreateTime)
 	return m.SelectByCond(qd)
 }
+
+type machineTermOpCmdRepoImpl struct {
+	base.RepoImpl[*entity.MachineTermOpCmd]
+}
+
+func newMachineTermOpCmdRepo() repository.MachineTermOpCmd {
+	return &machineTermOpCmdRepoImpl{}
+}
+
+func (m *machineTermOpCmdRepoImpl) GetPageList(condition *entity.MachineTermOpCmdQuery, orderBy ...string) (*model.PageResult[*entity.MachineTermOpCmd], error) {
+	qd := model.NewCond().
+		Eq("term_op_id", condition.TermOpId).
+		Eq("machine_id", condition.MachineId).
+		Like("cmd", condition.Cmd).
+		Like("creator", condition.Creator).
+		Ge("exec_time", condition.StartTime).
+		Le("exec_time", condition.EndTime).
+		OrderBy(orderBy...)
+	if condition.MachineIds != nil {
+		qd.In0("machine_id", condition.MachineIds)
+	}
+	return m.PageByCond(qd, condition.PageParam)
+}
@@ -11,5 +11,16 @@ func InitIoc() {
 	ioc.Register(newMachineCronJobRepo(), ioc.WithComponentName("MachineCronJobRepo"))
 	ioc.Register(newMachineCronJobExecRepo(), ioc.WithComponentName("MachineCronJobExecRepo"))
 	ioc.Register(newMachineTermOpRepoImpl(), ioc.WithComponentName("MachineTermOpRepo"))
+	ioc.Register(newMachineTermOpCmdRepo(), ioc.WithComponentName("MachineTermOpCmdRepo"))
 	ioc.Register(newMachineCmdConfRepo(), ioc.WithComponentName("MachineCmdConfRepo"))
+	ioc.Register(newMachineHostKeyRepo(), ioc.WithComponentName("MachineHostKeyRepo"))
+	ioc.Register(newMachineMonitorRepo(), ioc.WithComponentName("MachineMonitorRepo"))
+	ioc.Register(newMachineAlertRuleRepo(), ioc.WithComponentName("MachineAlertRuleRepo"))
+	ioc.Register(newMachineAlertRepo(), ioc.WithComponentName("MachineAlertRepo"))
+	ioc.Register(newMachineAlertSilenceRepo(), ioc.WithComponentName("MachineAlertSilenceRepo"))
+	ioc.Register(newMachineCmdBatchRepo(), ioc.WithComponentName("MachineCmdBatchRepo"))
+	ioc.Register(newMachineCmdBatchResultRepo(), ioc.WithComponentName("MachineCmdBatchResultRepo"))
+	ioc.Register(newMachineFileTransferRepo(), ioc.WithComponentName("MachineFileTransferRepo"))
+	ioc.Register(newMachineFileVersionRepo(), ioc.WithComponentName("MachineFileVersionRepo"))
+	ioc.Register(newMachinePortForwardRepo(), ioc.WithComponentName("MachinePortForwardRepo"))
 }
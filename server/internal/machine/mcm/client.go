@@ -1,6 +1,7 @@
 package mcm
 
 import (
+	"context"
 	"mayfly-go/pkg/errorx"
 	"mayfly-go/pkg/logx"
 	"strings"
@@ -102,6 +103,37 @@ func (c *Cli) Run(shell string) (string, error) {
 	return string(buf), nil
 }
 
+// RunWithContext 执行shell，ctx取消或超时时关闭会话以终止执行
+// @param shell shell脚本命令
+// @return 返回执行成功或错误的消息
+func (c *Cli) RunWithContext(ctx context.Context, shell string) (string, error) {
+	session, err := c.GetSession()
+	if err != nil {
+		return "", err
+	}
+	defer session.Close()
+
+	type runRes struct {
+		buf []byte
+		err error
+	}
+	resChan := make(chan runRes, 1)
+	go func() {
+		buf, err := session.CombinedOutput(strings.ReplaceAll(shell, "\r\n", "\n"))
+		resChan <- runRes{buf: buf, err: err}
+	}()
+
+	select {
+	case res := <-resChan:
+		return string(res.buf), res.err
+	case <-ctx.Done():
+		// 尽量通知远程进程终止，随后关闭会话
+		_ = session.Signal(ssh.SIGKILL)
+		session.Close()
+		return "", ctx.Err()
+	}
+}
+
 // GetAllStats 获取机器的所有状态信息
 func (c *Cli) GetAllStats() *Stats {
 	stats := new(Stats)
@@ -0,0 +1,196 @@
+package mcm
+
+import (
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/shellx"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// CmdRulePrefix 参数规则前缀，如 "cmd:rm -r|-R|--recursive /*" 表示携带递归选项且参数位于/下的rm命令
+const CmdRulePrefix = "cmd:"
+
+// CmdMatcher 命令匹配器。
+// 规则默认为正则表达式，会分别匹配原始命令行及解析后的每个简单命令(命令名与参数以单个空格拼接)；
+// 以 cmd: 开头则为参数规则，格式为 "cmd:命令名 [选项...] [参数...]"，每项可用|分隔多个候选值：
+//   - 命令名及参数支持*、?通配符，*可匹配/，路径参数会先规范化(如 // 视为 /)再匹配
+//   - -rf 等短选项要求每个字母均出现(可分散或组合书写)，--long 等长选项要求存在(含 --long=value)
+//   - 所有选项及参数项均需满足
+type CmdMatcher struct {
+	Rule string // 原始规则
+
+	regexp  *regexp.Regexp
+	names   []*regexp.Regexp // 命令名，满足其一即可
+	argRule [][]cmdArgCond   // 选项及参数规则，每项中满足其一即可
+}
+
+type cmdArgCond struct {
+	flag string         // 选项，如 -r、--recursive
+	glob *regexp.Regexp // 参数通配
+}
+
+// NewCmdMatcher 根据规则创建命令匹配器
+func NewCmdMatcher(rule string) (*CmdMatcher, error) {
+	m := &CmdMatcher{Rule: rule}
+	argRule, isArgRule := strings.CutPrefix(rule, CmdRulePrefix)
+	if !isArgRule {
+		p, err := regexp.Compile(rule)
+		if err != nil {
+			return nil, err
+		}
+		m.regexp = p
+		return m, nil
+	}
+
+	fields := strings.Fields(argRule)
+	if len(fields) == 0 {
+		return nil, errorx.NewBiz("the command name of the rule cannot be empty")
+	}
+	for _, name := range strings.Split(fields[0], "|") {
+		m.names = append(m.names, globToRegexp(name))
+	}
+	for _, field := range fields[1:] {
+		var conds []cmdArgCond
+		for _, alt := range strings.Split(field, "|") {
+			if alt == "" {
+				continue
+			}
+			if strings.HasPrefix(alt, "-") {
+				conds = append(conds, cmdArgCond{flag: alt})
+			} else {
+				conds = append(conds, cmdArgCond{glob: globToRegexp(alt)})
+			}
+		}
+		if len(conds) > 0 {
+			m.argRule = append(m.argRule, conds)
+		}
+	}
+	return m, nil
+}
+
+// Match 解析命令行并判断是否命中规则
+func (m *CmdMatcher) Match(cmdline string) bool {
+	return m.MatchCommands(cmdline, shellx.ParseCommands(cmdline))
+}
+
+// MatchCommands 判断命令行或其解析后的简单命令是否命中规则，用于多个匹配器共用解析结果
+func (m *CmdMatcher) MatchCommands(cmdline string, cmds []*shellx.Command) bool {
+	if m.regexp != nil {
+		if m.regexp.MatchString(cmdline) {
+			return true
+		}
+		for _, cmd := range cmds {
+			if m.regexp.MatchString(cmd.String()) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, cmd := range cmds {
+		if m.matchArgRule(cmd) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *CmdMatcher) matchArgRule(cmd *shellx.Command) bool {
+	if !matchAny(m.names, cmd.Name) {
+		return false
+	}
+
+	// 解析命令的短选项、长选项及普通参数
+	shortFlags := make(map[rune]bool)
+	var longFlags, args []string
+	endOfOpts := false
+	for _, arg := range cmd.Args {
+		switch {
+		case endOfOpts || arg == "-" || !strings.HasPrefix(arg, "-"):
+			args = append(args, arg)
+		case arg == "--":
+			endOfOpts = true
+		case strings.HasPrefix(arg, "--"):
+			longFlags = append(longFlags, arg)
+		default:
+			for _, c := range arg[1:] {
+				shortFlags[c] = true
+			}
+		}
+	}
+
+	for _, conds := range m.argRule {
+		matched := false
+		for _, cond := range conds {
+			if cond.flag != "" {
+				matched = hasFlag(cond.flag, shortFlags, longFlags)
+			} else {
+				matched = hasArg(cond.glob, args)
+			}
+			if matched {
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func hasFlag(flag string, shortFlags map[rune]bool, longFlags []string) bool {
+	if strings.HasPrefix(flag, "--") {
+		for _, longFlag := range longFlags {
+			if longFlag == flag || strings.HasPrefix(longFlag, flag+"=") {
+				return true
+			}
+		}
+		return false
+	}
+	for _, c := range flag[1:] {
+		if !shortFlags[c] {
+			return false
+		}
+	}
+	return true
+}
+
+func hasArg(glob *regexp.Regexp, args []string) bool {
+	for _, arg := range args {
+		if glob.MatchString(arg) {
+			return true
+		}
+		if strings.Contains(arg, "/") && glob.MatchString(path.Clean(arg)) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(ps []*regexp.Regexp, s string) bool {
+	for _, p := range ps {
+		if p.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// globToRegexp 将*、?通配符转为正则表达式
+func globToRegexp(glob string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, c := range glob {
+		switch c {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
@@ -0,0 +1,48 @@
+package mcm
+
+import "testing"
+
+func TestCmdMatcher(t *testing.T) {
+	testCases := []struct {
+		rule string
+		cmd  string
+		want bool
+	}{
+		{"^rm -rf", "rm -rf /tmp", true},
+		{"^rm -rf", "sudo \\rm  -rf /tmp", true},
+		{"^rm -rf", "bash -c 'rm -rf /tmp'", true},
+		{"^rm -rf", "ls -l", false},
+		{"^reboot$", "echo `reboot`", true},
+		{"cmd:rm -r|-R|--recursive /", "rm -fr /", true},
+		{"cmd:rm -r|-R|--recursive /", "rm -f -r //", true},
+		{"cmd:rm -r|-R|--recursive /", "rm --recursive --force /", true},
+		{"cmd:rm -r|-R|--recursive /", "rm -r /tmp", false},
+		{"cmd:rm -r|-R|--recursive /", "rm -f /", false},
+		{"cmd:rm -r|-R|--recursive /*", "env FOO=1 /bin/rm -r /var/lib", true},
+		{"cmd:rm -r|-R|--recursive /*", "rm -r tmp", false},
+		{"cmd:rm -rf", "rm -r -f a", true},
+		{"cmd:rm -rf", "rm -r a", false},
+		{"cmd:mkfs*|fdisk", "mkfs.ext4 /dev/sdb", true},
+		{"cmd:shutdown|reboot|halt", "ls && (sleep 1; reboot)", true},
+		{"cmd:chmod 777", "chmod -R 777 /data", true},
+		{"cmd:chmod 777", "chmod 755 /data", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.rule+" => "+tc.cmd, func(t *testing.T) {
+			m, err := NewCmdMatcher(tc.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.Match(tc.cmd); got != tc.want {
+				t.Errorf("Match() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	if _, err := NewCmdMatcher("cmd:"); err == nil {
+		t.Error("expected error for empty command name")
+	}
+	if _, err := NewCmdMatcher("rm ("); err == nil {
+		t.Error("expected error for invalid regexp")
+	}
+}
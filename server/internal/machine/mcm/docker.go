@@ -0,0 +1,174 @@
+package mcm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/shellx"
+	"regexp"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	DockerActionStart   = "start"
+	DockerActionStop    = "stop"
+	DockerActionRestart = "restart"
+	DockerActionRemove  = "remove"
+)
+
+var dockerContainerIdRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// DockerContainer docker ps输出的容器信息
+type DockerContainer struct {
+	Id         string `json:"id"`
+	Names      string `json:"names"`
+	Image      string `json:"image"`
+	Command    string `json:"command"`
+	CreatedAt  string `json:"createdAt"`
+	RunningFor string `json:"runningFor"`
+	Ports      string `json:"ports"`
+	State      string `json:"state"`  // 容器状态，如running、exited、paused
+	Status     string `json:"status"` // 状态描述，如Up 2 hours
+	Networks   string `json:"networks"`
+}
+
+// DockerContainerStats docker stats输出的容器资源使用情况
+type DockerContainerStats struct {
+	Id       string `json:"id"`
+	Name     string `json:"name"`
+	CPUPerc  string `json:"cpuPerc"`
+	MemUsage string `json:"memUsage"`
+	MemPerc  string `json:"memPerc"`
+	NetIO    string `json:"netIO"`
+	BlockIO  string `json:"blockIO"`
+	PIDs     string `json:"pids"`
+}
+
+// CheckDockerContainerId 校验容器id或名称，避免注入其他命令参数
+func CheckDockerContainerId(containerId string) error {
+	if !dockerContainerIdRegexp.MatchString(containerId) {
+		return errorx.NewBiz("invalid container: %s", containerId)
+	}
+	return nil
+}
+
+// GetDockerContainers 获取所有容器(包含已停止的容器)
+func (c *Cli) GetDockerContainers() ([]*DockerContainer, error) {
+	res, err := c.Run("docker ps -a --no-trunc --format '{{json .}}'")
+	if err != nil {
+		return nil, errorx.NewBiz("failed to list docker containers: %s", strings.TrimSpace(res))
+	}
+	return ParseDockerJsonLines[DockerContainer](res)
+}
+
+// GetDockerContainerStats 获取运行中容器的资源使用情况
+func (c *Cli) GetDockerContainerStats() ([]*DockerContainerStats, error) {
+	res, err := c.Run("docker stats --no-stream --no-trunc --format '{{json .}}'")
+	if err != nil {
+		return nil, errorx.NewBiz("failed to get docker stats: %s", strings.TrimSpace(res))
+	}
+	return ParseDockerJsonLines[DockerContainerStats](res)
+}
+
+// InspectDockerContainer 获取容器的docker inspect信息
+func (c *Cli) InspectDockerContainer(containerId string) (string, error) {
+	if err := CheckDockerContainerId(containerId); err != nil {
+		return "", err
+	}
+	res, err := c.Run(fmt.Sprintf("docker inspect --type container %s", shellx.Quote(containerId)))
+	if err != nil {
+		return "", errorx.NewBiz("failed to inspect the container: %s", strings.TrimSpace(res))
+	}
+	return res, nil
+}
+
+// OpDockerContainer 执行容器的启动、停止、重启、删除操作
+func (c *Cli) OpDockerContainer(containerId string, action string) (string, error) {
+	if err := CheckDockerContainerId(containerId); err != nil {
+		return "", err
+	}
+
+	var cmd string
+	switch action {
+	case DockerActionStart, DockerActionStop, DockerActionRestart:
+		cmd = fmt.Sprintf("docker %s %s", action, shellx.Quote(containerId))
+	case DockerActionRemove:
+		cmd = fmt.Sprintf("docker rm -f %s", shellx.Quote(containerId))
+	default:
+		return "", errorx.NewBiz("unsupported docker action: %s", action)
+	}
+
+	res, err := c.Run(cmd)
+	if err != nil {
+		return res, errorx.NewBiz("failed to %s the container: %s", action, strings.TrimSpace(res))
+	}
+	return res, nil
+}
+
+// FollowDockerLogs 持续读取容器日志并写入w，直至ctx结束或容器日志输出结束
+func (c *Cli) FollowDockerLogs(ctx context.Context, containerId string, tail int, w io.Writer) error {
+	if err := CheckDockerContainerId(containerId); err != nil {
+		return err
+	}
+	session, err := c.GetSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	// 容器的stderr输出同样为日志内容，故重定向至stdout
+	if err := session.Start(fmt.Sprintf("docker logs --follow --timestamps --tail %d %s 2>&1", tail, shellx.Quote(containerId))); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		br := bufio.NewReader(stdout)
+		_, err := br.WriteTo(w)
+		done <- err
+	}()
+
+	select {
+	case <-ctx.Done():
+		// 部分sshd不处理signal请求，故需显式关闭session以结束远程的docker logs进程
+		session.Signal(ssh.SIGTERM)
+		session.Close()
+		return nil
+	case err := <-done:
+		if err != nil {
+			return err
+		}
+		return session.Wait()
+	}
+}
+
+// DockerExecCmd 进入容器交互式终端的命令，优先使用bash，不存在则使用sh
+func DockerExecCmd(containerId string) string {
+	return fmt.Sprintf("docker exec -it %s sh -c 'if command -v bash >/dev/null 2>&1; then exec bash; else exec sh; fi'", shellx.Quote(containerId))
+}
+
+// ParseDockerJsonLines 解析docker --format '{{json .}}'的输出，每行为一个json对象
+func ParseDockerJsonLines[T any](output string) ([]*T, error) {
+	res := make([]*T, 0)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || !strings.HasPrefix(line, "{") {
+			continue
+		}
+		item := new(T)
+		if err := json.Unmarshal([]byte(line), item); err != nil {
+			return nil, errorx.NewBiz("failed to parse the docker output: %s", err.Error())
+		}
+		res = append(res, item)
+	}
+	return res, nil
+}
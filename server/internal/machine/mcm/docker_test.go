@@ -0,0 +1,44 @@
+package mcm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDockerJsonLines(t *testing.T) {
+	output := `{"Command":"\"/docker-entrypoint.sh nginx -g 'daemon off;'\"","CreatedAt":"2026-10-01 10:00:00 +0800 CST","ID":"3f4e1c2b9a","Image":"nginx:1.27","Names":"web","Networks":"bridge","Ports":"0.0.0.0:80->80/tcp","RunningFor":"2 weeks ago","State":"running","Status":"Up 2 weeks"}
+{"Command":"\"redis-server\"","ID":"8d7c6b5a4f","Image":"redis:7","Names":"cache","State":"exited","Status":"Exited (0) 3 days ago"}
+`
+	containers, err := ParseDockerJsonLines[DockerContainer](output)
+	assert.NoError(t, err)
+	assert.Len(t, containers, 2)
+	assert.Equal(t, "3f4e1c2b9a", containers[0].Id)
+	assert.Equal(t, "web", containers[0].Names)
+	assert.Equal(t, "0.0.0.0:80->80/tcp", containers[0].Ports)
+	assert.Equal(t, "running", containers[0].State)
+	assert.Equal(t, "exited", containers[1].State)
+
+	stats, err := ParseDockerJsonLines[DockerContainerStats](`{"BlockIO":"1MB / 0B","CPUPerc":"0.15%","Container":"3f4e1c2b9a","ID":"3f4e1c2b9a","MemPerc":"0.50%","MemUsage":"10MiB / 2GiB","Name":"web","NetIO":"1kB / 2kB","PIDs":"3"}`)
+	assert.NoError(t, err)
+	assert.Len(t, stats, 1)
+	assert.Equal(t, "0.15%", stats[0].CPUPerc)
+	assert.Equal(t, "3", stats[0].PIDs)
+
+	// 非json行(如警告信息)忽略
+	containers, err = ParseDockerJsonLines[DockerContainer]("WARNING: some warning\n")
+	assert.NoError(t, err)
+	assert.Empty(t, containers)
+
+	_, err = ParseDockerJsonLines[DockerContainer]("{invalid")
+	assert.Error(t, err)
+}
+
+func TestCheckDockerContainerId(t *testing.T) {
+	assert.NoError(t, CheckDockerContainerId("3f4e1c2b9a"))
+	assert.NoError(t, CheckDockerContainerId("my_app.web-1"))
+	assert.Error(t, CheckDockerContainerId(""))
+	assert.Error(t, CheckDockerContainerId("-f"))
+	assert.Error(t, CheckDockerContainerId("web;rm -rf /"))
+	assert.Error(t, CheckDockerContainerId("$(id)"))
+}
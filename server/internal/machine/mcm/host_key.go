@@ -0,0 +1,27 @@
+package mcm
+
+import (
+	"net"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// HostKeyVerifyFunc 主机公钥校验函数，返回错误则拒绝连接
+type HostKeyVerifyFunc func(mi *MachineInfo, key ssh.PublicKey) error
+
+var hostKeyVerifyFunc HostKeyVerifyFunc
+
+// SetHostKeyVerifyFunc 设置主机公钥校验函数
+func SetHostKeyVerifyFunc(verifyFunc HostKeyVerifyFunc) {
+	hostKeyVerifyFunc = verifyFunc
+}
+
+// hostKeyCallback 生成ssh主机公钥校验回调，未保存的机器(如测试连接)无法固定公钥，则不校验
+func hostKeyCallback(mi *MachineInfo) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		if hostKeyVerifyFunc == nil || mi.Code == "" {
+			return nil
+		}
+		return hostKeyVerifyFunc(mi, key)
+	}
+}
@@ -0,0 +1,725 @@
+package mcm
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 机器清单格式
+const (
+	InventoryFormatCsv         = "csv"
+	InventoryFormatSshConfig   = "ssh_config"
+	InventoryFormatAnsibleIni  = "ansible_ini"
+	InventoryFormatAnsibleYaml = "ansible_yaml"
+
+	inventoryCsvListSeparator = ";" // csv中多个标签路径的分隔符
+
+	ansibleGroupAll       = "all"
+	ansibleGroupUngrouped = "ungrouped"
+)
+
+// InventoryHost 从机器清单中解析出的主机信息
+type InventoryHost struct {
+	Line         int      // 来源行号
+	Name         string   // 名称，如ssh config的Host别名、ansible的主机名
+	Host         string   // 主机地址
+	Port         int      // 端口，未指定则为0
+	Protocol     string   // 协议，如ssh、rdp、vnc，未指定则为空
+	Username     string   // 登录用户名
+	Password     string   // 登录密码
+	PrivateKey   string   // 私钥内容
+	AuthCertName string   // 公共授权凭证名称
+	ProxyJump    string   // 跳板机，格式为[user@]host[:port]
+	Groups       []string // 所属分组，如ansible分组
+	TagCodePaths []string // 标签路径
+	Remark       string
+	Warnings     []string // 无法导入的配置等提示信息
+	Error        string   // 该主机的解析错误
+}
+
+// ParseInventory 解析机器清单
+func ParseInventory(format string, content string) ([]*InventoryHost, error) {
+	switch format {
+	case InventoryFormatCsv:
+		return ParseCsvInventory(content)
+	case InventoryFormatSshConfig:
+		return ParseSshConfig(content), nil
+	case InventoryFormatAnsibleIni:
+		return ParseAnsibleIniInventory(content)
+	case InventoryFormatAnsibleYaml:
+		return ParseAnsibleYamlInventory(content)
+	default:
+		return nil, fmt.Errorf("unsupported inventory format: %s", format)
+	}
+}
+
+/******************* csv *******************/
+
+// ParseCsvInventory 解析csv格式的机器清单，首行为表头(不区分大小写)：
+// name,ip,port,protocol,username,password,privateKey,authCert,tags,proxyJump,remark
+// 其中ip也可为host，tags为多个以;分隔的标签路径，未知的列忽略
+func ParseCsvInventory(content string) ([]*InventoryHost, error) {
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(content, "\ufeff")))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("the csv content is empty")
+		}
+		return nil, fmt.Errorf("failed to read the csv header: %s", err.Error())
+	}
+	columns := make(map[string]int, len(header))
+	for i, col := range header {
+		col = strings.ToLower(strings.TrimSpace(col))
+		if col == "host" {
+			col = "ip"
+		}
+		columns[col] = i
+	}
+	if _, ok := columns["ip"]; !ok {
+		return nil, fmt.Errorf("the csv header must contain the ip column")
+	}
+
+	hosts := make([]*InventoryHost, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the csv: %s", err.Error())
+		}
+		line, _ := reader.FieldPos(0)
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		get := func(col string) string {
+			if i, ok := columns[strings.ToLower(col)]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		host := &InventoryHost{
+			Line:         line,
+			Name:         get("name"),
+			Host:         get("ip"),
+			Protocol:     strings.ToLower(get("protocol")),
+			Username:     get("username"),
+			Password:     get("password"),
+			PrivateKey:   get("privateKey"),
+			AuthCertName: get("authCert"),
+			ProxyJump:    get("proxyJump"),
+			Remark:       get("remark"),
+		}
+		for _, tag := range strings.Split(get("tags"), inventoryCsvListSeparator) {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				host.TagCodePaths = append(host.TagCodePaths, tag)
+			}
+		}
+		if port := get("port"); port != "" {
+			if host.Port, err = strconv.Atoi(port); err != nil {
+				host.Error = fmt.Sprintf("invalid port: %s", port)
+			}
+		}
+		if host.Name == "" {
+			host.Name = host.Host
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+/******************* ssh config *******************/
+
+type sshConfigBlock struct {
+	patterns []string
+	options  map[string]string // 小写的配置项 -> 值，同一块中首次出现的值生效
+}
+
+// ParseSshConfig 解析OpenSSH客户端配置文件(~/.ssh/config)，每个非通配的Host别名作为一个主机，
+// 与ssh一致，对于每个配置项，按文件顺序首个匹配该别名的Host块中的值生效。Match块及Include指令不支持，将被忽略
+func ParseSshConfig(content string) []*InventoryHost {
+	var blocks []*sshConfigBlock
+	// 全局配置，即首个Host之前的配置
+	current := &sshConfigBlock{patterns: []string{"*"}, options: map[string]string{}}
+	blocks = append(blocks, current)
+
+	var aliases []string
+	aliasLines := map[string]int{}
+	var unsupported []string
+
+	for i, rawLine := range strings.Split(content, "\n") {
+		key, value := splitSshConfigLine(rawLine)
+		if key == "" {
+			continue
+		}
+		switch key {
+		case "host":
+			current = &sshConfigBlock{patterns: strings.Fields(value), options: map[string]string{}}
+			blocks = append(blocks, current)
+			for _, p := range current.patterns {
+				if strings.ContainsAny(p, "*?!") {
+					continue
+				}
+				if _, ok := aliasLines[p]; !ok {
+					aliases = append(aliases, p)
+					aliasLines[p] = i + 1
+				}
+			}
+		case "match":
+			// Match块不支持，使用无法匹配任何别名的块承接其配置
+			current = &sshConfigBlock{options: map[string]string{}}
+			blocks = append(blocks, current)
+			unsupported = append(unsupported, fmt.Sprintf("line %d: Match", i+1))
+		case "include":
+			unsupported = append(unsupported, fmt.Sprintf("line %d: Include", i+1))
+		default:
+			if _, ok := current.options[key]; !ok {
+				current.options[key] = value
+			}
+		}
+	}
+
+	hosts := make([]*InventoryHost, 0, len(aliases))
+	for _, alias := range aliases {
+		options := map[string]string{}
+		for _, block := range blocks {
+			if !matchSshConfigPatterns(block.patterns, alias) {
+				continue
+			}
+			for k, v := range block.options {
+				if _, ok := options[k]; !ok {
+					options[k] = v
+				}
+			}
+		}
+
+		host := &InventoryHost{
+			Line:      aliasLines[alias],
+			Name:      alias,
+			Host:      alias,
+			Username:  options["user"],
+			ProxyJump: options["proxyjump"],
+		}
+		if hostname := options["hostname"]; hostname != "" {
+			host.Host = strings.ReplaceAll(hostname, "%h", alias)
+		}
+		if port := options["port"]; port != "" {
+			p, err := strconv.Atoi(port)
+			if err != nil {
+				host.Error = fmt.Sprintf("invalid port: %s", port)
+			}
+			host.Port = p
+		}
+		if strings.EqualFold(host.ProxyJump, "none") {
+			host.ProxyJump = ""
+		}
+		if identityFile := options["identityfile"]; identityFile != "" {
+			host.Warnings = append(host.Warnings, fmt.Sprintf("the identity file %s cannot be imported", identityFile))
+		}
+		if options["proxycommand"] != "" && host.ProxyJump == "" {
+			host.Warnings = append(host.Warnings, "ProxyCommand is not supported")
+		}
+		for _, u := range unsupported {
+			host.Warnings = append(host.Warnings, fmt.Sprintf("%s is not supported", u))
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// splitSshConfigLine 拆分ssh config的配置行，返回小写的配置项及其值，配置项与值可使用空白或=分隔
+func splitSshConfigLine(line string) (string, string) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", ""
+	}
+	idx := strings.IndexAny(line, " \t=")
+	if idx < 0 {
+		return strings.ToLower(line), ""
+	}
+	key := strings.ToLower(line[:idx])
+	value := strings.TrimSpace(line[idx:])
+	value = strings.TrimSpace(strings.TrimPrefix(value, "="))
+	return key, strings.Trim(value, `"`)
+}
+
+// matchSshConfigPatterns 判断别名是否匹配Host的模式列表，存在匹配的否定模式(!pattern)则不匹配
+func matchSshConfigPatterns(patterns []string, alias string) bool {
+	matched := false
+	for _, p := range patterns {
+		negate := strings.HasPrefix(p, "!")
+		p = strings.TrimPrefix(p, "!")
+		if ok, _ := path.Match(p, alias); ok {
+			if negate {
+				return false
+			}
+			matched = true
+		}
+	}
+	return matched
+}
+
+/******************* ansible *******************/
+
+type ansibleInventory struct {
+	groups map[string]*ansibleGroup
+	hosts  []*ansibleHost // 按首次出现的顺序
+}
+
+type ansibleGroup struct {
+	name     string
+	vars     map[string]string
+	children []string
+}
+
+type ansibleHost struct {
+	name   string
+	line   int
+	vars   map[string]string
+	groups []string // 直接所属分组
+}
+
+func newAnsibleInventory() *ansibleInventory {
+	return &ansibleInventory{groups: map[string]*ansibleGroup{}}
+}
+
+func (inv *ansibleInventory) group(name string) *ansibleGroup {
+	g, ok := inv.groups[name]
+	if !ok {
+		g = &ansibleGroup{name: name, vars: map[string]string{}}
+		inv.groups[name] = g
+	}
+	return g
+}
+
+func (inv *ansibleInventory) addHost(name string, line int, group string, vars map[string]string) {
+	var host *ansibleHost
+	for _, h := range inv.hosts {
+		if h.name == name {
+			host = h
+			break
+		}
+	}
+	if host == nil {
+		host = &ansibleHost{name: name, line: line, vars: map[string]string{}}
+		inv.hosts = append(inv.hosts, host)
+	}
+	for k, v := range vars {
+		host.vars[k] = v
+	}
+	if group != "" && group != ansibleGroupAll && !slices.Contains(host.groups, group) {
+		host.groups = append(host.groups, group)
+	}
+}
+
+// parents 获取分组的所有父分组
+func (inv *ansibleInventory) parents(name string) []string {
+	var res []string
+	for _, g := range inv.groups {
+		if slices.Contains(g.children, name) {
+			res = append(res, g.name)
+		}
+	}
+	slices.Sort(res)
+	return res
+}
+
+// toHosts 合并变量并转换为主机信息。变量优先级：主机变量 > 直接所属分组变量 > 父分组变量 > all分组变量
+func (inv *ansibleInventory) toHosts() []*InventoryHost {
+	hosts := make([]*InventoryHost, 0, len(inv.hosts))
+	for _, h := range inv.hosts {
+		vars := map[string]string{}
+		merge := func(src map[string]string) {
+			for k, v := range src {
+				if _, ok := vars[k]; !ok {
+					vars[k] = v
+				}
+			}
+		}
+		merge(h.vars)
+
+		visited := map[string]bool{}
+		level := slices.Clone(h.groups)
+		for len(level) > 0 {
+			var next []string
+			for _, g := range level {
+				if visited[g] || g == ansibleGroupAll {
+					continue
+				}
+				visited[g] = true
+				if group, ok := inv.groups[g]; ok {
+					merge(group.vars)
+				}
+				next = append(next, inv.parents(g)...)
+			}
+			level = next
+		}
+		if all, ok := inv.groups[ansibleGroupAll]; ok {
+			merge(all.vars)
+		}
+
+		hosts = append(hosts, newAnsibleInventoryHost(h, vars))
+	}
+	return hosts
+}
+
+func newAnsibleInventoryHost(h *ansibleHost, vars map[string]string) *InventoryHost {
+	getVar := func(keys ...string) string {
+		for _, k := range keys {
+			if v := vars[k]; v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	host := &InventoryHost{
+		Line:     h.line,
+		Name:     h.name,
+		Host:     getVar("ansible_host", "ansible_ssh_host"),
+		Username: getVar("ansible_user", "ansible_ssh_user"),
+		Password: getVar("ansible_password", "ansible_ssh_pass"),
+	}
+	if host.Host == "" {
+		host.Host = h.name
+	}
+	for _, g := range h.groups {
+		if g != ansibleGroupUngrouped {
+			host.Groups = append(host.Groups, g)
+		}
+	}
+	if port := getVar("ansible_port", "ansible_ssh_port"); port != "" {
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			host.Error = fmt.Sprintf("invalid port: %s", port)
+		}
+		host.Port = p
+	}
+	if conn := getVar("ansible_connection"); conn != "" && conn != "ssh" && conn != "paramiko" && conn != "smart" {
+		host.Error = fmt.Sprintf("unsupported ansible_connection: %s", conn)
+	}
+	if keyFile := getVar("ansible_ssh_private_key_file", "ansible_private_key_file"); keyFile != "" {
+		host.Warnings = append(host.Warnings, fmt.Sprintf("the private key file %s cannot be imported", keyFile))
+	}
+	host.ProxyJump = parseSshArgsProxyJump(getVar("ansible_ssh_common_args") + " " + getVar("ansible_ssh_extra_args"))
+	return host
+}
+
+var (
+	sshArgsProxyJumpRegexp    = regexp.MustCompile(`(?i)(?:-o\s*ProxyJump\s*=?\s*|-J\s*)([^\s'"]+)`)
+	sshArgsProxyCommandRegexp = regexp.MustCompile(`(?i)ProxyCommand\s*=?\s*(?:"([^"]*)"|'([^']*)')`)
+)
+
+// ssh命令中需要参数值的选项
+const sshOptsWithArg = "bcDEeFIiJLlmOopQRSWw"
+
+// parseSshArgsProxyJump 从ssh命令参数中解析跳板机，支持-J、-o ProxyJump=及ProxyCommand="ssh -W %h:%p host"形式
+func parseSshArgsProxyJump(args string) string {
+	if m := sshArgsProxyJumpRegexp.FindStringSubmatch(args); m != nil {
+		return m[1]
+	}
+	m := sshArgsProxyCommandRegexp.FindStringSubmatch(args)
+	if m == nil {
+		return ""
+	}
+	fields := strings.Fields(m[1] + m[2])
+	if len(fields) == 0 || path.Base(fields[0]) != "ssh" || !slices.Contains(fields, "-W") {
+		return ""
+	}
+	// 跳板机为ssh命令中非选项及选项参数的值
+	for i := 1; i < len(fields); i++ {
+		f := fields[i]
+		if strings.HasPrefix(f, "-") {
+			if len(f) == 2 && strings.ContainsRune(sshOptsWithArg, rune(f[1])) {
+				i++
+			}
+			continue
+		}
+		return f
+	}
+	return ""
+}
+
+// ParseAnsibleIniInventory 解析ansible ini格式的清单，支持[group]、[group:vars]、[group:children]及主机名范围(如web[01:03])
+func ParseAnsibleIniInventory(content string) ([]*InventoryHost, error) {
+	inv := newAnsibleInventory()
+	section, sectionType := ansibleGroupUngrouped, ""
+
+	for i, rawLine := range strings.Split(content, "\n") {
+		line := strings.TrimSpace(rawLine)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		lineNo := i + 1
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section, sectionType, _ = strings.Cut(strings.TrimSpace(line[1:len(line)-1]), ":")
+			if section == "" {
+				return nil, fmt.Errorf("line %d: invalid section %s", lineNo, line)
+			}
+			if sectionType != "" && sectionType != "vars" && sectionType != "children" {
+				return nil, fmt.Errorf("line %d: invalid section type %s", lineNo, sectionType)
+			}
+			inv.group(section)
+			continue
+		}
+
+		switch sectionType {
+		case "vars":
+			k, v, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, fmt.Errorf("line %d: invalid variable %s", lineNo, line)
+			}
+			inv.group(section).vars[strings.TrimSpace(k)] = unquoteAnsibleValue(strings.TrimSpace(v))
+		case "children":
+			child := strings.Fields(line)[0]
+			inv.group(child)
+			g := inv.group(section)
+			if !slices.Contains(g.children, child) {
+				g.children = append(g.children, child)
+			}
+		default:
+			fields, err := splitAnsibleHostLine(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", lineNo, err.Error())
+			}
+			vars := map[string]string{}
+			for _, f := range fields[1:] {
+				k, v, ok := strings.Cut(f, "=")
+				if !ok {
+					return nil, fmt.Errorf("line %d: invalid host variable %s", lineNo, f)
+				}
+				vars[k] = v
+			}
+			names, err := expandAnsibleHostPattern(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", lineNo, err.Error())
+			}
+			for _, name := range names {
+				inv.addHost(name, lineNo, section, vars)
+			}
+		}
+	}
+	return inv.toHosts(), nil
+}
+
+// splitAnsibleHostLine 按空白拆分主机行，支持单双引号包裹含空白的值，#之后的内容视为注释
+func splitAnsibleHostLine(line string) ([]string, error) {
+	var fields []string
+	var sb strings.Builder
+	var quote rune
+	inField := false
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				sb.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inField = true
+		case r == ' ' || r == '\t':
+			if inField {
+				fields = append(fields, sb.String())
+				sb.Reset()
+				inField = false
+			}
+		case r == '#' && !inField:
+			quote = -1
+		default:
+			sb.WriteRune(r)
+			inField = true
+		}
+		if quote == -1 {
+			break
+		}
+	}
+	if quote > 0 {
+		return nil, fmt.Errorf("unclosed quote")
+	}
+	if inField {
+		fields = append(fields, sb.String())
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty host")
+	}
+	return fields, nil
+}
+
+func unquoteAnsibleValue(v string) string {
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+var ansibleHostRangeRegexp = regexp.MustCompile(`\[([0-9a-zA-Z]+):([0-9a-zA-Z]+)(?::([0-9]+))?\]`)
+
+// expandAnsibleHostPattern 展开主机名范围，如web[01:03].example.com、db-[a:c]
+func expandAnsibleHostPattern(pattern string) ([]string, error) {
+	loc := ansibleHostRangeRegexp.FindStringSubmatchIndex(pattern)
+	if loc == nil {
+		return []string{pattern}, nil
+	}
+	prefix, suffix := pattern[:loc[0]], pattern[loc[1]:]
+	start, end := pattern[loc[2]:loc[3]], pattern[loc[4]:loc[5]]
+	step := 1
+	if loc[6] >= 0 {
+		step, _ = strconv.Atoi(pattern[loc[6]:loc[7]])
+		if step <= 0 {
+			return nil, fmt.Errorf("invalid host range step: %s", pattern)
+		}
+	}
+
+	var items []string
+	if s, err := strconv.Atoi(start); err == nil {
+		e, err := strconv.Atoi(end)
+		if err != nil || e < s {
+			return nil, fmt.Errorf("invalid host range: %s", pattern)
+		}
+		width := 0
+		if len(start) > 1 && start[0] == '0' {
+			width = len(start)
+		}
+		for n := s; n <= e; n += step {
+			items = append(items, fmt.Sprintf("%0*d", width, n))
+		}
+	} else if len(start) == 1 && len(end) == 1 && start[0] <= end[0] {
+		for c := start[0]; c <= end[0]; c += byte(step) {
+			items = append(items, string(c))
+			if int(c)+step > 255 {
+				break
+			}
+		}
+	} else {
+		return nil, fmt.Errorf("invalid host range: %s", pattern)
+	}
+
+	var res []string
+	for _, item := range items {
+		// 后缀中可能还存在范围
+		expanded, err := expandAnsibleHostPattern(prefix + item + suffix)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, expanded...)
+	}
+	return res, nil
+}
+
+// ParseAnsibleYamlInventory 解析ansible yaml格式的清单
+func ParseAnsibleYamlInventory(content string) ([]*InventoryHost, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &root); err != nil {
+		return nil, fmt.Errorf("invalid yaml: %s", err.Error())
+	}
+	if len(root.Content) == 0 {
+		return nil, fmt.Errorf("the yaml content is empty")
+	}
+	doc := root.Content[0]
+	if doc.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: the inventory must be a mapping of groups", doc.Line)
+	}
+
+	inv := newAnsibleInventory()
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if err := parseAnsibleYamlGroup(inv, doc.Content[i].Value, doc.Content[i+1]); err != nil {
+			return nil, err
+		}
+	}
+	return inv.toHosts(), nil
+}
+
+func parseAnsibleYamlGroup(inv *ansibleInventory, name string, node *yaml.Node) error {
+	g := inv.group(name)
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: group %s must be a mapping", node.Line, name)
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+		switch key {
+		case "hosts":
+			if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+				continue
+			}
+			if value.Kind != yaml.MappingNode {
+				return fmt.Errorf("line %d: the hosts of group %s must be a mapping", value.Line, name)
+			}
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				hostNode := value.Content[j]
+				vars, err := yamlNodeToVars(value.Content[j+1])
+				if err != nil {
+					return err
+				}
+				names, err := expandAnsibleHostPattern(hostNode.Value)
+				if err != nil {
+					return fmt.Errorf("line %d: %s", hostNode.Line, err.Error())
+				}
+				for _, hostName := range names {
+					inv.addHost(hostName, hostNode.Line, name, vars)
+				}
+			}
+		case "vars":
+			vars, err := yamlNodeToVars(value)
+			if err != nil {
+				return err
+			}
+			for k, v := range vars {
+				g.vars[k] = v
+			}
+		case "children":
+			if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+				continue
+			}
+			if value.Kind != yaml.MappingNode {
+				return fmt.Errorf("line %d: the children of group %s must be a mapping", value.Line, name)
+			}
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				child := value.Content[j].Value
+				if !slices.Contains(g.children, child) {
+					g.children = append(g.children, child)
+				}
+				if err := parseAnsibleYamlGroup(inv, child, value.Content[j+1]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// yamlNodeToVars 将变量映射转换为字符串键值，非标量的变量值忽略
+func yamlNodeToVars(node *yaml.Node) (map[string]string, error) {
+	vars := map[string]string{}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return vars, nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: the variables must be a mapping", node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if v := node.Content[i+1]; v.Kind == yaml.ScalarNode {
+			vars[node.Content[i].Value] = v.Value
+		}
+	}
+	return vars, nil
+}
@@ -0,0 +1,207 @@
+package mcm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCsvInventory(t *testing.T) {
+	content := "\ufeffName,IP,Port,Username,Password,Tags,ProxyJump,Extra\n" +
+		"web1,192.168.1.10,22,root,\"pa,ss\",default/dev/;default/web/,bastion,x\n" +
+		"\n" +
+		",192.168.1.11,abc,root,,,,\n"
+	hosts, err := ParseCsvInventory(content)
+	assert.NoError(t, err)
+	assert.Len(t, hosts, 2)
+
+	assert.Equal(t, 2, hosts[0].Line)
+	assert.Equal(t, "web1", hosts[0].Name)
+	assert.Equal(t, "192.168.1.10", hosts[0].Host)
+	assert.Equal(t, 22, hosts[0].Port)
+	assert.Equal(t, "pa,ss", hosts[0].Password)
+	assert.Equal(t, []string{"default/dev/", "default/web/"}, hosts[0].TagCodePaths)
+	assert.Equal(t, "bastion", hosts[0].ProxyJump)
+	assert.Empty(t, hosts[0].Error)
+
+	// 名称为空则使用ip，端口有误则记录行错误
+	assert.Equal(t, 4, hosts[1].Line)
+	assert.Equal(t, "192.168.1.11", hosts[1].Name)
+	assert.Equal(t, "invalid port: abc", hosts[1].Error)
+
+	_, err = ParseCsvInventory("name,port\nweb,22\n")
+	assert.Error(t, err)
+	_, err = ParseCsvInventory("")
+	assert.Error(t, err)
+}
+
+func TestParseSshConfig(t *testing.T) {
+	content := `
+User admin
+
+Host bastion
+    HostName 10.0.0.1
+    Port 2222
+
+Host web1 web2
+    HostName %h.internal
+    ProxyJump ops@bastion:2222
+    IdentityFile ~/.ssh/id_web
+
+Host=db1
+    HostName=10.0.1.5
+    User root
+
+Host web1
+    Port 2200
+
+Host *
+    User nobody
+    Port 22
+`
+	hosts := ParseSshConfig(content)
+	assert.Len(t, hosts, 4)
+
+	assert.Equal(t, "bastion", hosts[0].Name)
+	assert.Equal(t, "10.0.0.1", hosts[0].Host)
+	assert.Equal(t, 2222, hosts[0].Port)
+	assert.Equal(t, "admin", hosts[0].Username)
+
+	// 首个匹配的值生效
+	assert.Equal(t, "web1", hosts[1].Name)
+	assert.Equal(t, 8, hosts[1].Line)
+	assert.Equal(t, "web1.internal", hosts[1].Host)
+	assert.Equal(t, 2200, hosts[1].Port)
+	assert.Equal(t, "ops@bastion:2222", hosts[1].ProxyJump)
+	assert.Len(t, hosts[1].Warnings, 1)
+
+	assert.Equal(t, "web2.internal", hosts[2].Host)
+	assert.Equal(t, 22, hosts[2].Port)
+
+	// 全局配置先于Host块中的配置
+	assert.Equal(t, "db1", hosts[3].Name)
+	assert.Equal(t, "10.0.1.5", hosts[3].Host)
+	assert.Equal(t, "admin", hosts[3].Username)
+}
+
+func TestParseAnsibleIniInventory(t *testing.T) {
+	content := `
+jump ansible_host=10.0.0.1
+
+[web]
+web[01:03].example.com ansible_user=deploy
+web-x ansible_host=10.0.2.9 ansible_port=2200 ansible_ssh_common_args='-o ProxyJump=jump'
+
+[db]
+db1 ansible_host=10.0.3.1 ansible_password="p w"  # comment
+
+[prod:children]
+web
+db
+
+[prod:vars]
+ansible_user=ops
+ansible_ssh_pass=secret
+
+[all:vars]
+ansible_port=22
+`
+	hosts, err := ParseAnsibleIniInventory(content)
+	assert.NoError(t, err)
+	assert.Len(t, hosts, 6)
+
+	assert.Equal(t, "jump", hosts[0].Name)
+	assert.Equal(t, "10.0.0.1", hosts[0].Host)
+	assert.Equal(t, 22, hosts[0].Port)
+	assert.Empty(t, hosts[0].Groups)
+
+	assert.Equal(t, "web01.example.com", hosts[1].Name)
+	assert.Equal(t, "web01.example.com", hosts[1].Host)
+	assert.Equal(t, "web03.example.com", hosts[3].Name)
+	// 主机变量优先于父分组变量
+	assert.Equal(t, "deploy", hosts[1].Username)
+	assert.Equal(t, "secret", hosts[1].Password)
+	assert.Equal(t, []string{"web"}, hosts[1].Groups)
+	assert.Equal(t, 5, hosts[1].Line)
+
+	assert.Equal(t, "10.0.2.9", hosts[4].Host)
+	assert.Equal(t, 2200, hosts[4].Port)
+	assert.Equal(t, "jump", hosts[4].ProxyJump)
+	assert.Equal(t, "ops", hosts[4].Username)
+
+	assert.Equal(t, "p w", hosts[5].Password)
+	assert.Equal(t, []string{"db"}, hosts[5].Groups)
+
+	_, err = ParseAnsibleIniInventory("[web]\nweb1 ansible_host='10.0.0.1\n")
+	assert.Error(t, err)
+	_, err = ParseAnsibleIniInventory("[web:unknown]\n")
+	assert.Error(t, err)
+}
+
+func TestParseAnsibleYamlInventory(t *testing.T) {
+	content := `
+all:
+  vars:
+    ansible_user: root
+  hosts:
+    jump:
+      ansible_host: 10.0.0.1
+      ansible_port: 2222
+  children:
+    web:
+      hosts:
+        web[1:2]:
+      vars:
+        ansible_ssh_extra_args: -J jump
+    db:
+      hosts:
+        db1:
+          ansible_host: 10.0.3.1
+          ansible_user: postgres
+          ansible_ssh_private_key_file: ~/.ssh/db
+`
+	hosts, err := ParseAnsibleYamlInventory(content)
+	assert.NoError(t, err)
+	assert.Len(t, hosts, 4)
+
+	assert.Equal(t, "jump", hosts[0].Name)
+	assert.Equal(t, 2222, hosts[0].Port)
+	assert.Equal(t, "root", hosts[0].Username)
+	assert.Empty(t, hosts[0].Groups)
+
+	assert.Equal(t, "web1", hosts[1].Name)
+	assert.Equal(t, "web2", hosts[2].Name)
+	assert.Equal(t, "jump", hosts[2].ProxyJump)
+	assert.Equal(t, []string{"web"}, hosts[2].Groups)
+
+	assert.Equal(t, "postgres", hosts[3].Username)
+	assert.Len(t, hosts[3].Warnings, 1)
+
+	_, err = ParseAnsibleYamlInventory("- a\n- b\n")
+	assert.Error(t, err)
+}
+
+func TestExpandAnsibleHostPattern(t *testing.T) {
+	names, err := expandAnsibleHostPattern("web[08:10].example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"web08.example.com", "web09.example.com", "web10.example.com"}, names)
+
+	names, err = expandAnsibleHostPattern("db-[a:c]")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"db-a", "db-b", "db-c"}, names)
+
+	names, err = expandAnsibleHostPattern("node[1:5:2]")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"node1", "node3", "node5"}, names)
+
+	_, err = expandAnsibleHostPattern("web[3:1]")
+	assert.Error(t, err)
+}
+
+func TestParseSshArgsProxyJump(t *testing.T) {
+	assert.Equal(t, "ops@bastion:22", parseSshArgsProxyJump("-o ProxyJump=ops@bastion:22"))
+	assert.Equal(t, "bastion", parseSshArgsProxyJump("-o StrictHostKeyChecking=no -J bastion"))
+	assert.Equal(t, "bastion", parseSshArgsProxyJump(`-o ProxyCommand="ssh -W %h:%p -q bastion"`))
+	assert.Equal(t, "jump", parseSshArgsProxyJump(`-o ProxyCommand='ssh -l ops -W %h:%p -p 2222 jump'`))
+	assert.Equal(t, "", parseSshArgsProxyJump("-o StrictHostKeyChecking=no"))
+}
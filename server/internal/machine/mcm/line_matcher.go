@@ -0,0 +1,68 @@
+package mcm
+
+import "regexp"
+
+// LineMatcher 文本行匹配器，用于日志跟踪时的行过滤及关键字高亮
+type LineMatcher struct {
+	regexp *regexp.Regexp
+}
+
+// LineSegment 行内片段，Match为true表示该片段命中匹配规则需高亮显示
+type LineSegment struct {
+	Text  string `json:"text"`
+	Match bool   `json:"match,omitempty"`
+}
+
+// NewLineMatcher 创建行匹配器，pattern为空则返回nil；isRegexp为false时按普通关键字匹配
+func NewLineMatcher(pattern string, isRegexp bool, ignoreCase bool) (*LineMatcher, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	if !isRegexp {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	p, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &LineMatcher{regexp: p}, nil
+}
+
+// Match 行是否命中，匹配器为nil时视为全部命中
+func (m *LineMatcher) Match(line string) bool {
+	if m == nil {
+		return true
+	}
+	return m.regexp.MatchString(line)
+}
+
+// Segments 将行按命中内容拆分为多个片段，未命中或匹配器为nil时返回nil
+func (m *LineMatcher) Segments(line string) []LineSegment {
+	if m == nil {
+		return nil
+	}
+	locs := m.regexp.FindAllStringIndex(line, -1)
+	var segments []LineSegment
+	last := 0
+	for _, loc := range locs {
+		// 忽略空匹配，如 a* 匹配空串
+		if loc[0] == loc[1] {
+			continue
+		}
+		if loc[0] > last {
+			segments = append(segments, LineSegment{Text: line[last:loc[0]]})
+		}
+		segments = append(segments, LineSegment{Text: line[loc[0]:loc[1]], Match: true})
+		last = loc[1]
+	}
+	if len(segments) == 0 {
+		return nil
+	}
+	if last < len(line) {
+		segments = append(segments, LineSegment{Text: line[last:]})
+	}
+	return segments
+}
@@ -0,0 +1,43 @@
+package mcm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineMatcher(t *testing.T) {
+	m, err := NewLineMatcher("error", false, true)
+	require.NoError(t, err)
+	require.True(t, m.Match("2024 ERROR connect fail"))
+	require.False(t, m.Match("2024 INFO started"))
+	require.Equal(t, []LineSegment{{Text: "a "}, {Text: "Error", Match: true}, {Text: " b "}, {Text: "error", Match: true}},
+		m.Segments("a Error b error"))
+	require.Nil(t, m.Segments("nothing"))
+
+	// 关键字中的正则元字符按普通字符匹配
+	m, err = NewLineMatcher("a.b", false, false)
+	require.NoError(t, err)
+	require.True(t, m.Match("xa.by"))
+	require.False(t, m.Match("axb"))
+
+	m, err = NewLineMatcher(`code=\d+`, true, false)
+	require.NoError(t, err)
+	require.Equal(t, []LineSegment{{Text: "code=500", Match: true}, {Text: " 中文"}}, m.Segments("code=500 中文"))
+
+	// 空匹配不产生高亮片段
+	m, err = NewLineMatcher("x*", true, false)
+	require.NoError(t, err)
+	require.Nil(t, m.Segments("abc"))
+
+	_, err = NewLineMatcher("(", true, false)
+	require.Error(t, err)
+
+	// 未设置规则时全部命中且无高亮
+	var nilMatcher *LineMatcher
+	m, err = NewLineMatcher("", true, false)
+	require.NoError(t, err)
+	require.Equal(t, nilMatcher, m)
+	require.True(t, m.Match("any"))
+	require.Nil(t, m.Segments("any"))
+}
@@ -8,7 +8,6 @@ import (
 	"mayfly-go/pkg/logx"
 	"mayfly-go/pkg/model"
 	"mayfly-go/pkg/utils/netx"
-	"net"
 	"strings"
 	"time"
 
@@ -118,6 +117,9 @@ func GetSshClient(m *MachineInfo, jumpClient *ssh.Client) (*ssh.Client, error) {
 		}
 		// 新建一个没有跳板机的机器信息
 		m1 := &MachineInfo{
+			Id:         m.Id,
+			Code:       m.Code,
+			Name:       m.Name,
 			Ip:         m.Ip,
 			Port:       m.Port,
 			AuthMethod: m.AuthMethod,
@@ -130,11 +132,9 @@ func GetSshClient(m *MachineInfo, jumpClient *ssh.Client) (*ssh.Client, error) {
 	}
 	// 配置 SSH 客户端
 	config := &ssh.ClientConfig{
-		User: m.Username,
-		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
-			return nil
-		},
-		Timeout: 5 * time.Second,
+		User:            m.Username,
+		HostKeyCallback: hostKeyCallback(m),
+		Timeout:         5 * time.Second,
 	}
 	if ciphers := m.GetExtraString("ciphers"); ciphers != "" {
 		config.Ciphers = strings.Split(ciphers, ",")
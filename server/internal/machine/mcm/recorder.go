@@ -1,8 +1,11 @@
 package mcm
 
 import (
+	"bufio"
 	"encoding/json"
 	"io"
+	"regexp"
+	"strings"
 	"sync"
 	"time"
 )
@@ -65,3 +68,61 @@ func (rec *Recorder) WriteData(rectype RecType, data string) {
 	rec.Writer.Write(b)
 	rec.Writer.Write([]byte("\r\n"))
 }
+
+var (
+	// 终端控制序列，包括CSI、OSC及其他ESC序列
+	ansiEscapeRegexp = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[()][0-9A-Za-z]|\x1b[@-Z\\-_=>]`)
+)
+
+// WriteTranscript 将asciicast v2格式的终端回放记录转为纯文本记录，去除终端控制序列
+func WriteTranscript(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	bw := bufio.NewWriter(w)
+	var line []rune
+	isHeader := true
+	for scanner.Scan() {
+		data := strings.TrimSpace(scanner.Text())
+		if data == "" {
+			continue
+		}
+		// 首行为头信息
+		if isHeader {
+			isHeader = false
+			continue
+		}
+
+		var event []any
+		if err := json.Unmarshal([]byte(data), &event); err != nil || len(event) < 3 {
+			continue
+		}
+		if recType, _ := event[1].(string); recType != string(OutPutType) {
+			continue
+		}
+		output, _ := event[2].(string)
+
+		for _, r := range ansiEscapeRegexp.ReplaceAllString(output, "") {
+			switch {
+			case r == '\n':
+				bw.WriteString(string(line))
+				bw.WriteByte('\n')
+				line = line[:0]
+			case r == '\b':
+				if len(line) > 0 {
+					line = line[:len(line)-1]
+				}
+			case r == '\t' || r >= 0x20 && r != 0x7f:
+				line = append(line, r)
+			}
+		}
+	}
+	if len(line) > 0 {
+		bw.WriteString(string(line))
+		bw.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,27 @@
+package mcm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteTranscript(t *testing.T) {
+	var rec bytes.Buffer
+	recorder := NewRecorder(&rec)
+	recorder.WriteHeader(30, 120)
+	recorder.WriteData(OutPutType, "\x1b[?2004h\x1b]0;root@host: ~\x07root@host:~# ")
+	recorder.WriteData(InputType, "ls\r")
+	recorder.WriteData(OutPutType, "lx\bs\r\n\x1b[0m\x1b[01;34mdir\x1b[0m  file.txt\r\n")
+	recorder.WriteData(OutPutType, "root@host:~# exit")
+
+	var out bytes.Buffer
+	if err := WriteTranscript(strings.NewReader(rec.String()), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "root@host:~# ls\ndir  file.txt\nroot@host:~# exit\n"
+	if out.String() != expected {
+		t.Errorf("unexpected transcript:\n%q\nexpected:\n%q", out.String(), expected)
+	}
+}
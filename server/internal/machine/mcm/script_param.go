@@ -0,0 +1,382 @@
+package mcm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/shellx"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const (
+	ScriptParamTypeString  = "string"
+	ScriptParamTypeNumber  = "number"
+	ScriptParamTypeEnum    = "enum"
+	ScriptParamTypeBoolean = "boolean"
+	ScriptParamTypeSecret  = "secret"
+
+	ScriptParamSecretMask = "******"
+)
+
+var scriptParamModelRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ScriptParam 机器脚本参数定义，脚本中使用 {{.model}} 引用参数值
+type ScriptParam struct {
+	Model       string   `json:"model"`                 // 参数名，即模板占位符名
+	Name        string   `json:"name"`                  // 展示名称
+	Type        string   `json:"type,omitempty"`        // 参数类型，为空时有可选值则为enum，否则为string
+	Placeholder string   `json:"placeholder,omitempty"` // 输入提示
+	Options     string   `json:"options,omitempty"`     // 可选值，逗号分隔，仅enum类型
+	Required    *bool    `json:"required,omitempty"`    // 是否必填，为空则为必填
+	Default     any      `json:"default,omitempty"`     // 默认值，未填写时使用
+	Pattern     string   `json:"pattern,omitempty"`     // 值需完整匹配的正则，仅string、secret类型
+	Min         *float64 `json:"min,omitempty"`         // 最小值，仅number类型
+	Max         *float64 `json:"max,omitempty"`         // 最大值，仅number类型
+
+	pattern *regexp.Regexp
+}
+
+// ParseScriptParams 解析并校验脚本参数定义json
+func ParseScriptParams(paramsJson string) ([]*ScriptParam, error) {
+	if strings.TrimSpace(paramsJson) == "" {
+		return nil, nil
+	}
+
+	var params []*ScriptParam
+	if err := json.Unmarshal([]byte(paramsJson), &params); err != nil {
+		// 兼容旧版本初始化数据中被多余转义的参数定义，如 [{\"model\": \"name\"}]
+		if json.Unmarshal([]byte(strings.ReplaceAll(paramsJson, `\"`, `"`)), &params) != nil {
+			return nil, errorx.NewBiz("invalid script params: %s", err.Error())
+		}
+	}
+
+	models := make(map[string]bool, len(params))
+	for _, p := range params {
+		if strings.TrimSpace(p.Model) == "" {
+			return nil, errorx.NewBiz("script param model cannot be empty")
+		}
+		if models[p.Model] {
+			return nil, errorx.NewBiz("duplicate script param model: %s", p.Model)
+		}
+		models[p.Model] = true
+
+		if err := p.init(); err != nil {
+			return nil, err
+		}
+	}
+	return params, nil
+}
+
+// CheckScriptParamModels 校验参数名是否可直接作为模板占位符({{.model}})使用，仅用于保存新的参数定义，
+// 旧版本中不符合规则的参数名仍可通过 {{index . "model"}} 引用，故执行时不做校验
+func CheckScriptParamModels(params []*ScriptParam) error {
+	for _, p := range params {
+		if !scriptParamModelRegexp.MatchString(p.Model) {
+			return errorx.NewBiz("invalid script param model: %s", p.Model)
+		}
+	}
+	return nil
+}
+
+// init 规范化参数类型并校验参数定义
+func (p *ScriptParam) init() error {
+	if p.Type == "" {
+		p.Type = ScriptParamTypeString
+		if p.Options != "" {
+			p.Type = ScriptParamTypeEnum
+		}
+	}
+
+	switch p.Type {
+	case ScriptParamTypeString, ScriptParamTypeSecret:
+		if p.Pattern != "" {
+			pattern, err := regexp.Compile(`^(?:` + p.Pattern + `)$`)
+			if err != nil {
+				return errorx.NewBiz("invalid pattern of script param [%s]: %s", p.Model, err.Error())
+			}
+			p.pattern = pattern
+		}
+	case ScriptParamTypeNumber:
+		if p.Min != nil && p.Max != nil && *p.Min > *p.Max {
+			return errorx.NewBiz("the min value of script param [%s] cannot be greater than the max value", p.Model)
+		}
+	case ScriptParamTypeEnum:
+		if len(p.GetOptions()) == 0 {
+			return errorx.NewBiz("the options of script param [%s] cannot be empty", p.Model)
+		}
+	case ScriptParamTypeBoolean:
+	default:
+		return errorx.NewBiz("unsupported type of script param [%s]: %s", p.Model, p.Type)
+	}
+
+	if !isBlankParamValue(p.Default) {
+		if _, err := p.Resolve(p.Default); err != nil {
+			return errorx.NewBiz("invalid default value of script param [%s]: %s", p.Model, err.Error())
+		}
+	}
+	return nil
+}
+
+// IsRequired 是否必填，与前端动态表单一致，未指定时为必填
+func (p *ScriptParam) IsRequired() bool {
+	return p.Required == nil || *p.Required
+}
+
+// GetOptions 获取可选值列表
+func (p *ScriptParam) GetOptions() []string {
+	var options []string
+	for _, option := range strings.Split(p.Options, ",") {
+		if option = strings.TrimSpace(option); option != "" {
+			options = append(options, option)
+		}
+	}
+	return options
+}
+
+// Resolve 校验参数值，并返回可安全替换至shell脚本中的值。
+// 字符串类值会进行shell转义，数字及布尔值校验后原样替换，未填写则使用默认值
+func (p *ScriptParam) Resolve(value any) (string, error) {
+	val, quote, err := p.resolve(value)
+	if err != nil || !quote {
+		return val, err
+	}
+	return shellx.Quote(val), nil
+}
+
+// resolve 校验参数值，返回未转义的参数值及其是否需要shell转义(字符串类值)
+func (p *ScriptParam) resolve(value any) (string, bool, error) {
+	if isBlankParamValue(value) {
+		value = p.Default
+	}
+	if isBlankParamValue(value) {
+		if p.IsRequired() {
+			return "", false, errorx.NewBiz("script param [%s] is required", p.Model)
+		}
+		return "", p.Type != ScriptParamTypeNumber && p.Type != ScriptParamTypeBoolean, nil
+	}
+
+	switch p.Type {
+	case ScriptParamTypeNumber:
+		num, err := toParamNumber(value)
+		if err != nil {
+			return "", false, errorx.NewBiz("script param [%s] must be a number", p.Model)
+		}
+		if p.Min != nil && num < *p.Min {
+			return "", false, errorx.NewBiz("script param [%s] cannot be less than %v", p.Model, *p.Min)
+		}
+		if p.Max != nil && num > *p.Max {
+			return "", false, errorx.NewBiz("script param [%s] cannot be greater than %v", p.Model, *p.Max)
+		}
+		return strconv.FormatFloat(num, 'f', -1, 64), false, nil
+	case ScriptParamTypeBoolean:
+		b, ok := value.(bool)
+		if !ok {
+			var err error
+			if b, err = strconv.ParseBool(fmt.Sprint(value)); err != nil {
+				return "", false, errorx.NewBiz("script param [%s] must be a boolean", p.Model)
+			}
+		}
+		return strconv.FormatBool(b), false, nil
+	}
+
+	str := fmt.Sprint(value)
+	if p.Type == ScriptParamTypeEnum {
+		found := false
+		for _, option := range p.GetOptions() {
+			if option == str {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", false, errorx.NewBiz("script param [%s] must be one of: %s", p.Model, strings.Join(p.GetOptions(), ", "))
+		}
+	}
+	if p.pattern != nil && !p.pattern.MatchString(str) {
+		return "", false, errorx.NewBiz("script param [%s] does not match the pattern: %s", p.Model, p.Pattern)
+	}
+	return str, true, nil
+}
+
+// RenderScript 使用参数值替换脚本中的模板占位符，返回实际执行的脚本及secret类参数脱敏后的脚本(用于记录)。
+// 未定义参数的脚本原样返回，以免误解析脚本中的其他模板内容(如docker --format)。
+// 位于引号内的占位符(如 echo "Hello {{.name}}")按所在引号的规则转义，其余占位符替换为单引号转义后的值
+func RenderScript(script string, params []*ScriptParam, values map[string]any) (string, string, error) {
+	if len(params) == 0 {
+		return script, script, nil
+	}
+
+	vars := make(map[string]string, len(params))
+	rawVars := make(map[string]string, len(params))
+	maskedVars := make(map[string]string, len(params))
+	maskedRawVars := make(map[string]string, len(params))
+	for _, p := range params {
+		raw, quote, err := p.resolve(values[p.Model])
+		if err != nil {
+			return "", "", err
+		}
+		val := raw
+		if quote {
+			val = shellx.Quote(raw)
+		}
+		vars[p.Model], rawVars[p.Model] = val, raw
+		maskedVars[p.Model], maskedRawVars[p.Model] = val, raw
+		if p.Type == ScriptParamTypeSecret {
+			maskedVars[p.Model], maskedRawVars[p.Model] = shellx.Quote(ScriptParamSecretMask), ScriptParamSecretMask
+		}
+	}
+
+	script = quoteScriptPlaceholders(script)
+	cmd, err := execScriptTemplate(script, vars, rawVars)
+	if err != nil {
+		return "", "", err
+	}
+	maskedCmd, err := execScriptTemplate(script, maskedVars, maskedRawVars)
+	if err != nil {
+		return "", "", err
+	}
+	return cmd, maskedCmd, nil
+}
+
+const (
+	scriptFuncSingleQuoted = "scriptSingleQuoted"
+	scriptFuncDoubleQuoted = "scriptDoubleQuoted"
+)
+
+var scriptPlaceholderRegexp = regexp.MustCompile(`^\{\{\s*\.([a-zA-Z_][a-zA-Z0-9_]*)\s*\}\}$`)
+
+// execScriptTemplate 渲染脚本模板，rawVars为未转义的参数值，供引号内的占位符按引号规则转义
+func execScriptTemplate(script string, vars, rawVars map[string]string) (string, error) {
+	rawVar := func(model string) (string, error) {
+		val, ok := rawVars[model]
+		if !ok {
+			return "", fmt.Errorf("map has no entry for key %q", model)
+		}
+		return val, nil
+	}
+
+	tmpl, err := template.New("script").Option("missingkey=error").Funcs(template.FuncMap{
+		scriptFuncSingleQuoted: func(model string) (string, error) {
+			val, err := rawVar(model)
+			return strings.ReplaceAll(val, "'", `'\''`), err
+		},
+		scriptFuncDoubleQuoted: func(model string) (string, error) {
+			val, err := rawVar(model)
+			return shellDoubleQuoteReplacer.Replace(val), err
+		},
+	}).Parse(script)
+	if err != nil {
+		return "", errorx.NewBiz("failed to parse the script template: %s", err.Error())
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", errorx.NewBiz("failed to render the script: %s", err.Error())
+	}
+	return buf.String(), nil
+}
+
+// 双引号内具有特殊含义的字符
+var shellDoubleQuoteReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+
+// quoteScriptPlaceholders 将位于单引号、双引号内的参数占位符替换为对应引号的转义函数调用，
+// 如 "Hello {{.name}}" 替换为 "Hello {{scriptDoubleQuoted "name"}}"，其余内容保持不变
+func quoteScriptPlaceholders(script string) string {
+	var (
+		sb    strings.Builder
+		quote byte // 当前所在的引号，0表示不在引号内
+		n     = len(script)
+	)
+	for i := 0; i < n; i++ {
+		c := script[i]
+
+		if c == '{' && i+1 < n && script[i+1] == '{' {
+			end := strings.Index(script[i:], "}}")
+			if end < 0 {
+				sb.WriteString(script[i:])
+				break
+			}
+			action := script[i : i+end+2]
+			i += end + 1
+			if m := scriptPlaceholderRegexp.FindStringSubmatch(action); m != nil && quote != 0 {
+				fn := scriptFuncDoubleQuoted
+				if quote == '\'' {
+					fn = scriptFuncSingleQuoted
+				}
+				action = fmt.Sprintf("{{%s %q}}", fn, m[1])
+			}
+			sb.WriteString(action)
+			continue
+		}
+
+		sb.WriteByte(c)
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			}
+		case c == '\\':
+			// 单引号外的反斜杠转义下一个字符
+			if i+1 < n && script[i+1] != '{' {
+				i++
+				sb.WriteByte(script[i])
+			}
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '#' && (i == 0 || strings.IndexByte(" \t\r\n;&|()", script[i-1]) >= 0):
+			// 注释内的引号无需处理
+			end := strings.IndexByte(script[i+1:], '\n')
+			if end < 0 {
+				end = n - i - 1
+			}
+			sb.WriteString(script[i+1 : i+1+end])
+			i += end
+		}
+	}
+	return sb.String()
+}
+
+func isBlankParamValue(value any) bool {
+	if value == nil {
+		return true
+	}
+	str, ok := value.(string)
+	return ok && str == ""
+}
+
+func toParamNumber(value any) (float64, error) {
+	var num float64
+	switch v := value.(type) {
+	case float64:
+		num = v
+	case int:
+		num = float64(v)
+	case int64:
+		num = float64(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return 0, err
+		}
+		num = f
+	default:
+		f, err := strconv.ParseFloat(strings.TrimSpace(fmt.Sprint(v)), 64)
+		if err != nil {
+			return 0, err
+		}
+		num = f
+	}
+	if math.IsNaN(num) || math.IsInf(num, 0) {
+		return 0, fmt.Errorf("invalid number: %v", value)
+	}
+	return num, nil
+}
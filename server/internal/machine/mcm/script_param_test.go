@@ -0,0 +1,156 @@
+package mcm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScriptParams(t *testing.T) {
+	params, err := ParseScriptParams(`[
+		{"model":"name","name":"name"},
+		{"model":"level","name":"level","options":"debug, info,warn"},
+		{"model":"count","name":"count","type":"number","min":1,"max":10,"default":3},
+		{"model":"pwd","name":"pwd","type":"secret","required":false}
+	]`)
+	assert.NoError(t, err)
+	assert.Len(t, params, 4)
+	assert.Equal(t, ScriptParamTypeString, params[0].Type)
+	assert.True(t, params[0].IsRequired())
+	// 兼容旧参数定义，有可选值则为枚举类型
+	assert.Equal(t, ScriptParamTypeEnum, params[1].Type)
+	assert.Equal(t, []string{"debug", "info", "warn"}, params[1].GetOptions())
+	assert.False(t, params[3].IsRequired())
+
+	// 兼容旧版本被多余转义的参数定义及不符合占位符规则的参数名
+	params, err = ParseScriptParams(`[{\"name\": \"pname\",\"model\": \"process-name\"}]`)
+	assert.NoError(t, err)
+	assert.Equal(t, "process-name", params[0].Model)
+	assert.Error(t, CheckScriptParamModels(params))
+
+	params, err = ParseScriptParams(" ")
+	assert.NoError(t, err)
+	assert.Nil(t, params)
+
+	for _, invalid := range []string{
+		`{"model":"a"}`,
+		`[{"model":""}]`,
+		`[{"model":"a"},{"model":"a"}]`,
+		`[{"model":"a","type":"date"}]`,
+		`[{"model":"a","type":"enum"}]`,
+		`[{"model":"a","pattern":"("}]`,
+		`[{"model":"a","type":"number","min":5,"max":1}]`,
+		`[{"model":"a","type":"number","max":1,"default":2}]`,
+	} {
+		_, err := ParseScriptParams(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestScriptParamResolve(t *testing.T) {
+	params, err := ParseScriptParams(`[
+		{"model":"str","pattern":"[a-z ;']+"},
+		{"model":"num","type":"number","min":0,"max":100},
+		{"model":"enabled","type":"boolean"},
+		{"model":"level","type":"enum","options":"debug,info"},
+		{"model":"opt","required":false},
+		{"model":"optNum","type":"number","required":false}
+	]`)
+	assert.NoError(t, err)
+	str, num, enabled, level, opt, optNum := params[0], params[1], params[2], params[3], params[4], params[5]
+
+	val, err := str.Resolve("a; rm 'x'")
+	assert.NoError(t, err)
+	assert.Equal(t, `'a; rm '\''x'\'''`, val)
+	_, err = str.Resolve("ABC")
+	assert.Error(t, err)
+	_, err = str.Resolve("")
+	assert.Error(t, err)
+
+	val, err = num.Resolve(float64(12.5))
+	assert.NoError(t, err)
+	assert.Equal(t, "12.5", val)
+	val, err = num.Resolve("42")
+	assert.NoError(t, err)
+	assert.Equal(t, "42", val)
+	_, err = num.Resolve("1; reboot")
+	assert.Error(t, err)
+	_, err = num.Resolve(101)
+	assert.Error(t, err)
+
+	val, err = enabled.Resolve("true")
+	assert.NoError(t, err)
+	assert.Equal(t, "true", val)
+	val, err = enabled.Resolve(false)
+	assert.NoError(t, err)
+	assert.Equal(t, "false", val)
+	_, err = enabled.Resolve("yes")
+	assert.Error(t, err)
+
+	val, err = level.Resolve("info")
+	assert.NoError(t, err)
+	assert.Equal(t, "info", val)
+	_, err = level.Resolve("error")
+	assert.Error(t, err)
+
+	val, err = opt.Resolve(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "''", val)
+	val, err = optNum.Resolve("")
+	assert.NoError(t, err)
+	assert.Equal(t, "", val)
+}
+
+func TestRenderScript(t *testing.T) {
+	params, err := ParseScriptParams(`[
+		{"model":"file","name":"file"},
+		{"model":"lines","type":"number","default":10},
+		{"model":"token","type":"secret"}
+	]`)
+	assert.NoError(t, err)
+
+	cmd, masked, err := RenderScript("tail -n {{.lines}} {{.file}} && curl -H {{.token}}", params, map[string]any{
+		"file":  "/var/log/$(id).log",
+		"token": "s3cr3t'",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `tail -n 10 '/var/log/$(id).log' && curl -H 's3cr3t'\'''`, cmd)
+	assert.Equal(t, `tail -n 10 '/var/log/$(id).log' && curl -H '******'`, masked)
+
+	// 引用未定义的参数
+	_, _, err = RenderScript("echo {{.other}}", params, map[string]any{"file": "a", "token": "b"})
+	assert.Error(t, err)
+
+	_, _, err = RenderScript("echo {{.file}}", params, map[string]any{"file": "a"})
+	assert.Error(t, err)
+
+	// 未定义参数的脚本原样返回
+	cmd, masked, err = RenderScript("docker ps --format '{{.Names}}'", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "docker ps --format '{{.Names}}'", cmd)
+	assert.Equal(t, cmd, masked)
+}
+
+func TestRenderScriptQuoted(t *testing.T) {
+	params, err := ParseScriptParams(`[
+		{"model":"name"},
+		{"model":"count","type":"number"},
+		{"model":"token","type":"secret"}
+	]`)
+	assert.NoError(t, err)
+	values := map[string]any{"name": `b"o'b $(id)`, "count": 3, "token": "s3cr3t"}
+
+	// 引号内的占位符按所在引号的规则转义
+	cmd, masked, err := RenderScript(`echo "Hello {{.name}}, {{ .count }}" 'Hi {{.name}}' {{.name}} "{{.token}}"`, params, values)
+	assert.NoError(t, err)
+	assert.Equal(t, `echo "Hello b\"o'b \$(id), 3" 'Hi b"o'\''b $(id)' 'b"o'\''b $(id)' "s3cr3t"`, cmd)
+	assert.Equal(t, `echo "Hello b\"o'b \$(id), 3" 'Hi b"o'\''b $(id)' 'b"o'\''b $(id)' "******"`, masked)
+
+	// 转义的引号、注释中的引号不影响后续占位符
+	cmd, _, err = RenderScript("echo \\\" {{.name}} # it's\necho {{.count}} \"{{.count}}\"", params, values)
+	assert.NoError(t, err)
+	assert.Equal(t, "echo \\\" 'b\"o'\\''b $(id)' # it's\necho 3 \"3\"", cmd)
+
+	_, _, err = RenderScript(`echo "{{.other}}"`, params, values)
+	assert.Error(t, err)
+}
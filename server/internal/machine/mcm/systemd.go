@@ -0,0 +1,149 @@
+package mcm
+
+import (
+	"fmt"
+	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/shellx"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	SystemdActionStart   = "start"
+	SystemdActionStop    = "stop"
+	SystemdActionRestart = "restart"
+	SystemdActionEnable  = "enable"
+	SystemdActionDisable = "disable"
+
+	systemdUnitsSeparator = "----mayfly-unit-files----"
+)
+
+var systemdUnitNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9:_.@\\-]+$`)
+
+// SystemdUnit systemd服务单元
+type SystemdUnit struct {
+	Unit          string `json:"unit"`
+	Load          string `json:"load"`          // 加载状态，如loaded、not-found
+	Active        string `json:"active"`        // 运行状态，如active、inactive、failed
+	Sub           string `json:"sub"`           // 详细运行状态，如running、exited、dead
+	UnitFileState string `json:"unitFileState"` // 开机启动状态，如enabled、disabled、static
+	Description   string `json:"description"`
+}
+
+// CheckSystemdUnitName 校验服务单元名称，避免注入其他命令参数
+func CheckSystemdUnitName(unit string) error {
+	if unit == "" || strings.HasPrefix(unit, "-") || !systemdUnitNameRegexp.MatchString(unit) {
+		return errorx.NewBiz("invalid systemd unit: %s", unit)
+	}
+	return nil
+}
+
+// GetSystemdUnits 获取所有service类型的服务单元，包含未加载但已安装的服务
+func (c *Cli) GetSystemdUnits() ([]*SystemdUnit, error) {
+	res, err := c.Run(fmt.Sprintf("systemctl list-units --type=service --all --no-legend --no-pager --plain 2>/dev/null; echo '%s'; systemctl list-unit-files --type=service --no-legend --no-pager 2>/dev/null", systemdUnitsSeparator))
+	if err != nil && !strings.Contains(res, systemdUnitsSeparator) {
+		return nil, errorx.NewBiz("failed to list systemd units: %s", strings.TrimSpace(res))
+	}
+	units, unitFiles, _ := strings.Cut(res, systemdUnitsSeparator)
+	return ParseSystemdUnits(units, unitFiles), nil
+}
+
+// GetSystemdUnitStatus 获取服务单元的systemctl status信息
+func (c *Cli) GetSystemdUnitStatus(unit string) (string, error) {
+	if err := CheckSystemdUnitName(unit); err != nil {
+		return "", err
+	}
+	// 服务未运行时systemctl status返回非0状态码，此时仍返回其输出
+	res, err := c.Run(c.sudo(fmt.Sprintf("systemctl status --no-pager --full --lines=0 -- %s", shellx.Quote(unit))))
+	if err != nil && strings.TrimSpace(res) == "" {
+		return "", err
+	}
+	return res, nil
+}
+
+// GetSystemdUnitJournal 获取服务单元最近的journalctl日志
+func (c *Cli) GetSystemdUnitJournal(unit string, lines int) (string, error) {
+	if err := CheckSystemdUnitName(unit); err != nil {
+		return "", err
+	}
+	res, err := c.Run(c.sudo(fmt.Sprintf("journalctl --no-pager --output=short-iso -n %d -u %s", lines, shellx.Quote(unit))))
+	if err != nil {
+		return "", errorx.NewBiz("failed to get the journal: %s", strings.TrimSpace(res))
+	}
+	return res, nil
+}
+
+// OpSystemdUnit 执行服务单元的启动、停止、重启、开机启动、禁止开机启动操作
+func (c *Cli) OpSystemdUnit(unit string, action string) (string, error) {
+	if err := CheckSystemdUnitName(unit); err != nil {
+		return "", err
+	}
+	switch action {
+	case SystemdActionStart, SystemdActionStop, SystemdActionRestart, SystemdActionEnable, SystemdActionDisable:
+	default:
+		return "", errorx.NewBiz("unsupported systemd action: %s", action)
+	}
+
+	res, err := c.Run(c.sudo(fmt.Sprintf("systemctl %s -- %s", action, shellx.Quote(unit))))
+	if err != nil {
+		if res = strings.TrimSpace(res); res == "" {
+			res = err.Error()
+		}
+		return res, errorx.NewBiz("systemctl %s %s failed: %s", action, unit, res)
+	}
+	return res, nil
+}
+
+// sudo 非root用户通过sudo执行，-n 需要密码时直接失败而不是等待输入
+func (c *Cli) sudo(cmd string) string {
+	if c.Info.Username == "root" {
+		return cmd
+	}
+	return "sudo -n " + cmd
+}
+
+// ParseSystemdUnits 解析systemctl list-units及list-unit-files的输出，结果按名称排序
+func ParseSystemdUnits(listUnits, listUnitFiles string) []*SystemdUnit {
+	unitMap := make(map[string]*SystemdUnit)
+	for _, line := range strings.Split(listUnits, "\n") {
+		// 部分版本即使指定--plain，失败的服务仍以●开头
+		fields := strings.Fields(strings.TrimLeft(strings.TrimSpace(line), "●* "))
+		if len(fields) < 4 {
+			continue
+		}
+		unitMap[fields[0]] = &SystemdUnit{
+			Unit:        fields[0],
+			Load:        fields[1],
+			Active:      fields[2],
+			Sub:         fields[3],
+			Description: strings.Join(fields[4:], " "),
+		}
+	}
+
+	for _, line := range strings.Split(listUnitFiles, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		unit := unitMap[fields[0]]
+		if unit == nil {
+			// 模板服务(如getty@.service)无法直接运行，不展示
+			if strings.HasSuffix(fields[0], "@.service") {
+				continue
+			}
+			unit = &SystemdUnit{Unit: fields[0], Active: "inactive", Sub: "dead"}
+			unitMap[fields[0]] = unit
+		}
+		unit.UnitFileState = fields[1]
+	}
+
+	units := make([]*SystemdUnit, 0, len(unitMap))
+	for _, unit := range unitMap {
+		units = append(units, unit)
+	}
+	sort.Slice(units, func(i, j int) bool {
+		return units[i].Unit < units[j].Unit
+	})
+	return units
+}
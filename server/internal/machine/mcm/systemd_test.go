@@ -0,0 +1,39 @@
+package mcm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSystemdUnits(t *testing.T) {
+	listUnits := `
+nginx.service                loaded    active   running OpenBSD nginx web server
+● mysql.service              loaded    failed   failed  MySQL Community Server
+ntp.service                  not-found inactive dead    ntp.service
+`
+	listUnitFiles := `
+nginx.service        enabled  enabled
+mysql.service        disabled enabled
+redis.service        disabled enabled
+getty@.service       enabled  enabled
+`
+	units := ParseSystemdUnits(listUnits, listUnitFiles)
+	require.Len(t, units, 4)
+
+	require.Equal(t, &SystemdUnit{Unit: "mysql.service", Load: "loaded", Active: "failed", Sub: "failed", UnitFileState: "disabled", Description: "MySQL Community Server"}, units[0])
+	require.Equal(t, &SystemdUnit{Unit: "nginx.service", Load: "loaded", Active: "active", Sub: "running", UnitFileState: "enabled", Description: "OpenBSD nginx web server"}, units[1])
+	require.Equal(t, "ntp.service", units[2].Unit)
+	require.Equal(t, "", units[2].UnitFileState)
+	// 未加载但已安装的服务
+	require.Equal(t, &SystemdUnit{Unit: "redis.service", Active: "inactive", Sub: "dead", UnitFileState: "disabled"}, units[3])
+}
+
+func TestCheckSystemdUnitName(t *testing.T) {
+	for _, unit := range []string{"nginx.service", "getty@tty1.service", `dev-disk-by\x2duuid.swap`, "sshd"} {
+		require.NoError(t, CheckSystemdUnitName(unit), unit)
+	}
+	for _, unit := range []string{"", "--now", "nginx;reboot", "a b", "$(id)"} {
+		require.Error(t, CheckSystemdUnitName(unit), unit)
+	}
+}
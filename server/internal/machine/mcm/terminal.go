@@ -73,3 +73,8 @@ func (t *Terminal) RequestPty(term string, h, w int) error {
 func (t *Terminal) Shell() error {
 	return t.SshSession.Shell()
 }
+
+// Start 在终端中执行指定命令，如docker exec进入容器
+func (t *Terminal) Start(cmd string) error {
+	return t.SshSession.Start(cmd)
+}
@@ -2,10 +2,14 @@ package mcm
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"mayfly-go/pkg/errorx"
+	"mayfly-go/pkg/utils/collx"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/veops/go-ansiterm"
 )
@@ -13,56 +17,159 @@ import (
 // 命令过滤函数，若返回error，则不执行该命令
 type CmdFilterFunc func(cmd string) error
 
+// 命令审批函数，命令无需审批则返回nil，否则返回等待审批结果的函数
+type CmdApproveFunc func(cmd string) CmdApproveWaitFunc
+
+// 等待命令审批结果的函数，在独立协程中执行，不阻塞终端会话。若返回error，则不执行该命令
+// @param ctx 审批等待上下文，会话结束或用户取消审批时取消
+// @param notify 向终端用户输出审批进度等提示信息
+type CmdApproveWaitFunc func(ctx context.Context, notify func(msg string)) error
+
 const (
 	CR  = 0x0d // 单个字节 13 通常表示发送一个 CR（Carriage Return，回车）字符 \r
 	EOT = 0x03 // 通过向标准输入发送单个字节 3 通常表示发送一个 EOT（End of Transmission）信号。EOT 是一种控制字符，在通信中用于指示数据传输的结束。发送 EOT 信号可以被用来终止当前的交互或数据传输
 )
 
 type ExecutedCmd struct {
-	Cmd  string `json:"cmd"`  // 执行的命令
-	Time int64  `json:"time"` // 执行时间戳
+	Cmd    string  `json:"cmd"`    // 执行的命令
+	Time   int64   `json:"time"`   // 执行时间戳
+	Offset float64 `json:"offset"` // 命令在终端回放中的偏移时间(秒)，未开启回放记录则为0
 }
 
 // TerminalHandler 终端处理器
 type TerminalHandler struct {
 	Filters      []CmdFilterFunc
+	Approvers    []CmdApproveFunc
 	ExecutedCmds []*ExecutedCmd // 已执行的命令
 
 	Parser *Parser
+
+	RecStartTime time.Time // 终端回放记录开始时间，用于计算命令在回放中的偏移时间
+
+	cmdsLock sync.RWMutex
 }
 
-// PreWriteHandle 写入数据至终端前的处理，可进行过滤等操作
-func (tf *TerminalHandler) PreWriteHandle(p []byte) error {
+// GetExecutedCmds 获取已执行命令的副本，可在会话进行中并发调用
+func (tf *TerminalHandler) GetExecutedCmds() []*ExecutedCmd {
+	tf.cmdsLock.RLock()
+	defer tf.cmdsLock.RUnlock()
+	cmds := make([]*ExecutedCmd, len(tf.ExecutedCmds))
+	copy(cmds, tf.ExecutedCmds)
+	return cmds
+}
+
+// PreWriteHandle 写入数据至终端前的处理，可进行过滤等操作。
+// 数据中可能包含多行命令(如粘贴内容)，需在写入终端前校验其中所有以回车结束的命令，任一命令被拒绝则整段数据都不写入。
+// 若命令需要审批，则返回等待审批结果的函数，调用方需在审批通过后再将数据写入终端
+func (tf *TerminalHandler) PreWriteHandle(p []byte) (CmdApproveWaitFunc, error) {
 	tf.Parser.AppendInputData(p)
 
-	// 不是回车命令，则表示命令未结束
-	if bytes.LastIndex(p, []byte{CR}) != 0 {
-		return nil
+	// 不包含回车，则表示命令未结束
+	lines := bytes.Split(p, []byte{CR})
+	if len(lines) == 1 {
+		return nil, nil
 	}
 
-	// time.Sleep(time.Millisecond * 30)
-	command := tf.Parser.GetCmd()
-	// 重置终端输入输出
+	// 第一行命令的前半部分已写入终端并回显，需从终端输出中获取，其余行均未写入终端，直接从输入数据中解析
+	commands := []string{tf.Parser.GetCmd() + parseInputText(lines[0])}
+	for _, line := range lines[1 : len(lines)-1] {
+		commands = append(commands, parseInputText(line))
+	}
+	ps1 := tf.Parser.Ps1
+	// 重置终端输入输出，并记录最后未以回车结束的输入，用于后续从终端回显中解析提示符
 	tf.Parser.Reset()
+	tf.Parser.pendingInput = parseInputText(lines[len(lines)-1])
 
-	if command == "" {
-		return nil
+	commands = collx.ArrayFilter(commands, func(command string) bool {
+		return strings.TrimSpace(command) != ""
+	})
+	if len(commands) == 0 {
+		return nil, nil
 	}
 
 	// 执行命令过滤器
-	for _, filter := range tf.Filters {
-		if err := filter(command); err != nil {
-			msg := fmt.Sprintf("\r\n%s%s", tf.Parser.Ps1, GetErrorContent(err.Error()))
-			return errorx.NewBiz(msg)
+	for _, command := range commands {
+		for _, filter := range tf.Filters {
+			if err := filter(command); err != nil {
+				msg := fmt.Sprintf("\r\n%s%s", ps1, GetErrorContent(err.Error()))
+				return nil, errorx.NewBiz("%s", msg)
+			}
 		}
 	}
 
-	// 记录执行命令
-	tf.ExecutedCmds = append(tf.ExecutedCmds, &ExecutedCmd{
-		Cmd:  command,
-		Time: time.Now().Unix(),
-	})
-	return nil
+	var approveWaits []CmdApproveWaitFunc
+	for _, command := range commands {
+		for _, approve := range tf.Approvers {
+			if wait := approve(command); wait != nil {
+				approveWaits = append(approveWaits, wait)
+			}
+		}
+	}
+	if len(approveWaits) == 0 {
+		tf.recordCmd(commands...)
+		return nil, nil
+	}
+
+	// 依次等待所有审批通过后才记录命令
+	return func(ctx context.Context, notify func(msg string)) error {
+		for _, wait := range approveWaits {
+			if err := wait(ctx, notify); err != nil {
+				if ctx.Err() != nil {
+					err = errorx.NewBiz("the command approval has been cancelled")
+				}
+				msg := fmt.Sprintf("\r\n%s%s", ps1, GetErrorContent(err.Error()))
+				return errorx.NewBiz("%s", msg)
+			}
+		}
+		tf.recordCmd(commands...)
+		return nil
+	}, nil
+}
+
+// recordCmd 记录执行的命令
+func (tf *TerminalHandler) recordCmd(commands ...string) {
+	now := time.Now()
+	var offset float64
+	if !tf.RecStartTime.IsZero() {
+		offset = float64(now.Sub(tf.RecStartTime).Milliseconds()) / 1000
+	}
+	tf.cmdsLock.Lock()
+	for _, command := range commands {
+		tf.ExecutedCmds = append(tf.ExecutedCmds, &ExecutedCmd{Cmd: command, Time: now.Unix(), Offset: offset})
+	}
+	tf.cmdsLock.Unlock()
+}
+
+// parseInputText 解析用户输入数据中的命令文本，忽略转义序列(如方向键、粘贴标记)及控制字符，并处理退格
+func parseInputText(data []byte) string {
+	text := make([]rune, 0, len(data))
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		i += size
+
+		switch {
+		case r == 0x1b:
+			// CSI序列: ESC [ 参数 终止字符(0x40-0x7e)；其他转义序列: ESC + 单个字符
+			if i < len(data) && data[i] == '[' {
+				i++
+				for i < len(data) && (data[i] < 0x40 || data[i] > 0x7e) {
+					i++
+				}
+			}
+			i++
+		case r == 0x7f || r == 0x08:
+			if len(text) > 0 {
+				text = text[:len(text)-1]
+			}
+		case r == '\t':
+			text = append(text, ' ')
+		case r < 0x20:
+			// 忽略其他控制字符
+		default:
+			text = append(text, r)
+		}
+	}
+	return string(text)
 }
 
 // HandleRead 处理从终端读取的数据进行操作
@@ -77,6 +184,8 @@ type Parser struct {
 	OutputData []byte
 	Ps1        string
 
+	pendingInput string // 重置前已写入终端但未以回车结束的输入，其回显不属于提示符
+
 	vimState     bool
 	commandState bool
 }
@@ -123,6 +232,10 @@ func (p *Parser) AppendInputData(data []byte) {
 	if len(p.InputData) == 0 {
 		// 如 "root@cloud-s0ervh-hh87:~# " 获取前一段用户名等提示内容
 		p.Ps1 = p.GetOutput()
+		if p.pendingInput != "" {
+			p.Ps1 = strings.TrimSuffix(strings.TrimRight(p.Ps1, " "), strings.TrimRight(p.pendingInput, " "))
+			p.pendingInput = ""
+		}
 	}
 	p.InputData = append(p.InputData, data...)
 }
@@ -146,6 +259,7 @@ func (p *Parser) Reset() {
 	p.Output.Listener.Reset()
 	p.OutputData = nil
 	p.InputData = nil
+	p.pendingInput = ""
 }
 
 func (p *Parser) GetOutput() string {
@@ -213,3 +327,8 @@ func GetErrorContent(msg string) string {
 func GetErrorContentRn(msg string) string {
 	return fmt.Sprintf("\r\n%s", GetErrorContent(msg))
 }
+
+// GetWarnContentRn 包装返回终端提示消息, 并自动回车换行
+func GetWarnContentRn(msg string) string {
+	return fmt.Sprintf("\r\n\033[1;33m%s\033[0m", msg)
+}
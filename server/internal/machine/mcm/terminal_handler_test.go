@@ -0,0 +1,84 @@
+package mcm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTerminalHandler() *TerminalHandler {
+	return &TerminalHandler{
+		Parser: NewParser(120, 40),
+		Filters: []CmdFilterFunc{func(cmd string) error {
+			if strings.HasPrefix(cmd, "rm ") {
+				return errors.New("command denied")
+			}
+			return nil
+		}},
+		Approvers: []CmdApproveFunc{func(cmd string) CmdApproveWaitFunc {
+			if !strings.HasPrefix(cmd, "reboot") {
+				return nil
+			}
+			return func(ctx context.Context, notify func(msg string)) error { return nil }
+		}},
+	}
+}
+
+func TestTerminalHandlerPreWriteHandle(t *testing.T) {
+	th := newTestTerminalHandler()
+	// 终端输出的提示符前通常为换行
+	th.HandleRead([]byte("\r\nroot@host:~# "))
+
+	// 逐字符输入，回车时从终端回显中解析命令
+	for _, c := range "ls" {
+		wait, err := th.PreWriteHandle([]byte(string(c)))
+		require.NoError(t, err)
+		require.Nil(t, wait)
+		th.HandleRead([]byte(string(c)))
+	}
+	wait, err := th.PreWriteHandle([]byte{CR})
+	require.NoError(t, err)
+	require.Nil(t, wait)
+	require.Equal(t, "ls", th.GetExecutedCmds()[0].Cmd)
+
+	// 粘贴的多行命令需在写入终端前全部校验
+	th.HandleRead([]byte("\r\nroot@host:~# "))
+	_, err = th.PreWriteHandle([]byte("cd /tmp\rrm -rf /\r"))
+	require.Error(t, err)
+	require.Len(t, th.GetExecutedCmds(), 1)
+
+	_, err = th.PreWriteHandle([]byte("\x1b[200~rx\x7fm -rf /\x1b[201~\r"))
+	require.Error(t, err)
+
+	wait, err = th.PreWriteHandle([]byte("cd /tmp\rreboot\r"))
+	require.NoError(t, err)
+	require.NotNil(t, wait)
+	require.Len(t, th.GetExecutedCmds(), 1)
+	require.NoError(t, wait(context.Background(), func(msg string) {}))
+	require.Equal(t, []string{"ls", "cd /tmp", "reboot"}, []string{th.GetExecutedCmds()[0].Cmd, th.GetExecutedCmds()[1].Cmd, th.GetExecutedCmds()[2].Cmd})
+}
+
+func TestTerminalHandlerPendingInput(t *testing.T) {
+	th := newTestTerminalHandler()
+	// 终端输出的提示符前通常为换行
+	th.HandleRead([]byte("\r\nroot@host:~# "))
+
+	// 回车后未结束的输入回显不属于提示符
+	_, err := th.PreWriteHandle([]byte("cd /tmp\rpw"))
+	require.NoError(t, err)
+	th.HandleRead([]byte("cd /tmp\r\nroot@host:/tmp# pw"))
+
+	_, err = th.PreWriteHandle([]byte("d"))
+	require.NoError(t, err)
+	th.HandleRead([]byte("d"))
+	_, err = th.PreWriteHandle([]byte{CR})
+	require.NoError(t, err)
+
+	cmds := th.GetExecutedCmds()
+	require.Len(t, cmds, 2)
+	require.Equal(t, "cd /tmp", cmds[0].Cmd)
+	require.Equal(t, "pwd", cmds[1].Cmd)
+}
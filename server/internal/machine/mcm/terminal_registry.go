@@ -0,0 +1,135 @@
+package mcm
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// 当前实例中活跃的终端会话，sessionId -> *TerminalSession
+var terminalSessions sync.Map
+
+// TerminalSessionInfo 活跃终端会话信息
+type TerminalSessionInfo struct {
+	SessionId   string         `json:"sessionId"`
+	CreatorId   uint64         `json:"creatorId"`
+	Creator     string         `json:"creator"`
+	MachineId   uint64         `json:"machineId"`
+	MachineCode string         `json:"machineCode"`
+	MachineName string         `json:"machineName"`
+	Ip          string         `json:"ip"`
+	Username    string         `json:"username"` // 登录机器的用户名
+	StartTime   time.Time      `json:"startTime"`
+	ExecCmds    []*ExecutedCmd `json:"execCmds"`    // 目前已执行的命令
+	ShadowCount int            `json:"shadowCount"` // 旁观者数量
+	CodePath    []string       `json:"-"`           // 机器标签路径，用于权限校验
+}
+
+func registerTerminalSession(ts *TerminalSession) {
+	terminalSessions.Store(ts.ID, ts)
+}
+
+func unregisterTerminalSession(sessionId string) {
+	terminalSessions.Delete(sessionId)
+}
+
+// GetTerminalSessions 获取当前实例中所有活跃的终端会话
+func GetTerminalSessions() []*TerminalSession {
+	sessions := make([]*TerminalSession, 0)
+	terminalSessions.Range(func(key, value any) bool {
+		sessions = append(sessions, value.(*TerminalSession))
+		return true
+	})
+	return sessions
+}
+
+// GetTerminalSession 根据会话id获取活跃的终端会话
+func GetTerminalSession(sessionId string) *TerminalSession {
+	if ts, ok := terminalSessions.Load(sessionId); ok {
+		return ts.(*TerminalSession)
+	}
+	return nil
+}
+
+// GetInfo 获取终端会话信息
+func (ts *TerminalSession) GetInfo() *TerminalSessionInfo {
+	info := &TerminalSessionInfo{
+		SessionId: ts.ID,
+		CreatorId: ts.creatorId,
+		Creator:   ts.creator,
+		StartTime: ts.startTime,
+		ExecCmds:  ts.GetExecCmds(),
+	}
+	if mi := ts.cli.Info; mi != nil {
+		info.MachineId = mi.Id
+		info.MachineCode = mi.Code
+		info.MachineName = mi.Name
+		info.Ip = mi.Ip
+		info.Username = mi.Username
+		info.CodePath = mi.CodePath
+	}
+	ts.shadows.Range(func(key, value any) bool {
+		info.ShadowCount++
+		return true
+	})
+	return info
+}
+
+// Kill 强制断开终端会话，并向会话用户展示断开原因
+func (ts *TerminalSession) Kill(msg string) {
+	ts.WriteToWs(GetErrorContentRn(msg))
+	// 关闭websocket连接，使读取客户端消息失败从而结束会话
+	ts.wsLock.Lock()
+	ts.wsConn.Close()
+	ts.wsLock.Unlock()
+	// 取消会话上下文，使等待审批等阻塞操作及时结束
+	ts.cancel()
+}
+
+type shadowConn struct {
+	msgChan chan string
+}
+
+// Shadow 以只读方式旁观终端会话的输出，阻塞至旁观者断开连接或会话结束
+func (ts *TerminalSession) Shadow(wsConn *websocket.Conn) {
+	shadow := &shadowConn{msgChan: make(chan string, 256)}
+	ts.shadows.Store(shadow, struct{}{})
+	defer ts.shadows.Delete(shadow)
+
+	// 旁观者为只读，仅读取消息用于感知连接断开
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := wsConn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ts.ctx.Done():
+			wsConn.WriteMessage(websocket.TextMessage, []byte(GetErrorContentRn("the terminal session has ended...")))
+			return
+		case <-closed:
+			return
+		case msg := <-shadow.msgChan:
+			if err := wsConn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// broadcastToShadows 将终端输出发送给所有旁观者，旁观者消费过慢时丢弃消息，避免阻塞会话
+func (ts *TerminalSession) broadcastToShadows(msg string) {
+	ts.shadows.Range(func(key, value any) bool {
+		select {
+		case key.(*shadowConn).msgChan <- msg:
+		default:
+		}
+		return true
+	})
+}
@@ -1,6 +1,7 @@
 package mcm
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -10,6 +11,7 @@ import (
 	"github.com/may-fly/cast"
 
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 
@@ -34,17 +36,32 @@ type TerminalSession struct {
 	cancel   context.CancelFunc
 	dataChan chan rune
 	tick     *time.Ticker
+
+	cli       *Cli
+	creatorId uint64
+	creator   string
+	startTime time.Time
+	wsLock    *sync.Mutex // websocket不支持并发写
+	shadows   *sync.Map   // *shadowConn -> struct{}，只读旁观者
+
+	approveLock   sync.Mutex
+	approveCancel context.CancelFunc // 不为nil表示有命令正在等待审批
+	approveHinted bool               // 审批期间是否已提示过输入被拒绝
 }
 
 type CreateTerminalSessionParam struct {
-	SessionId      string
-	Cli            *Cli
-	WsConn         *websocket.Conn
-	Rows           int
-	Cols           int
-	Recorder       *Recorder
-	LogCmd         bool            // 是否记录命令
-	CmdFilterFuncs []CmdFilterFunc // 命令过滤器
+	SessionId       string
+	Cli             *Cli
+	WsConn          *websocket.Conn
+	Rows            int
+	Cols            int
+	Recorder        *Recorder
+	LogCmd          bool             // 是否记录命令
+	CmdFilterFuncs  []CmdFilterFunc  // 命令过滤器
+	CmdApproveFuncs []CmdApproveFunc // 命令审批器
+	CreatorId       uint64           // 会话创建者账号id
+	Creator         string           // 会话创建者用户名
+	Cmd             string           // 终端启动后执行的命令，为空则启动登录shell
 }
 
 func NewTerminalSession(param *CreateTerminalSessionParam) (*TerminalSession, error) {
@@ -59,7 +76,11 @@ func NewTerminalSession(param *CreateTerminalSessionParam) (*TerminalSession, er
 	if err != nil {
 		return nil, err
 	}
-	err = terminal.Shell()
+	if param.Cmd != "" {
+		err = terminal.Start(param.Cmd)
+	} else {
+		err = terminal.Shell()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -69,9 +90,12 @@ func NewTerminalSession(param *CreateTerminalSessionParam) (*TerminalSession, er
 	}
 
 	var handler *TerminalHandler
-	// 记录命令或者存在命令过滤器时，则创建对应的终端处理器
-	if param.LogCmd || param.CmdFilterFuncs != nil {
-		handler = &TerminalHandler{Parser: NewParser(120, 40), Filters: param.CmdFilterFuncs}
+	// 记录命令或者存在命令过滤器、审批器时，则创建对应的终端处理器
+	if param.LogCmd || param.CmdFilterFuncs != nil || param.CmdApproveFuncs != nil {
+		handler = &TerminalHandler{Parser: NewParser(120, 40), Filters: param.CmdFilterFuncs, Approvers: param.CmdApproveFuncs}
+		if recorder != nil {
+			handler.RecStartTime = recorder.StartTime
+		}
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -86,6 +110,13 @@ func NewTerminalSession(param *CreateTerminalSessionParam) (*TerminalSession, er
 		cancel:   cancel,
 		dataChan: make(chan rune),
 		tick:     tick,
+
+		cli:       cli,
+		creatorId: param.CreatorId,
+		creator:   param.Creator,
+		startTime: time.Now(),
+		wsLock:    &sync.Mutex{},
+		shadows:   &sync.Map{},
 	}
 
 	// 清除终端内容
@@ -93,14 +124,16 @@ func NewTerminalSession(param *CreateTerminalSessionParam) (*TerminalSession, er
 	return ts, nil
 }
 
-func (r TerminalSession) Start() {
+func (r *TerminalSession) Start() {
+	registerTerminalSession(r)
 	go r.readFromTerminal()
 	go r.writeToWebsocket()
 	r.receiveWsMsg()
 }
 
-func (r TerminalSession) Stop() {
+func (r *TerminalSession) Stop() {
 	logx.Debug("close machine ssh terminal session")
+	unregisterTerminalSession(r.ID)
 	r.tick.Stop()
 	r.cancel()
 	if r.terminal != nil {
@@ -113,14 +146,14 @@ func (r TerminalSession) Stop() {
 }
 
 // 获取终端会话执行的所有命令
-func (r TerminalSession) GetExecCmds() []*ExecutedCmd {
+func (r *TerminalSession) GetExecCmds() []*ExecutedCmd {
 	if r.handler != nil {
-		return r.handler.ExecutedCmds
+		return r.handler.GetExecutedCmds()
 	}
 	return []*ExecutedCmd{}
 }
 
-func (ts TerminalSession) readFromTerminal() {
+func (ts *TerminalSession) readFromTerminal() {
 	for {
 		select {
 		case <-ts.ctx.Done():
@@ -140,7 +173,7 @@ func (ts TerminalSession) readFromTerminal() {
 	}
 }
 
-func (ts TerminalSession) writeToWebsocket() {
+func (ts *TerminalSession) writeToWebsocket() {
 	var buf []byte
 	for {
 		select {
@@ -159,6 +192,7 @@ func (ts TerminalSession) writeToWebsocket() {
 				logx.Error("the machine ssh endpoint failed to send a message to the websocket: ", err)
 				return
 			}
+			ts.broadcastToShadows(s)
 
 			// 如果记录器存在，则记录操作回放信息
 			if ts.recorder != nil {
@@ -217,13 +251,23 @@ func (ts *TerminalSession) receiveWsMsg() {
 				}
 			case Data:
 				data := []byte(msgObj.Msg)
+				// 命令审批中拒绝输入，Ctrl+C则取消审批
+				if ts.rejectInputIfApproving(data) {
+					continue
+				}
+
 				if ts.handler != nil {
-					if err := ts.handler.PreWriteHandle(data); err != nil {
+					approveWait, err := ts.handler.PreWriteHandle(data)
+					if err != nil {
 						ts.WriteToWs(err.Error())
 						// 发送命令终止指令
 						ts.terminal.Write([]byte{EOT})
 						continue
 					}
+					if approveWait != nil {
+						ts.waitApprove(approveWait, data)
+						continue
+					}
 				}
 
 				_, err := ts.terminal.Write([]byte(msgObj.Msg))
@@ -242,8 +286,62 @@ func (ts *TerminalSession) receiveWsMsg() {
 	}
 }
 
+// waitApprove 异步等待命令审批结果，审批通过后再将命令写入终端，期间仍可处理窗口调整、心跳等消息
+func (ts *TerminalSession) waitApprove(wait CmdApproveWaitFunc, data []byte) {
+	ctx, cancel := context.WithCancel(ts.ctx)
+	ts.approveLock.Lock()
+	ts.approveCancel = cancel
+	ts.approveHinted = false
+	ts.approveLock.Unlock()
+
+	go func() {
+		defer func() {
+			cancel()
+			ts.approveLock.Lock()
+			ts.approveCancel = nil
+			ts.approveLock.Unlock()
+		}()
+
+		if err := wait(ctx, func(msg string) { ts.WriteToWs(msg) }); err != nil {
+			// 会话已结束则无需处理
+			if ts.ctx.Err() != nil {
+				return
+			}
+			ts.WriteToWs(err.Error())
+			ts.terminal.Write([]byte{EOT})
+			return
+		}
+
+		if _, err := ts.terminal.Write(data); err != nil {
+			logx.Errorf("failed to write data to the ssh terminal: %s", err)
+			ts.WriteToWs(GetErrorContentRn(fmt.Sprintf("failed to write data to the ssh terminal: %s", err.Error())))
+		}
+	}()
+}
+
+// rejectInputIfApproving 存在等待审批的命令时拒绝用户输入，输入Ctrl+C则取消审批
+func (ts *TerminalSession) rejectInputIfApproving(data []byte) bool {
+	ts.approveLock.Lock()
+	defer ts.approveLock.Unlock()
+	if ts.approveCancel == nil {
+		return false
+	}
+
+	if bytes.IndexByte(data, EOT) != -1 {
+		ts.approveCancel()
+		return true
+	}
+	if !ts.approveHinted {
+		ts.approveHinted = true
+		ts.WriteToWs(GetWarnContentRn("the command is waiting for approval, input is rejected, press Ctrl+C to cancel the approval"))
+	}
+	return true
+}
+
 // WriteToWs 将消息写入websocket连接
 func (ts *TerminalSession) WriteToWs(msg string) error {
+	ts.wsLock.Lock()
+	defer ts.wsLock.Unlock()
 	return ts.wsConn.WriteMessage(websocket.TextMessage, []byte(msg))
 }
 
@@ -2,6 +2,9 @@ package application
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"maps"
 	"mayfly-go/internal/msg/application/dto"
 	"mayfly-go/internal/msg/domain/entity"
 	"mayfly-go/internal/msg/domain/repository"
@@ -31,6 +34,9 @@ type MsgTmpl interface {
 	// Send 发送消息
 	Send(ctx context.Context, tmplCode string, params map[string]any, receiverId ...uint64) error
 
+	// SendSync 使用指定模板同步发送消息，并返回各渠道的发送错误。msg的内容、标题及类型使用模板信息填充
+	SendSync(ctx context.Context, tmplId uint64, msg *msgx.Msg, receiverId ...uint64) error
+
 	// DeleteTmplChannel 删除指定渠道关联的模板
 	DeleteTmplChannel(ctx context.Context, channelId uint64) error
 }
@@ -133,43 +139,90 @@ func (m *msgTmplAppImpl) Send(ctx context.Context, tmplCode string, params map[s
 	if err != nil {
 		return errorx.NewBiz("message template does not exist")
 	}
+
+	msg := &msgx.Msg{Params: params}
+	channels, err := m.prepareSend(tmpl, msg, receiverId...)
+	if err != nil {
+		return err
+	}
+
+	for _, channel := range channels {
+		go func(channel *entity.MsgChannel) {
+			if err := msgx.Send(&msgx.Channel{
+				Type:      channel.Type,
+				Name:      channel.Name,
+				URL:       channel.Url,
+				ExtraData: channel.ExtraData,
+			}, msg); err != nil {
+				logx.Errorf("send msg error => channel=%s, msg=%s, err -> %v", channel.Code, msg.Content, err)
+			}
+		}(channel)
+	}
+
+	return nil
+}
+
+func (m *msgTmplAppImpl) SendSync(ctx context.Context, tmplId uint64, msg *msgx.Msg, receiverId ...uint64) error {
+	tmpl, err := m.GetById(tmplId)
+	if err != nil {
+		return errorx.NewBiz("message template does not exist")
+	}
+
+	channels, err := m.prepareSend(tmpl, msg, receiverId...)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, channel := range channels {
+		// 每个渠道使用独立的参数副本，避免渠道发送时对receiver参数的修改相互影响
+		channelMsg := *msg
+		channelMsg.Params = maps.Clone(msg.Params)
+		if err := msgx.Send(&msgx.Channel{
+			Type:      channel.Type,
+			Name:      channel.Name,
+			URL:       channel.Url,
+			ExtraData: channel.ExtraData,
+		}, &channelMsg); err != nil {
+			logx.Errorf("send msg error => channel=%s, err -> %v", channel.Code, err)
+			errs = append(errs, fmt.Errorf("[%s] %w", channel.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// prepareSend 校验模板状态，使用模板信息及接收人填充msg，并返回模板关联的启用状态的渠道
+func (m *msgTmplAppImpl) prepareSend(tmpl *entity.MsgTmpl, msg *msgx.Msg, receiverId ...uint64) ([]*entity.MsgChannel, error) {
 	if tmpl.Status != entity.TmplStatusEnable {
-		return errorx.NewBiz("message template is disabled")
+		return nil, errorx.NewBiz("message template is disabled")
 	}
 
 	tmplChannels, err := m.msgTmplChannelRepo.SelectByCond(&entity.MsgTmplChannel{TmplId: tmpl.Id}, "channel_id")
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if len(tmplChannels) == 0 {
-		return errorx.NewBiz("message template is not associated with any channel")
+		return nil, errorx.NewBiz("message template is not associated with any channel")
 	}
 
 	channels, err := m.msgChannelApp.GetByIds(collx.ArrayMap(tmplChannels, func(c *entity.MsgTmplChannel) uint64 {
 		return c.ChannelId
 	}))
-
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// content, err := stringx.TemplateParse(tmpl.Tmpl, params)
-	// if err != nil {
-	// 	return err
-	// }
-
-	// toAll := len(receiverId) == 0
 	accounts, err := m.accountApp.GetByIds(receiverId)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	msg := &msgx.Msg{
-		Content:   tmpl.Tmpl,
-		Params:    params,
-		Title:     tmpl.Title,
-		Type:      tmpl.MsgType,
-		ExtraData: tmpl.ExtraData,
+	msg.Content = tmpl.Tmpl
+	msg.Title = tmpl.Title
+	msg.Type = tmpl.MsgType
+	msg.ExtraData = tmpl.ExtraData
+	if msg.Params == nil {
+		msg.Params = make(map[string]any)
 	}
 
 	if len(accounts) > 0 {
@@ -182,25 +235,13 @@ func (m *msgTmplAppImpl) Send(ctx context.Context, tmplCode string, params map[s
 		})
 	}
 
-	for _, channel := range channels {
+	return collx.ArrayFilter(channels, func(channel *entity.MsgChannel) bool {
 		if channel.Status != entity.ChannelStatusEnable {
 			logx.Warnf("channel is disabled => %s", channel.Code)
-			continue
+			return false
 		}
-
-		go func(channel *entity.MsgChannel) {
-			if err := msgx.Send(&msgx.Channel{
-				Type:      channel.Type,
-				Name:      channel.Name,
-				URL:       channel.Url,
-				ExtraData: channel.ExtraData,
-			}, msg); err != nil {
-				logx.Errorf("send msg error => channel=%s, msg=%s, err -> %v", channel.Code, msg.Content, err)
-			}
-		}(channel)
-	}
-
-	return nil
+		return true
+	}), nil
 }
 
 func (m *msgTmplAppImpl) DeleteTmplChannel(ctx context.Context, channelId uint64) error {
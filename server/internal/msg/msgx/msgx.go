@@ -50,6 +50,15 @@ type Msg struct {
 	Params  map[string]any // 消息参数(替换消息中的占位符)
 
 	Receivers []Receiver // 消息接收人
+
+	Attachments []Attachment // 消息附件，仅邮件渠道支持
+}
+
+// Attachment 消息附件
+type Attachment struct {
+	Name        string // 附件名
+	ContentType string // 附件类型，如text/csv
+	Content     []byte // 附件内容
 }
 
 // Channel 消息发送渠道信息
@@ -1,6 +1,7 @@
 package sender
 
 import (
+	"bytes"
 	"crypto/tls"
 	"encoding/base64"
 	"errors"
@@ -8,7 +9,10 @@ import (
 	"mayfly-go/internal/msg/msgx"
 	"mayfly-go/pkg/utils/collx"
 	"mayfly-go/pkg/utils/stringx"
+	"mime"
+	"mime/multipart"
 	"net/smtp"
+	"net/textproto"
 	"strings"
 
 	"github.com/may-fly/cast"
@@ -48,11 +52,21 @@ func (e EmailSender) SendEmail(channel *msgx.Channel, msg *msgx.Msg) error {
 	smtpPassword := channel.GetExtraString("smtpPassword")
 
 	encodedSubject := fmt.Sprintf("=?UTF-8?B?%s?=", base64.StdEncoding.EncodeToString([]byte(subject)))
-	mail := []byte(fmt.Sprintf("To: %s\r\n"+
+	header := fmt.Sprintf("To: %s\r\n"+
 		"From: %s<%s>\r\n"+
-		"Subject: %s\r\n"+
-		"Content-Type: text/html; charset=UTF-8\r\n\r\n%s\r\n",
-		strings.Join(to, ";"), systemName, smtpAccount, encodedSubject, content))
+		"Subject: %s\r\n",
+		strings.Join(to, ";"), systemName, smtpAccount, encodedSubject)
+
+	var mail []byte
+	if len(msg.Attachments) == 0 {
+		mail = []byte(fmt.Sprintf("%sContent-Type: text/html; charset=UTF-8\r\n\r\n%s\r\n", header, content))
+	} else {
+		body, err := e.buildMixedBody(header, content, msg.Attachments)
+		if err != nil {
+			return err
+		}
+		mail = body
+	}
 	auth := smtp.PlainAuth("", smtpAccount, smtpPassword, smtpServer)
 	addr := fmt.Sprintf("%s:%d", smtpServer, smtpPort)
 
@@ -98,3 +112,50 @@ func (e EmailSender) SendEmail(channel *msgx.Channel, msg *msgx.Msg) error {
 	}
 	return err
 }
+
+// buildMixedBody 构建包含附件的multipart/mixed邮件内容
+func (e EmailSender) buildMixedBody(header string, content string, attachments []msgx.Attachment) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	buf.WriteString(header)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString(fmt.Sprintf("Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mw.Boundary()))
+
+	htmlPart, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/html; charset=UTF-8"}})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := htmlPart.Write([]byte(content)); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		encodedName := mime.BEncoding.Encode("UTF-8", attachment.Name)
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {fmt.Sprintf("%s; name=\"%s\"", contentType, encodedName)},
+			"Content-Disposition":       {fmt.Sprintf("attachment; filename=\"%s\"", encodedName)},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// base64内容按76字符换行
+		encoded := base64.StdEncoding.EncodeToString(attachment.Content)
+		for i := 0; i < len(encoded); i += 76 {
+			if _, err := part.Write([]byte(encoded[i:min(i+76, len(encoded))] + "\r\n")); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
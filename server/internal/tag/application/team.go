@@ -12,6 +12,7 @@ import (
 	"mayfly-go/pkg/errorx"
 	"mayfly-go/pkg/logx"
 	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/collx"
 )
 
 type Team interface {
@@ -35,6 +36,9 @@ type Team interface {
 
 	IsExistMember(teamId, accounId uint64) bool
 
+	// GetAccountTeamIds 获取账号所属的团队id
+	GetAccountTeamIds(accountId uint64) []uint64
+
 	DeleteTag(tx context.Context, teamId, tagId uint64) error
 }
 
@@ -133,6 +137,17 @@ func (p *teamAppImpl) IsExistMember(teamId, accounId uint64) bool {
 	return p.teamMemberRepo.IsExist(teamId, accounId)
 }
 
+// 获取账号所属的团队id
+func (p *teamAppImpl) GetAccountTeamIds(accountId uint64) []uint64 {
+	teamMembers, err := p.teamMemberRepo.SelectByCond(&entity.TeamMember{AccountId: accountId}, "team_id")
+	if err != nil {
+		return []uint64{}
+	}
+	return collx.ArrayMap(teamMembers, func(tm *entity.TeamMember) uint64 {
+		return tm.TeamId
+	})
+}
+
 //--------------- 标签相关接口 ---------------
 
 // 删除关联标签信息
@@ -0,0 +1,668 @@
+package migrations
+
+import (
+	"encoding/json"
+	"errors"
+	dbentity "mayfly-go/internal/db/domain/entity"
+	machineentity "mayfly-go/internal/machine/domain/entity"
+	"mayfly-go/internal/machine/mcm"
+	sysentity "mayfly-go/internal/sys/domain/entity"
+	"mayfly-go/pkg/logx"
+	"mayfly-go/pkg/model"
+	"mayfly-go/pkg/utils/collx"
+	"mayfly-go/pkg/utils/stringx"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func V1_11() []*gormigrate.Migration {
+	var migrations []*gormigrate.Migration
+	migrations = append(migrations, V1_11_0()...)
+	return migrations
+}
+
+func V1_11_0() []*gormigrate.Migration {
+	return []*gormigrate.Migration{
+		{
+			ID: "20261018-v1.11.0-db-data-sync-tables",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&dbentity.DataSyncTaskTable{}, &dbentity.DataSyncLog{}); err != nil {
+					return err
+				}
+
+				// 将旧版本单表同步配置迁移至同步表配置
+				var tasks []*dbentity.DataSyncTask
+				if err := tx.Where("is_deleted = ?", 0).Find(&tasks).Error; err != nil {
+					return err
+				}
+				for _, task := range tasks {
+					if task.DataSql == "" || task.TargetTableName == "" {
+						continue
+					}
+					table := &dbentity.DataSyncTaskTable{
+						TaskId:            task.Id,
+						Sort:              1,
+						DataSql:           task.DataSql,
+						UpdField:          task.UpdField,
+						UpdFieldVal:       task.UpdFieldVal,
+						UpdFieldSrc:       task.UpdFieldSrc,
+						TargetTableName:   task.TargetTableName,
+						FieldMap:          task.FieldMap,
+						DuplicateStrategy: task.DuplicateStrategy,
+					}
+					if err := tx.Create(table).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-db-transfer-file-type",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&dbentity.DbTransferTask{}, &dbentity.DbTransferFile{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-db-data-gen",
+			Migrate: func(tx *gorm.DB) error {
+				return createResources(tx, &sysentity.Resource{
+					Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281601}}}},
+					Pid:    38,
+					UiPath: "dbms23ax/exaeca2x/Gn4dTq7w/",
+					Name:   "menu.dbDataOpDataGen",
+					Code:   "db:data:gen",
+					Type:   2,
+					Weight: 1792281601,
+				})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-db-sql-report",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&dbentity.DbSqlReport{}, &dbentity.DbSqlReportLog{}); err != nil {
+					return err
+				}
+				return createResources(tx,
+					&sysentity.Resource{
+						Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281602}}}},
+						Pid:    38,
+						UiPath: "dbms23ax/exaeca2x/Rp8kWc3n/",
+						Name:   "menu.dbSqlReportSave",
+						Code:   "db:sqlreport:save",
+						Type:   2,
+						Weight: 1792281602,
+					},
+					&sysentity.Resource{
+						Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281603}}}},
+						Pid:    38,
+						UiPath: "dbms23ax/exaeca2x/Hq2vLx9d/",
+						Name:   "menu.dbSqlReportDelete",
+						Code:   "db:sqlreport:del",
+						Type:   2,
+						Weight: 1792281603,
+					},
+				)
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-db-sql-version-share",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&dbentity.DbSql{}, &dbentity.DbSqlVersion{}, &dbentity.DbSqlShare{}); err != nil {
+					return err
+				}
+
+				// 为已保存的sql生成初始版本
+				var sqls []*dbentity.DbSql
+				if err := tx.Where("is_deleted = ?", model.ModelUndeleted).Find(&sqls).Error; err != nil {
+					return err
+				}
+				now := time.Now()
+				for _, sql := range sqls {
+					version := &dbentity.DbSqlVersion{
+						DbSqlId: sql.Id,
+						Version: 1,
+						Sql:     sql.Sql,
+						Remark:  "init",
+					}
+					version.CreateTime = &now
+					version.CreatorId = sql.CreatorId
+					version.Creator = sql.Creator
+					if err := tx.Create(version).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-machine-host-key",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&machineentity.MachineHostKey{}); err != nil {
+					return err
+				}
+				return createResources(tx, &sysentity.Resource{
+					Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281604}}}},
+					Pid:    3,
+					UiPath: "12sSjal1/lskeiql1/Hk7pQz2m/",
+					Name:   "menu.machineHostKey",
+					Code:   "machine:hostkey",
+					Type:   2,
+					Weight: 1792281604,
+				})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-machine-monitor",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&machineentity.MachineMonitor{}); err != nil {
+					return err
+				}
+				return appendConfigParams(tx, "MachineConfig",
+					map[string]any{"model": "monitorRawDays", "name": "system.sysconf.monitorRawDays", "placeholder": "system.sysconf.monitorRawDaysPlaceholder", "required": false},
+					map[string]any{"model": "monitorSaveDays", "name": "system.sysconf.monitorSaveDays", "placeholder": "system.sysconf.monitorSaveDaysPlaceholder", "required": false},
+				)
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-machine-alert",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&machineentity.MachineAlertRule{}, &machineentity.MachineAlert{}, &machineentity.MachineAlertSilence{}); err != nil {
+					return err
+				}
+				return createResources(tx,
+					&sysentity.Resource{
+						Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281605}}}},
+						Pid:    1713875842,
+						UiPath: "12sSjal1/UnWIUhW0/Al3rTs7v/",
+						Name:   "menu.machineAlertSave",
+						Code:   "machine:alert:save",
+						Type:   2,
+						Weight: 1792281605,
+					},
+					&sysentity.Resource{
+						Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281606}}}},
+						Pid:    1713875842,
+						UiPath: "12sSjal1/UnWIUhW0/Al9dEl2k/",
+						Name:   "menu.machineAlertDelete",
+						Code:   "machine:alert:del",
+						Type:   2,
+						Weight: 1792281606,
+					},
+				)
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-machine-cmd-batch",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&machineentity.MachineCmdBatch{}, &machineentity.MachineCmdBatchResult{}); err != nil {
+					return err
+				}
+				return createResources(tx, &sysentity.Resource{
+					Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281607}}}},
+					Pid:    3,
+					UiPath: "12sSjal1/lskeiql1/Cb8tRn4w/",
+					Name:   "menu.machineCmdBatch",
+					Code:   "machine:cmd:batch",
+					Type:   2,
+					Weight: 1792281607,
+				})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-machine-cronjob-retry",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&machineentity.MachineCronJob{}, &machineentity.MachineCronJobExec{}); err != nil {
+					return err
+				}
+				// 已有任务保持原有的并发执行行为
+				return tx.Model(&machineentity.MachineCronJob{}).Where("concurrency_policy IS NULL OR concurrency_policy = 0").
+					Update("concurrency_policy", machineentity.MachineCronJobConcurrencyAllow).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-machine-terminal-session",
+			Migrate: func(tx *gorm.DB) error {
+				return createResources(tx,
+					&sysentity.Resource{
+						Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281608}}}},
+						Pid:    1713875842,
+						UiPath: "12sSjal1/UnWIUhW0/Ts5mNt8q/",
+						Name:   "menu.machineTerminalMonitor",
+						Code:   "machine:terminal:monitor",
+						Type:   2,
+						Weight: 1792281608,
+					},
+					&sysentity.Resource{
+						Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281609}}}},
+						Pid:    1713875842,
+						UiPath: "12sSjal1/UnWIUhW0/Ts6kLl3c/",
+						Name:   "menu.machineTerminalKill",
+						Code:   "machine:terminal:kill",
+						Type:   2,
+						Weight: 1792281609,
+					},
+				)
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-machine-term-op-cmd",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&machineentity.MachineTermOpCmd{}); err != nil {
+					return err
+				}
+
+				// 根据已有终端记录的命令建立索引，偏移时间按命令执行时间与记录开始时间计算
+				var termOps []*machineentity.MachineTermOp
+				return tx.Model(&machineentity.MachineTermOp{}).Where("exec_cmds IS NOT NULL AND exec_cmds != ''").FindInBatches(&termOps, 200, func(_ *gorm.DB, _ int) error {
+					for _, termOp := range termOps {
+						var execCmds []struct {
+							Cmd  string `json:"cmd"`
+							Time int64  `json:"time"`
+						}
+						if err := json.Unmarshal([]byte(termOp.ExecCmds), &execCmds); err != nil || len(execCmds) == 0 {
+							continue
+						}
+
+						cmds := make([]*machineentity.MachineTermOpCmd, 0, len(execCmds))
+						for _, execCmd := range execCmds {
+							cmd := &machineentity.MachineTermOpCmd{
+								TermOpId:  termOp.Id,
+								MachineId: termOp.MachineId,
+								Cmd:       stringx.Truncate(execCmd.Cmd, 2000, 1900, "..."),
+								ExecTime:  time.Unix(execCmd.Time, 0),
+								CreatorId: termOp.CreatorId,
+								Creator:   termOp.Creator,
+							}
+							if termOp.CreateTime != nil && execCmd.Time >= termOp.CreateTime.Unix() {
+								cmd.Offset = float64(execCmd.Time - termOp.CreateTime.Unix())
+							}
+							cmds = append(cmds, cmd)
+						}
+						if err := tx.Create(cmds).Error; err != nil {
+							return err
+						}
+					}
+					return nil
+				}).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-machine-guac-recording",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&machineentity.MachineTermOp{}); err != nil {
+					return err
+				}
+				return appendConfigParams(tx, "MachineConfig",
+					map[string]any{"model": "guacdRecPath", "name": "system.sysconf.guacdRecPath", "placeholder": "system.sysconf.guacdRecPathPlaceholder", "required": false},
+				)
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-machine-cmd-approve",
+			Migrate: func(tx *gorm.DB) error {
+				return appendConfigParams(tx, "MachineConfig",
+					map[string]any{"model": "cmdApproveTimeout", "name": "system.sysconf.cmdApproveTimeout", "placeholder": "system.sysconf.cmdApproveTimeoutPlaceholder", "required": false},
+				)
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-machine-file-transfer",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&machineentity.MachineFileTransfer{}); err != nil {
+					return err
+				}
+				return createResources(tx, &sysentity.Resource{
+					Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281610}}}},
+					Pid:    3,
+					UiPath: "12sSjal1/lskeiql1/Ft7xQm2p/",
+					Name:   "menu.machineFileTransfer",
+					Code:   "machine:file:transfer",
+					Type:   2,
+					Weight: 1792281610,
+				})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-machine-file-version",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&machineentity.MachineFileVersion{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-machine-port-forward",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&machineentity.MachinePortForward{}); err != nil {
+					return err
+				}
+				if err := appendConfigParams(tx, "MachineConfig",
+					map[string]any{"model": "portForwardBindHost", "name": "system.sysconf.portForwardBindHost", "placeholder": "system.sysconf.portForwardBindHostPlaceholder", "required": false},
+					map[string]any{"model": "portForwardHost", "name": "system.sysconf.portForwardHost", "placeholder": "system.sysconf.portForwardHostPlaceholder", "required": false},
+					map[string]any{"model": "portForwardPorts", "name": "system.sysconf.portForwardPorts", "placeholder": "system.sysconf.portForwardPortsPlaceholder", "required": false},
+					map[string]any{"model": "portForwardMaxTtl", "name": "system.sysconf.portForwardMaxTtl", "placeholder": "system.sysconf.portForwardMaxTtlPlaceholder", "required": false},
+					map[string]any{"model": "portForwardUserLimit", "name": "system.sysconf.portForwardUserLimit", "placeholder": "system.sysconf.portForwardUserLimitPlaceholder", "required": false},
+				); err != nil {
+					return err
+				}
+				return createResources(tx, &sysentity.Resource{
+					Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281611}}}},
+					Pid:    3,
+					UiPath: "12sSjal1/lskeiql1/Pf4wRd8k/",
+					Name:   "menu.machinePortForward",
+					Code:   "machine:port-forward",
+					Type:   2,
+					Weight: 1792281611,
+				})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-machine-service",
+			Migrate: func(tx *gorm.DB) error {
+				return createResources(tx,
+					&sysentity.Resource{
+						Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281612}}}},
+						Pid:    3,
+						UiPath: "12sSjal1/lskeiql1/Sv5tRt1a/",
+						Name:   "menu.machineServiceStart",
+						Code:   "machine:service:start",
+						Type:   2,
+						Weight: 1792281612,
+					},
+					&sysentity.Resource{
+						Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281613}}}},
+						Pid:    3,
+						UiPath: "12sSjal1/lskeiql1/Sv5tOp2b/",
+						Name:   "menu.machineServiceStop",
+						Code:   "machine:service:stop",
+						Type:   2,
+						Weight: 1792281613,
+					},
+					&sysentity.Resource{
+						Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281614}}}},
+						Pid:    3,
+						UiPath: "12sSjal1/lskeiql1/Sv5rSt3c/",
+						Name:   "menu.machineServiceRestart",
+						Code:   "machine:service:restart",
+						Type:   2,
+						Weight: 1792281614,
+					},
+					&sysentity.Resource{
+						Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281615}}}},
+						Pid:    3,
+						UiPath: "12sSjal1/lskeiql1/Sv5eNb4d/",
+						Name:   "menu.machineServiceEnable",
+						Code:   "machine:service:enable",
+						Type:   2,
+						Weight: 1792281615,
+					},
+					&sysentity.Resource{
+						Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281616}}}},
+						Pid:    3,
+						UiPath: "12sSjal1/lskeiql1/Sv5dSb5e/",
+						Name:   "menu.machineServiceDisable",
+						Code:   "machine:service:disable",
+						Type:   2,
+						Weight: 1792281616,
+					},
+				)
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-machine-container",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&machineentity.MachineTermOp{}); err != nil {
+					return err
+				}
+				return createResources(tx,
+					&sysentity.Resource{
+						Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281617}}}},
+						Pid:    3,
+						UiPath: "12sSjal1/lskeiql1/Dk3rOp6f/",
+						Name:   "menu.machineContainerOp",
+						Code:   "machine:container:op",
+						Type:   2,
+						Weight: 1792281617,
+					},
+					&sysentity.Resource{
+						Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281618}}}},
+						Pid:    3,
+						UiPath: "12sSjal1/lskeiql1/Dk3rRm7g/",
+						Name:   "menu.machineContainerRemove",
+						Code:   "machine:container:remove",
+						Type:   2,
+						Weight: 1792281618,
+					},
+					&sysentity.Resource{
+						Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281619}}}},
+						Pid:    3,
+						UiPath: "12sSjal1/lskeiql1/Dk3rEx8h/",
+						Name:   "menu.machineContainerExec",
+						Code:   "machine:container:exec",
+						Type:   2,
+						Weight: 1792281619,
+					},
+				)
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-machine-script-params",
+			Migrate: func(tx *gorm.DB) error {
+				// 脚本参数定义支持类型、默认值及校验规则，扩大参数列长度
+				return tx.AutoMigrate(&machineentity.MachineScript{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-db-sql-report-menu",
+			Migrate: func(tx *gorm.DB) error {
+				// 报表新增运行状态，用于防止并发执行
+				if err := tx.AutoMigrate(&dbentity.DbSqlReport{}); err != nil {
+					return err
+				}
+				if err := createResources(tx,
+					&sysentity.Resource{
+						Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281620}}}},
+						Pid:    36,
+						UiPath: "Rp8kSq7e/",
+						Name:   "menu.dbSqlReport",
+						Code:   "sql-report",
+						Type:   1,
+						Meta:   `{"component":"ops/db/SqlReportList","icon":"Document","isKeepAlive":true,"routeName":"SqlReportList"}`,
+						Weight: 1792281620,
+					},
+				); err != nil {
+					return err
+				}
+				// 报表权限移至报表菜单下
+				resourceModel := &sysentity.Resource{}
+				if err := tx.Model(resourceModel).Where("id = ?", 1792281602).Updates(map[string]any{"pid": 1792281620, "ui_path": "Rp8kSq7e/Rp8kWc3n/"}).Error; err != nil {
+					return err
+				}
+				return tx.Model(resourceModel).Where("id = ?", 1792281603).Updates(map[string]any{"pid": 1792281620, "ui_path": "Rp8kSq7e/Hq2vLx9d/"}).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-machine-host-key-alert",
+			Migrate: func(tx *gorm.DB) error {
+				return appendConfigParams(tx, "MachineConfig",
+					map[string]any{"model": "hostKeyAlertReceiverIds", "name": "system.sysconf.hostKeyAlertReceiverIds", "placeholder": "system.sysconf.hostKeyAlertReceiverIdsPlaceholder", "required": false},
+					map[string]any{"model": "hostKeyAlertMsgTmplCode", "name": "system.sysconf.hostKeyAlertMsgTmplCode", "placeholder": "system.sysconf.hostKeyAlertMsgTmplCodePlaceholder", "required": false},
+				)
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-machine-file-tail-permission",
+			Migrate: func(tx *gorm.DB) error {
+				return createResources(tx, &sysentity.Resource{
+					Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281621}}}},
+					Pid:    3,
+					UiPath: "12sSjal1/lskeiql1/Tl6fWs9q/",
+					Name:   "menu.machineFileTail",
+					Code:   "machine:file:tail",
+					Type:   2,
+					Weight: 1792281621,
+				})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-machine-container-logs-permission",
+			Migrate: func(tx *gorm.DB) error {
+				return createResources(tx, &sysentity.Resource{
+					Model:  model.Model{CreateModel: model.CreateModel{DeletedModel: model.DeletedModel{IdModel: model.IdModel{Id: 1792281622}}}},
+					Pid:    3,
+					UiPath: "12sSjal1/lskeiql1/Dk3rLg9j/",
+					Name:   "menu.machineContainerLogs",
+					Code:   "machine:container:logs",
+					Type:   2,
+					Weight: 1792281622,
+				})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "20261018-v1.11.0-machine-script-params-check",
+			Migrate: func(tx *gorm.DB) error {
+				// 仅检查参数定义，不修改用户脚本，无法解析的脚本需用户自行修改参数定义后方可执行
+				var scripts []*machineentity.MachineScript
+				return tx.Model(&machineentity.MachineScript{}).Where("params IS NOT NULL AND params != ''").FindInBatches(&scripts, 200, func(_ *gorm.DB, _ int) error {
+					for _, script := range scripts {
+						if _, err := mcm.ParseScriptParams(script.Params); err != nil {
+							logx.Warnf("the params of machine script [id=%d, name=%s] are invalid, please modify them before running: %s", script.Id, script.Name, err.Error())
+						}
+					}
+					return nil
+				}).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
+	}
+}
+
+// createResources 创建菜单或权限资源，已存在相同ui_path的资源则跳过
+func createResources(tx *gorm.DB, resources ...*sysentity.Resource) error {
+	now := time.Now()
+	for _, res := range resources {
+		var count int64
+		if err := tx.Model(&sysentity.Resource{}).Where("ui_path = ?", res.UiPath).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		res.Status = 1
+		res.CreateTime = &now
+		res.CreatorId = 1
+		res.Creator = "admin"
+		res.UpdateTime = &now
+		res.ModifierId = 1
+		res.Modifier = "admin"
+		if err := tx.Create(res).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendConfigParams 为系统配置追加配置项参数，已存在相同model的参数则跳过
+func appendConfigParams(tx *gorm.DB, key string, params ...map[string]any) error {
+	config := &sysentity.Config{}
+	if err := tx.Where(&sysentity.Config{Key: key}).First(config).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	var oldParams []map[string]any
+	if config.Params != "" {
+		if err := json.Unmarshal([]byte(config.Params), &oldParams); err != nil {
+			return err
+		}
+	}
+	for _, param := range params {
+		if !collx.AnyMatch(oldParams, func(p map[string]any) bool { return p["model"] == param["model"] }) {
+			oldParams = append(oldParams, param)
+		}
+	}
+
+	newParams, err := json.Marshal(oldParams)
+	if err != nil {
+		return err
+	}
+	return tx.Model(&sysentity.Config{}).Where("id = ?", config.Id).Update("params", string(newParams)).Error
+}
@@ -64,3 +64,12 @@ func ExistKey(key string) bool {
 	_, ok := key2IdMap.Load(key)
 	return ok
 }
+
+// cronParser 与cronService一致的cron表达式解析器(支持秒)
+var cronParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ValidateCron 校验cron表达式是否合法
+func ValidateCron(spec string) error {
+	_, err := cronParser.Parse(spec)
+	return err
+}
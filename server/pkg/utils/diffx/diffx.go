@@ -0,0 +1,224 @@
+package diffx
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// OpType 行差异操作类型
+type OpType int8
+
+const (
+	OpEqual  OpType = 0
+	OpDelete OpType = -1
+	OpInsert OpType = 1
+)
+
+// LineOp 单行差异
+type LineOp struct {
+	Type OpType
+	Text string
+}
+
+// DefaultContext 统一diff格式默认的上下文行数
+const DefaultContext = 3
+
+const (
+	MaxLines = 100000 // 参与对比的最大总行数
+	MaxEdits = 2000   // 允许的最大差异行数(编辑距离)，回溯路径所需内存与其平方成正比
+)
+
+var (
+	ErrTooManyLines = errors.New("the content is too large to diff")
+	ErrTooDifferent = errors.New("the contents are too different to diff")
+)
+
+// Lines 使用Myers算法计算两组行之间的差异，总行数超过MaxLines或差异行数超过MaxEdits时返回错误
+func Lines(a, b []string) ([]LineOp, error) {
+	n, m := len(a), len(b)
+	if n+m == 0 {
+		return nil, nil
+	}
+	if n+m > MaxLines {
+		return nil, ErrTooManyLines
+	}
+
+	maxD := min(n+m, MaxEdits)
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	// 记录每一步开始时v在[-d-1, d+1]范围内的值，用于回溯差异路径
+	trace := make([][]int, 0)
+
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, slices.Clone(v[offset-d-1:offset+d+2]))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b), nil
+			}
+		}
+	}
+	return nil, ErrTooDifferent
+}
+
+func backtrack(trace [][]int, a, b []string) []LineOp {
+	x, y := len(a), len(b)
+	ops := make([]LineOp, 0, x+y)
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		// trace[d]下标0对应k=-d-1
+		v, offset := trace[d], d+1
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, LineOp{Type: OpEqual, Text: a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				ops = append(ops, LineOp{Type: OpInsert, Text: b[y]})
+			} else {
+				x--
+				ops = append(ops, LineOp{Type: OpDelete, Text: a[x]})
+			}
+		}
+	}
+
+	// 回溯得到的是逆序结果
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// Unified 生成统一diff格式(unified diff)的文本差异，内容相同时返回空字符串
+func Unified(fromName, toName, from, to string, context int) (string, error) {
+	ops, err := Lines(splitLines(from), splitLines(to))
+	if err != nil {
+		return "", err
+	}
+
+	hasChange := false
+	for _, op := range ops {
+		if op.Type != OpEqual {
+			hasChange = true
+			break
+		}
+	}
+	if !hasChange {
+		return "", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromName, toName))
+
+	// 每个op对应的from、to起始行号(从1开始)
+	fromLines := make([]int, len(ops)+1)
+	toLines := make([]int, len(ops)+1)
+	fromLine, toLine := 1, 1
+	for i, op := range ops {
+		fromLines[i], toLines[i] = fromLine, toLine
+		if op.Type != OpInsert {
+			fromLine++
+		}
+		if op.Type != OpDelete {
+			toLine++
+		}
+	}
+	fromLines[len(ops)], toLines[len(ops)] = fromLine, toLine
+
+	for i := 0; i < len(ops); {
+		if ops[i].Type == OpEqual {
+			i++
+			continue
+		}
+
+		// 确定hunk范围，间隔不超过2*context行相等内容的变更合并为一个hunk
+		start := max(i-context, 0)
+		end := i
+		for end < len(ops) {
+			if ops[end].Type != OpEqual {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].Type == OpEqual {
+				next++
+			}
+			if next == len(ops) || next-end > 2*context {
+				end = min(end+context, len(ops))
+				break
+			}
+			end = next
+		}
+
+		fromCount, toCount := 0, 0
+		for _, op := range ops[start:end] {
+			if op.Type != OpInsert {
+				fromCount++
+			}
+			if op.Type != OpDelete {
+				toCount++
+			}
+		}
+		sb.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(fromLines[start], fromCount), hunkRange(toLines[start], toCount)))
+		for _, op := range ops[start:end] {
+			switch op.Type {
+			case OpEqual:
+				sb.WriteString(" ")
+			case OpDelete:
+				sb.WriteString("-")
+			case OpInsert:
+				sb.WriteString("+")
+			}
+			sb.WriteString(op.Text)
+			sb.WriteString("\n")
+		}
+		i = end
+	}
+
+	return sb.String(), nil
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		// 空范围时起始行为前一行
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
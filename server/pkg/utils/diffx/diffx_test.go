@@ -0,0 +1,87 @@
+package diffx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLines(t *testing.T) {
+	ops, err := Lines([]string{"a", "b", "c"}, []string{"a", "c", "d"})
+	require.NoError(t, err)
+	var sb strings.Builder
+	for _, op := range ops {
+		switch op.Type {
+		case OpEqual:
+			sb.WriteString(" ")
+		case OpDelete:
+			sb.WriteString("-")
+		case OpInsert:
+			sb.WriteString("+")
+		}
+		sb.WriteString(op.Text)
+	}
+	require.Equal(t, " a-b c+d", sb.String())
+}
+
+func TestLinesLimit(t *testing.T) {
+	a := make([]string, MaxEdits)
+	b := make([]string, MaxEdits)
+	for i := range a {
+		a[i] = fmt.Sprintf("a%d", i)
+		b[i] = fmt.Sprintf("b%d", i)
+	}
+	_, err := Lines(a, b)
+	require.ErrorIs(t, err, ErrTooDifferent)
+
+	// 差异行数在限制内时可正常对比
+	ops, err := Lines(a[:MaxEdits/2], b[:MaxEdits/2])
+	require.NoError(t, err)
+	require.Len(t, ops, MaxEdits)
+
+	_, err = Lines(make([]string, MaxLines+1), nil)
+	require.ErrorIs(t, err, ErrTooManyLines)
+}
+
+func TestUnified(t *testing.T) {
+	testCases := []struct {
+		name string
+		from string
+		to   string
+		want string
+	}{
+		{
+			name: "equal",
+			from: "select 1\n",
+			to:   "select 1\n",
+			want: "",
+		},
+		{
+			name: "modify",
+			from: "select *\nfrom t\nwhere id = 1\n",
+			to:   "select *\nfrom t\nwhere id = 2\n",
+			want: "--- v1\n+++ v2\n@@ -2,2 +2,2 @@\n from t\n-where id = 1\n+where id = 2\n",
+		},
+		{
+			name: "empty from",
+			from: "",
+			to:   "a\nb",
+			want: "--- v1\n+++ v2\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "separate hunks",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			to:   "0\n2\n3\n4\n5\n6\n7\n8\n9\n11\n",
+			want: "--- v1\n+++ v2\n@@ -1,2 +1,2 @@\n-1\n+0\n 2\n@@ -9,2 +9,2 @@\n 9\n-10\n+11\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			diff, err := Unified("v1", "v2", tc.from, tc.to, 1)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, diff)
+		})
+	}
+}
@@ -0,0 +1,349 @@
+package parquetx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/may-fly/cast"
+)
+
+// Type 列的物理类型，所有列均为可空（OPTIONAL）列
+type Type int8
+
+const (
+	TypeString    Type = iota // utf8字符串
+	TypeInt64                 // 64位整数
+	TypeDouble                // 双精度浮点数
+	TypeBoolean               // 布尔值
+	TypeByteArray             // 二进制
+)
+
+// parquet format 枚举值
+const (
+	ptBoolean   int32 = 0
+	ptInt64     int32 = 2
+	ptDouble    int32 = 5
+	ptByteArray int32 = 6
+
+	repetitionOptional int32 = 1
+	convertedTypeUtf8  int32 = 0
+
+	encodingPlain int32 = 0
+	encodingRle   int32 = 3
+
+	codecUncompressed int32 = 0
+	pageTypeData      int32 = 0
+)
+
+const (
+	magic = "PAR1"
+
+	// DefaultRowGroupSize 默认每个row group的行数
+	DefaultRowGroupSize = 10000
+)
+
+type Column struct {
+	Name string
+	Type Type
+}
+
+func (c Column) physicalType() int32 {
+	switch c.Type {
+	case TypeInt64:
+		return ptInt64
+	case TypeDouble:
+		return ptDouble
+	case TypeBoolean:
+		return ptBoolean
+	default:
+		return ptByteArray
+	}
+}
+
+type columnChunkMeta struct {
+	numValues      int64
+	totalSize      int64
+	dataPageOffset int64
+}
+
+type rowGroupMeta struct {
+	columns   []columnChunkMeta
+	totalSize int64
+	numRows   int64
+}
+
+// Writer 简易的parquet文件writer，不压缩、每个列块仅包含一个PLAIN编码的数据页
+type Writer struct {
+	w       io.Writer
+	offset  int64
+	columns []Column
+
+	rowGroupSize int
+	rows         [][]any
+	rowGroups    []rowGroupMeta
+	numRows      int64
+	closed       bool
+}
+
+// NewWriter 创建parquet writer，写入完成后必须调用Close写入文件元数据
+func NewWriter(w io.Writer, columns []Column) *Writer {
+	return &Writer{
+		w:            w,
+		columns:      columns,
+		rowGroupSize: DefaultRowGroupSize,
+	}
+}
+
+// WithRowGroupSize 设置每个row group的行数
+func (pw *Writer) WithRowGroupSize(size int) *Writer {
+	if size > 0 {
+		pw.rowGroupSize = size
+	}
+	return pw
+}
+
+// Write 写入一行数据，row中值的顺序需与列顺序一致，nil表示空值
+func (pw *Writer) Write(row []any) error {
+	if pw.closed {
+		return fmt.Errorf("parquet writer is closed")
+	}
+	if len(row) != len(pw.columns) {
+		return fmt.Errorf("the number of row values [%d] does not match the number of columns [%d]", len(row), len(pw.columns))
+	}
+
+	values := make([]any, len(row))
+	for i, val := range row {
+		v, err := convertValue(pw.columns[i].Type, val)
+		if err != nil {
+			return fmt.Errorf("column [%s]: %w", pw.columns[i].Name, err)
+		}
+		values[i] = v
+	}
+	pw.rows = append(pw.rows, values)
+	if len(pw.rows) >= pw.rowGroupSize {
+		return pw.flushRowGroup()
+	}
+	return nil
+}
+
+// Close 写入剩余数据及文件元数据，不会关闭底层的writer
+func (pw *Writer) Close() error {
+	if pw.closed {
+		return nil
+	}
+	if pw.offset == 0 {
+		if err := pw.write([]byte(magic)); err != nil {
+			return err
+		}
+	}
+	if err := pw.flushRowGroup(); err != nil {
+		return err
+	}
+	pw.closed = true
+
+	footer := pw.fileMetadata()
+	if err := pw.write(footer); err != nil {
+		return err
+	}
+	if err := pw.write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer)))); err != nil {
+		return err
+	}
+	return pw.write([]byte(magic))
+}
+
+func (pw *Writer) write(p []byte) error {
+	n, err := pw.w.Write(p)
+	pw.offset += int64(n)
+	return err
+}
+
+func (pw *Writer) flushRowGroup() error {
+	if len(pw.rows) == 0 {
+		return nil
+	}
+	if pw.offset == 0 {
+		if err := pw.write([]byte(magic)); err != nil {
+			return err
+		}
+	}
+
+	rg := rowGroupMeta{numRows: int64(len(pw.rows))}
+	for i, column := range pw.columns {
+		page := encodeDataPage(column, pw.rows, i)
+
+		header := newCompactWriter()
+		header.I32(1, pageTypeData)
+		header.I32(2, int32(len(page)))
+		header.I32(3, int32(len(page)))
+		header.StructBegin(5)
+		header.I32(1, int32(len(pw.rows)))
+		header.I32(2, encodingPlain)
+		header.I32(3, encodingRle)
+		header.I32(4, encodingRle)
+		header.StructEnd()
+		header.StructEnd()
+
+		chunk := columnChunkMeta{
+			numValues:      int64(len(pw.rows)),
+			dataPageOffset: pw.offset,
+			totalSize:      int64(len(header.Bytes()) + len(page)),
+		}
+		if err := pw.write(header.Bytes()); err != nil {
+			return err
+		}
+		if err := pw.write(page); err != nil {
+			return err
+		}
+		rg.columns = append(rg.columns, chunk)
+		rg.totalSize += chunk.totalSize
+	}
+
+	pw.rowGroups = append(pw.rowGroups, rg)
+	pw.numRows += rg.numRows
+	pw.rows = pw.rows[:0]
+	return nil
+}
+
+// convertValue 将值转换为列类型对应的go类型，nil表示空值
+func convertValue(typ Type, val any) (any, error) {
+	if val == nil {
+		return nil, nil
+	}
+	switch typ {
+	case TypeInt64:
+		return cast.ToInt64E(val)
+	case TypeDouble:
+		return cast.ToFloat64E(val)
+	case TypeBoolean:
+		return cast.ToBoolE(val)
+	default:
+		return toBytes(val), nil
+	}
+}
+
+// encodeDataPage 编码数据页：定义级别（RLE）+ 非空值（PLAIN）
+func encodeDataPage(column Column, rows [][]any, colIndex int) []byte {
+	defLevels := make([]bool, len(rows))
+	var values []byte
+	var bools []bool
+
+	for i, row := range rows {
+		val := row[colIndex]
+		if val == nil {
+			continue
+		}
+		defLevels[i] = true
+
+		switch v := val.(type) {
+		case int64:
+			values = binary.LittleEndian.AppendUint64(values, uint64(v))
+		case float64:
+			values = binary.LittleEndian.AppendUint64(values, math.Float64bits(v))
+		case bool:
+			bools = append(bools, v)
+		case []byte:
+			values = binary.LittleEndian.AppendUint32(values, uint32(len(v)))
+			values = append(values, v...)
+		}
+	}
+
+	if column.Type == TypeBoolean {
+		values = make([]byte, (len(bools)+7)/8)
+		for i, b := range bools {
+			if b {
+				values[i/8] |= 1 << (i % 8)
+			}
+		}
+	}
+
+	levels := encodeDefLevels(defLevels)
+	page := binary.LittleEndian.AppendUint32(nil, uint32(len(levels)))
+	page = append(page, levels...)
+	return append(page, values...)
+}
+
+// encodeDefLevels 使用RLE编码定义级别（位宽为1）
+func encodeDefLevels(defLevels []bool) []byte {
+	var buf []byte
+	for i := 0; i < len(defLevels); {
+		j := i
+		for j < len(defLevels) && defLevels[j] == defLevels[i] {
+			j++
+		}
+		buf = binary.AppendUvarint(buf, uint64(j-i)<<1)
+		if defLevels[i] {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+		i = j
+	}
+	return buf
+}
+
+func toBytes(val any) []byte {
+	switch v := val.(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	case time.Time:
+		return []byte(v.Format(time.DateTime))
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return []byte(v.Format(time.DateTime))
+	default:
+		return []byte(cast.ToString(v))
+	}
+}
+
+func (pw *Writer) fileMetadata() []byte {
+	c := newCompactWriter()
+	c.I32(1, 1)
+
+	// schema，第一个元素为根节点
+	c.StructList(2, len(pw.columns)+1, func(i int) {
+		if i == 0 {
+			c.String(4, "schema")
+			c.I32(5, int32(len(pw.columns)))
+			return
+		}
+		column := pw.columns[i-1]
+		c.I32(1, column.physicalType())
+		c.I32(3, repetitionOptional)
+		c.String(4, column.Name)
+		if column.Type == TypeString {
+			c.I32(6, convertedTypeUtf8)
+		}
+	})
+	c.I64(3, pw.numRows)
+
+	c.StructList(4, len(pw.rowGroups), func(i int) {
+		rg := pw.rowGroups[i]
+		c.StructList(1, len(rg.columns), func(j int) {
+			chunk := rg.columns[j]
+			column := pw.columns[j]
+			c.I64(2, chunk.dataPageOffset)
+			c.StructBegin(3)
+			c.I32(1, column.physicalType())
+			c.I32List(2, []int32{encodingPlain, encodingRle})
+			c.StringList(3, []string{column.Name})
+			c.I32(4, codecUncompressed)
+			c.I64(5, chunk.numValues)
+			c.I64(6, chunk.totalSize)
+			c.I64(7, chunk.totalSize)
+			c.I64(9, chunk.dataPageOffset)
+			c.StructEnd()
+		})
+		c.I64(2, rg.totalSize)
+		c.I64(3, rg.numRows)
+	})
+	c.String(6, "mayfly-go")
+	c.StructEnd()
+	return c.Bytes()
+}
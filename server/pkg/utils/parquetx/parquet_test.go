@@ -0,0 +1,216 @@
+package parquetx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, []Column{
+		{Name: "id", Type: TypeInt64},
+		{Name: "name", Type: TypeString},
+		{Name: "score", Type: TypeDouble},
+		{Name: "enable", Type: TypeBoolean},
+	}).WithRowGroupSize(2)
+
+	require.NoError(t, w.Write([]any{1, "a", 1.5, true}))
+	require.NoError(t, w.Write([]any{2, nil, "2.5", false}))
+	require.NoError(t, w.Write([]any{"3", "c", nil, nil}))
+	require.Error(t, w.Write([]any{4}))
+	require.Error(t, w.Write([]any{"x", "d", 1, true}))
+	require.NoError(t, w.Close())
+	require.Len(t, w.rowGroups, 2)
+	require.Equal(t, int64(3), w.numRows)
+
+	data := buf.Bytes()
+	require.Equal(t, magic, string(data[:4]))
+	require.Equal(t, magic, string(data[len(data)-4:]))
+	footerLen := binary.LittleEndian.Uint32(data[len(data)-8 : len(data)-4])
+	require.Less(t, int(footerLen), len(data)-12)
+}
+
+// TestWriterRoundTrip 按parquet格式解析写入的文件元数据及数据页，校验文件格式及数据
+func TestWriterRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, []Column{
+		{Name: "id", Type: TypeInt64},
+		{Name: "name", Type: TypeString},
+		{Name: "score", Type: TypeDouble},
+		{Name: "enable", Type: TypeBoolean},
+		{Name: "data", Type: TypeByteArray},
+	}).WithRowGroupSize(2)
+	require.NoError(t, w.Write([]any{1, "张三", 1.5, true, []byte{0x01, 0x02}}))
+	require.NoError(t, w.Write([]any{2, nil, "2.5", false, nil}))
+	require.NoError(t, w.Write([]any{"3", "c", nil, nil, []byte("x")}))
+	require.NoError(t, w.Close())
+
+	data := buf.Bytes()
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8 : len(data)-4]))
+	r := &compactReader{buf: data[len(data)-8-footerLen : len(data)-8]}
+	meta := r.readStruct()
+	require.Empty(t, r.buf)
+	require.Equal(t, int64(3), meta[3])
+
+	schema := meta[2].([]any)
+	require.Len(t, schema, 6)
+	require.Equal(t, int64(5), schema[0].(map[int16]any)[5])
+	names := []string{"id", "name", "score", "enable", "data"}
+	types := []int32{ptInt64, ptByteArray, ptDouble, ptBoolean, ptByteArray}
+	for i, name := range names {
+		elem := schema[i+1].(map[int16]any)
+		require.Equal(t, name, string(elem[4].([]byte)))
+		require.Equal(t, int64(types[i]), elem[1])
+		require.Equal(t, int64(repetitionOptional), elem[3])
+	}
+	require.Equal(t, int64(convertedTypeUtf8), schema[2].(map[int16]any)[6])
+
+	columns := make([][]any, len(names))
+	rowGroups := meta[4].([]any)
+	require.Len(t, rowGroups, 2)
+	for _, rg := range rowGroups {
+		rg := rg.(map[int16]any)
+		numRows := int(rg[3].(int64))
+		var totalSize int64
+		for i, chunk := range rg[1].([]any) {
+			chunkMeta := chunk.(map[int16]any)[3].(map[int16]any)
+			require.Equal(t, names[i], string(chunkMeta[3].([]any)[0].([]byte)))
+			require.Equal(t, int64(numRows), chunkMeta[5])
+			totalSize += chunkMeta[6].(int64)
+
+			offset := chunkMeta[9].(int64)
+			r := &compactReader{buf: data[offset : offset+chunkMeta[6].(int64)]}
+			header := r.readStruct()
+			require.Equal(t, int64(pageTypeData), header[1])
+			require.Equal(t, int64(len(r.buf)), header[2])
+			require.Equal(t, int64(numRows), header[5].(map[int16]any)[1])
+			columns[i] = append(columns[i], decodeTestPage(t, types[i], numRows, r.buf)...)
+		}
+		require.Equal(t, totalSize, rg[2])
+	}
+
+	require.Equal(t, []any{int64(1), int64(2), int64(3)}, columns[0])
+	require.Equal(t, []any{"张三", nil, "c"}, columns[1])
+	require.Equal(t, []any{1.5, 2.5, nil}, columns[2])
+	require.Equal(t, []any{true, false, nil}, columns[3])
+	require.Equal(t, []any{"\x01\x02", nil, "x"}, columns[4])
+}
+
+// decodeTestPage 解析数据页中的定义级别及PLAIN编码的值，空值为nil，二进制值转为字符串
+func decodeTestPage(t *testing.T, typ int32, numRows int, page []byte) []any {
+	levelsLen := int(binary.LittleEndian.Uint32(page))
+	levels, values := page[4:4+levelsLen], page[4+levelsLen:]
+
+	var defLevels []bool
+	for len(levels) > 0 {
+		header, n := binary.Uvarint(levels)
+		require.Greater(t, n, 0)
+		for i := 0; i < int(header>>1); i++ {
+			defLevels = append(defLevels, levels[n] == 1)
+		}
+		levels = levels[n+1:]
+	}
+	require.Len(t, defLevels, numRows)
+
+	res := make([]any, numRows)
+	bitIndex := 0
+	for i, defined := range defLevels {
+		if !defined {
+			continue
+		}
+		switch typ {
+		case ptInt64:
+			res[i] = int64(binary.LittleEndian.Uint64(values))
+			values = values[8:]
+		case ptDouble:
+			res[i] = math.Float64frombits(binary.LittleEndian.Uint64(values))
+			values = values[8:]
+		case ptBoolean:
+			res[i] = values[bitIndex/8]&(1<<(bitIndex%8)) != 0
+			bitIndex++
+		case ptByteArray:
+			size := binary.LittleEndian.Uint32(values)
+			res[i] = string(values[4 : 4+size])
+			values = values[4+size:]
+		}
+	}
+	if typ == ptBoolean {
+		require.Len(t, values, (bitIndex+7)/8)
+	} else {
+		require.Empty(t, values)
+	}
+	return res
+}
+
+// compactReader 解析thrift compact protocol编码的结构体，字段值为int64、[]byte、[]any或map[int16]any
+type compactReader struct {
+	buf []byte
+}
+
+func (r *compactReader) readStruct() map[int16]any {
+	fields := make(map[int16]any)
+	var last int16
+	for {
+		b := r.buf[0]
+		r.buf = r.buf[1:]
+		if b == 0 {
+			return fields
+		}
+		id := last + int16(b>>4)
+		if b>>4 == 0 {
+			id = int16(r.readVarint())
+		}
+		fields[id] = r.readValue(b & 0x0f)
+		last = id
+	}
+}
+
+func (r *compactReader) readValue(typ byte) any {
+	switch typ {
+	case ctI32, ctI64:
+		return r.readVarint()
+	case ctBinary:
+		size := r.readUvarint()
+		v := r.buf[:size]
+		r.buf = r.buf[size:]
+		return v
+	case ctList:
+		b := r.buf[0]
+		r.buf = r.buf[1:]
+		size := uint64(b >> 4)
+		if size == 15 {
+			size = r.readUvarint()
+		}
+		list := make([]any, size)
+		for i := range list {
+			list[i] = r.readValue(b & 0x0f)
+		}
+		return list
+	case ctStruct:
+		return r.readStruct()
+	}
+	panic(fmt.Sprintf("unsupported thrift type: %d", typ))
+}
+
+func (r *compactReader) readVarint() int64 {
+	v, n := binary.Varint(r.buf)
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *compactReader) readUvarint() uint64 {
+	v, n := binary.Uvarint(r.buf)
+	r.buf = r.buf[n:]
+	return v
+}
+
+func TestEncodeDefLevels(t *testing.T) {
+	// 3个非空、2个空值、1个非空
+	levels := encodeDefLevels([]bool{true, true, true, false, false, true})
+	require.Equal(t, []byte{3 << 1, 1, 2 << 1, 0, 1 << 1, 1}, levels)
+}
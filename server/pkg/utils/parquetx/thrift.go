@@ -0,0 +1,111 @@
+package parquetx
+
+import (
+	"encoding/binary"
+)
+
+// thrift compact protocol 字段类型
+const (
+	ctI32    byte = 5
+	ctI64    byte = 6
+	ctBinary byte = 8
+	ctList   byte = 9
+	ctStruct byte = 12
+)
+
+// compactWriter 简易的thrift compact protocol编码器，仅实现parquet元数据所需的类型
+type compactWriter struct {
+	buf       []byte
+	lastField []int16 // 嵌套结构体的上一个字段id栈
+}
+
+func newCompactWriter() *compactWriter {
+	return &compactWriter{lastField: []int16{0}}
+}
+
+func (c *compactWriter) Bytes() []byte {
+	return c.buf
+}
+
+func (c *compactWriter) fieldHeader(id int16, typ byte) {
+	last := c.lastField[len(c.lastField)-1]
+	if delta := id - last; delta > 0 && delta <= 15 {
+		c.buf = append(c.buf, byte(delta)<<4|typ)
+	} else {
+		c.buf = append(c.buf, typ)
+		c.writeVarint(int64(id))
+	}
+	c.lastField[len(c.lastField)-1] = id
+}
+
+func (c *compactWriter) writeUvarint(v uint64) {
+	c.buf = binary.AppendUvarint(c.buf, v)
+}
+
+func (c *compactWriter) writeVarint(v int64) {
+	c.buf = binary.AppendVarint(c.buf, v)
+}
+
+func (c *compactWriter) I32(id int16, v int32) {
+	c.fieldHeader(id, ctI32)
+	c.writeVarint(int64(v))
+}
+
+func (c *compactWriter) I64(id int16, v int64) {
+	c.fieldHeader(id, ctI64)
+	c.writeVarint(v)
+}
+
+func (c *compactWriter) String(id int16, v string) {
+	c.fieldHeader(id, ctBinary)
+	c.writeUvarint(uint64(len(v)))
+	c.buf = append(c.buf, v...)
+}
+
+// StructBegin 开始写入结构体字段，id为0表示写入的是列表元素
+func (c *compactWriter) StructBegin(id int16) {
+	if id > 0 {
+		c.fieldHeader(id, ctStruct)
+	}
+	c.lastField = append(c.lastField, 0)
+}
+
+func (c *compactWriter) StructEnd() {
+	c.buf = append(c.buf, 0)
+	c.lastField = c.lastField[:len(c.lastField)-1]
+}
+
+func (c *compactWriter) listHeader(id int16, elemType byte, size int) {
+	c.fieldHeader(id, ctList)
+	if size < 15 {
+		c.buf = append(c.buf, byte(size)<<4|elemType)
+	} else {
+		c.buf = append(c.buf, 0xf0|elemType)
+		c.writeUvarint(uint64(size))
+	}
+}
+
+func (c *compactWriter) I32List(id int16, vs []int32) {
+	c.listHeader(id, ctI32, len(vs))
+	for _, v := range vs {
+		c.writeVarint(int64(v))
+	}
+}
+
+func (c *compactWriter) StringList(id int16, vs []string) {
+	c.listHeader(id, ctBinary, len(vs))
+	for _, v := range vs {
+		c.writeUvarint(uint64(len(v)))
+		c.buf = append(c.buf, v...)
+	}
+}
+
+// StructList 写入结构体列表，writeElem负责写入第i个元素的字段
+func (c *compactWriter) StructList(id int16, size int, writeElem func(i int)) {
+	c.listHeader(id, ctStruct, size)
+	for i := 0; i < size; i++ {
+		c.StructBegin(0)
+		writeElem(i)
+		c.StructEnd()
+	}
+}
@@ -0,0 +1,332 @@
+package shellx
+
+import "strings"
+
+type tokenType int8
+
+const (
+	tokenWord     tokenType = iota // 命令名或参数
+	tokenOp                        // 命令分隔符，如 | || & && ; 换行
+	tokenLParen                    // (
+	tokenRParen                    // )
+	tokenRedirect                  // 重定向，如 > >> < << >& &>
+)
+
+type token struct {
+	typ    tokenType
+	val    string
+	assign bool // 是否为未加引号的变量赋值，如 NAME=value
+}
+
+type lexer struct {
+	s   []rune
+	pos int
+
+	tokens []token
+	subs   []string // 命令替换、进程替换中的命令内容
+
+	word       strings.Builder
+	inWord     bool // 当前是否存在未结束的单词(包括空引号'')
+	wordQuoted bool // 当前单词是否包含引号或转义
+}
+
+func newLexer(s string) *lexer {
+	return &lexer{s: []rune(s)}
+}
+
+func (l *lexer) lex() ([]token, []string) {
+	n := len(l.s)
+	for l.pos < n {
+		c := l.s[l.pos]
+		switch c {
+		case ' ', '\t', '\r':
+			l.flush()
+			l.pos++
+		case '\n':
+			l.flush()
+			l.emit(tokenOp, "\n")
+			l.pos++
+		case '#':
+			if l.inWord {
+				l.appendRune(c)
+				l.pos++
+				continue
+			}
+			// 注释
+			for l.pos < n && l.s[l.pos] != '\n' {
+				l.pos++
+			}
+		case ';':
+			l.flush()
+			l.emitOp(";", ";;", ";&")
+		case '|':
+			l.flush()
+			l.emitOp("|", "||", "|&")
+		case '&':
+			l.flush()
+			if l.hasPrefix("&>") {
+				l.emitOp("&>", "&>>")
+				l.tokens[len(l.tokens)-1].typ = tokenRedirect
+				continue
+			}
+			l.emitOp("&", "&&")
+		case '(':
+			l.flush()
+			l.emit(tokenLParen, "(")
+			l.pos++
+		case ')':
+			l.flush()
+			l.emit(tokenRParen, ")")
+			l.pos++
+		case '<', '>':
+			// 进程替换 <(cmd) >(cmd)
+			if l.pos+1 < n && l.s[l.pos+1] == '(' {
+				l.flush()
+				inner, end := l.readBalanced(l.pos + 2)
+				l.subs = append(l.subs, inner)
+				l.pos = end
+				continue
+			}
+			// 文件描述符，如 2>&1 中的2
+			if l.inWord && !l.wordQuoted && isDigits(l.word.String()) {
+				l.resetWord()
+			} else {
+				l.flush()
+			}
+			l.emitOp(string(c), ">>", ">&", ">|", "<<<", "<<-", "<<", "<&", "<>")
+			l.tokens[len(l.tokens)-1].typ = tokenRedirect
+		case '\'':
+			l.markQuoted()
+			end := l.indexFrom(l.pos+1, '\'')
+			l.word.WriteString(string(l.s[l.pos+1 : end]))
+			l.pos = min(end+1, n)
+		case '"':
+			l.markQuoted()
+			l.readDoubleQuoted()
+		case '\\':
+			l.markQuoted()
+			if l.pos+1 < n {
+				// 续行
+				if l.s[l.pos+1] != '\n' {
+					l.word.WriteRune(l.s[l.pos+1])
+				}
+			}
+			l.pos += 2
+		case '$':
+			l.readDollar(false)
+		case '`':
+			l.readBacktick()
+		default:
+			l.appendRune(c)
+			l.pos++
+		}
+	}
+	l.flush()
+	return l.tokens, l.subs
+}
+
+func (l *lexer) appendRune(c rune) {
+	l.inWord = true
+	l.word.WriteRune(c)
+}
+
+func (l *lexer) markQuoted() {
+	l.inWord = true
+	l.wordQuoted = true
+}
+
+func (l *lexer) resetWord() {
+	l.word.Reset()
+	l.inWord = false
+	l.wordQuoted = false
+}
+
+func (l *lexer) flush() {
+	if !l.inWord {
+		return
+	}
+	val := l.word.String()
+	t := token{typ: tokenWord, val: val}
+	if !l.wordQuoted {
+		t.assign = isAssignment(val)
+	}
+	l.tokens = append(l.tokens, t)
+	l.resetWord()
+}
+
+func (l *lexer) emit(typ tokenType, val string) {
+	l.tokens = append(l.tokens, token{typ: typ, val: val})
+}
+
+// emitOp 优先匹配最长的操作符，均未匹配则使用defaultOp
+func (l *lexer) emitOp(defaultOp string, ops ...string) {
+	op := defaultOp
+	for _, o := range ops {
+		if len(o) > len(op) && l.hasPrefix(o) {
+			op = o
+		}
+	}
+	l.emit(tokenOp, op)
+	l.pos += len([]rune(op))
+}
+
+func (l *lexer) hasPrefix(s string) bool {
+	rs := []rune(s)
+	if l.pos+len(rs) > len(l.s) {
+		return false
+	}
+	return string(l.s[l.pos:l.pos+len(rs)]) == s
+}
+
+func (l *lexer) indexFrom(start int, c rune) int {
+	for i := start; i < len(l.s); i++ {
+		if l.s[i] == c {
+			return i
+		}
+	}
+	return len(l.s)
+}
+
+func (l *lexer) readDoubleQuoted() {
+	n := len(l.s)
+	l.pos++
+	for l.pos < n {
+		c := l.s[l.pos]
+		switch c {
+		case '"':
+			l.pos++
+			return
+		case '\\':
+			if l.pos+1 < n {
+				next := l.s[l.pos+1]
+				switch next {
+				case '"', '\\', '$', '`':
+					l.word.WriteRune(next)
+				case '\n':
+				default:
+					l.word.WriteRune(c)
+					l.word.WriteRune(next)
+				}
+			}
+			l.pos += 2
+		case '$':
+			l.readDollar(true)
+		case '`':
+			l.readBacktick()
+		default:
+			l.word.WriteRune(c)
+			l.pos++
+		}
+	}
+}
+
+// readDollar 处理 $(cmd)、$((expr))、${var}、$'str' 等，命令替换中的命令会记录至subs
+func (l *lexer) readDollar(inDoubleQuote bool) {
+	n := len(l.s)
+	l.inWord = true
+	if l.pos+1 >= n {
+		l.word.WriteRune('$')
+		l.pos++
+		return
+	}
+	switch l.s[l.pos+1] {
+	case '(':
+		start := l.pos
+		if l.pos+2 < n && l.s[l.pos+2] == '(' {
+			// 算术展开
+			_, end := l.readBalanced(l.pos + 2)
+			l.word.WriteString(string(l.s[start:min(end, n)]))
+			l.pos = end
+			return
+		}
+		inner, end := l.readBalanced(l.pos + 2)
+		l.subs = append(l.subs, inner)
+		l.word.WriteString(string(l.s[start:min(end, n)]))
+		l.pos = end
+	case '{':
+		end := l.indexFrom(l.pos+2, '}')
+		l.word.WriteString(string(l.s[l.pos:min(end+1, n)]))
+		l.pos = min(end+1, n)
+	case '\'':
+		if inDoubleQuote {
+			l.word.WriteRune('$')
+			l.pos++
+			return
+		}
+		// ANSI-C 引号
+		l.wordQuoted = true
+		i := l.pos + 2
+		for i < n && l.s[i] != '\'' {
+			if l.s[i] == '\\' && i+1 < n {
+				i++
+			}
+			l.word.WriteRune(l.s[i])
+			i++
+		}
+		l.pos = min(i+1, n)
+	default:
+		l.word.WriteRune('$')
+		l.pos++
+	}
+}
+
+// readBacktick 处理 `cmd` 命令替换
+func (l *lexer) readBacktick() {
+	n := len(l.s)
+	l.inWord = true
+	var inner strings.Builder
+	i := l.pos + 1
+	for i < n && l.s[i] != '`' {
+		if l.s[i] == '\\' && i+1 < n {
+			i++
+		}
+		inner.WriteRune(l.s[i])
+		i++
+	}
+	l.subs = append(l.subs, inner.String())
+	l.word.WriteString(string(l.s[l.pos:min(i+1, n)]))
+	l.pos = min(i+1, n)
+}
+
+// readBalanced 从start开始读取至与之配对的右括号，忽略引号中的括号，返回括号内内容及右括号后的位置
+func (l *lexer) readBalanced(start int) (string, int) {
+	n := len(l.s)
+	depth := 1
+	i := start
+	for i < n {
+		c := l.s[i]
+		switch c {
+		case '\\':
+			i++
+		case '\'':
+			i = l.indexFrom(i+1, '\'')
+		case '"':
+			for i++; i < n && l.s[i] != '"'; i++ {
+				if l.s[i] == '\\' {
+					i++
+				}
+			}
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return string(l.s[start:i]), i + 1
+			}
+		}
+		i++
+	}
+	return string(l.s[min(start, n):n]), n
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,27 @@
+package shellx
+
+import "strings"
+
+// Quote 将字符串转义为可安全拼接至shell命令行中的单个参数，如 a'b 转为 'a'\''b'
+func Quote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if isSafeWord(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// isSafeWord 是否仅包含无需转义的字符
+func isSafeWord(s string) bool {
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("-_./:@%+=,", c):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,280 @@
+package shellx
+
+import (
+	"path"
+	"strings"
+)
+
+// Command 解析后的简单命令
+type Command struct {
+	Name string   // 命令名，已去除引号、转义及路径，如 "\rm"、"/bin/rm" 均解析为 rm
+	Args []string // 命令参数，已去除引号及转义，不包含重定向
+}
+
+// String 命令名及参数以单个空格拼接的规范化命令
+func (c *Command) String() string {
+	if len(c.Args) == 0 {
+		return c.Name
+	}
+	return c.Name + " " + strings.Join(c.Args, " ")
+}
+
+// 最大递归解析深度，用于子shell、命令替换、sh -c、alias展开等嵌套场景
+const maxDepth = 8
+
+// ParseCommands 将shell命令行(或脚本内容)解析为实际会执行的简单命令列表。
+// 会拆分管道、命令列表(; && || & 换行)、子shell及命令替换，并展开sudo、env、nohup等前缀命令，
+// sh -c、eval、find -exec 等携带的命令，以及同一命令行中定义的alias。
+// 前缀命令本身也会保留在结果中，如 "sudo rm -rf /" 解析为 [sudo -- rm -rf /, rm -rf /] 两条命令
+func ParseCommands(line string) []*Command {
+	p := &parser{aliases: make(map[string][]string)}
+	p.parse(line, 0)
+	return p.cmds
+}
+
+type parser struct {
+	cmds    []*Command
+	aliases map[string][]string // alias名 -> 展开后的命令词
+}
+
+func (p *parser) parse(line string, depth int) {
+	if depth > maxDepth {
+		return
+	}
+
+	tokens, subs := newLexer(line).lex()
+	// 命令替换、进程替换中的命令
+	for _, sub := range subs {
+		p.parse(sub, depth+1)
+	}
+
+	var words []token
+	skipNext := false
+	for _, t := range tokens {
+		switch t.typ {
+		case tokenWord:
+			// 重定向目标不属于命令参数
+			if skipNext {
+				skipNext = false
+				continue
+			}
+			words = append(words, t)
+		case tokenRedirect:
+			skipNext = true
+		default:
+			p.addSimpleCommand(words, depth)
+			words = nil
+			skipNext = false
+		}
+	}
+	p.addSimpleCommand(words, depth)
+}
+
+func (p *parser) addSimpleCommand(words []token, depth int) {
+	// 跳过命令前的变量赋值，如 LANG=C rm -rf /
+	for len(words) > 0 && words[0].assign {
+		words = words[1:]
+	}
+	args := make([]string, 0, len(words))
+	for _, w := range words {
+		args = append(args, w.val)
+	}
+	p.addCommand(args, depth)
+}
+
+// 保留字，出现在命令开头时跳过
+var reservedWords = map[string]bool{
+	"!": true, "{": true, "}": true, "if": true, "then": true, "else": true, "elif": true, "fi": true,
+	"do": true, "done": true, "while": true, "until": true, "esac": true,
+}
+
+func (p *parser) addCommand(args []string, depth int) {
+	if depth > maxDepth {
+		return
+	}
+	for len(args) > 0 && reservedWords[args[0]] {
+		args = args[1:]
+	}
+	if len(args) == 0 || args[0] == "" {
+		return
+	}
+
+	name := normalizeName(args[0])
+	args = args[1:]
+
+	// 展开同一命令行中定义的alias
+	if expanded, ok := p.aliases[name]; ok {
+		p.addCommand(append(append([]string{}, expanded...), args...), depth+1)
+		return
+	}
+
+	p.cmds = append(p.cmds, &Command{Name: name, Args: args})
+
+	if wrapper, ok := wrapperCmds[name]; ok {
+		p.addCommand(wrapper.innerCmd(p, args, depth), depth+1)
+		return
+	}
+
+	switch name {
+	case "sh", "bash", "zsh", "dash", "ksh", "ash", "fish", "csh", "tcsh", "su", "runuser":
+		if script, ok := shellScriptArg(name, args); ok {
+			p.parse(script, depth+1)
+		}
+	case "eval":
+		p.parse(strings.Join(args, " "), depth+1)
+	case "alias":
+		for _, arg := range args {
+			aliasName, value, ok := strings.Cut(arg, "=")
+			if !ok || aliasName == "" {
+				continue
+			}
+			tokens, _ := newLexer(value).lex()
+			var words []string
+			for _, t := range tokens {
+				if t.typ == tokenWord {
+					words = append(words, t.val)
+				}
+			}
+			p.aliases[aliasName] = words
+			p.parse(value, depth+1)
+		}
+	case "find":
+		// find ... -exec cmd {} ; 或 -execdir、-ok、-okdir
+		for i := 0; i < len(args); i++ {
+			switch args[i] {
+			case "-exec", "-execdir", "-ok", "-okdir":
+				j := i + 1
+				for j < len(args) && args[j] != ";" && args[j] != "+" {
+					j++
+				}
+				p.addCommand(args[i+1:j], depth+1)
+				i = j
+			}
+		}
+	}
+}
+
+// wrapperCmd 执行其参数中命令的前缀命令，如 sudo、env、nohup 等
+type wrapperCmd struct {
+	valueOpts  []string // 需要携带值的选项
+	positional int      // 命令前的固定位置参数个数，如 timeout 的超时时间
+}
+
+var wrapperCmds = map[string]wrapperCmd{
+	"sudo":    {valueOpts: []string{"-u", "-g", "-h", "-p", "-C", "-D", "-r", "-t", "-U", "-T", "--user", "--group", "--host", "--prompt", "--close-from", "--chdir", "--role", "--type", "--other-user", "--command-timeout"}},
+	"doas":    {valueOpts: []string{"-u", "-C"}},
+	"env":     {valueOpts: []string{"-u", "-C", "--unset", "--chdir"}},
+	"nohup":   {},
+	"exec":    {valueOpts: []string{"-a"}},
+	"command": {},
+	"builtin": {},
+	"time":    {valueOpts: []string{"-f", "-o", "--format", "--output"}},
+	"nice":    {valueOpts: []string{"-n", "--adjustment"}},
+	"ionice":  {valueOpts: []string{"-c", "-n", "--class", "--classdata"}},
+	"setsid":  {},
+	"stdbuf":  {valueOpts: []string{"-i", "-o", "-e"}},
+	"timeout": {valueOpts: []string{"-s", "-k", "--signal", "--kill-after"}, positional: 1},
+	"xargs":   {valueOpts: []string{"-a", "-d", "-E", "-I", "-L", "-n", "-P", "-s", "--arg-file", "--delimiter", "--max-args", "--max-procs", "--max-chars", "--replace"}},
+	"chroot":  {valueOpts: []string{"--userspec", "--groups"}, positional: 1},
+	"busybox": {},
+}
+
+// innerCmd 跳过前缀命令的选项及固定参数，返回实际执行的命令及参数
+func (w wrapperCmd) innerCmd(p *parser, args []string, depth int) []string {
+	i := 0
+	for i < len(args) {
+		arg := args[i]
+		if arg == "--" {
+			i++
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			break
+		}
+		// env -S "rm -rf /" 将字符串拆分为命令执行
+		if arg == "-S" || arg == "--split-string" {
+			if i+1 < len(args) {
+				p.parse(args[i+1], depth+1)
+			}
+			i += 2
+			continue
+		}
+		if s, ok := strings.CutPrefix(arg, "--split-string="); ok {
+			p.parse(s, depth+1)
+		}
+		if w.takesValue(arg) {
+			i++
+		}
+		i++
+	}
+	// env、sudo 等命令后可跟变量赋值
+	for i < len(args) && isAssignment(args[i]) {
+		i++
+	}
+	i += w.positional
+	if i >= len(args) {
+		return nil
+	}
+	return args[i:]
+}
+
+func (w wrapperCmd) takesValue(opt string) bool {
+	if strings.Contains(opt, "=") {
+		return false
+	}
+	for _, valueOpt := range w.valueOpts {
+		if opt == valueOpt {
+			return true
+		}
+	}
+	return false
+}
+
+// shellScriptArg 获取 sh -c script、su -c script 等方式执行的脚本内容
+func shellScriptArg(name string, args []string) (string, bool) {
+	for i, arg := range args {
+		if s, ok := strings.CutPrefix(arg, "--command="); ok {
+			return s, true
+		}
+		isCmdOpt := arg == "-c" || arg == "--command"
+		// bash -lc、sh -ec 等组合选项
+		if !isCmdOpt && name != "su" && name != "runuser" && len(arg) > 1 && arg[0] == '-' && arg[1] != '-' {
+			isCmdOpt = strings.Contains(arg[1:], "c")
+		}
+		if !isCmdOpt {
+			continue
+		}
+		for _, scriptArg := range args[i+1:] {
+			if !strings.HasPrefix(scriptArg, "-") {
+				return scriptArg, true
+			}
+		}
+		return "", false
+	}
+	return "", false
+}
+
+func normalizeName(name string) string {
+	if strings.Contains(name, "/") {
+		return path.Base(name)
+	}
+	return name
+}
+
+func isAssignment(s string) bool {
+	name, _, ok := strings.Cut(s, "=")
+	return ok && isVarName(name)
+}
+
+func isVarName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
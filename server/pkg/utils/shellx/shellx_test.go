@@ -0,0 +1,71 @@
+package shellx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func cmdStrs(line string) []string {
+	var res []string
+	for _, cmd := range ParseCommands(line) {
+		res = append(res, cmd.String())
+	}
+	return res
+}
+
+func TestParseCommands(t *testing.T) {
+	testCases := []struct {
+		line string
+		want []string
+	}{
+		{"ls -l", []string{"ls -l"}},
+		{"  rm   -rf   / ", []string{"rm -rf /"}},
+		{`\rm -rf /`, []string{"rm -rf /"}},
+		{`/bin/rm -rf /tmp`, []string{"rm -rf /tmp"}},
+		{`'r'm -rf "/"`, []string{"rm -rf /"}},
+		{"ls | grep a && rm -r /tmp; echo ok", []string{"ls", "grep a", "rm -r /tmp", "echo ok"}},
+		{"ls\nrm -r /tmp", []string{"ls", "rm -r /tmp"}},
+		{"sudo -u root rm -rf /", []string{"sudo -u root rm -rf /", "rm -rf /"}},
+		{"LANG=C env -i FOO=1 rm -f a", []string{"env -i FOO=1 rm -f a", "rm -f a"}},
+		{"nohup timeout 10 rm -r /data &", []string{"nohup timeout 10 rm -r /data", "timeout 10 rm -r /data", "rm -r /data"}},
+		{`bash -c "rm -rf /"`, []string{"bash -c rm -rf /", "rm -rf /"}},
+		{`sh -xc 'cd / && rm -rf *'`, []string{"sh -xc cd / && rm -rf *", "cd /", "rm -rf *"}},
+		{"echo $(rm -rf /) `reboot`", []string{"rm -rf /", "reboot", "echo $(rm -rf /) `reboot`"}},
+		{"(cd /; rm -rf .)", []string{"cd /", "rm -rf ."}},
+		{"rm -rf / > /dev/null 2>&1", []string{"rm -rf /"}},
+		{"alias x='rm -rf'; x /", []string{"alias x=rm -rf", "rm -rf", "rm -rf /"}},
+		{"eval 'rm -rf /'", []string{"eval rm -rf /", "rm -rf /"}},
+		{`find / -name a -exec rm -rf {} \;`, []string{`find / -name a -exec rm -rf {} ;`, "rm -rf {}"}},
+		{"if true; then reboot; fi", []string{"true", "reboot"}},
+		{"echo 'a;b' # rm -rf /", []string{"echo a;b"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.line, func(t *testing.T) {
+			require.Equal(t, tc.want, cmdStrs(tc.line))
+		})
+	}
+}
+
+func TestQuote(t *testing.T) {
+	testCases := []struct {
+		s    string
+		want string
+	}{
+		{"", "''"},
+		{"/var/log/app.log", "/var/log/app.log"},
+		{"a b", "'a b'"},
+		{"a'b", `'a'\''b'`},
+		{"$(reboot)", "'$(reboot)'"},
+		{"x;rm -rf /", "'x;rm -rf /'"},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.want, Quote(tc.s), tc.s)
+		// 转义后解析应还原为原始参数
+		cmds := ParseCommands("echo " + Quote(tc.s))
+		require.Len(t, cmds, 1, tc.s)
+		if tc.s != "" {
+			require.Equal(t, []string{tc.s}, cmds[0].Args, tc.s)
+		}
+	}
+}